@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runBench 实现 bench 子命令: 对运行中的实例 (stdio 或 SSE) 并发发起工具调用并统计延迟和吞吐量
+func runBench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	mode := fs.String("mode", "stdio", "连接方式: stdio (启动子进程) 或 sse (连接运行中的实例)")
+	binary := fs.String("binary", "", "stdio 模式启动的 mcp2rest-stdio 可执行文件，默认为当前程序所在目录下的 mcp2rest-stdio")
+	specPath := fs.String("config", "configs/bmc_api.yaml", "stdio 模式传给服务器的 OpenAPI 规范文件")
+	url := fs.String("url", "http://localhost:8088", "sse 模式的服务器地址")
+	tool := fs.String("tool", "", "调用的工具名称，为空时压测 tools/list")
+	toolArgs := fs.String("args", "{}", "工具参数，JSON 对象")
+	total := fs.Int("n", 1000, "请求总数")
+	concurrency := fs.Int("c", 10, "并发数")
+	timeout := fs.Duration("timeout", 30*time.Second, "单个请求的超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *total <= 0 || *concurrency <= 0 {
+		return fmt.Errorf("-n 和 -c 必须大于 0")
+	}
+
+	method, params := "tools/list", interface{}(map[string]interface{}{})
+	if *tool != "" {
+		var arguments map[string]interface{}
+		if err := json.Unmarshal([]byte(*toolArgs), &arguments); err != nil {
+			return fmt.Errorf("解析 -args 失败: %w", err)
+		}
+		method = "tools/call"
+		params = map[string]interface{}{"name": *tool, "arguments": arguments}
+	}
+
+	var client *rpcClient
+	var err error
+	switch *mode {
+	case "stdio":
+		client, err = dialStdio(stdioBinary(*binary), *specPath)
+	case "sse":
+		client, err = dialSSE(strings.TrimRight(*url, "/"))
+	default:
+		return fmt.Errorf("不支持的连接方式: %s (支持: stdio, sse)", *mode)
+	}
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.initialize(*timeout); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "压测 %s (%s)，请求数 %d，并发 %d\n", method, *mode, *total, *concurrency)
+	stats := runLoad(client, method, params, *total, *concurrency, *timeout)
+	stats.print(os.Stdout)
+	return nil
+}
+
+// benchStats 压测结果
+type benchStats struct {
+	latencies  []time.Duration
+	errors     int64
+	toolErrors int64
+	elapsed    time.Duration
+	firstError string
+}
+
+// runLoad 以固定并发发出 total 个请求
+func runLoad(client *rpcClient, method string, params interface{}, total, concurrency int, timeout time.Duration) *benchStats {
+	stats := &benchStats{latencies: make([]time.Duration, 0, total)}
+	var mu sync.Mutex
+	var next int64
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.AddInt64(&next, 1) <= int64(total) {
+				began := time.Now()
+				result, err := client.call(method, params, timeout)
+				latency := time.Since(began)
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					stats.errors++
+					if stats.firstError == "" {
+						stats.firstError = err.Error()
+					}
+				default:
+					stats.latencies = append(stats.latencies, latency)
+					if isToolError(result) {
+						stats.toolErrors++
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	stats.elapsed = time.Since(start)
+	return stats
+}
+
+// isToolError 判断工具调用结果是否带有 isError 标记
+func isToolError(result json.RawMessage) bool {
+	var body struct {
+		IsError bool `json:"isError"`
+	}
+	return json.Unmarshal(result, &body) == nil && body.IsError
+}
+
+// histogramBounds 延迟直方图的桶上界
+var histogramBounds = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second,
+}
+
+// print 输出吞吐量、延迟分位数和直方图
+func (s *benchStats) print(w *os.File) {
+	completed := len(s.latencies)
+	fmt.Fprintf(w, "完成: %d  失败: %d  工具错误: %d  耗时: %v\n", completed, s.errors, s.toolErrors, s.elapsed.Round(time.Millisecond))
+	if s.firstError != "" {
+		fmt.Fprintf(w, "首个错误: %s\n", s.firstError)
+	}
+	if completed == 0 {
+		return
+	}
+	fmt.Fprintf(w, "吞吐量: %.1f 请求/秒\n", float64(completed)/s.elapsed.Seconds())
+
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	var sum time.Duration
+	for _, latency := range s.latencies {
+		sum += latency
+	}
+	fmt.Fprintf(w, "延迟: 最小 %v  平均 %v  p50 %v  p90 %v  p99 %v  最大 %v\n",
+		s.latencies[0], sum/time.Duration(completed),
+		s.percentile(50), s.percentile(90), s.percentile(99), s.latencies[completed-1])
+
+	counts := make([]int, len(histogramBounds)+1)
+	for _, latency := range s.latencies {
+		bucket := sort.Search(len(histogramBounds), func(i int) bool { return latency <= histogramBounds[i] })
+		counts[bucket]++
+	}
+
+	fmt.Fprintln(w, "\n延迟分布:")
+	maxCount := 0
+	for _, count := range counts {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		label := "> " + histogramBounds[len(histogramBounds)-1].String()
+		if i < len(histogramBounds) {
+			label = "<= " + histogramBounds[i].String()
+		}
+		bar := strings.Repeat("█", (count*40+maxCount-1)/maxCount)
+		fmt.Fprintf(w, "  %-8s %6d %5.1f%% %s\n", label, count, float64(count)*100/float64(completed), bar)
+	}
+}
+
+// percentile 返回已排序延迟的分位数
+func (s *benchStats) percentile(p int) time.Duration {
+	index := (len(s.latencies)*p+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return s.latencies[index]
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mcp2rest/internal/framing"
+	"github.com/mcp2rest/internal/process"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// rpcClient 压测用的 JSON-RPC 客户端，按请求 ID 匹配并发请求的响应
+type rpcClient struct {
+	send  func(data []byte) error
+	close func() error
+
+	seq     uint64
+	mu      sync.Mutex
+	pending map[string]chan *mcp.MCPResponse
+}
+
+func newRPCClient() *rpcClient {
+	return &rpcClient{pending: make(map[string]chan *mcp.MCPResponse)}
+}
+
+// call 发送请求并等待响应
+func (c *rpcClient) call(method string, params interface{}, timeout time.Duration) (json.RawMessage, error) {
+	id := fmt.Sprintf("bench-%d", atomic.AddUint64(&c.seq, 1))
+	request, err := mcp.NewRequest(id, method, params)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseChan := make(chan *mcp.MCPResponse, 1)
+	c.mu.Lock()
+	c.pending[id] = responseChan
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(data); err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+
+	select {
+	case response := <-responseChan:
+		if response.Error != nil {
+			return nil, fmt.Errorf("%d %s", response.Error.Code, response.Error.Message)
+		}
+		return response.Result, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("请求超时 (%v)", timeout)
+	}
+}
+
+// notify 发送通知
+func (c *rpcClient) notify(method string) error {
+	notification, err := mcp.NewNotification(method, map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return c.send(data)
+}
+
+// deliver 把服务器消息交给等待中的请求，忽略通知和服务器发起的请求
+func (c *rpcClient) deliver(data []byte) {
+	var response mcp.MCPResponse
+	if err := json.Unmarshal(data, &response); err != nil || response.ID == nil {
+		return
+	}
+	c.mu.Lock()
+	responseChan, exists := c.pending[response.GetIDString()]
+	c.mu.Unlock()
+	if exists {
+		responseChan <- &response
+	}
+}
+
+// initialize 完成 MCP 握手
+func (c *rpcClient) initialize(timeout time.Duration) error {
+	params := map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "mcp2rest-bench", "version": "1.0.0"},
+	}
+	if _, err := c.call("initialize", params, timeout); err != nil {
+		return fmt.Errorf("初始化失败: %w", err)
+	}
+	return c.notify("notifications/initialized")
+}
+
+// Close 关闭连接
+func (c *rpcClient) Close() error {
+	return c.close()
+}
+
+// dialStdio 启动 stdio 服务器子进程
+func dialStdio(binary, specPath string) (*rpcClient, error) {
+	cmd := exec.Command(binary, "-config", specPath)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建标准输入管道失败: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建标准输出管道失败: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动 %s 失败: %w", binary, err)
+	}
+
+	client := newRPCClient()
+	var writeMu sync.Mutex
+	client.send = func(data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := stdin.Write(framing.Encode(framing.NDJSON, data))
+		return err
+	}
+	client.close = func() error {
+		return process.Shutdown(cmd, stdin, 5*time.Second)
+	}
+
+	go func() {
+		reader := framing.NewReader(bufio.NewReaderSize(stdout, 64*1024), framing.NDJSON)
+		for {
+			message, err := reader.ReadMessage()
+			if err != nil {
+				return
+			}
+			client.deliver(message)
+		}
+	}()
+	return client, nil
+}
+
+// dialSSE 连接运行中的 SSE 服务器，等待消息端点后返回
+func dialSSE(baseURL string) (*rpcClient, error) {
+	resp, err := http.Get(baseURL + "/sse")
+	if err != nil {
+		return nil, fmt.Errorf("建立 SSE 连接失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("建立 SSE 连接失败: HTTP %d", resp.StatusCode)
+	}
+
+	client := newRPCClient()
+	endpoint := make(chan string, 1)
+	go readSSE(resp.Body, func(event, data string) {
+		switch event {
+		case "endpoint":
+			endpoint <- data
+		case "message":
+			client.deliver([]byte(data))
+		}
+	})
+
+	select {
+	case path := <-endpoint:
+		messagesURL := baseURL + path
+		client.send = func(data []byte) error {
+			resp, err := http.Post(messagesURL, "application/json", bytes.NewReader(data))
+			if err != nil {
+				return err
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("HTTP %d", resp.StatusCode)
+			}
+			return nil
+		}
+		client.close = resp.Body.Close
+		return client, nil
+	case <-time.After(10 * time.Second):
+		resp.Body.Close()
+		return nil, fmt.Errorf("等待 SSE 消息端点超时")
+	}
+}
+
+// readSSE 解析 SSE 事件流
+func readSSE(body io.Reader, handle func(event, data string)) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	event, data := "", []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				handle(event, strings.Join(data, "\n"))
+			}
+			event, data = "", data[:0]
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
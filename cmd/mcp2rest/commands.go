@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+)
+
+// command 表示 mcp2rest 的子命令
+type command struct {
+	name        string
+	description string // 描述的消息代码
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{name: "import", description: "cli.command.import", run: runImport},
+	{name: "export", description: "cli.command.export", run: runExport},
+	{name: "presets", description: "cli.command.presets", run: runPresets},
+	{name: "bench", description: "cli.command.bench", run: runBench},
+	{name: "diff", description: "cli.command.diff", run: runDiff},
+	{name: "report", description: "cli.command.report", run: runReport},
+	{name: "env", description: "cli.command.env", run: runEnv},
+	{name: "secrets", description: "cli.command.secrets", run: runSecrets},
+}
+
+// runCommand 执行子命令，返回进程退出码；不是子命令时 ok 为 false
+func runCommand(args []string) (code int, ok bool) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return 0, false
+	}
+
+	// 命令行输出的语言由 MCP2REST_LOCALE 或 LANG 等环境变量决定
+	i18n.SetDefault(i18n.FromEnvironment())
+
+	if args[0] == "help" {
+		printCommands()
+		return 0, true
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			if err := cmd.run(args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%s\n\n", msg("cli.unknown_command", args[0]))
+	printCommands()
+	return 2, true
+}
+
+func printCommands() {
+	fmt.Fprintln(os.Stderr, msg("cli.usage"))
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, msg(cmd.description))
+	}
+}
+
+// msg 返回命令行输出使用的消息
+func msg(code string, args ...interface{}) string {
+	return i18n.T(i18n.Default(), code, args...)
+}
+
+// errorf 按命令行输出的语言创建错误
+func errorf(code string, args ...interface{}) error {
+	return fmt.Errorf(i18n.Message(i18n.Default(), code), args...)
+}
+
+// writeOutput 将结果写入文件，路径为空或 "-" 时写入标准输出
+func writeOutput(path string, data []byte) error {
+	if path == "" || path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return errorf("cli.write_failed", path, err)
+	}
+	fmt.Fprintln(os.Stderr, msg("cli.written", path))
+	return nil
+}
+
+// loadCommandConfig 加载生成工具列表使用的服务器配置，路径为空时使用默认配置
+func loadCommandConfig(path string) (*config.Config, error) {
+	if path == "" {
+		server, global := config.GetDefaultServerConfig()
+		return &config.Config{Server: *server, Global: *global}, nil
+	}
+	server, global, err := config.LoadServerConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return &config.Config{Server: *server, Global: *global}, nil
+}
+
+// discardLogger 丢弃所有输出的日志器
+func discardLogger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
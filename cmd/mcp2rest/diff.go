@@ -0,0 +1,406 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// diffTool 工具及其对应的操作，分组、目录和组合工具没有操作
+type diffTool struct {
+	Name      string                 `json:"name"`
+	Operation string                 `json:"operation,omitempty"`
+	tool      map[string]interface{} // 工具列表中的定义
+}
+
+// schemaChange 一处工具定义的变化，Breaking 表示按旧定义调用的智能体可能失败
+type schemaChange struct {
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+	Breaking bool   `json:"breaking"`
+}
+
+// toolChange 名称相同或操作相同 (重命名) 的工具的变化
+type toolChange struct {
+	Name        string         `json:"name"`
+	RenamedFrom string         `json:"renamedFrom,omitempty"`
+	Operation   string         `json:"operation,omitempty"`
+	Changes     []schemaChange `json:"changes"`
+	Breaking    bool           `json:"breaking"`
+}
+
+// specDiff 两个规范生成的工具列表之间的差异
+type specDiff struct {
+	Old      string       `json:"old"`
+	New      string       `json:"new"`
+	Added    []diffTool   `json:"added"`
+	Removed  []diffTool   `json:"removed"`
+	Changed  []toolChange `json:"changed"`
+	Breaking int          `json:"breaking"`
+}
+
+// runDiff 实现 diff 子命令: 比较两个规范生成的工具列表，报告新增、删除、重命名的工具和输入参数的不兼容变化
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	serverConfig := fs.String("server-config", "", "服务器配置文件，工具列表受 hide_deprecated、tool_budget、catalog、workflows 等设置影响，默认使用默认配置")
+	format := fs.String("format", "text", "输出格式: text 或 json")
+	failOnBreaking := fs.Bool("fail-on-breaking", false, "存在不兼容的变化时以退出码 1 结束，便于在 CI 中检查")
+	output := fs.String("o", "", msg("cli.flag_output"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("用法: mcp2rest diff [-server-config stdio.yaml] [-format text|json] [-fail-on-breaking] <旧规范> <新规范>")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("不支持的输出格式: %s (可选 text、json)", *format)
+	}
+
+	cfg, err := loadCommandConfig(*serverConfig)
+	if err != nil {
+		return err
+	}
+
+	// 创建处理器时的日志不输出
+	logging.Logger = discardLogger()
+	oldTools, err := specTools(fs.Arg(0), cfg)
+	if err != nil {
+		return err
+	}
+	newTools, err := specTools(fs.Arg(1), cfg)
+	if err != nil {
+		return err
+	}
+
+	diff := diffTools(oldTools, newTools)
+	diff.Old, diff.New = fs.Arg(0), fs.Arg(1)
+
+	var out []byte
+	if *format == "json" {
+		out, err = marshalConfig(diff)
+		if err != nil {
+			return err
+		}
+	} else {
+		out = []byte(diff.text())
+	}
+	if err := writeOutput(*output, out); err != nil {
+		return err
+	}
+	if *failOnBreaking && diff.Breaking > 0 {
+		return fmt.Errorf("存在 %d 处不兼容的变化", diff.Breaking)
+	}
+	return nil
+}
+
+// specTools 加载规范并返回智能体看到的工具列表，按名称索引
+func specTools(specPath string, cfg *config.Config) (map[string]*diffTool, error) {
+	_, spec, err := loadExportSpec(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载 %s 失败: %w", specPath, err)
+	}
+	h, err := handler.NewRequestHandler(cfg, spec)
+	if err != nil {
+		return nil, fmt.Errorf("为 %s 生成工具列表失败: %w", specPath, err)
+	}
+
+	tools := make(map[string]*diffTool)
+	for _, tool := range h.GetAvailableTools() {
+		name, _ := tool["name"].(string)
+		entry := &diffTool{Name: name, tool: tool}
+		if _, method, path, err := openapi.GetOperationByID(spec, name); err == nil {
+			entry.Operation = method + " " + path
+		}
+		tools[name] = entry
+	}
+	return tools, nil
+}
+
+// diffTools 比较两个工具列表，操作相同而名称不同的工具视为重命名
+func diffTools(oldTools, newTools map[string]*diffTool) *specDiff {
+	diff := &specDiff{Added: []diffTool{}, Removed: []diffTool{}, Changed: []toolChange{}}
+
+	removed := make(map[string]*diffTool)
+	for name, tool := range oldTools {
+		if _, exists := newTools[name]; !exists {
+			removed[name] = tool
+		}
+	}
+	removedByOperation := make(map[string]*diffTool)
+	for _, tool := range removed {
+		if tool.Operation != "" {
+			removedByOperation[tool.Operation] = tool
+		}
+	}
+
+	for name, tool := range newTools {
+		if old, exists := oldTools[name]; exists {
+			if change := compareTools(old, tool); len(change.Changes) > 0 {
+				diff.Changed = append(diff.Changed, change)
+			}
+			continue
+		}
+		if old := removedByOperation[tool.Operation]; tool.Operation != "" && old != nil {
+			delete(removed, old.Name)
+			delete(removedByOperation, tool.Operation)
+			change := compareTools(old, tool)
+			change.RenamedFrom = old.Name
+			change.Breaking = true
+			diff.Changed = append(diff.Changed, change)
+			continue
+		}
+		diff.Added = append(diff.Added, *tool)
+	}
+	for _, tool := range removed {
+		diff.Removed = append(diff.Removed, *tool)
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Name < diff.Added[j].Name })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Name < diff.Removed[j].Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+
+	// 删除和重命名的工具各算一处，变更的工具按不兼容的参数变化计数
+	diff.Breaking = len(diff.Removed)
+	for _, change := range diff.Changed {
+		if change.RenamedFrom != "" {
+			diff.Breaking++
+		}
+		for _, c := range change.Changes {
+			if c.Breaking {
+				diff.Breaking++
+			}
+		}
+	}
+	return diff
+}
+
+// compareTools 比较同一工具的新旧定义
+func compareTools(old, tool *diffTool) toolChange {
+	change := toolChange{Name: tool.Name, Operation: tool.Operation, Changes: []schemaChange{}}
+	if old.Operation != "" && tool.Operation != "" && old.Operation != tool.Operation {
+		change.Changes = append(change.Changes, schemaChange{
+			Message: fmt.Sprintf("对应的操作由 %s 改为 %s", old.Operation, tool.Operation),
+		})
+	}
+	for _, field := range []string{"title", "description"} {
+		if !reflect.DeepEqual(old.tool[field], tool.tool[field]) {
+			change.Changes = append(change.Changes, schemaChange{Message: fmt.Sprintf("%s 已更改", field)})
+		}
+	}
+	if !reflect.DeepEqual(old.tool["annotations"], tool.tool["annotations"]) {
+		change.Changes = append(change.Changes, schemaChange{Message: "annotations 已更改"})
+	}
+
+	oldSchema, _ := old.tool["inputSchema"].(map[string]interface{})
+	newSchema, _ := tool.tool["inputSchema"].(map[string]interface{})
+	compareSchema("", oldSchema, newSchema, &change.Changes)
+
+	for _, c := range change.Changes {
+		change.Breaking = change.Breaking || c.Breaking
+	}
+	return change
+}
+
+// compareSchema 比较输入模式，递归比较对象的属性和数组的元素
+// 不兼容的变化: 删除参数、新增必需参数、可选改为必需、类型改变、删除枚举值
+func compareSchema(path string, old, schema map[string]interface{}, changes *[]schemaChange) {
+	add := func(path, message string, breaking bool) {
+		*changes = append(*changes, schemaChange{Path: path, Message: message, Breaking: breaking})
+	}
+
+	if oldType, newType := schemaType(old), schemaType(schema); path != "" && oldType != newType {
+		add(path, fmt.Sprintf("类型由 %s 改为 %s", oldType, newType), true)
+		return
+	}
+	if path != "" {
+		removedValues, addedValues := diffValues(old["enum"], schema["enum"])
+		if len(removedValues) > 0 {
+			add(path, "删除了枚举值 "+strings.Join(removedValues, ", "), true)
+		}
+		if len(addedValues) > 0 {
+			add(path, "新增了枚举值 "+strings.Join(addedValues, ", "), false)
+		}
+		if !reflect.DeepEqual(old["default"], schema["default"]) {
+			add(path, fmt.Sprintf("默认值由 %v 改为 %v", old["default"], schema["default"]), false)
+		}
+		if !reflect.DeepEqual(old["description"], schema["description"]) {
+			add(path, "描述已更改", false)
+		}
+	}
+
+	oldProperties, _ := old["properties"].(map[string]interface{})
+	newProperties, _ := schema["properties"].(map[string]interface{})
+	oldRequired, newRequired := requiredSet(old), requiredSet(schema)
+	for _, name := range sortedKeys(oldProperties, newProperties) {
+		propertyPath := joinSchemaPath(path, name)
+		oldProperty, inOld := oldProperties[name].(map[string]interface{})
+		newProperty, inNew := newProperties[name].(map[string]interface{})
+		switch {
+		case !inNew:
+			add(propertyPath, "参数已删除", true)
+		case !inOld:
+			if newRequired[name] {
+				add(propertyPath, "新增必需参数", true)
+			} else {
+				add(propertyPath, "新增可选参数", false)
+			}
+		default:
+			if newRequired[name] && !oldRequired[name] {
+				add(propertyPath, "由可选改为必需", true)
+			} else if oldRequired[name] && !newRequired[name] {
+				add(propertyPath, "由必需改为可选", false)
+			}
+			compareSchema(propertyPath, oldProperty, newProperty, changes)
+		}
+	}
+
+	oldItems, _ := old["items"].(map[string]interface{})
+	newItems, _ := schema["items"].(map[string]interface{})
+	if oldItems != nil && newItems != nil {
+		compareSchema(path+"[]", oldItems, newItems, changes)
+	}
+}
+
+func schemaType(schema map[string]interface{}) string {
+	if t, ok := schema["type"].(string); ok {
+		return t
+	}
+	return fmt.Sprintf("%v", schema["type"])
+}
+
+// requiredSet 返回模式中的必需属性，兼容 []string 和解析 JSON 得到的 []interface{}
+func requiredSet(schema map[string]interface{}) map[string]bool {
+	set := make(map[string]bool)
+	switch required := schema["required"].(type) {
+	case []string:
+		for _, name := range required {
+			set[name] = true
+		}
+	case []interface{}:
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				set[s] = true
+			}
+		}
+	}
+	return set
+}
+
+// diffValues 比较两个枚举，返回删除和新增的值
+func diffValues(old, values interface{}) (removed, added []string) {
+	oldSet, newSet := valueSet(old), valueSet(values)
+	for value := range oldSet {
+		if !newSet[value] {
+			removed = append(removed, value)
+		}
+	}
+	for value := range newSet {
+		if !oldSet[value] {
+			added = append(added, value)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return removed, added
+}
+
+func valueSet(values interface{}) map[string]bool {
+	set := make(map[string]bool)
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice {
+		return set
+	}
+	for i := 0; i < v.Len(); i++ {
+		encoded, _ := json.Marshal(v.Index(i).Interface())
+		set[string(encoded)] = true
+	}
+	return set
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// text 以文本格式输出差异，不兼容的变化以 ! 标记
+func (d *specDiff) text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "工具列表变化: %s -> %s\n", d.Old, d.New)
+	if len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 {
+		sb.WriteString("\n没有变化\n")
+		return sb.String()
+	}
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(&sb, "\n新增 %d 个工具:\n", len(d.Added))
+		for _, tool := range d.Added {
+			fmt.Fprintf(&sb, "  + %s%s\n", tool.Name, operationSuffix(tool.Operation))
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(&sb, "\n删除 %d 个工具:\n", len(d.Removed))
+		for _, tool := range d.Removed {
+			fmt.Fprintf(&sb, "! - %s%s\n", tool.Name, operationSuffix(tool.Operation))
+		}
+	}
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(&sb, "\n变更 %d 个工具:\n", len(d.Changed))
+		for _, change := range d.Changed {
+			mark := " "
+			if change.Breaking {
+				mark = "!"
+			}
+			name := change.Name
+			if change.RenamedFrom != "" {
+				name = change.RenamedFrom + " -> " + change.Name + " (重命名)"
+			}
+			fmt.Fprintf(&sb, "%s ~ %s%s\n", mark, name, operationSuffix(change.Operation))
+			for _, c := range change.Changes {
+				mark := " "
+				if c.Breaking {
+					mark = "!"
+				}
+				if c.Path != "" {
+					fmt.Fprintf(&sb, "%s     %s: %s\n", mark, c.Path, c.Message)
+				} else {
+					fmt.Fprintf(&sb, "%s     %s\n", mark, c.Message)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n新增 %d，删除 %d，变更 %d，不兼容的变化 %d 处\n", len(d.Added), len(d.Removed), len(d.Changed), d.Breaking)
+	return sb.String()
+}
+
+func operationSuffix(operation string) string {
+	if operation == "" {
+		return ""
+	}
+	return " (" + operation + ")"
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/diagnostics"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// runEnv 实现 env 子命令: 列出规范和服务器配置需要的环境变量 (认证密钥、令牌)，可以检查是否已设置或生成 .env.example
+func runEnv(args []string) error {
+	fs := flag.NewFlagSet("env", flag.ContinueOnError)
+	specPath := fs.String("config", "configs/bmc_api.yaml", msg("cli.export.flag_config"))
+	serverConfig := fs.String("server-config", "", "服务器配置文件，认证覆盖 (auth、auth_config) 以及 GraphQL/gRPC 后端、入站客户端和结果摘要的凭据来自该文件，默认使用默认配置")
+	all := fs.Bool("all", false, "同时列出覆盖配置的 MCP2REST_* 等可选变量")
+	check := fs.Bool("check", false, "检查需要的变量是否已设置 (包括 .env 和 .env.enc 中的)，有缺少的变量时退出码为 1")
+	example := fs.String("example", "", "生成 .env.example 写入该文件，\"-\" 表示写入标准输出")
+	format := fs.String("format", "text", "输出格式: text 或 json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("不支持的输出格式: %s (可选 text、json)", *format)
+	}
+
+	// 与服务器启动时相同，.env 中的变量视为已设置，其中的 MCP2REST_* 同样覆盖服务器配置
+	if err := config.LoadEnvFile(""); err != nil {
+		fmt.Fprintf(os.Stderr, "加载 .env 失败: %v\n", err)
+	}
+	if _, err := config.LoadSecretsFile(""); err != nil {
+		fmt.Fprintf(os.Stderr, "加载加密凭据文件失败: %v\n", err)
+	}
+
+	cfg, err := loadCommandConfig(*serverConfig)
+	if err != nil {
+		return err
+	}
+	_, spec, err := loadExportSpec(*specPath)
+	if err != nil {
+		return fmt.Errorf("加载 %s 失败: %w", *specPath, err)
+	}
+
+	openapi.ApplyAuthConfigs(spec, cfg.Global.Auth)
+	vars := diagnostics.EnvVars(spec, &cfg.Global, *all)
+	if *example != "" {
+		return writeOutput(*example, diagnostics.EnvExample(spec.Info.Title, vars))
+	}
+
+	if *format == "json" {
+		out, err := marshalConfig(vars)
+		if err != nil {
+			return err
+		}
+		if err := writeOutput("", out); err != nil {
+			return err
+		}
+	} else {
+		printEnvVars(vars)
+	}
+
+	if *check {
+		var missing []string
+		for _, v := range vars {
+			if v.Required && !v.Set {
+				missing = append(missing, v.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("缺少环境变量: %s", strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// printEnvVars 以表格输出环境变量是否已设置，不输出取值
+func printEnvVars(vars []diagnostics.EnvVar) {
+	if len(vars) == 0 {
+		fmt.Println("不需要环境变量")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "变量\t状态\t说明\t来源")
+	for _, v := range vars {
+		status := "未设置"
+		if v.Set {
+			status = "已设置"
+		}
+		if !v.Required {
+			status += " (可选)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Name, status, v.Description, v.Source)
+	}
+	w.Flush()
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/diagnostics"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/internal/paths"
+)
+
+// loadExportSpec 加载规范并返回客户端配置中使用的路径：规范文件使用绝对路径，预置保持 preset:<名称>
+func loadExportSpec(specPath string) (string, *config.OpenAPISpec, error) {
+	data, isPreset, err := config.PresetSpec(specPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if isPreset {
+		spec, err := openapi.ParseOpenAPISpecData(data, "yaml")
+		return specPath, spec, err
+	}
+
+	absSpec, err := filepath.Abs(paths.Resolve(specPath))
+	if err != nil {
+		return "", nil, errorf("cli.export.abs_path_failed", err)
+	}
+	spec, err := openapi.ParseOpenAPISpec(absSpec)
+	return absSpec, spec, err
+}
+
+// runExport 实现 export 子命令，为常见 MCP 客户端生成配置片段
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	specPath := fs.String("config", "configs/bmc_api.yaml", msg("cli.export.flag_config"))
+	serverConfig := fs.String("server-config", "", msg("cli.export.flag_server_config"))
+	client := fs.String("client", "claude", msg("cli.export.flag_client"))
+	name := fs.String("name", "mcp2rest", msg("cli.export.flag_name"))
+	binary := fs.String("binary", "", msg("cli.export.flag_binary"))
+	output := fs.String("o", "", msg("cli.flag_output"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	absSpec, spec, err := loadExportSpec(*specPath)
+	if err != nil {
+		return err
+	}
+
+	if *serverConfig == "" {
+		*serverConfig = "configs/stdio.yaml"
+		if *client == "sse" {
+			*serverConfig = "configs/sse.yaml"
+		}
+	}
+	server, global, err := config.LoadServerConfig(*serverConfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg("cli.export.server_config_default", err))
+		server, global = config.GetDefaultServerConfig()
+	}
+
+	openapi.ApplyAuthConfigs(spec, global.Auth)
+	env := diagnostics.RequiredEnv(spec, global)
+
+	var out []byte
+	switch *client {
+	case "claude", "cursor":
+		// Claude Desktop (claude_desktop_config.json) 和 Cursor (.cursor/mcp.json) 使用相同的格式
+		entry := map[string]interface{}{
+			"command": stdioBinary(*binary),
+			"args":    []string{"-config", absSpec},
+		}
+		if len(env) > 0 {
+			entry["env"] = placeholderEnv(env)
+		}
+		out, err = marshalConfig(map[string]interface{}{
+			"mcpServers": map[string]interface{}{*name: entry},
+		})
+	case "vscode":
+		// VS Code (.vscode/mcp.json) 通过 inputs 在首次启动时提示输入凭据
+		entry := map[string]interface{}{
+			"type":    "stdio",
+			"command": stdioBinary(*binary),
+			"args":    []string{"-config", absSpec},
+		}
+		doc := map[string]interface{}{
+			"servers": map[string]interface{}{*name: entry},
+		}
+		if len(env) > 0 {
+			values := make(map[string]string, len(env))
+			inputs := make([]map[string]interface{}, 0, len(env))
+			for _, key := range env {
+				values[key] = "${input:" + key + "}"
+				inputs = append(inputs, map[string]interface{}{
+					"type":        "promptString",
+					"id":          key,
+					"description": key,
+					"password":    true,
+				})
+			}
+			entry["env"] = values
+			doc["inputs"] = inputs
+		}
+		out, err = marshalConfig(doc)
+	case "stdio":
+		// 通用的 stdio 启动命令
+		var sb strings.Builder
+		for _, key := range env {
+			sb.WriteString(key + "=" + shellQuote("<"+key+">") + " ")
+		}
+		sb.WriteString(shellQuote(stdioBinary(*binary)) + " -config " + shellQuote(absSpec) + "\n")
+		out = []byte(sb.String())
+	case "sse":
+		host := server.Host
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		out, err = marshalConfig(map[string]interface{}{
+			"mcpServers": map[string]interface{}{
+				*name: map[string]interface{}{
+					"url": fmt.Sprintf("http://%s:%d/sse", host, server.Port),
+				},
+			},
+		})
+		if err == nil && len(env) > 0 {
+			fmt.Fprintln(os.Stderr, msg("cli.export.sse_env", strings.Join(env, ", ")))
+		}
+	default:
+		return errorf("cli.export.unsupported_client", *client)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*output, out)
+}
+
+// placeholderEnv 生成环境变量占位符，不导出当前环境中的实际凭据
+func placeholderEnv(keys []string) map[string]string {
+	env := make(map[string]string, len(keys))
+	for _, key := range keys {
+		env[key] = "<" + key + ">"
+	}
+	return env
+}
+
+// stdioBinary 返回 mcp2rest-stdio 的绝对路径
+func stdioBinary(binary string) string {
+	if binary == "" {
+		name := "mcp2rest-stdio"
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		binary = name
+		if exePath, err := os.Executable(); err == nil {
+			binary = filepath.Join(filepath.Dir(exePath), name)
+		}
+		if _, err := os.Stat(binary); err != nil {
+			fmt.Fprintln(os.Stderr, msg("cli.export.binary_missing", binary))
+		}
+	}
+	if abs, err := filepath.Abs(binary); err == nil {
+		return abs
+	}
+	return binary
+}
+
+func marshalConfig(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, errorf("cli.export.generate_failed", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t'\"$`\\!*?;&|<>()") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mcp2rest/internal/importer"
+)
+
+// runImport 实现 import har / import curl 子命令
+func runImport(args []string) error {
+	if len(args) == 0 || (args[0] != "har" && args[0] != "curl") {
+		return errorf("cli.import.usage")
+	}
+	source := args[0]
+
+	fs := flag.NewFlagSet("import "+source, flag.ContinueOnError)
+	output := fs.String("o", "", msg("cli.flag_output"))
+	title := fs.String("title", "", msg("cli.import.flag_title"))
+	host := fs.String("host", "", msg("cli.import.flag_host"))
+	prefix := fs.String("prefix", "", msg("cli.import.flag_prefix"))
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errorf("cli.import.missing_input")
+	}
+
+	var samples []importer.Sample
+	switch source {
+	case "har":
+		data, err := readInput(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		samples, err = importer.ParseHAR(data, importer.HARFilter{Host: *host, PathPrefix: *prefix})
+		if err != nil {
+			return err
+		}
+	case "curl":
+		// 参数可以是 curl 命令本身，也可以是包含多条命令的文件
+		text := strings.Join(fs.Args(), " ")
+		if fs.NArg() == 1 && !strings.HasPrefix(strings.TrimSpace(text), "curl ") {
+			data, err := readInput(fs.Arg(0))
+			if err != nil {
+				return err
+			}
+			text = string(data)
+		} else if fs.NArg() > 1 && fs.Arg(0) != "curl" {
+			text = "curl " + text
+		}
+
+		for _, cmd := range importer.SplitCurlCommands(text) {
+			sample, err := importer.ParseCurl(cmd)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, sample)
+		}
+	}
+
+	spec, err := importer.BuildSpec(samples, importer.Options{
+		Title:       *title,
+		Description: fmt.Sprintf("由 mcp2rest import %s 从 %d 个请求生成，请检查推断出的参数和类型", source, len(samples)),
+	})
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, spec)
+}
+
+// readInput 读取文件，路径为 "-" 时读取标准输入
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errorf("cli.read_failed", path, err)
+	}
+	return data, nil
+}
@@ -1,21 +1,40 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/mcp2rest/internal/config"
 	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/diagnostics"
 	"github.com/mcp2rest/internal/logging"
 	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/internal/paths"
+	"github.com/mcp2rest/internal/process"
 	"github.com/mcp2rest/internal/server"
 )
 
 func main() {
+	// 子命令 (import 等) 不启动服务器
+	if code, ok := runCommand(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
+	// 命令行参数，需要在查找 .env 和日志目录之前解析基础目录
+	openAPIPath := flag.String("config", "configs/bmc_api.yaml", "OpenAPI规范文件路径")
+	tags := flag.String("tags", "", "只加载带有这些标签的操作，逗号分隔 (覆盖服务器配置中的 global.tags)")
+	excludeTags := flag.String("exclude-tags", "", "不加载带有这些标签的操作，逗号分隔 (覆盖服务器配置中的 global.exclude_tags)")
+	dryRun := flag.Bool("dry-run", false, "不请求上游，返回规范中的示例响应 (覆盖服务器配置中的 global.dry_run)")
+	baseDir := flag.String("base-dir", "", "基础目录，配置、规范、.env 和日志的相对路径都基于该目录解析 (也可以通过 MCP2REST_HOME 设置)")
+	diagnose := flag.Bool("diagnostics", false, "运行启动自检，把 JSON 报告写入标准输出后退出，有检查失败时退出码为 1")
+	flag.Parse()
+	paths.SetBaseDir(*baseDir)
+
 	// 自动加载 .env 文件
 	if err := config.LoadEnvFileWithLog(""); err != nil {
 		log.Printf("加载环境变量文件失败: %v", err)
@@ -23,7 +42,11 @@ func main() {
 
 	// 初始化日志
 	if err := logging.InitLogger(); err != nil {
-		log.Fatalf("初始化日志失败: %v", err)
+		if !*diagnose {
+			log.Fatalf("初始化日志失败: %v", err)
+		}
+		// 自检时日志目录不可写由 log_dir 检查报告，日志改为输出到标准错误
+		logging.InitServiceLogger()
 	}
 
 	// 初始化调试模式
@@ -37,10 +60,7 @@ func main() {
 	logging.Logger.Printf("环境变量 PATH: %s", os.Getenv("PATH"))
 	logging.Logger.Printf("环境变量 GOPATH: %s", os.Getenv("GOPATH"))
 
-	// 命令行参数
-	openAPIPath := flag.String("config", "configs/bmc_api.yaml", "OpenAPI规范文件路径")
-	flag.Parse()
-	logging.Logger.Printf("命令行参数: config=%s", *openAPIPath)
+	logging.Logger.Printf("命令行参数: config=%s, base-dir=%s", *openAPIPath, paths.BaseDir())
 
 	// 注册OpenAPI加载器
 	loader := openapi.NewLoader()
@@ -50,17 +70,34 @@ func main() {
 	logging.Logger.Printf("开始加载OpenAPI规范: %s", *openAPIPath)
 	cfg, spec, err := config.LoadConfigWithOpenAPI(*openAPIPath)
 	if err != nil {
+		if *diagnose {
+			diagnostics.ExitWithError(*openAPIPath, fmt.Errorf("加载配置失败: %w", err))
+		}
 		logging.Logger.Fatalf("加载配置失败: %v", err)
 	}
 	logging.Logger.Printf("配置加载成功: 模式=%s, 主机=%s, 端口=%d", cfg.Server.Mode, cfg.Server.Host, cfg.Server.Port)
 	logging.Logger.Printf("OpenAPI规范: %s v%s", spec.Info.Title, spec.Info.Version)
 
+	// 命令行参数优先于配置文件中的标签过滤和演练模式
+	cfg.Global.SetTagFilter(*tags, *excludeTags)
+	if *dryRun {
+		cfg.Global.DryRun = true
+	}
+
 	// 创建服务器
 	srv, err := server.NewServer(cfg, spec)
 	if err != nil {
+		if *diagnose {
+			diagnostics.ExitWithError(*openAPIPath, fmt.Errorf("创建服务器失败: %w", err))
+		}
 		log.Fatalf("创建服务器失败: %v", err)
 	}
 
+	// 自检模式只输出报告，不启动服务器
+	if *diagnose {
+		diagnostics.Exit(srv.SelfCheck(context.Background()))
+	}
+
 	// 启动服务器
 	go func() {
 		if err := srv.Start(); err != nil {
@@ -73,12 +110,12 @@ func main() {
 
 	// 设置信号处理 - 根据 MCP 标准协议
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	signal.Notify(sigCh, process.ShutdownSignals()...)
 	
 	// 等待信号或服务器停止
 	select {
 	case sig := <-sigCh:
-		logging.Logger.Printf("收到信号: %v (SIGTERM/SIGINT)，开始优雅关闭", sig)
+		logging.Logger.Printf("收到信号: %v，开始优雅关闭", sig)
 		// 立即取消上下文
 		srv.Cancel()
 		// 给服务器一点时间优雅关闭
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mcp2rest/internal/presets"
+)
+
+// runPresets 实现 presets 子命令：列出、查看和在本地生成预置配置
+func runPresets(args []string) error {
+	if len(args) == 0 || args[0] == "list" {
+		for _, preset := range presets.List() {
+			fmt.Printf("%-12s %s\n", preset.Name, preset.Description)
+		}
+		fmt.Fprintln(os.Stderr, "\n"+msg("cli.presets.hint"))
+		return nil
+	}
+
+	switch args[0] {
+	case "show":
+		return runPresetsShow(args[1:])
+	case "init":
+		return runPresetsInit(args[1:])
+	}
+	return errorf("cli.presets.usage")
+}
+
+// runPresetsShow 输出预置的 OpenAPI 规范
+func runPresetsShow(args []string) error {
+	fs := flag.NewFlagSet("presets show", flag.ContinueOnError)
+	output := fs.String("o", "", msg("cli.flag_output"))
+	preset, err := lookupPreset(args)
+	if err != nil {
+		return err
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	spec, err := preset.Spec()
+	if err != nil {
+		return err
+	}
+	return writeOutput(*output, spec)
+}
+
+// presetFile 表示 presets init 写入的文件，路径相对于目标目录
+type presetFile struct {
+	path string
+	data []byte
+}
+
+// runPresetsInit 把预置的规范、服务器配置和凭据模板写入目录，目录结构与 -base-dir 一致
+func runPresetsInit(args []string) error {
+	fs := flag.NewFlagSet("presets init", flag.ContinueOnError)
+	dir := fs.String("dir", "", msg("cli.presets.flag_dir"))
+	force := fs.Bool("force", false, msg("cli.presets.flag_force"))
+	preset, err := lookupPreset(args)
+	if err != nil {
+		return err
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *dir == "" {
+		*dir = preset.Name
+	}
+
+	spec, err := preset.Spec()
+	if err != nil {
+		return err
+	}
+	serverConfig, err := preset.ServerConfig()
+	if err != nil {
+		return err
+	}
+	envExample, err := preset.EnvExample()
+	if err != nil {
+		return err
+	}
+
+	specPath := filepath.Join("configs", preset.Name+".yaml")
+	files := []presetFile{
+		{specPath, spec},
+		{filepath.Join("configs", "stdio.yaml"), serverConfig},
+	}
+	if envExample != nil {
+		files = append(files, presetFile{".env.example", envExample})
+	}
+
+	// 先检查所有文件，避免只写入一部分
+	if !*force {
+		for _, file := range files {
+			target := filepath.Join(*dir, file.path)
+			if _, err := os.Stat(target); err == nil {
+				return errorf("cli.presets.exists", target)
+			}
+		}
+	}
+	for _, file := range files {
+		target := filepath.Join(*dir, file.path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return errorf("cli.presets.mkdir_failed", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, file.data, 0644); err != nil {
+			return errorf("cli.write_failed", target, err)
+		}
+		fmt.Fprintln(os.Stderr, msg("cli.written", target))
+	}
+
+	fmt.Fprintln(os.Stderr, "\n"+msg("cli.presets.next_steps"))
+	if envExample != nil {
+		fmt.Fprintln(os.Stderr, "  "+msg("cli.presets.step_env", filepath.Join(*dir, ".env.example"), filepath.Join(*dir, ".env")))
+	}
+	fmt.Fprintln(os.Stderr, "  "+msg("cli.presets.step_review", filepath.Join(*dir, specPath)))
+	fmt.Fprintln(os.Stderr, "  "+msg("cli.presets.step_run", *dir, filepath.ToSlash(specPath)))
+	return nil
+}
+
+// lookupPreset 按名称查找预置，名称必须是第一个参数
+func lookupPreset(args []string) (presets.Preset, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return presets.Preset{}, errorf("cli.presets.missing_name")
+	}
+	preset, exists := presets.Get(args[0])
+	if !exists {
+		return presets.Preset{}, errorf("cli.presets.unknown", args[0])
+	}
+	return preset, nil
+}
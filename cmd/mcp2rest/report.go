@@ -0,0 +1,368 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// toolReport 一个工具与 HTTP 操作的对应关系，分组、目录和组合工具只有名称和描述
+type toolReport struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	ReadOnly    bool              `json:"readOnly"`
+	Method      string            `json:"method,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Deprecated  bool              `json:"deprecated,omitempty"`
+	Auth        []authReport      `json:"auth,omitempty"`
+	Parameters  []parameterReport `json:"parameters,omitempty"`
+	BodyTypes   []string          `json:"requestBody,omitempty"`
+	Transforms  []string          `json:"transforms,omitempty"`
+	Extensions  []string          `json:"extensions,omitempty"`
+}
+
+// authReport 操作使用的安全方案和提供凭据的环境变量
+type authReport struct {
+	Scheme string   `json:"scheme"`
+	Type   string   `json:"type"`
+	Detail string   `json:"detail,omitempty"`
+	Env    []string `json:"env,omitempty"`
+	// Bound 认证配置来自 global.auth 或认证配置文件中的绑定
+	Bound bool `json:"bound,omitempty"`
+}
+
+// parameterReport 工具参数及其在 HTTP 请求中的位置
+type parameterReport struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// mappingReport 规范生成的全部工具
+type mappingReport struct {
+	Title   string       `json:"title"`
+	Version string       `json:"version"`
+	Server  string       `json:"server"`
+	Tools   []toolReport `json:"tools"`
+}
+
+// runReport 实现 report 子命令: 输出每个工具对应的 HTTP 方法、路径、认证方案、参数和响应转换，供评审网关暴露的内容
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	specPath := fs.String("config", "configs/bmc_api.yaml", msg("cli.export.flag_config"))
+	serverConfig := fs.String("server-config", "", "服务器配置文件，工具列表受 hide_deprecated、tool_budget、catalog、workflows 等设置影响，默认使用默认配置")
+	format := fs.String("format", "markdown", "输出格式: markdown 或 json")
+	output := fs.String("o", "", msg("cli.flag_output"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("不支持的输出格式: %s (可选 markdown、json)", *format)
+	}
+
+	cfg, err := loadCommandConfig(*serverConfig)
+	if err != nil {
+		return err
+	}
+	_, spec, err := loadExportSpec(*specPath)
+	if err != nil {
+		return fmt.Errorf("加载 %s 失败: %w", *specPath, err)
+	}
+
+	// 创建处理器时的日志不输出
+	logging.Logger = discardLogger()
+	h, err := handler.NewRequestHandler(cfg, spec)
+	if err != nil {
+		return fmt.Errorf("生成工具列表失败: %w", err)
+	}
+
+	report := &mappingReport{
+		Title:   spec.Info.Title,
+		Version: spec.Info.Version,
+		Server:  openapi.GetBaseURL(spec),
+		Tools:   []toolReport{},
+	}
+	for _, tool := range h.GetAvailableTools() {
+		report.Tools = append(report.Tools, buildToolReport(spec, tool))
+	}
+	sort.Slice(report.Tools, func(i, j int) bool { return report.Tools[i].Name < report.Tools[j].Name })
+
+	var out []byte
+	if *format == "json" {
+		if out, err = marshalConfig(report); err != nil {
+			return err
+		}
+	} else {
+		out = []byte(report.markdown())
+	}
+	return writeOutput(*output, out)
+}
+
+// buildToolReport 汇总工具对应的操作，工具不对应规范中的操作时只包含工具列表中的信息
+func buildToolReport(spec *config.OpenAPISpec, tool map[string]interface{}) toolReport {
+	name, _ := tool["name"].(string)
+	description, _ := tool["description"].(string)
+	report := toolReport{Name: name, Description: firstLine(description)}
+	if annotations, ok := tool["annotations"].(map[string]interface{}); ok {
+		report.ReadOnly, _ = annotations["readOnlyHint"].(bool)
+	}
+
+	operation, method, path, err := openapi.GetOperationByID(spec, name)
+	if err != nil {
+		return report
+	}
+	report.Method, report.Path = method, path
+	report.Tags = operation.Tags
+	report.Deprecated = operation.Deprecated
+	if report.Description == "" {
+		report.Description = operation.Summary
+	}
+	report.Auth = operationAuth(spec, operation)
+
+	for _, param := range operation.Parameters {
+		in := param.In
+		if in == "" {
+			in = "body"
+		}
+		schemaType := param.Schema.Type
+		if schemaType == "" {
+			schemaType = "string"
+		}
+		report.Parameters = append(report.Parameters, parameterReport{
+			Name:        param.Name,
+			In:          in,
+			Type:        schemaType,
+			Required:    param.Required,
+			Description: firstLine(param.Description),
+		})
+	}
+	for mediaType := range operation.RequestBody.Content {
+		report.BodyTypes = append(report.BodyTypes, mediaType)
+	}
+	sort.Strings(report.BodyTypes)
+
+	for _, step := range operation.Transform {
+		report.Transforms = append(report.Transforms, describeTransform(step))
+	}
+	report.Extensions = operationExtensions(operation)
+	return report
+}
+
+// operationAuth 返回处理器实际使用的第一个安全要求中的方案
+func operationAuth(spec *config.OpenAPISpec, operation *config.Operation) []authReport {
+	if len(operation.Security) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(operation.Security[0]))
+	for name := range operation.Security[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	auth := make([]authReport, 0, len(names))
+	for _, name := range names {
+		scheme, err := openapi.GetSecurityScheme(spec, name)
+		if err != nil {
+			auth = append(auth, authReport{Scheme: name, Type: "未定义"})
+			continue
+		}
+		entry := authReport{Scheme: name, Type: scheme.Type}
+		switch scheme.Type {
+		case "apiKey":
+			entry.Detail = scheme.In + " " + scheme.Name
+		case "http":
+			entry.Detail = scheme.Scheme
+		}
+		authConfig := openapi.AuthConfigForScheme(name, scheme)
+		entry.Bound = scheme.Auth != nil
+		for _, env := range []string{authConfig.TokenEnv, authConfig.KeyEnv} {
+			if env != "" {
+				entry.Env = append(entry.Env, env)
+			}
+		}
+		auth = append(auth, entry)
+	}
+	return auth
+}
+
+// describeTransform 返回转换步骤的简短说明
+func describeTransform(step config.TransformConfig) string {
+	switch {
+	case step.Expression != "":
+		return step.Type + ": " + step.Expression
+	case len(step.Fields) > 0:
+		return step.Type + ": " + strings.Join(step.Fields, ", ")
+	default:
+		return step.Type
+	}
+}
+
+// operationExtensions 列出操作使用的其他 x-mcp-* 扩展
+func operationExtensions(operation *config.Operation) []string {
+	var extensions []string
+	if len(operation.Errors) > 0 {
+		keys := make([]string, 0, len(operation.Errors))
+		for key := range operation.Errors {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		extensions = append(extensions, "x-mcp-errors ("+strings.Join(keys, ", ")+")")
+	}
+	if len(operation.ExposeHeaders) > 0 {
+		extensions = append(extensions, "x-mcp-expose-headers ("+strings.Join(operation.ExposeHeaders, ", ")+")")
+	}
+	if operation.BodyTemplate != "" {
+		extensions = append(extensions, "x-mcp-body-template")
+	}
+	if operation.Patch != "" {
+		extensions = append(extensions, "x-mcp-patch ("+operation.Patch+")")
+	}
+	if operation.PruneEmpty != "" {
+		extensions = append(extensions, "x-mcp-prune-empty ("+operation.PruneEmpty+")")
+	}
+	if operation.XML != nil {
+		extensions = append(extensions, "x-mcp-xml")
+	}
+	if operation.CSV != nil {
+		extensions = append(extensions, "x-mcp-csv")
+	}
+	if operation.Poll != nil {
+		extensions = append(extensions, "x-mcp-poll")
+	}
+	for _, flag := range []struct {
+		name  string
+		value *bool
+	}{
+		{"x-mcp-follow-location", operation.FollowLocation},
+		{"x-mcp-compress-request", operation.CompressRequest},
+		{"x-mcp-cache", operation.Cache},
+		{"x-mcp-coalesce", operation.Coalesce},
+		{"x-mcp-validate-response", operation.ValidateResponse},
+		{"x-mcp-fix-hints", operation.FixHints},
+		{"x-mcp-description-example", operation.DescriptionExample},
+	} {
+		if flag.value != nil {
+			extensions = append(extensions, fmt.Sprintf("%s (%v)", flag.name, *flag.value))
+		}
+	}
+	if operation.Redirects != nil {
+		extensions = append(extensions, "x-mcp-redirects")
+	}
+	if len(operation.SuccessCodes) > 0 {
+		extensions = append(extensions, "x-mcp-success-codes ("+strings.Join(operation.SuccessCodes, ", ")+")")
+	}
+	if operation.Backend != "" {
+		extensions = append(extensions, "x-mcp-backend ("+operation.Backend+")")
+	}
+	if operation.MaxResponseSize != "" {
+		extensions = append(extensions, "x-mcp-max-response-size ("+operation.MaxResponseSize+")")
+	}
+	return extensions
+}
+
+// markdown 以 Markdown 输出报告: 一张总表，之后每个操作工具一节
+func (r *mappingReport) markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s %s 工具映射\n\n", r.Title, r.Version)
+	if r.Server != "" {
+		fmt.Fprintf(&sb, "上游: `%s`，共 %d 个工具\n\n", r.Server, len(r.Tools))
+	}
+
+	sb.WriteString("| 工具 | 方法 | 路径 | 认证 | 只读 |\n|---|---|---|---|---|\n")
+	for _, tool := range r.Tools {
+		auth := make([]string, 0, len(tool.Auth))
+		for _, a := range tool.Auth {
+			auth = append(auth, a.Scheme)
+		}
+		readOnly := ""
+		if tool.ReadOnly {
+			readOnly = "是"
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %s | %s |\n", tool.Name, tool.Method, markdownCode(tool.Path), strings.Join(auth, ", "), readOnly)
+	}
+
+	for _, tool := range r.Tools {
+		if tool.Method == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", tool.Name)
+		if tool.Description != "" {
+			fmt.Fprintf(&sb, "%s\n\n", markdownCell(tool.Description))
+		}
+		fmt.Fprintf(&sb, "- 请求: `%s %s`\n", tool.Method, tool.Path)
+		if len(tool.Tags) > 0 {
+			fmt.Fprintf(&sb, "- 标签: %s\n", strings.Join(tool.Tags, ", "))
+		}
+		if tool.Deprecated {
+			sb.WriteString("- 已弃用\n")
+		}
+		if len(tool.Auth) == 0 {
+			sb.WriteString("- 认证: 无\n")
+		}
+		for _, a := range tool.Auth {
+			detail := a.Type
+			if a.Detail != "" {
+				detail += " " + a.Detail
+			}
+			fmt.Fprintf(&sb, "- 认证: `%s` (%s)", a.Scheme, detail)
+			if len(a.Env) > 0 {
+				fmt.Fprintf(&sb, "，环境变量 `%s`", strings.Join(a.Env, "`、`"))
+			}
+			if a.Bound {
+				sb.WriteString(" (auth 绑定)")
+			}
+			sb.WriteString("\n")
+		}
+		if len(tool.BodyTypes) > 0 {
+			fmt.Fprintf(&sb, "- 请求体: %s\n", strings.Join(tool.BodyTypes, ", "))
+		}
+		for _, transform := range tool.Transforms {
+			fmt.Fprintf(&sb, "- 响应转换: `%s`\n", transform)
+		}
+		for _, extension := range tool.Extensions {
+			fmt.Fprintf(&sb, "- 扩展: %s\n", extension)
+		}
+
+		if len(tool.Parameters) > 0 {
+			sb.WriteString("\n| 参数 | 位置 | 类型 | 必需 | 说明 |\n|---|---|---|---|---|\n")
+			for _, param := range tool.Parameters {
+				required := ""
+				if param.Required {
+					required = "是"
+				}
+				fmt.Fprintf(&sb, "| `%s` | %s | %s | %s | %s |\n", param.Name, param.In, param.Type, required, markdownCell(param.Description))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// firstLine 返回描述的第一行
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// markdownCell 转义表格单元格中的竖线
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+const secretsUsage = "用法: mcp2rest secrets keygen | encrypt [-in .env] [-o .env.enc] | decrypt [-in .env.enc] [-o -]"
+
+// runSecrets 实现 secrets 子命令: 生成主密钥，加密或解密凭据文件，主密钥从 MCP2REST_MASTER_KEY 读取
+func runSecrets(args []string) error {
+	if len(args) == 0 {
+		return errors.New(secretsUsage)
+	}
+
+	switch args[0] {
+	case "keygen":
+		key, err := config.GenerateMasterKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		fmt.Fprintf(os.Stderr, "将该密钥保存到 %s，不要与加密的凭据文件一起分发\n", config.MasterKeyEnv)
+		return nil
+	case "encrypt":
+		return runSecretsCrypt("encrypt", args[1:], ".env", ".env.enc", config.EncryptEnv)
+	case "decrypt":
+		return runSecretsCrypt("decrypt", args[1:], ".env.enc", "-", config.DecryptEnv)
+	}
+	return fmt.Errorf("未知的操作: %s\n%s", args[0], secretsUsage)
+}
+
+// runSecretsCrypt 读取输入文件，用主密钥加密或解密后写入输出文件
+func runSecretsCrypt(name string, args []string, defaultIn, defaultOut string, crypt func(data, key []byte) ([]byte, error)) error {
+	fs := flag.NewFlagSet("secrets "+name, flag.ContinueOnError)
+	in := fs.String("in", defaultIn, "输入文件，\"-\" 表示从标准输入读取")
+	out := fs.String("o", defaultOut, "输出文件，\"-\" 表示写入标准输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	encodedKey := os.Getenv(config.MasterKeyEnv)
+	if encodedKey == "" {
+		return fmt.Errorf("未设置 %s，可以用 mcp2rest secrets keygen 生成", config.MasterKeyEnv)
+	}
+	key, err := config.ParseMasterKey(encodedKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.MasterKeyEnv, err)
+	}
+
+	var data []byte
+	if *in == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", *in, err)
+	}
+
+	result, err := crypt(data, key)
+	if err != nil {
+		return err
+	}
+	if *out == "-" {
+		_, err := os.Stdout.Write(result)
+		return err
+	}
+	// 输出包含凭据或加密后的凭据，只允许当前用户读写
+	if err := os.WriteFile(*out, result, 0600); err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", *out, err)
+	}
+	fmt.Fprintf(os.Stderr, "已写入 %s\n", *out)
+	return nil
+}
@@ -3,29 +3,37 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
-	"syscall"
 	"time"
 
+	"github.com/mcp2rest/internal/framing"
+	"github.com/mcp2rest/internal/process"
 	"github.com/mcp2rest/pkg/mcp"
 )
 
 // TestClient MCP 测试客户端
 type TestClient struct {
-	cmd    *exec.Cmd
-	stdin  io.WriteCloser
-	stdout io.ReadCloser
-	reader *bufio.Reader
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  io.ReadCloser
+	reader  *framing.Reader
+	framing framing.Mode // 发送请求使用的分帧方式
 }
 
 // NewTestClient 创建新的测试客户端
-func NewTestClient(serverPath, configPath string) (*TestClient, error) {
+// mode 为发送请求的分帧方式，响应按自动检测读取；serverFraming 非空时通过 MCP2REST_FRAMING 传给服务器
+func NewTestClient(serverPath, configPath string, mode framing.Mode, serverFraming string) (*TestClient, error) {
 	cmd := exec.Command(serverPath, "-config", configPath)
+	if serverFraming != "" {
+		cmd.Env = append(os.Environ(), "MCP2REST_FRAMING="+serverFraming)
+	}
 
 	// 设置管道
 	stdin, err := cmd.StdinPipe()
@@ -43,16 +51,32 @@ func NewTestClient(serverPath, configPath string) (*TestClient, error) {
 		return nil, fmt.Errorf("启动服务器失败: %w", err)
 	}
 
-	reader := bufio.NewReader(stdout)
+	reader := framing.NewReader(bufio.NewReader(stdout), framing.Auto)
 
 	return &TestClient{
-		cmd:    cmd,
-		stdin:  stdin,
-		stdout: stdout,
-		reader: reader,
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  stdout,
+		reader:  reader,
+		framing: mode,
 	}, nil
 }
 
+// writeMessage 按客户端的分帧方式发送消息
+func (tc *TestClient) writeMessage(message []byte) error {
+	_, err := tc.stdin.Write(framing.Encode(tc.framing, message))
+	return err
+}
+
+// readMessage 读取一条服务器消息
+func (tc *TestClient) readMessage() (string, error) {
+	message, err := tc.reader.ReadMessage()
+	if err != nil {
+		return "", err
+	}
+	return string(message), nil
+}
+
 // SendRequest 发送 MCP 请求
 func (tc *TestClient) SendRequest(method string, params interface{}) (*mcp.MCPResponse, error) {
 	// 创建请求
@@ -79,9 +103,8 @@ func (tc *TestClient) SendRequest(method string, params interface{}) (*mcp.MCPRe
 	}
 
 	// 发送请求
-	requestStr := string(requestBytes) + "\n"
-	fmt.Printf("DEBUG: 发送请求: %s", requestStr)
-	if _, err := tc.stdin.Write([]byte(requestStr)); err != nil {
+	fmt.Printf("DEBUG: 发送请求: %s\n", requestBytes)
+	if err := tc.writeMessage(requestBytes); err != nil {
 		return nil, fmt.Errorf("发送请求失败: %w", err)
 	}
 
@@ -90,7 +113,7 @@ func (tc *TestClient) SendRequest(method string, params interface{}) (*mcp.MCPRe
 	errChan := make(chan error, 1)
 
 	go func() {
-		responseStr, err := tc.reader.ReadString('\n')
+		responseStr, err := tc.readMessage()
 		if err != nil {
 			errChan <- err
 			return
@@ -100,7 +123,7 @@ func (tc *TestClient) SendRequest(method string, params interface{}) (*mcp.MCPRe
 
 	select {
 	case responseStr := <-responseChan:
-		fmt.Printf("DEBUG: 收到响应: %s", responseStr)
+		fmt.Printf("DEBUG: 收到响应: %s\n", responseStr)
 		// 解析响应
 		var response mcp.MCPResponse
 		if err := json.Unmarshal([]byte(strings.TrimSpace(responseStr)), &response); err != nil {
@@ -167,8 +190,7 @@ func (tc *TestClient) SendInitialized() error {
 		return fmt.Errorf("序列化请求失败: %w", err)
 	}
 
-	requestStr := string(requestBytes) + "\n"
-	if _, err := tc.stdin.Write([]byte(requestStr)); err != nil {
+	if err := tc.writeMessage(requestBytes); err != nil {
 		return fmt.Errorf("发送请求失败: %w", err)
 	}
 
@@ -197,14 +219,13 @@ func (tc *TestClient) GetToolsList() ([]map[string]interface{}, error) {
 		return nil, fmt.Errorf("序列化请求失败: %w", err)
 	}
 
-	requestStr := string(requestBytes) + "\n"
-	fmt.Printf("DEBUG: 发送工具列表请求: %s", requestStr)
-	if _, err := tc.stdin.Write([]byte(requestStr)); err != nil {
+	fmt.Printf("DEBUG: 发送工具列表请求: %s\n", requestBytes)
+	if err := tc.writeMessage(requestBytes); err != nil {
 		return nil, fmt.Errorf("发送请求失败: %w", err)
 	}
 
 	// 读取响应
-	responseStr, err := tc.reader.ReadString('\n')
+	responseStr, err := tc.readMessage()
 	if err != nil {
 		return nil, fmt.Errorf("读取响应失败: %w", err)
 	}
@@ -251,33 +272,21 @@ func (tc *TestClient) Close() error {
 
 	exitBytes, err := json.Marshal(exitRequest)
 	if err == nil {
-		exitStr := string(exitBytes) + "\n"
-		tc.stdin.Write([]byte(exitStr))
+		tc.writeMessage(exitBytes)
 		// 给服务器一点时间处理 exit 命令
 		time.Sleep(500 * time.Millisecond)
 	}
 
-	// 然后尝试优雅关闭
-	if err := tc.cmd.Process.Signal(syscall.SIGTERM); err != nil {
-		fmt.Printf("发送 SIGTERM 失败: %v\n", err)
-	}
-
-	// 等待进程退出
-	done := make(chan error, 1)
-	go func() {
-		_, err := tc.cmd.Process.Wait()
-		done <- err
-	}()
+	// 然后关闭标准输入，服务器读到 EOF 后会自行退出 (Windows 上无法发送 SIGTERM)
+	// 超时后依次尝试终止信号和强制终止
+	err = process.Shutdown(tc.cmd, tc.stdin, 5*time.Second)
+	fmt.Printf("进程已退出: %v\n", err)
+	return err
+}
 
-	select {
-	case err := <-done:
-		fmt.Printf("进程已退出: %v\n", err)
-		return err
-	case <-time.After(5 * time.Second):
-		fmt.Println("进程退出超时，强制终止...")
-		// 超时后强制终止
-		return tc.cmd.Process.Kill()
-	}
+// Running 返回服务器进程是否仍在运行
+func (tc *TestClient) Running() bool {
+	return tc.cmd != nil && tc.cmd.Process != nil && tc.cmd.ProcessState == nil
 }
 
 // TestCase 测试用例
@@ -395,21 +404,24 @@ func (ts *TestSuite) PrintSummary(results []TestResult) {
 	}
 }
 
-func getProcessCount(name string) int {
-	cmd := exec.Command("pgrep", "-f", name)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0
+func main() {
+	clientFraming := flag.String("framing", "ndjson", "发送请求的分帧方式 (ndjson/content-length)")
+	serverFraming := flag.String("server-framing", "auto", "服务器的分帧方式 (ndjson/content-length/auto)，为空时使用服务器配置")
+	flag.Parse()
+
+	mode, err := framing.ParseMode(*clientFraming)
+	if err != nil || mode == framing.Auto {
+		log.Fatalf("无效的客户端分帧方式: %s", *clientFraming)
 	}
-	return len(strings.Split(strings.TrimSpace(string(output)), "\n"))
-}
 
-func main() {
 	// 设置环境变量
 	os.Setenv("APIKEYAUTH_API_KEY", "ded45a001ffb9c47b1e29fcbdd6bcec6")
 
 	// 配置参数
 	serverPath := "./bin/mcp2rest"
+	if runtime.GOOS == "windows" {
+		serverPath += ".exe"
+	}
 	configPath := "./configs/bmc_api.yaml"
 
 	// 检查服务器是否存在
@@ -423,21 +435,20 @@ func main() {
 	}
 
 	// 创建测试客户端
-	fmt.Println("创建测试客户端前进程数:", getProcessCount("mcp2rest"))
-	client, err := NewTestClient(serverPath, configPath)
+	client, err := NewTestClient(serverPath, configPath, mode, *serverFraming)
 	if err != nil {
 		log.Fatalf("创建测试客户端失败: %v", err)
 	}
 	defer func() {
-		fmt.Println("关闭客户端前进程数:", getProcessCount("mcp2rest"))
+		fmt.Println("关闭客户端前服务器运行中:", client.Running())
 		client.Close()
-		fmt.Println("关闭客户端后进程数:", getProcessCount("mcp2rest"))
+		fmt.Println("关闭客户端后服务器运行中:", client.Running())
 	}()
 
 	// 等待服务器启动
 	fmt.Println("等待服务器启动...")
 	time.Sleep(2 * time.Second)
-	fmt.Println("服务器启动后进程数:", getProcessCount("mcp2rest"))
+	fmt.Println("服务器启动后运行中:", client.Running())
 
 	// 测试基本功能
 	fmt.Println("=== 测试基本功能 ===")
@@ -0,0 +1,213 @@
+// Package access 按入站客户端的身份控制可用的工具和调用频率
+//
+// 客户端通过 Authorization: Bearer <token> 或前置认证代理传递的主体请求头识别，
+// 同一网关可以向管理用的智能体开放全部工具，只向面向公众的智能体开放只读工具。
+package access
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// ErrUnauthorized 请求携带的凭据不属于任何客户端，或未携带凭据且没有配置匿名客户端
+var ErrUnauthorized = errors.New("未识别的客户端凭据")
+
+// Policy 访问控制策略
+type Policy struct {
+	clients       []*Client
+	byName        map[string]*Client
+	subjectHeader string
+	stdio         *Client
+}
+
+// Client 一个入站客户端及其权限
+type Client struct {
+	Name     string
+	token    string
+	subject  string
+	tools    []string
+	exclude  []string
+	readOnly bool
+	limiter  *limiter
+}
+
+// New 根据配置创建访问控制策略，未配置或没有客户端时返回 nil
+func New(cfg *config.AccessConfig) (*Policy, error) {
+	if cfg == nil || len(cfg.Clients) == 0 {
+		return nil, nil
+	}
+
+	policy := &Policy{
+		byName:        make(map[string]*Client, len(cfg.Clients)),
+		subjectHeader: cfg.SubjectHeader,
+	}
+	anonymous := ""
+	for i, clientCfg := range cfg.Clients {
+		if clientCfg.Name == "" {
+			return nil, fmt.Errorf("access.clients[%d] 缺少 name", i)
+		}
+		if _, exists := policy.byName[clientCfg.Name]; exists {
+			return nil, fmt.Errorf("access.clients 中的客户端名称重复: %s", clientCfg.Name)
+		}
+		for _, pattern := range append(append([]string{}, clientCfg.Tools...), clientCfg.ExcludeTools...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("客户端 %s 的工具名称模式无效 %q: %w", clientCfg.Name, pattern, err)
+			}
+		}
+
+		token := clientCfg.Token
+		if clientCfg.TokenEnv != "" {
+			token = os.Getenv(clientCfg.TokenEnv)
+			if token == "" {
+				return nil, fmt.Errorf("客户端 %s 的令牌环境变量 %s 未设置", clientCfg.Name, clientCfg.TokenEnv)
+			}
+		}
+		if clientCfg.Subject != "" && cfg.SubjectHeader == "" {
+			return nil, fmt.Errorf("客户端 %s 配置了 subject，但未配置 access.subject_header", clientCfg.Name)
+		}
+		if token == "" && clientCfg.Subject == "" {
+			if anonymous != "" {
+				return nil, fmt.Errorf("客户端 %s 和 %s 都没有配置令牌或主体，最多只能有一个匿名客户端", anonymous, clientCfg.Name)
+			}
+			anonymous = clientCfg.Name
+		}
+
+		client := &Client{
+			Name:     clientCfg.Name,
+			token:    token,
+			subject:  clientCfg.Subject,
+			tools:    clientCfg.Tools,
+			exclude:  clientCfg.ExcludeTools,
+			readOnly: clientCfg.ReadOnly,
+			limiter:  newLimiter(clientCfg.RateLimit, clientCfg.Burst),
+		}
+		policy.clients = append(policy.clients, client)
+		policy.byName[client.Name] = client
+	}
+
+	if cfg.StdioClient != "" {
+		policy.stdio = policy.byName[cfg.StdioClient]
+		if policy.stdio == nil {
+			return nil, fmt.Errorf("access.stdio_client 引用了不存在的客户端: %s", cfg.StdioClient)
+		}
+	}
+	return policy, nil
+}
+
+// Authenticate 按请求携带的令牌或主体识别客户端
+// 携带了凭据但不匹配任何客户端时不会退回匿名客户端
+func (p *Policy) Authenticate(r *http.Request) (*Client, error) {
+	token, hasToken := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	hasToken = hasToken && token != ""
+	subject := ""
+	if p.subjectHeader != "" {
+		subject = r.Header.Get(p.subjectHeader)
+	}
+
+	var anonymous *Client
+	for _, client := range p.clients {
+		switch {
+		case client.token != "":
+			if hasToken && subtle.ConstantTimeCompare([]byte(token), []byte(client.token)) == 1 {
+				return client, nil
+			}
+		case client.subject != "":
+			if !hasToken && subject == client.subject {
+				return client, nil
+			}
+		default:
+			anonymous = client
+		}
+	}
+	if hasToken || subject != "" || anonymous == nil {
+		return nil, ErrUnauthorized
+	}
+	return anonymous, nil
+}
+
+// Client 按名称返回客户端，不存在时返回 nil
+func (p *Policy) Client(name string) *Client {
+	return p.byName[name]
+}
+
+// StdioClient 返回标准输入/输出模式使用的客户端，未配置时返回 nil (不限制)
+func (p *Policy) StdioClient() *Client {
+	return p.stdio
+}
+
+// Allows 检查客户端能否使用工具，readOnly 表示工具只读取数据
+func (c *Client) Allows(name string, readOnly bool) bool {
+	if c.readOnly && !readOnly {
+		return false
+	}
+	if matchAny(c.exclude, name) {
+		return false
+	}
+	return len(c.tools) == 0 || matchAny(c.tools, name)
+}
+
+// Reserve 消耗一次调用配额，超过频率限制时返回 false 和需要等待的时间
+func (c *Client) Reserve() (bool, time.Duration) {
+	if c.limiter == nil {
+		return true, 0
+	}
+	return c.limiter.reserve(time.Now())
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// limiter 令牌桶，按每分钟的调用次数匀速补充
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration // 补充一次配额的间隔
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+func newLimiter(perMinute, burst int) *limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &limiter{
+		interval: time.Minute / time.Duration(perMinute),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+	}
+}
+
+func (l *limiter) reserve(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false, time.Duration((1 - l.tokens) * float64(l.interval))
+	}
+	l.tokens--
+	return true, 0
+}
@@ -0,0 +1,152 @@
+package access
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+func newPolicy(t *testing.T, cfg *config.AccessConfig) *Policy {
+	t.Helper()
+	policy, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return policy
+}
+
+func TestAuthenticate(t *testing.T) {
+	policy := newPolicy(t, &config.AccessConfig{
+		SubjectHeader: "X-Forwarded-User",
+		Clients: []config.AccessClientConfig{
+			{Name: "admin", Token: "admin-token"},
+			{Name: "alice", Subject: "alice"},
+			{Name: "public"},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string // 为空表示拒绝
+	}{
+		{"bearer token", map[string]string{"Authorization": "Bearer admin-token"}, "admin"},
+		{"wrong token", map[string]string{"Authorization": "Bearer other"}, ""},
+		{"subject", map[string]string{"X-Forwarded-User": "alice"}, "alice"},
+		{"unknown subject", map[string]string{"X-Forwarded-User": "mallory"}, ""},
+		// 令牌优先，携带令牌时不按主体识别
+		{"token with subject", map[string]string{"Authorization": "Bearer admin-token", "X-Forwarded-User": "alice"}, "admin"},
+		{"wrong token with subject", map[string]string{"Authorization": "Bearer other", "X-Forwarded-User": "alice"}, ""},
+		{"no credentials", nil, "public"},
+		{"empty bearer", map[string]string{"Authorization": "Bearer "}, "public"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "http://localhost/message", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			client, err := policy.Authenticate(req)
+			if tt.want == "" {
+				if err != ErrUnauthorized {
+					t.Fatalf("Authenticate() = %v, %v, want ErrUnauthorized", client, err)
+				}
+				return
+			}
+			if err != nil || client.Name != tt.want {
+				t.Fatalf("Authenticate() = %v, %v, want %s", client, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutAnonymous(t *testing.T) {
+	policy := newPolicy(t, &config.AccessConfig{Clients: []config.AccessClientConfig{{Name: "admin", Token: "admin-token"}}})
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost/message", nil)
+	if _, err := policy.Authenticate(req); err != ErrUnauthorized {
+		t.Fatalf("Authenticate() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	policy := newPolicy(t, &config.AccessConfig{Clients: []config.AccessClientConfig{
+		{Name: "reader", Token: "r", ReadOnly: true, ExcludeTools: []string{"getSecret*"}},
+		{Name: "users", Token: "u", Tools: []string{"*User*"}, ExcludeTools: []string{"deleteUser"}},
+	}})
+
+	tests := []struct {
+		client   string
+		tool     string
+		readOnly bool
+		want     bool
+	}{
+		{"reader", "getUser", true, true},
+		{"reader", "createUser", false, false},
+		{"reader", "getSecretKey", true, false},
+		{"reader", "getSecretKey", false, false},
+		{"users", "getUser", true, true},
+		{"users", "updateUser", false, true},
+		{"users", "deleteUser", false, false},
+		{"users", "listOrders", true, false},
+	}
+	for _, tt := range tests {
+		if got := policy.Client(tt.client).Allows(tt.tool, tt.readOnly); got != tt.want {
+			t.Errorf("%s.Allows(%s, %v) = %v, want %v", tt.client, tt.tool, tt.readOnly, got, tt.want)
+		}
+	}
+}
+
+func TestLimiterRefill(t *testing.T) {
+	l := newLimiter(60, 2) // 每秒补充一次，最多累积 2 次
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.reserve(now); !ok {
+			t.Fatalf("突发调用 #%d 被拒绝", i+1)
+		}
+	}
+	ok, wait := l.reserve(now)
+	if ok || wait != time.Second {
+		t.Fatalf("reserve() = %v, %v, want false, 1s", ok, wait)
+	}
+	if ok, wait := l.reserve(now.Add(500 * time.Millisecond)); ok || wait != 500*time.Millisecond {
+		t.Fatalf("半个间隔后 reserve() = %v, %v, want false, 500ms", ok, wait)
+	}
+	if ok, _ := l.reserve(now.Add(time.Second)); !ok {
+		t.Fatal("补充后 reserve() 被拒绝")
+	}
+
+	// 长时间空闲后最多累积 burst 次
+	later := now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.reserve(later); !ok {
+			t.Fatalf("空闲后调用 #%d 被拒绝", i+1)
+		}
+	}
+	if ok, _ := l.reserve(later); ok {
+		t.Fatal("累积的配额超过了 burst")
+	}
+
+	if newLimiter(0, 5) != nil {
+		t.Fatal("rate_limit 为 0 时不应限制")
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := map[string]*config.AccessConfig{
+		"missing name":           {Clients: []config.AccessClientConfig{{Token: "t"}}},
+		"duplicate name":         {Clients: []config.AccessClientConfig{{Name: "a", Token: "1"}, {Name: "a", Token: "2"}}},
+		"bad pattern":            {Clients: []config.AccessClientConfig{{Name: "a", Tools: []string{"["}}}},
+		"subject without header": {Clients: []config.AccessClientConfig{{Name: "a", Subject: "alice"}}},
+		"two anonymous":          {Clients: []config.AccessClientConfig{{Name: "a"}, {Name: "b"}}},
+		"unknown stdio client":   {StdioClient: "x", Clients: []config.AccessClientConfig{{Name: "a"}}},
+		"unset token env":        {Clients: []config.AccessClientConfig{{Name: "a", TokenEnv: "MCP2REST_TEST_UNSET_TOKEN"}}},
+	}
+	for name, cfg := range tests {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: New() error = nil", name)
+		}
+	}
+}
@@ -5,16 +5,21 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sync"
 
 	"github.com/mcp2rest/internal/config"
 )
 
 // AuthManager 管理API身份验证
-type AuthManager struct{}
+type AuthManager struct {
+	// refreshes 按认证名称 (安全方案名称) 记录的凭据刷新状态
+	mu        sync.Mutex
+	refreshes map[string]*refreshState
+}
 
 // NewAuthManager 创建新的身份验证管理器
 func NewAuthManager() (*AuthManager, error) {
-	return &AuthManager{}, nil
+	return &AuthManager{refreshes: make(map[string]*refreshState)}, nil
 }
 
 // ApplyAuth 应用身份验证到请求
@@ -32,6 +37,10 @@ func (a *AuthManager) ApplyAuth(req *http.Request, authConfig *config.AuthConfig
 		return a.applyBasicAuth(req, authConfig)
 	case "oauth2":
 		return a.applyOAuth2Auth(req, authConfig)
+	case "ntlm", "negotiate":
+		// 需要与上游握手，这里只检查凭据，由执行后端通过 Handshaker 发送请求
+		_, err := NewHandshake(authConfig)
+		return err
 	default:
 		return fmt.Errorf("不支持的身份验证类型: %s", authConfig.Type)
 	}
@@ -54,8 +63,8 @@ func (a *AuthManager) applyBearerAuth(req *http.Request, authConfig *config.Auth
 
 // applyAPIKeyAuth 应用API密钥身份验证
 func (a *AuthManager) applyAPIKeyAuth(req *http.Request, authConfig *config.AuthConfig) error {
-	if authConfig.HeaderName == "" {
-		return fmt.Errorf("API密钥身份验证需要指定header_name")
+	if authConfig.HeaderName == "" && authConfig.QueryParam == "" {
+		return fmt.Errorf("API密钥身份验证需要指定header_name或query_param")
 	}
 	if authConfig.KeyEnv == "" {
 		return fmt.Errorf("API密钥身份验证需要指定key_env")
@@ -66,6 +75,12 @@ func (a *AuthManager) applyAPIKeyAuth(req *http.Request, authConfig *config.Auth
 		return fmt.Errorf("环境变量 %s 未设置或为空", authConfig.KeyEnv)
 	}
 
+	if authConfig.QueryParam != "" {
+		query := req.URL.Query()
+		query.Set(authConfig.QueryParam, apiKey)
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
 	req.Header.Set(authConfig.HeaderName, apiKey)
 	return nil
 }
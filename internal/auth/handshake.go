@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// Handshaker 需要与上游握手的认证方式 (NTLM、Kerberos)，由上游执行后端在发送请求时调用
+type Handshaker interface {
+	Do(client *http.Client, req *http.Request) (*http.Response, error)
+}
+
+// newKerberosAuth 创建 Kerberos SPNEGO 认证，未使用 kerberos 构建标签时为 nil
+var newKerberosAuth func(authConfig *config.AuthConfig) (Handshaker, error)
+
+type handshakeContextKey struct{}
+
+// UsesHandshake 检查认证配置是否需要与上游握手 (ntlm 或 negotiate)
+func UsesHandshake(authConfig *config.AuthConfig) bool {
+	return authConfig != nil && (authConfig.Type == "ntlm" || authConfig.Type == "negotiate")
+}
+
+// NewHandshake 根据认证配置创建握手认证: 配置了 krb5_config 的 negotiate 使用 Kerberos，其余使用 NTLMv2
+func NewHandshake(authConfig *config.AuthConfig) (Handshaker, error) {
+	if authConfig.Type == "negotiate" && authConfig.Krb5Config != "" {
+		if newKerberosAuth == nil {
+			return nil, errors.New("negotiate 的 Kerberos 认证需要使用 kerberos 构建标签编译 (go build -tags kerberos)")
+		}
+		return newKerberosAuth(authConfig)
+	}
+	return NewNTLMAuth(authConfig)
+}
+
+// WithHandshake 把握手认证附加到请求的上下文，由上游执行后端在发送时完成握手
+func WithHandshake(ctx context.Context, h Handshaker) context.Context {
+	return context.WithValue(ctx, handshakeContextKey{}, h)
+}
+
+// HandshakeFromContext 返回请求上下文中的握手认证，没有时返回 nil
+func HandshakeFromContext(ctx context.Context) Handshaker {
+	h, _ := ctx.Value(handshakeContextKey{}).(Handshaker)
+	return h
+}
+
+// handshakeCredentials 与基本认证相同，用户名和密码优先使用配置中的值，其次从 token_env 和 key_env 读取
+func handshakeCredentials(authConfig *config.AuthConfig) (username, password string) {
+	username = authConfig.Username
+	password = authConfig.Password
+	if username == "" && authConfig.TokenEnv != "" {
+		username = os.Getenv(authConfig.TokenEnv)
+	}
+	if password == "" && authConfig.KeyEnv != "" {
+		password = os.Getenv(authConfig.KeyEnv)
+	}
+	return username, password
+}
@@ -0,0 +1,42 @@
+//go:build !kerberos
+
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+func TestNewHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		auth    config.AuthConfig
+		scheme  string
+		wantErr string
+	}{
+		{"ntlm", config.AuthConfig{Type: "ntlm", Username: `CORP\alice`, Password: "secret"}, "NTLM", ""},
+		{"negotiate without krb5_config falls back to NTLM", config.AuthConfig{Type: "negotiate", Username: "alice@corp", Password: "secret"}, "Negotiate", ""},
+		{"kerberos requires build tag", config.AuthConfig{Type: "negotiate", Username: "alice", Password: "secret", Krb5Config: "/etc/krb5.conf"}, "", "kerberos 构建标签"},
+		{"missing password", config.AuthConfig{Type: "ntlm", Username: "alice"}, "", "需要用户名和密码"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHandshake(&tt.auth)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewHandshake() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewHandshake() error = %v", err)
+			}
+			n, ok := h.(*NTLMAuth)
+			if !ok || n.Scheme != tt.scheme {
+				t.Fatalf("NewHandshake() = %#v, want NTLMAuth with scheme %s", h, tt.scheme)
+			}
+		})
+	}
+}
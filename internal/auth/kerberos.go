@@ -0,0 +1,90 @@
+//go:build kerberos
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	krb5config "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+func init() {
+	newKerberosAuth = newKerberosHandshake
+}
+
+// kerberosAuth 通过 SPNEGO 在 Authorization: Negotiate 中发送 Kerberos 服务票据 (RFC 4559)
+type kerberosAuth struct {
+	client *client.Client
+	spn    string
+}
+
+// 已登录的 Kerberos 客户端按配置和凭据缓存，客户端自动续订 TGT 并缓存服务票据
+var (
+	kerberosClients = make(map[string]*client.Client)
+	kerberosMutex   sync.Mutex
+)
+
+// newKerberosHandshake 读取 krb5.conf 并用密码或 keytab 登录，用户名写成 user@REALM 或 DOMAIN\user，
+// 省略领域时使用 krb5.conf 的 default_realm
+func newKerberosHandshake(authConfig *config.AuthConfig) (Handshaker, error) {
+	username, password := handshakeCredentials(authConfig)
+	if username == "" || (password == "" && authConfig.Keytab == "") {
+		return nil, errors.New("negotiate 的 Kerberos 认证需要用户名以及密码或 keytab")
+	}
+
+	kerberosMutex.Lock()
+	defer kerberosMutex.Unlock()
+	key := strings.Join([]string{authConfig.Krb5Config, authConfig.Keytab, username, password}, "\x00")
+	if cl, ok := kerberosClients[key]; ok {
+		return &kerberosAuth{client: cl, spn: authConfig.SPN}, nil
+	}
+
+	krb5conf, err := krb5config.Load(authConfig.Krb5Config)
+	if err != nil {
+		return nil, fmt.Errorf("读取 krb5.conf 失败: %w", err)
+	}
+	user, realm := username, ""
+	if domain, name, ok := strings.Cut(username, `\`); ok {
+		user, realm = name, strings.ToUpper(domain)
+	} else if name, domain, ok := strings.Cut(username, "@"); ok {
+		user, realm = name, domain
+	}
+	if realm == "" {
+		realm = krb5conf.LibDefaults.DefaultRealm
+	}
+
+	var cl *client.Client
+	if authConfig.Keytab != "" {
+		kt, err := keytab.Load(authConfig.Keytab)
+		if err != nil {
+			return nil, fmt.Errorf("读取 keytab 失败: %w", err)
+		}
+		cl = client.NewWithKeytab(user, realm, kt, krb5conf, client.DisablePAFXFAST(true))
+	} else {
+		cl = client.NewWithPassword(user, realm, password, krb5conf, client.DisablePAFXFAST(true))
+	}
+	if err := cl.Login(); err != nil {
+		return nil, fmt.Errorf("Kerberos 登录失败: %w", err)
+	}
+	kerberosClients[key] = cl
+	return &kerberosAuth{client: cl, spn: authConfig.SPN}, nil
+}
+
+// Do 在请求中附加 SPNEGO 令牌后发送，Kerberos 不需要服务器质询，一次往返完成认证。
+// 未配置 spn 时使用 HTTP/<主机名>
+func (k *kerberosAuth) Do(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := spnego.SetSPNEGOHeader(k.client, req, k.spn); err != nil {
+		return nil, fmt.Errorf("Negotiate 握手失败: %w", err)
+	}
+	return httpClient.Do(req)
+}
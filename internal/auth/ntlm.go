@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// NTLM 协商标志 (MS-NLMP 2.2.2.5)
+const (
+	ntlmNegotiateUnicode                = 0x00000001
+	ntlmRequestTarget                   = 0x00000004
+	ntlmNegotiateNTLM                   = 0x00000200
+	ntlmNegotiateAlwaysSign             = 0x00008000
+	ntlmNegotiateExtendedSecurity       = 0x00080000
+	ntlmNegotiateTargetInfo             = 0x00800000
+	ntlmNegotiate128                    = 0x20000000
+	ntlmNegotiate56                     = 0x80000000
+	ntlmNegotiateFlags                  = ntlmNegotiateUnicode | ntlmRequestTarget | ntlmNegotiateNTLM | ntlmNegotiateAlwaysSign | ntlmNegotiateExtendedSecurity | ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+	ntlmAvTimestamp                     = 7
+	ntlmAvEOL                           = 0
+	ntlmSignature                       = "NTLMSSP\x00"
+	filetimeUnixOffset            int64 = 116444736000000000
+)
+
+// NTLMAuth Windows 集成认证的凭据，通过 NTLMv2 握手认证请求
+// Scheme 为 "NTLM" 或 "Negotiate"；未配置 Kerberos 的 Negotiate 同样发送 NTLM 令牌，由服务器的 Negotiate 提供程序回退到 NTLM
+type NTLMAuth struct {
+	Scheme   string
+	Domain   string
+	User     string
+	Password string
+}
+
+// NewNTLMAuth 根据认证配置读取凭据，用户名可以写成 DOMAIN\user 或 user@domain
+// 与基本认证相同，用户名和密码优先使用配置中的值，其次从 token_env 和 key_env 读取
+func NewNTLMAuth(authConfig *config.AuthConfig) (*NTLMAuth, error) {
+	username, password := handshakeCredentials(authConfig)
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("%s 身份验证需要用户名和密码", authConfig.Type)
+	}
+
+	n := &NTLMAuth{Scheme: "NTLM", User: username, Password: password}
+	if authConfig.Type == "negotiate" {
+		n.Scheme = "Negotiate"
+	}
+	if domain, user, ok := strings.Cut(username, `\`); ok {
+		n.Domain, n.User = domain, user
+	} else if user, domain, ok := strings.Cut(username, "@"); ok {
+		n.Domain, n.User = domain, user
+	}
+	return n, nil
+}
+
+// Do 通过 NTLM 握手发送请求: 先发送协商消息，再用服务器的质询计算认证消息并重新发送。
+// 握手依赖同一个连接，中间的 401 响应体被完整读取以便连接复用；服务器没有返回质询时直接返回该响应
+func (n *NTLMAuth) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	body, err := replayableBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	negotiate := req.Clone(req.Context())
+	negotiate.Body = body()
+	negotiate.Header.Set("Authorization", n.Scheme+" "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()))
+	resp, err := client.Do(negotiate)
+	if err != nil {
+		return nil, err
+	}
+	challenge := n.challenge(resp)
+	if resp.StatusCode != http.StatusUnauthorized || challenge == nil {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	message, err := n.authenticateMessage(challenge)
+	if err != nil {
+		return nil, fmt.Errorf("%s 握手失败: %w", n.Scheme, err)
+	}
+	authenticate := req.Clone(req.Context())
+	authenticate.Body = body()
+	authenticate.Header.Set("Authorization", n.Scheme+" "+base64.StdEncoding.EncodeToString(message))
+	return client.Do(authenticate)
+}
+
+// challenge 从 WWW-Authenticate 中读取服务器的质询消息
+func (n *NTLMAuth) challenge(resp *http.Response) []byte {
+	for _, value := range resp.Header.Values("WWW-Authenticate") {
+		scheme, token, ok := strings.Cut(strings.TrimSpace(value), " ")
+		if !ok || !strings.EqualFold(scheme, n.Scheme) {
+			continue
+		}
+		if data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token)); err == nil {
+			return data
+		}
+	}
+	return nil
+}
+
+// replayableBody 返回可以多次读取请求体的函数，握手的每一步都需要发送完整的请求体
+func replayableBody(req *http.Request) (func() io.ReadCloser, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return func() io.ReadCloser { return http.NoBody }, nil
+	}
+	if req.GetBody != nil {
+		return func() io.ReadCloser {
+			body, err := req.GetBody()
+			if err != nil {
+				return io.NopCloser(errReader{err})
+			}
+			return body
+		}, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	return func() io.ReadCloser { return io.NopCloser(bytes.NewReader(data)) }, nil
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+// ntlmNegotiateMessage 生成协商消息 (类型 1)，不包含域和工作站
+func ntlmNegotiateMessage() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmNegotiateFlags)
+	return msg
+}
+
+// authenticateMessage 根据质询消息 (类型 2) 计算 NTLMv2 认证消息 (类型 3)
+func (n *NTLMAuth) authenticateMessage(challenge []byte) ([]byte, error) {
+	if len(challenge) < 48 || string(challenge[:8]) != ntlmSignature || binary.LittleEndian.Uint32(challenge[8:]) != 2 {
+		return nil, errors.New("无效的质询消息")
+	}
+	flags := binary.LittleEndian.Uint32(challenge[20:])
+	serverChallenge := challenge[24:32]
+	targetInfo, err := securityBuffer(challenge, 40)
+	if err != nil {
+		return nil, err
+	}
+
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+	// 服务器提供了时间戳时使用它，此时 LM 响应为全零
+	timestamp, hasTimestamp := avTimestamp(targetInfo)
+	if !hasTimestamp {
+		timestamp = make([]byte, 8)
+		binary.LittleEndian.PutUint64(timestamp, uint64(time.Now().UnixNano()/100+filetimeUnixOffset))
+	}
+
+	key := ntowfv2(n.User, n.Password, n.Domain)
+	var temp bytes.Buffer
+	temp.Write([]byte{1, 1, 0, 0, 0, 0, 0, 0})
+	temp.Write(timestamp)
+	temp.Write(clientChallenge)
+	temp.Write([]byte{0, 0, 0, 0})
+	temp.Write(targetInfo)
+	temp.Write([]byte{0, 0, 0, 0})
+	proof := hmacMD5(key, serverChallenge, temp.Bytes())
+	ntResponse := append(proof, temp.Bytes()...)
+
+	lmResponse := make([]byte, 24)
+	if !hasTimestamp {
+		lmResponse = append(hmacMD5(key, serverChallenge, clientChallenge), clientChallenge...)
+	}
+
+	// 不使用签名和加密，不需要会话密钥
+	flags &^= 0x40000000 | 0x00000010 | 0x00000020
+	flags |= ntlmNegotiateUnicode
+
+	fields := [][]byte{lmResponse, ntResponse, utf16le(n.Domain), utf16le(n.User), nil, nil}
+	const header = 64
+	msg := make([]byte, header)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+	offset := header
+	for i, field := range fields {
+		pos := 12 + i*8
+		binary.LittleEndian.PutUint16(msg[pos:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(msg[pos+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(msg[pos+4:], uint32(offset))
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], flags)
+	for _, field := range fields {
+		msg = append(msg, field...)
+	}
+	return msg, nil
+}
+
+// securityBuffer 读取消息中 pos 处的安全缓冲区 (长度、分配长度、偏移)
+func securityBuffer(msg []byte, pos int) ([]byte, error) {
+	length := int(binary.LittleEndian.Uint16(msg[pos:]))
+	offset := int(binary.LittleEndian.Uint32(msg[pos+4:]))
+	if offset+length > len(msg) {
+		return nil, errors.New("质询消息被截断")
+	}
+	return msg[offset : offset+length], nil
+}
+
+// avTimestamp 从目标信息的 AV_PAIR 列表中读取 MsvAvTimestamp
+func avTimestamp(targetInfo []byte) ([]byte, bool) {
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == ntlmAvEOL || 4+length > len(targetInfo) {
+			break
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return targetInfo[4:12], true
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil, false
+}
+
+// ntowfv2 计算 NTLMv2 的密钥: HMAC-MD5(MD4(UTF16LE(password)), UTF16LE(UPPER(user) + domain))
+func ntowfv2(user, password, domain string) []byte {
+	hash := md4Sum(utf16le(password))
+	return hmacMD5(hash[:], utf16le(strings.ToUpper(user)+domain))
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func utf16le(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, len(units)*2)
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[i*2:], u)
+	}
+	return b
+}
+
+// md4Sum 计算 MD4 摘要 (RFC 1320)，只用于 NTLM 的密码哈希，标准库不提供 MD4
+func md4Sum(data []byte) [16]byte {
+	msg := make([]byte, len(data), len(data)+72)
+	copy(msg, data)
+	msg = append(msg, 0x80)
+	for len(msg)%64 != 56 {
+		msg = append(msg, 0)
+	}
+	msg = binary.LittleEndian.AppendUint64(msg, uint64(len(data))*8)
+
+	state := [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+	rounds := []struct {
+		order  [16]int
+		shifts [4]uint
+		add    uint32
+		f      func(x, y, z uint32) uint32
+	}{
+		{[16]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, [4]uint{3, 7, 11, 19}, 0,
+			func(x, y, z uint32) uint32 { return x&y | ^x&z }},
+		{[16]int{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}, [4]uint{3, 5, 9, 13}, 0x5a827999,
+			func(x, y, z uint32) uint32 { return x&y | x&z | y&z }},
+		{[16]int{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}, [4]uint{3, 9, 11, 15}, 0x6ed9eba1,
+			func(x, y, z uint32) uint32 { return x ^ y ^ z }},
+	}
+
+	var x [16]uint32
+	for block := 0; block < len(msg); block += 64 {
+		for i := range x {
+			x[i] = binary.LittleEndian.Uint32(msg[block+i*4:])
+		}
+		h := state
+		for _, round := range rounds {
+			for i, k := range round.order {
+				// 依次更新 a、d、c、b
+				j := (4 - i%4) % 4
+				v := h[j] + round.f(h[(j+1)%4], h[(j+2)%4], h[(j+3)%4]) + x[k] + round.add
+				s := round.shifts[i%4]
+				h[j] = v<<s | v>>(32-s)
+			}
+		}
+		for i := range state {
+			state[i] += h[i]
+		}
+	}
+
+	var sum [16]byte
+	for i, v := range state {
+		binary.LittleEndian.PutUint32(sum[i*4:], v)
+	}
+	return sum
+}
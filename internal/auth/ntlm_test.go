@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// RFC 1320 附录 A.5 的测试向量
+func TestMD4Sum(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "043f8582f241db351ce627e153e7f0e4"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := md4Sum([]byte(tt.input))
+			if hex.EncodeToString(got[:]) != tt.want {
+				t.Errorf("md4Sum(%q) = %x, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// MS-NLMP 4.2.2.1.2 和 4.2.4 的示例: 用户 User、域 Domain、密码 Password
+func TestNTLMv2KnownAnswers(t *testing.T) {
+	ntHash := md4Sum(utf16le("Password"))
+	if got, want := hex.EncodeToString(ntHash[:]), "a4f49c406510bdcab6824ee7c30fd852"; got != want {
+		t.Errorf("NTOWFv1 = %s, want %s", got, want)
+	}
+
+	key := ntowfv2("User", "Password", "Domain")
+	if got, want := hex.EncodeToString(key), "0c868a403bfd7a93a3001ef22ef02e3f"; got != want {
+		t.Fatalf("NTOWFv2 = %s, want %s", got, want)
+	}
+
+	serverChallenge := mustHex(t, "0123456789abcdef")
+	clientChallenge := mustHex(t, "aaaaaaaaaaaaaaaa")
+	lmv2 := hmacMD5(key, serverChallenge, clientChallenge)
+	if got, want := hex.EncodeToString(lmv2), "86c35097ac9cec102554764a57cccc19"; got != want {
+		t.Errorf("LMv2 = %s, want %s", got, want)
+	}
+
+	// 时间戳为零，目标信息包含 MsvAvNbDomainName "Domain" 和 MsvAvNbComputerName "Server"
+	targetInfo := mustHex(t, "02000c0044006f006d00610069006e0001000c005300650072007600650072000000000000000000")
+	var temp bytes.Buffer
+	temp.Write([]byte{1, 1, 0, 0, 0, 0, 0, 0})
+	temp.Write(make([]byte, 8))
+	temp.Write(clientChallenge)
+	temp.Write([]byte{0, 0, 0, 0})
+	temp.Write(targetInfo[:36])
+	temp.Write([]byte{0, 0, 0, 0})
+	if got, want := hex.EncodeToString(hmacMD5(key, serverChallenge, temp.Bytes())), "68cd0ab851e51c96aabc927bebef6a1c"; got != want {
+		t.Errorf("NTProofStr = %s, want %s", got, want)
+	}
+}
+
+// challengeMessage 构造类型 2 消息，目标信息放在固定头之后
+func challengeMessage(serverChallenge, targetInfo []byte) []byte {
+	msg := make([]byte, 48)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 2)
+	binary.LittleEndian.PutUint32(msg[20:], ntlmNegotiateFlags)
+	copy(msg[24:], serverChallenge)
+	binary.LittleEndian.PutUint16(msg[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(msg[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(msg[44:], 48)
+	return append(msg, targetInfo...)
+}
+
+func TestAuthenticateMessage(t *testing.T) {
+	n := &NTLMAuth{Scheme: "NTLM", Domain: "Domain", User: "User", Password: "Password"}
+	serverChallenge := mustHex(t, "0123456789abcdef")
+	timestamp := mustHex(t, "0090d336b734c301")
+	targetInfo := append(append([]byte{ntlmAvTimestamp, 0, 8, 0}, timestamp...), 0, 0, 0, 0)
+
+	msg, err := n.authenticateMessage(challengeMessage(serverChallenge, targetInfo))
+	if err != nil {
+		t.Fatalf("authenticateMessage() error = %v", err)
+	}
+	if string(msg[:8]) != ntlmSignature || binary.LittleEndian.Uint32(msg[8:]) != 3 {
+		t.Fatalf("authenticateMessage() header = %x", msg[:12])
+	}
+
+	field := func(i int) []byte {
+		b, err := securityBuffer(msg, 12+i*8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	// 服务器提供时间戳时 LM 响应为全零
+	if lm := field(0); !bytes.Equal(lm, make([]byte, 24)) {
+		t.Errorf("LM response = %x, want zeros", lm)
+	}
+	if got := field(2); !bytes.Equal(got, utf16le("Domain")) {
+		t.Errorf("domain = %x", got)
+	}
+	if got := field(3); !bytes.Equal(got, utf16le("User")) {
+		t.Errorf("user = %x", got)
+	}
+
+	// NT 响应的证明值必须能用同样的密钥和服务器质询验证，并回显服务器的时间戳
+	nt := field(1)
+	proof, temp := nt[:16], nt[16:]
+	if !bytes.Equal(temp[8:16], timestamp) {
+		t.Errorf("timestamp = %x, want %x", temp[8:16], timestamp)
+	}
+	if want := hmacMD5(ntowfv2("User", "Password", "Domain"), serverChallenge, temp); !bytes.Equal(proof, want) {
+		t.Errorf("NTProofStr = %x, want %x", proof, want)
+	}
+}
+
+func TestAuthenticateMessageRejectsInvalidChallenge(t *testing.T) {
+	n := &NTLMAuth{Scheme: "NTLM", User: "User", Password: "Password"}
+	truncated := challengeMessage(make([]byte, 8), nil)
+	binary.LittleEndian.PutUint16(truncated[40:], 16)
+	wrongType := challengeMessage(make([]byte, 8), nil)
+	binary.LittleEndian.PutUint32(wrongType[8:], 1)
+	for name, challenge := range map[string][]byte{
+		"short":      []byte(ntlmSignature),
+		"wrong type": wrongType,
+		"truncated":  truncated,
+	} {
+		if _, err := n.authenticateMessage(challenge); err == nil {
+			t.Errorf("%s: authenticateMessage() error = nil", name)
+		}
+	}
+}
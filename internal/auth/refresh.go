@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+const (
+	defaultRefreshTimeout  = 30 * time.Second
+	defaultRefreshInterval = 10 * time.Second
+	// expiryLeeway 令牌在到期前这段时间内视为已过期，提前刷新
+	expiryLeeway = 30 * time.Second
+)
+
+// refreshState 一个认证配置的刷新状态，mu 保证同一凭据同时只有一次刷新
+type refreshState struct {
+	mu     sync.Mutex
+	last   time.Time // 上次刷新完成的时间
+	expiry time.Time // oauth2 令牌端点返回的到期时间，未知时为零值
+}
+
+// state 返回认证名称对应的刷新状态
+func (a *AuthManager) state(name string) *refreshState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	st, ok := a.refreshes[name]
+	if !ok {
+		st = &refreshState{}
+		a.refreshes[name] = st
+	}
+	return st
+}
+
+// CanRefresh 检查认证配置是否配置了凭据刷新
+func CanRefresh(authConfig *config.AuthConfig) bool {
+	return authConfig != nil && authConfig.Refresh != nil
+}
+
+// Expired 检查凭据是否已过期或即将过期: 优先使用 oauth2 刷新时记录的到期时间，其次是 JWT 令牌中的 exp
+// 只对配置了刷新的认证检查，无法判断时返回 false
+func (a *AuthManager) Expired(name string, authConfig *config.AuthConfig) bool {
+	if !CanRefresh(authConfig) {
+		return false
+	}
+	st := a.state(name)
+	st.mu.Lock()
+	expiry := st.expiry
+	st.mu.Unlock()
+
+	if expiry.IsZero() && (authConfig.Type == "bearer" || authConfig.Type == "oauth2") {
+		expiry = jwtExpiry(os.Getenv(authConfig.TokenEnv))
+	}
+	return !expiry.IsZero() && time.Now().Add(expiryLeeway).After(expiry)
+}
+
+// Refresh 刷新认证名称为 name 的凭据，新凭据写入认证配置读取的环境变量
+// sentAt 为使用旧凭据的请求的发出时间: 此后已有其他调用完成刷新时直接返回，调用方用新凭据重试即可；
+// 距离上次刷新不足 min_interval 时返回错误，避免凭据确实无效时反复刷新
+func (a *AuthManager) Refresh(ctx context.Context, name string, authConfig *config.AuthConfig, sentAt time.Time) error {
+	if !CanRefresh(authConfig) {
+		return fmt.Errorf("%s 未配置凭据刷新", name)
+	}
+	refresh := authConfig.Refresh
+
+	st := a.state(name)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.last.After(sentAt) {
+		return nil
+	}
+	interval := refresh.MinInterval
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	if since := time.Since(st.last); since < interval {
+		return fmt.Errorf("%s 的凭据在 %s 前刚刚刷新过，刷新后仍被拒绝", name, since.Round(time.Millisecond))
+	}
+
+	timeout := refresh.Timeout
+	if timeout <= 0 {
+		timeout = defaultRefreshTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// 失败时同样记录时间，在 min_interval 内不再重试
+	defer func() { st.last = time.Now() }()
+
+	switch refresh.Type {
+	case "env":
+		if err := config.ReloadEnvFiles(); err != nil {
+			return fmt.Errorf("刷新 %s 的凭据失败: %w", name, err)
+		}
+		return nil
+	case "command":
+		value, err := runRefreshCommand(ctx, refresh.Command)
+		if err != nil {
+			return fmt.Errorf("刷新 %s 的凭据失败: %w", name, err)
+		}
+		return setCredential(authConfig, value)
+	case "oauth2":
+		token, expiresIn, err := fetchClientCredentialsToken(ctx, refresh)
+		if err != nil {
+			return fmt.Errorf("刷新 %s 的凭据失败: %w", name, err)
+		}
+		st.expiry = time.Time{}
+		if expiresIn > 0 {
+			st.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+		}
+		return setCredential(authConfig, token)
+	}
+	return fmt.Errorf("不支持的凭据刷新类型: %s", refresh.Type)
+}
+
+// setCredential 把新凭据写入认证配置读取的环境变量: 令牌写入 token_env，API 密钥和基本认证密码写入 key_env
+func setCredential(authConfig *config.AuthConfig, value string) error {
+	env := authConfig.KeyEnv
+	if authConfig.Type == "bearer" || authConfig.Type == "oauth2" {
+		env = authConfig.TokenEnv
+	}
+	if env == "" {
+		return fmt.Errorf("%s 认证没有用于保存凭据的环境变量", authConfig.Type)
+	}
+	return os.Setenv(env, value)
+}
+
+// runRefreshCommand 执行刷新命令，去掉首尾空白的标准输出即为新凭据
+func runRefreshCommand(ctx context.Context, command []string) (string, error) {
+	if len(command) == 0 {
+		return "", fmt.Errorf("未配置刷新命令")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("执行刷新命令失败: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("执行刷新命令失败: %w", err)
+	}
+	value := strings.TrimSpace(stdout.String())
+	if value == "" {
+		return "", fmt.Errorf("刷新命令没有输出凭据")
+	}
+	return value, nil
+}
+
+// fetchClientCredentialsToken 通过 OAuth2 client_credentials 流程获取访问令牌，返回令牌和有效秒数 (未知时为 0)
+func fetchClientCredentialsToken(ctx context.Context, refresh *config.AuthRefreshConfig) (string, int64, error) {
+	clientID := os.Getenv(refresh.ClientIDEnv)
+	clientSecret := os.Getenv(refresh.ClientSecretEnv)
+	if clientID == "" || clientSecret == "" {
+		return "", 0, fmt.Errorf("环境变量 %s 或 %s 未设置或为空", refresh.ClientIDEnv, refresh.ClientSecretEnv)
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}, "client_id": {clientID}, "client_secret": {clientSecret}}
+	if len(refresh.Scopes) > 0 {
+		form.Set("scope", strings.Join(refresh.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, refresh.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("创建令牌请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("请求令牌端点失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("读取令牌响应失败: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", 0, fmt.Errorf("令牌端点返回状态码 %d", resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", 0, fmt.Errorf("解析令牌响应失败: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", 0, fmt.Errorf("令牌响应中没有 access_token")
+	}
+	return token.AccessToken, token.ExpiresIn, nil
+}
+
+// jwtExpiry 读取 JWT 令牌中的 exp，不是 JWT 或没有 exp 时返回零值；不校验签名
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(claims.Exp), 0)
+}
@@ -0,0 +1,110 @@
+// Package cache 缓存带有校验器 (ETag、Last-Modified) 的上游 GET 响应
+//
+// 缓存的响应不会直接返回，每次都通过 If-None-Match / If-Modified-Since 向上游确认，
+// 上游返回 304 Not Modified 时使用缓存的响应体，从而在不返回过期数据的前提下减少传输量。
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// DefaultMaxEntries 未配置 max_entries 时的缓存条目上限
+const DefaultMaxEntries = 1000
+
+// Entry 缓存的响应
+type Entry struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	ETag         string
+	LastModified string
+}
+
+// Store 按最近使用顺序淘汰的响应缓存，可并发使用
+type Store struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+	hits       int64
+	misses     int64
+}
+
+type item struct {
+	key   string
+	entry *Entry
+}
+
+// New 创建最多保存 maxEntries 个条目的缓存，maxEntries <= 0 时使用默认值
+func New(maxEntries int) *Store {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Store{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get 返回缓存的条目
+func (s *Store) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, exists := s.entries[key]
+	if !exists {
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*item).entry, true
+}
+
+// Put 保存条目，超过上限时淘汰最久未使用的条目
+func (s *Store) Put(key string, entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, exists := s.entries[key]; exists {
+		element.Value.(*item).entry = entry
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&item{key: key, entry: entry})
+	for s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*item).key)
+	}
+}
+
+// Delete 删除条目
+func (s *Store) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, exists := s.entries[key]; exists {
+		s.order.Remove(element)
+		delete(s.entries, key)
+	}
+}
+
+// RecordValidation 记录一次条件请求的结果，notModified 表示上游返回了 304
+func (s *Store) RecordValidation(notModified bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if notModified {
+		s.hits++
+	} else {
+		s.misses++
+	}
+}
+
+// Stats 返回条目数和条件请求的命中、未命中次数
+func (s *Store) Stats() (entries int, hits, misses int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len(), s.hits, s.misses
+}
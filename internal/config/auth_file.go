@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/mcp2rest/internal/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// authConfigFileName 自动查找的认证配置文件名
+const authConfigFileName = "auth_config.yaml"
+
+// envNamePattern 合法的环境变量名
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// authTypes 支持的认证类型
+var authTypes = map[string]bool{"bearer": true, "api_key": true, "basic": true, "oauth2": true, "ntlm": true, "negotiate": true}
+
+// LoadAuthConfigFile 读取认证配置文件，返回按安全方案名称索引的认证配置。文件可以直接以名称为键:
+//
+//	bmc_api:
+//	  type: api_key
+//	  header_name: X-API-Key
+//	  key_env: BMC_API_KEY
+//
+// 也可以放在 apis: 下；不使用 apis: 时顶层的 global 键被忽略 (兼容旧格式中的全局设置)
+func LoadAuthConfigFile(path string) (map[string]*AuthConfig, error) {
+	data, err := os.ReadFile(paths.Resolve(path))
+	if err != nil {
+		return nil, fmt.Errorf("读取认证配置文件失败: %w", err)
+	}
+
+	var root map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("解析认证配置文件 %s 失败: %w", path, err)
+	}
+	if apis, ok := root["apis"]; ok {
+		root = nil
+		if err := apis.Decode(&root); err != nil {
+			return nil, fmt.Errorf("解析认证配置文件 %s 失败: apis 必须是以名称为键的映射: %w", path, err)
+		}
+	} else {
+		delete(root, "global")
+	}
+
+	configs := make(map[string]*AuthConfig, len(root))
+	for name, node := range root {
+		var auth AuthConfig
+		if err := node.Decode(&auth); err != nil {
+			return nil, fmt.Errorf("解析认证配置文件 %s 中的 %s 失败: %w", path, name, err)
+		}
+		if err := validateAuthConfig(&auth); err != nil {
+			return nil, fmt.Errorf("认证配置文件 %s 中的 %s: %w", path, name, err)
+		}
+		configs[name] = &auth
+	}
+	return configs, nil
+}
+
+// validateAuthConfig 检查认证类型，未指定类型时沿用从安全方案推导的类型
+func validateAuthConfig(auth *AuthConfig) error {
+	if auth.Type != "" && !authTypes[auth.Type] {
+		return fmt.Errorf("不支持的认证类型: %s (支持: api_key, bearer, basic, oauth2, ntlm, negotiate)", auth.Type)
+	}
+	if auth.HeaderName != "" && auth.QueryParam != "" {
+		return fmt.Errorf("header_name 和 query_param 只能设置一个")
+	}
+	// 常见的错误是把凭据本身写进 token_env/key_env，错误消息中不包含取值
+	for field, name := range map[string]string{"token_env": auth.TokenEnv, "key_env": auth.KeyEnv} {
+		if name != "" && !envNamePattern.MatchString(name) {
+			return fmt.Errorf("%s 应为环境变量名 (字母、数字和下划线)，而不是凭据本身", field)
+		}
+	}
+	if auth.Refresh != nil {
+		return validateAuthRefresh(auth.Refresh)
+	}
+	return nil
+}
+
+// loadAuthConfig 加载 global.auth_config 指定或自动找到的认证配置文件，合并到 global.Auth
+func loadAuthConfig(global *GlobalConfig) error {
+	for name, auth := range global.Auth {
+		if auth == nil {
+			return fmt.Errorf("auth.%s 不能为空", name)
+		}
+		if err := validateAuthConfig(auth); err != nil {
+			return fmt.Errorf("auth.%s: %w", name, err)
+		}
+	}
+
+	path := global.AuthConfigFile
+	if path == "" {
+		path = findInSearchDirs(authConfigFileName)
+		if path == "" {
+			return nil
+		}
+	}
+	configs, err := LoadAuthConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	if global.Auth == nil {
+		global.Auth = make(map[string]*AuthConfig, len(configs))
+	}
+	for name, auth := range configs {
+		if _, exists := global.Auth[name]; !exists {
+			global.Auth[name] = auth
+		}
+	}
+	return nil
+}
+
+// validateAuthRefresh 检查凭据刷新配置
+func validateAuthRefresh(refresh *AuthRefreshConfig) error {
+	switch refresh.Type {
+	case "env":
+	case "command":
+		if len(refresh.Command) == 0 {
+			return fmt.Errorf("refresh.command 不能为空")
+		}
+	case "oauth2":
+		if refresh.TokenURL == "" || refresh.ClientIDEnv == "" || refresh.ClientSecretEnv == "" {
+			return fmt.Errorf("oauth2 刷新需要 token_url、client_id_env 和 client_secret_env")
+		}
+	default:
+		return fmt.Errorf("不支持的凭据刷新类型: %q (支持: env, command, oauth2)", refresh.Type)
+	}
+	return nil
+}
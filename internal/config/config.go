@@ -1,13 +1,16 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/mcp2rest/internal/paths"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,9 +22,23 @@ type Config struct {
 
 // ServerConfig 表示服务器配置
 type ServerConfig struct {
-	Port int    `yaml:"port"`
-	Host string `yaml:"host"`
-	Mode string `yaml:"mode"` // "stdio" 或 "sse"
+	Port int       `yaml:"port"`
+	Host string    `yaml:"host"`
+	Mode string    `yaml:"mode"` // "stdio" 或 "sse"
+	SSE  SSEConfig `yaml:"sse"`
+	// Framing 标准输入/输出的消息分帧方式: "ndjson" (默认)、"content-length" 或 "auto"
+	Framing string `yaml:"framing"`
+	// Ordering 标准输入/输出的响应顺序: "concurrent" (默认，处理完成即写出) 或 "ordered" (按请求顺序写出)
+	Ordering string `yaml:"ordering"`
+	// WriteTimeout 标准输出单条消息的写入超时，宿主进程停止读取时超时后关闭服务器，默认 30s，小于 0 表示不限制
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+}
+
+// SSEConfig 表示 SSE 连接的保活配置
+type SSEConfig struct {
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"` // 心跳间隔，默认 30s
+	WriteTimeout      time.Duration `yaml:"write_timeout"`      // 单次事件写入超时，超时或写入失败时关闭连接，0 表示不限制
+	MaxLifetime       time.Duration `yaml:"max_lifetime"`       // 连接最长存活时间，到期后关闭由客户端重连，0 表示不限制
 }
 
 // GlobalConfig 表示全局设置
@@ -29,6 +46,372 @@ type GlobalConfig struct {
 	Timeout        time.Duration     `yaml:"timeout"`
 	MaxRequestSize string            `yaml:"max_request_size"`
 	DefaultHeaders map[string]string `yaml:"default_headers"`
+	HideDeprecated bool              `yaml:"hide_deprecated"` // 是否在工具列表中隐藏已弃用的操作
+	Approval       ApprovalConfig    `yaml:"approval"`
+	Elicitation    ElicitationConfig `yaml:"elicitation"`
+	// ServerVariables 规范 servers 地址中变量 (如 {region}) 的取值，覆盖规范中的默认值，声明了 enum 时必须是其中之一
+	ServerVariables map[string]string `yaml:"server_variables"`
+	// MaxResponseSize 上游响应 (解压后) 的大小上限，超过时停止读取并返回错误，默认与 max_request_size 相同
+	MaxResponseSize string `yaml:"max_response_size"`
+	// TransformPlugins 启动时加载的 Go 插件路径，插件中的转换可通过 "custom:<name>" 引用
+	TransformPlugins []string `yaml:"transform_plugins"`
+	// Tags 只加载带有这些标签之一的操作，为空时加载全部操作
+	Tags []string `yaml:"tags"`
+	// ExcludeTags 不加载带有这些标签之一的操作，优先于 Tags
+	ExcludeTags []string `yaml:"exclude_tags"`
+	// ToolBudget 工具数量上限，超出时按标签把 REST 操作合并为分组工具，0 表示不限制
+	ToolBudget int `yaml:"tool_budget"`
+	// Catalog 按关键字搜索操作、按需获取操作完整模式的目录工具
+	Catalog CatalogConfig `yaml:"catalog"`
+	// Locale 返回给客户端的错误消息的语言 (zh 或 en)，默认 zh；SSE 请求的 Accept-Language 优先
+	Locale string `yaml:"locale"`
+	// Descriptions 描述覆盖文件，替换工具名称、标题、描述和参数描述 (如为英文智能体提供中文规范的译文)，格式见 LoadDescriptionsFile
+	Descriptions string `yaml:"descriptions"`
+	// SelfCheck 启动时检查规范、认证环境变量、上游连通性和日志目录，并把报告写入日志，默认启用
+	SelfCheck *bool `yaml:"self_check"`
+	// ClockCheck 启动时检查本地时钟与 SNTP 服务器的偏差，偏差过大时自检给出警告；max_skew 同时用于判断上游 Date 头的偏差
+	ClockCheck *ClockCheckConfig `yaml:"clock_check"`
+	// ExposeHeaders 需要包含在工具结果中的上游响应头
+	ExposeHeaders []string `yaml:"expose_headers"`
+	// StrictPathParams 拒绝会改变路径结构的路径参数值 (空值、"."、".." 等)，默认只记录警告
+	StrictPathParams bool `yaml:"strict_path_params"`
+	// PruneEmpty 发送前去掉值为空的可选参数: "empty" 去掉 null、空字符串、空数组和空对象，"zero" 还去掉 0 和 false，默认不处理
+	PruneEmpty string `yaml:"prune_empty"`
+	// FollowCreated POST 返回 201、Location 头且响应体为空时，自动获取新建的资源
+	FollowCreated bool `yaml:"follow_created"`
+	// ValidateResponses 按规范中声明的响应模式校验上游响应，不匹配之处记录在工具结果的 _meta 中
+	ValidateResponses bool `yaml:"validate_responses"`
+	// FixHints 上游返回 400 或 422 时从 problem+json 和常见的字段错误数组中提取逐个参数的修正提示，附加到工具错误结果的 fix_hints 中
+	FixHints bool `yaml:"fix_hints"`
+	// DescriptionExamples 在工具描述末尾附加由规范中的示例生成的调用示例和精简的示例响应
+	DescriptionExamples *DescriptionExamplesConfig `yaml:"description_examples"`
+	// Provenance 在工具结果的 _meta.provenance 中记录上游 URL、状态码、耗时、缓存命中等来源信息，默认启用
+	Provenance *bool `yaml:"provenance"`
+	// MaxResultChars 工具结果文本的最大字符数，超出部分截断并在 _meta.provenance 中标记，0 表示不限制
+	MaxResultChars int `yaml:"max_result_chars"`
+	// Compression 上游请求体压缩设置，响应始终按 Content-Encoding 解压
+	Compression CompressionConfig `yaml:"compression"`
+	// Cache 缓存带有 ETag/Last-Modified 的 GET 响应，通过条件请求向上游确认
+	Cache CacheConfig `yaml:"cache"`
+	// Coalesce 合并同时进行的相同 GET 请求 (相同操作、参数和凭据)，只向上游发送一次并共用响应
+	Coalesce bool `yaml:"coalesce"`
+	// TLS 访问上游时的 TLS 设置，如 mTLS 客户端证书
+	TLS *UpstreamTLSConfig `yaml:"tls"`
+	// Redirects 跟随上游重定向的策略，可被操作的 x-mcp-redirects 覆盖
+	Redirects RedirectConfig `yaml:"redirects"`
+	// HostOverrides 把上游主机名 ("host" 或 "host:port") 连接到指定的地址，不修改 Host 头和 TLS 服务器名称
+	HostOverrides map[string]string `yaml:"host_overrides"`
+	// GraphQL 与 REST 并存的 GraphQL 后端
+	GraphQL *GraphQLConfig `yaml:"graphql"`
+	// GRPC 通过 protobuf 描述符暴露一元 gRPC 方法
+	GRPC *GRPCConfig `yaml:"grpc"`
+	// Backends 具名的执行后端，操作通过 x-mcp-backend 选用，未选用的操作直接请求上游
+	Backends map[string]BackendConfig `yaml:"backends"`
+	// DryRun 不请求上游，REST 操作返回规范中响应的示例，没有示例时按响应模式生成，忽略 x-mcp-backend
+	DryRun bool `yaml:"dry_run"`
+	// Identity 附加到每个上游请求的 User-Agent 和客户端标识头
+	Identity IdentityConfig `yaml:"identity"`
+	// Deadline 把工具调用的剩余时间传给上游
+	Deadline DeadlineConfig `yaml:"deadline"`
+	// Summarize 通过外部模型摘要过长的工具结果，未启用时为 nil
+	Summarize *SummarizeConfig `yaml:"summarize"`
+	// Auth 按安全方案名称覆盖从规范推导的认证配置，与 auth_config 文件中的条目合并，这里的优先
+	Auth map[string]*AuthConfig `yaml:"auth"`
+	// AuthConfigFile 认证配置文件，未设置时在 .env 的查找目录中查找 auth_config.yaml，格式见 LoadAuthConfigFile
+	AuthConfigFile string `yaml:"auth_config"`
+	// SessionStore 多实例部署时共享 SSE 会话的存储，未配置时会话只保存在进程内
+	SessionStore *SessionStoreConfig `yaml:"session_store"`
+	// SessionResume SSE 会话恢复，连接断开或服务器重启后客户端可以用原会话ID继续
+	SessionResume *SessionResumeConfig `yaml:"session_resume"`
+	// Stats 工具调用统计
+	Stats StatsConfig `yaml:"stats"`
+	// Admin 管理接口
+	Admin AdminConfig `yaml:"admin"`
+	// Exit 客户端通过 exit 方法关闭服务器的设置
+	Exit ExitConfig `yaml:"exit"`
+	// Access 按入站客户端的身份限制可用的工具和调用频率，未配置时所有客户端都可以使用全部工具
+	Access *AccessConfig `yaml:"access"`
+	// Quotas 选定工具每小时、每天的调用次数上限，按会话或入站客户端计算
+	Quotas []QuotaConfig `yaml:"quotas"`
+	// Webhooks 入站 Webhook，仅 SSE 模式可用
+	Webhooks []WebhookConfig `yaml:"webhooks"`
+	// Schedules 定时执行的工具调用，结果以资源 schedule://<name> 提供
+	Schedules []ScheduleConfig `yaml:"schedules"`
+	// Workflows 由多个操作组成的组合工具
+	Workflows []WorkflowConfig `yaml:"workflows"`
+}
+
+// RedirectConfig 表示跟随上游重定向的策略，未设置的字段使用默认值
+type RedirectConfig struct {
+	// Follow 是否跟随重定向，为 false 时直接返回 3xx 响应，默认 true
+	Follow *bool `json:"follow" yaml:"follow"`
+	// Max 最多跟随的次数，默认 10
+	Max int `json:"max" yaml:"max"`
+	// PreserveMethod 307/308 重定向是否保留请求方法和请求体，为 false 时改为不带请求体的 GET，默认 true
+	PreserveMethod *bool `json:"preserve_method" yaml:"preserve_method"`
+	// StripAuth 重定向到其他协议、主机或端口时去掉 Authorization、Cookie 和 API 密钥头，默认 true
+	StripAuth *bool `json:"strip_auth" yaml:"strip_auth"`
+}
+
+// UpstreamTLSConfig 表示访问上游时的 TLS 设置
+type UpstreamTLSConfig struct {
+	ClientCert         string        `yaml:"client_cert"` // PEM 格式的客户端证书，用于 mTLS
+	ClientKey          string        `yaml:"client_key"`
+	CACert             string        `yaml:"ca_cert"` // 额外信任的 CA 证书 (PEM)
+	InsecureSkipVerify bool          `yaml:"insecure_skip_verify"`
+	ExpiryWarning      time.Duration `yaml:"expiry_warning"` // 证书剩余有效期低于该值时记录警告，默认 720h
+}
+
+// CatalogConfig 表示操作目录工具 (searchOperations、describeOperation) 的设置
+type CatalogConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Lazy 工具列表不再包含各个操作，操作通过 callOperation 调用，适用于非常大的规范
+	Lazy bool `yaml:"lazy"`
+}
+
+// CacheConfig 表示上游响应缓存设置
+type CacheConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MaxEntries int  `yaml:"max_entries"` // 缓存条目上限，默认 1000
+}
+
+// CompressionConfig 表示上游请求体压缩设置
+type CompressionConfig struct {
+	Requests bool   `yaml:"requests"` // 使用 gzip 压缩 JSON 请求体，可被操作的 x-mcp-compress-request 覆盖
+	MinSize  string `yaml:"min_size"` // 请求体达到该大小才压缩，默认 1KB
+}
+
+// WorkflowConfig 表示组合工具，按顺序执行多个操作，通过 jq 表达式把前面步骤的结果传给后续步骤
+type WorkflowConfig struct {
+	Name        string              `yaml:"name"`
+	Description string              `yaml:"description"`
+	Parameters  []WorkflowParameter `yaml:"parameters"`
+	Steps       []WorkflowStep      `yaml:"steps"`
+	// Output 生成工具结果的 jq 表达式，输入为 {params, steps}，默认返回所有步骤的结果
+	Output string `yaml:"output"`
+}
+
+// WorkflowParameter 表示组合工具的输入参数
+type WorkflowParameter struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"` // 默认 string
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+}
+
+// WorkflowStep 表示组合工具中的一个步骤
+type WorkflowStep struct {
+	Name      string `yaml:"name"`      // 步骤名称，默认使用操作ID
+	Operation string `yaml:"operation"` // 操作ID，也可以是 GraphQL 或 gRPC 后端提供的工具
+	// Params 参数名到 jq 表达式的映射，表达式的输入为 {params, steps}
+	Params map[string]string `yaml:"params"`
+	// Compensate 后续步骤失败时执行的补偿操作，如删除本步骤创建的资源
+	Compensate *WorkflowCompensation `yaml:"compensate"`
+}
+
+// WorkflowCompensation 表示步骤的补偿操作，表达式的输入中 steps 包含本步骤的结果
+type WorkflowCompensation struct {
+	Operation string            `yaml:"operation"`
+	Params    map[string]string `yaml:"params"`
+}
+
+// ScheduleConfig 表示定时执行的工具调用
+type ScheduleConfig struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Cron        string                 `yaml:"cron"`     // 5 字段 cron 表达式，或 @hourly、@daily、@every 10m 等
+	Timezone    string                 `yaml:"timezone"` // 如 Asia/Shanghai，默认使用本地时区
+	Tool        string                 `yaml:"tool"`
+	Params      map[string]interface{} `yaml:"params"`
+	Keep        int                    `yaml:"keep"`         // 保留的最近结果数，默认 10
+	RunOnStart  bool                   `yaml:"run_on_start"` // 启动时立即执行一次
+}
+
+// WebhookConfig 表示入站 Webhook 配置，POST /webhooks/<name> 收到的事件缓存为 MCP 资源
+type WebhookConfig struct {
+	Name            string            `yaml:"name"`
+	Description     string            `yaml:"description"`
+	URI             string            `yaml:"uri"`              // 事件资源 URI，默认 events://webhooks/<name>
+	Secret          string            `yaml:"secret"`           // 非空时校验请求体的 HMAC-SHA256 签名
+	SignatureHeader string            `yaml:"signature_header"` // 携带签名的请求头，默认 X-Hub-Signature-256
+	Buffer          int               `yaml:"buffer"`           // 保留的最近事件数，默认 100
+	Notify          string            `yaml:"notify"`           // 收到事件时通知: subscribers (默认)、all 或 none
+	Transform       TransformPipeline `yaml:"transform"`        // 保存前对事件内容执行的转换
+	// TimestampHeader 非空时签名内容为 "<时间戳>.<请求体>"，时间戳 (Unix 秒或 RFC 3339) 与本地时钟的偏差不能超过 MaxSkew
+	TimestampHeader string        `yaml:"timestamp_header"`
+	MaxSkew         time.Duration `yaml:"max_skew"` // 默认 5m
+}
+
+// ClockCheckConfig 表示时钟检查配置
+type ClockCheckConfig struct {
+	Server  string        `yaml:"server"`   // SNTP 服务器，如 pool.ntp.org，为空时只根据上游响应的 Date 头提示偏差
+	MaxSkew time.Duration `yaml:"max_skew"` // 允许的时钟偏差，默认 30s
+	Timeout time.Duration `yaml:"timeout"`  // 查询 SNTP 服务器的超时时间，默认 5s
+}
+
+// DefaultMaxClockSkew 未配置 clock_check.max_skew 时允许的时钟偏差
+const DefaultMaxClockSkew = 30 * time.Second
+
+// AllowedSkew 返回允许的时钟偏差，未配置时钟检查时使用默认值
+func (c *ClockCheckConfig) AllowedSkew() time.Duration {
+	if c == nil || c.MaxSkew <= 0 {
+		return DefaultMaxClockSkew
+	}
+	return c.MaxSkew
+}
+
+// DescriptionExamplesConfig 表示工具描述中的调用示例配置
+type DescriptionExamplesConfig struct {
+	MaxResponseChars int  `yaml:"max_response_chars"` // 示例响应的最大字符数，默认 300，-1 表示不附加示例响应
+	Synthesized      bool `yaml:"synthesized"`        // 规范没有示例响应时使用按响应模式生成的数据，默认不使用
+}
+
+// StatsConfig 表示工具调用统计配置，统计始终在内存中记录
+type StatsConfig struct {
+	File          string        `yaml:"file"`           // 持久化文件，启动时加载、停止时写入，为空时不持久化
+	LogInterval   time.Duration `yaml:"log_interval"`   // 周期性输出统计摘要的间隔，0 表示不输出
+	SlowThreshold time.Duration `yaml:"slow_threshold"` // 超过该耗时的调用记为慢调用并记录日志，0 表示不记录
+}
+
+// IdentityConfig 表示发送给上游的客户端标识，值为 Go 模板，可以引用 .Version (mcp2rest 版本)、
+// .API 和 .APIVersion (规范的标题和版本)、.GoVersion、.OS 和 .Arch
+type IdentityConfig struct {
+	UserAgent string            `yaml:"user_agent"` // 为空时使用 mcp2rest/<版本>，default_headers 中的 User-Agent 优先于默认值
+	Headers   map[string]string `yaml:"headers"`    // 其他标识头，如 X-Client-Name
+}
+
+// SummarizeConfig 表示结果摘要配置，过长的工具结果发送给 OpenAI 兼容的对话接口或通过客户端的 sampling 生成摘要，
+// 原始结果保存为资源，工具结果中附带指向它的 resource_link
+type SummarizeConfig struct {
+	Sampling      string        `yaml:"sampling"`        // "off" (默认)、"prefer" (客户端支持 sampling 时优先使用) 或 "only" (只使用 sampling，不需要 endpoint)
+	Endpoint      string        `yaml:"endpoint"`        // 接口地址，如 https://api.openai.com/v1，请求发送到 <endpoint>/chat/completions
+	Model         string        `yaml:"model"`           // 模型名称，使用 sampling 时作为模型提示发送给客户端
+	APIKey        string        `yaml:"api_key"`         // API 密钥
+	APIKeyEnv     string        `yaml:"api_key_env"`     // 从环境变量读取 API 密钥，优先于 api_key
+	Prompt        string        `yaml:"prompt"`          // 系统提示词，为空时使用内置的提示词
+	Threshold     int           `yaml:"threshold"`       // 结果超过该字符数时摘要，默认为 max_result_chars，未设置时为 20000
+	MaxInputChars int           `yaml:"max_input_chars"` // 发送给模型的最大字符数，超出部分截断，默认 100000
+	MaxTokens     int           `yaml:"max_tokens"`      // 摘要的最大令牌数，默认 1024
+	Timeout       time.Duration `yaml:"timeout"`         // 请求超时，默认 60s
+	Keep          int           `yaml:"keep"`            // 保留的原始结果数量，超出时丢弃最早的，默认 50
+	Tools         []string      `yaml:"tools"`           // 只摘要这些工具的结果，为空时适用于所有工具
+}
+
+// DeadlineConfig 表示截止时间传递配置，启用后工具调用以 global.timeout 为截止时间，
+// 每个上游请求通过请求头携带剩余时间并以此作为自身的截止时间，上游可以放弃网关已不再等待的工作
+type DeadlineConfig struct {
+	Propagate bool          `yaml:"propagate"`
+	Header    string        `yaml:"header"` // 请求头名称，默认 X-Request-Timeout
+	Format    string        `yaml:"format"` // seconds (默认，如 4.750)、ms 或 grpc (如 4750m)
+	Margin    time.Duration `yaml:"margin"` // 从剩余时间中扣除的余量，用于网络传输和结果处理
+}
+
+// ExitConfig 表示 exit 方法的配置
+type ExitConfig struct {
+	// Enabled 是否允许客户端通过 exit 关闭服务器，未设置时 stdio 模式允许，sse 模式不允许
+	Enabled *bool `yaml:"enabled"`
+	// DrainTimeout 关闭前等待正在执行的工具调用完成的最长时间，默认 10s
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+}
+
+// AccessConfig 表示入站客户端的访问控制配置
+type AccessConfig struct {
+	// Clients 客户端列表，按令牌或主体识别，未携带凭据的请求使用没有配置令牌和主体的客户端
+	Clients []AccessClientConfig `yaml:"clients"`
+	// SubjectHeader 前置认证代理传递客户端主体的请求头，如 X-Forwarded-User，为空时不按主体识别
+	SubjectHeader string `yaml:"subject_header"`
+	// StdioClient 标准输入/输出模式使用的客户端名称，为空时不限制
+	StdioClient string `yaml:"stdio_client"`
+}
+
+// AccessClientConfig 表示一个入站客户端的权限
+type AccessClientConfig struct {
+	Name     string `yaml:"name"`
+	Token    string `yaml:"token"`     // 请求携带 Authorization: Bearer <token> 时匹配
+	TokenEnv string `yaml:"token_env"` // 从环境变量读取令牌，优先于 token
+	Subject  string `yaml:"subject"`   // subject_header 的值等于该值时匹配
+	// Tools 允许使用的工具名称，支持 * 和 ? 通配符，为空时允许全部工具
+	Tools []string `yaml:"tools"`
+	// ExcludeTools 禁止使用的工具名称，优先于 Tools
+	ExcludeTools []string `yaml:"exclude_tools"`
+	// ReadOnly 只允许只读的工具：GET/HEAD/OPTIONS 操作和操作目录工具
+	ReadOnly bool `yaml:"read_only"`
+	// RateLimit 每分钟最多调用工具的次数，同一客户端的所有会话共享，0 表示不限制
+	RateLimit int `yaml:"rate_limit"`
+	// Burst 允许的突发调用次数，默认等于 RateLimit
+	Burst int `yaml:"burst"`
+}
+
+// QuotaConfig 表示一组工具共享的调用配额
+type QuotaConfig struct {
+	Name string `yaml:"name"` // 配额名称，出现在超出配额的错误中
+	// Tools 计入该配额的工具名称，支持 * 和 ? 通配符，分组工具和 callOperation 按所选的操作计算
+	Tools  []string `yaml:"tools"`
+	Per    string   `yaml:"per"`    // "session" (默认) 按会话计算，"client" 按入站客户端计算
+	Hourly int      `yaml:"hourly"` // 每小时最多调用次数，0 表示不限制
+	Daily  int      `yaml:"daily"`  // 每天最多调用次数，0 表示不限制
+}
+
+// AdminConfig 表示管理接口配置
+type AdminConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Address string `yaml:"address"` // 独立的监听地址，如 127.0.0.1:9090；为空时 SSE 模式挂载在主端口的 /admin/ 下
+	Token   string `yaml:"token"`   // 非空时要求请求携带 Authorization: Bearer <token>
+}
+
+// SessionStoreConfig 表示共享会话存储配置
+type SessionStoreConfig struct {
+	Type      string        `yaml:"type"` // 目前仅支持 "redis"
+	Address   string        `yaml:"address"`
+	Password  string        `yaml:"password"`
+	DB        int           `yaml:"db"`
+	KeyPrefix string        `yaml:"key_prefix"` // 键和频道名前缀，默认 mcp2rest
+	TTL       time.Duration `yaml:"ttl"`        // 会话记录的过期时间，默认 10m，心跳和消息会续期
+}
+
+// SessionResumeConfig 表示 SSE 会话恢复配置
+type SessionResumeConfig struct {
+	Type       string        `yaml:"type"`        // "file" 或 "redis"，redis 使用 session_store 的连接设置
+	Dir        string        `yaml:"dir"`         // file 类型的保存目录，默认 sessions
+	TTL        time.Duration `yaml:"ttl"`         // 连接断开后会话保留的时间，默认 5m
+	MaxPending int           `yaml:"max_pending"` // 连接断开期间最多缓存的待发送消息数，默认 100
+}
+
+// GraphQLConfig 表示 GraphQL 后端配置，文档中的每个具名 query/mutation 都会成为一个工具
+type GraphQLConfig struct {
+	Endpoint   string            `yaml:"endpoint"`
+	Documents  []string          `yaml:"documents"`   // .graphql 操作文档路径
+	ToolPrefix string            `yaml:"tool_prefix"` // 工具名称前缀，用于避免与 REST 工具重名
+	Headers    map[string]string `yaml:"headers"`
+	Auth       AuthConfig        `yaml:"auth"`
+}
+
+// BackendConfig 表示执行后端配置
+type BackendConfig struct {
+	Type string `yaml:"type"` // "mock" 返回固定的响应，"example" 返回规范中的示例响应，"recorded" 回放或录制上游响应
+	// mock 后端的默认响应，Responses 按工具名称覆盖
+	MockResponse `yaml:",inline"`
+	Responses    map[string]MockResponse `yaml:"responses"`
+	// recorded 后端的录制目录和模式
+	Dir  string `yaml:"dir"`  // 录制文件目录，默认 recordings
+	Mode string `yaml:"mode"` // "replay" (默认) 只回放，没有录制时返回错误；"record" 请求上游并保存响应
+}
+
+// MockResponse 表示 mock 后端返回的响应
+type MockResponse struct {
+	Status  int               `yaml:"status"` // 默认 200
+	Headers map[string]string `yaml:"headers"`
+	Body    interface{}       `yaml:"body"` // 字符串原样返回，其他值编码为 JSON
+	// Fake 没有 body 时返回规范中的示例，没有示例时按响应模式生成假数据
+	Fake bool `yaml:"fake"`
+}
+
+// ApprovalConfig 表示破坏性操作的人工确认配置
+type ApprovalConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Methods []string      `yaml:"methods"` // 需要确认的HTTP方法，未配置方法和工具时默认为 DELETE/PUT/POST
+	Tools   []string      `yaml:"tools"`   // 需要确认的工具名称列表
+	TTL     time.Duration `yaml:"ttl"`     // 待确认令牌的有效期
 }
 
 // OpenAPISpec 表示 OpenAPI 规范
@@ -50,13 +433,114 @@ type OpenAPIInfo struct {
 
 // OpenAPIServer 表示 OpenAPI 服务器
 type OpenAPIServer struct {
-	URL         string `json:"url" yaml:"url"`
-	Description string `json:"description" yaml:"description"`
+	URL         string                    `json:"url" yaml:"url"`
+	Description string                    `json:"description" yaml:"description"`
+	Variables   map[string]ServerVariable `json:"variables" yaml:"variables"` // URL 中 {name} 形式的变量
+}
+
+// ServerVariable 表示服务器地址中的变量
+type ServerVariable struct {
+	Default     string   `json:"default" yaml:"default"`
+	Enum        []string `json:"enum" yaml:"enum"`
+	Description string   `json:"description" yaml:"description"`
 }
 
-// PathItem 表示路径项
+// PathItem 表示路径项，键为小写的 HTTP 方法
+// 路径级的 servers 和 parameters 在解析时合并到该路径的每个操作中，其他非操作字段 (summary、description 等) 被忽略
 type PathItem map[string]Operation
 
+// pathItemMethods 路径项中表示操作的键
+var pathItemMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true,
+}
+
+// pathItemFields 路径项的原始字段，操作在合并路径级字段之前解析
+type pathItemFields struct {
+	operations map[string]Operation
+	servers    []OpenAPIServer
+	parameters []Parameter
+}
+
+// merge 把路径级的 servers 和 parameters 合并到操作中：操作声明了 servers 时使用自己的，
+// 同名同位置的参数以操作中的为准
+func (f pathItemFields) merge() PathItem {
+	item := make(PathItem, len(f.operations))
+	for method, operation := range f.operations {
+		if len(operation.Servers) == 0 && len(f.servers) > 0 {
+			operation.Servers = f.servers
+		}
+		if len(f.parameters) > 0 {
+			declared := make(map[string]bool, len(operation.Parameters))
+			for _, param := range operation.Parameters {
+				declared[param.In+"\x00"+param.Name] = true
+			}
+			var inherited []Parameter
+			for _, param := range f.parameters {
+				if !declared[param.In+"\x00"+param.Name] {
+					inherited = append(inherited, param)
+				}
+			}
+			operation.Parameters = append(inherited, operation.Parameters...)
+		}
+		item[method] = operation
+	}
+	return item
+}
+
+// UnmarshalJSON 解析路径项并合并路径级字段
+func (p *PathItem) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	fields := pathItemFields{operations: make(map[string]Operation)}
+	for key, value := range raw {
+		var err error
+		switch key = strings.ToLower(key); {
+		case pathItemMethods[key]:
+			var operation Operation
+			err = json.Unmarshal(value, &operation)
+			fields.operations[key] = operation
+		case key == "servers":
+			err = json.Unmarshal(value, &fields.servers)
+		case key == "parameters":
+			err = json.Unmarshal(value, &fields.parameters)
+		}
+		if err != nil {
+			return fmt.Errorf("解析路径项的 %s 失败: %w", key, err)
+		}
+	}
+	*p = fields.merge()
+	return nil
+}
+
+// UnmarshalYAML 解析路径项并合并路径级字段
+func (p *PathItem) UnmarshalYAML(value *yaml.Node) error {
+	var raw map[string]yaml.Node
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	fields := pathItemFields{operations: make(map[string]Operation)}
+	for key, node := range raw {
+		var err error
+		switch key = strings.ToLower(key); {
+		case pathItemMethods[key]:
+			var operation Operation
+			err = node.Decode(&operation)
+			fields.operations[key] = operation
+		case key == "servers":
+			err = node.Decode(&fields.servers)
+		case key == "parameters":
+			err = node.Decode(&fields.parameters)
+		}
+		if err != nil {
+			return fmt.Errorf("解析路径项的 %s 失败: %w", key, err)
+		}
+	}
+	*p = fields.merge()
+	return nil
+}
+
 // Operation 表示操作
 type Operation struct {
 	Summary     string                 `json:"summary" yaml:"summary"`
@@ -67,6 +551,28 @@ type Operation struct {
 	RequestBody RequestBody            `json:"requestBody" yaml:"requestBody"`
 	Responses   map[string]Response    `json:"responses" yaml:"responses"`
 	Security    []map[string][]string  `json:"security" yaml:"security"`
+	Deprecated  bool                   `json:"deprecated" yaml:"deprecated"`
+	Servers     []OpenAPIServer        `json:"servers" yaml:"servers"` // 操作级 servers，覆盖路径级和规范级的 servers
+	Transform   TransformPipeline      `json:"x-mcp-transform" yaml:"x-mcp-transform"`
+	Errors      map[string]ErrorMapping `json:"x-mcp-errors" yaml:"x-mcp-errors"` // 键为状态码、"4XX" 形式的范围或 "default"
+	ExposeHeaders []string             `json:"x-mcp-expose-headers" yaml:"x-mcp-expose-headers"`
+	FollowLocation *bool               `json:"x-mcp-follow-location" yaml:"x-mcp-follow-location"` // 覆盖全局 follow_created 设置
+	Poll        *PollConfig            `json:"x-mcp-poll" yaml:"x-mcp-poll"`
+	BodyTemplate string                `json:"x-mcp-body-template" yaml:"x-mcp-body-template"` // 把工具参数渲染为 JSON 请求体的 Go 模板
+	Redirects   *RedirectConfig        `json:"x-mcp-redirects" yaml:"x-mcp-redirects"` // 覆盖全局 redirects 中设置了的字段
+	Patch       string                 `json:"x-mcp-patch" yaml:"x-mcp-patch"` // "merge" 或 "json-patch"，按补丁格式发送请求体
+	PruneEmpty  string                 `json:"x-mcp-prune-empty" yaml:"x-mcp-prune-empty"` // 覆盖全局 prune_empty 设置，"off" 表示不处理
+	XML         *XMLConfig             `json:"x-mcp-xml" yaml:"x-mcp-xml"`
+	CSV         *CSVConfig             `json:"x-mcp-csv" yaml:"x-mcp-csv"`
+	CompressRequest *bool              `json:"x-mcp-compress-request" yaml:"x-mcp-compress-request"` // 覆盖全局 compression.requests 设置
+	MaxResponseSize string             `json:"x-mcp-max-response-size" yaml:"x-mcp-max-response-size"` // 覆盖全局 max_response_size 设置
+	Cache       *bool                  `json:"x-mcp-cache" yaml:"x-mcp-cache"` // 为 false 时不缓存该操作的响应
+	Coalesce    *bool                  `json:"x-mcp-coalesce" yaml:"x-mcp-coalesce"` // 为 false 时不合并该操作的并发请求
+	ValidateResponse *bool             `json:"x-mcp-validate-response" yaml:"x-mcp-validate-response"` // 覆盖全局 validate_responses 设置
+	FixHints    *bool                  `json:"x-mcp-fix-hints" yaml:"x-mcp-fix-hints"` // 覆盖全局 fix_hints 设置
+	DescriptionExample *bool           `json:"x-mcp-description-example" yaml:"x-mcp-description-example"` // 为 false 时不在该工具的描述中附加示例
+	SuccessCodes StatusCodes           `json:"x-mcp-success-codes" yaml:"x-mcp-success-codes"` // 表示成功的状态码 (如 207、"3XX")，设置后取代默认的 2xx 判断
+	Backend     string                 `json:"x-mcp-backend" yaml:"x-mcp-backend"` // global.backends 中或嵌入方注册的执行后端名称，为空时直接请求上游
 }
 
 // Parameter 表示参数
@@ -77,6 +583,10 @@ type Parameter struct {
 	Required    bool        `json:"required" yaml:"required"`
 	Schema      Schema      `json:"schema" yaml:"schema"`
 	Example     interface{} `json:"example" yaml:"example"`
+	// AllowReserved 路径参数的值中 RFC 3986 保留字符 (如 /) 不编码，用于本身包含多段路径的参数
+	AllowReserved bool `json:"allowReserved" yaml:"allowReserved"`
+	// DateFormat 日期时间参数发送给上游的格式，覆盖 schema.format，见 Schema.DateFormat
+	DateFormat string `json:"x-mcp-format" yaml:"x-mcp-format"`
 }
 
 // RequestBody 表示请求体
@@ -88,17 +598,41 @@ type RequestBody struct {
 
 // MediaType 表示媒体类型
 type MediaType struct {
-	Schema Schema `json:"schema" yaml:"schema"`
+	Schema   Schema             `json:"schema" yaml:"schema"`
+	Example  interface{}        `json:"example" yaml:"example"`
+	Examples map[string]Example `json:"examples" yaml:"examples"`
+}
+
+// Example 表示具名示例，只支持内联的 value
+type Example struct {
+	Summary string      `json:"summary" yaml:"summary"`
+	Value   interface{} `json:"value" yaml:"value"`
 }
 
 // Schema 表示模式
 type Schema struct {
-	Type       string                 `json:"type" yaml:"type"`
-	Format     string                 `json:"format" yaml:"format"`
-	Properties map[string]Schema      `json:"properties" yaml:"properties"`
-	Required   []string               `json:"required" yaml:"required"`
-	Items      *Schema                `json:"items" yaml:"items"`
-	Ref        string                 `json:"$ref" yaml:"$ref"`
+	Type        string            `json:"type" yaml:"type"`
+	Format      string            `json:"format" yaml:"format"`
+	Properties  map[string]Schema `json:"properties" yaml:"properties"`
+	Required    []string          `json:"required" yaml:"required"`
+	Items       *Schema           `json:"items" yaml:"items"`
+	Ref         string            `json:"$ref" yaml:"$ref"`
+	Nullable    bool              `json:"nullable" yaml:"nullable"`
+	Default     interface{}       `json:"default" yaml:"default"`
+	Description string            `json:"description" yaml:"description"`
+	Enum        []interface{}     `json:"enum" yaml:"enum"`
+	Example     interface{}       `json:"example" yaml:"example"`
+	// 数值、字符串长度和数组长度的约束，生成示例数据时遵守
+	Minimum          *float64 `json:"minimum" yaml:"minimum"`
+	Maximum          *float64 `json:"maximum" yaml:"maximum"`
+	ExclusiveMinimum bool     `json:"exclusiveMinimum" yaml:"exclusiveMinimum"`
+	ExclusiveMaximum bool     `json:"exclusiveMaximum" yaml:"exclusiveMaximum"`
+	MinLength        *int     `json:"minLength" yaml:"minLength"`
+	MaxLength        *int     `json:"maxLength" yaml:"maxLength"`
+	MinItems         *int     `json:"minItems" yaml:"minItems"`
+	MaxItems         *int     `json:"maxItems" yaml:"maxItems"`
+	// DateFormat 日期时间字段发送给上游的格式: "date"、"date-time"、"unix"、"unix-ms" 或 Go 时间布局 (如 "2006/01/02")
+	DateFormat string `json:"x-mcp-format" yaml:"x-mcp-format"`
 }
 
 // Response 表示响应
@@ -107,6 +641,139 @@ type Response struct {
 	Content     map[string]MediaType `json:"content" yaml:"content"`
 }
 
+// TransformConfig 表示响应转换步骤
+type TransformConfig struct {
+	Type       string                 `json:"type" yaml:"type"`             // "jq"、"jsonpath"、"fields"、"template" 或 "custom:<name>"
+	Expression string                 `json:"expression" yaml:"expression"` // jq 或 JSONPath 表达式
+	Template   string                 `json:"template" yaml:"template"`     // Go 模板
+	Fields     []string               `json:"fields" yaml:"fields"`         // 保留的字段路径，使用 "." 分隔
+	Args       map[string]interface{} `json:"args" yaml:"args"`             // 自定义转换的参数
+}
+
+// TransformPipeline 表示按顺序执行的响应转换步骤，规范中可写为单个对象或数组
+type TransformPipeline []TransformConfig
+
+// UnmarshalJSON 支持单个对象或数组形式
+func (p *TransformPipeline) UnmarshalJSON(data []byte) error {
+	var steps []TransformConfig
+	if err := json.Unmarshal(data, &steps); err == nil {
+		*p = steps
+		return nil
+	}
+
+	var step TransformConfig
+	if err := json.Unmarshal(data, &step); err != nil {
+		return fmt.Errorf("解析x-mcp-transform失败: %w", err)
+	}
+	*p = TransformPipeline{step}
+	return nil
+}
+
+// UnmarshalYAML 支持单个对象或数组形式
+func (p *TransformPipeline) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		var steps []TransformConfig
+		if err := value.Decode(&steps); err != nil {
+			return fmt.Errorf("解析x-mcp-transform失败: %w", err)
+		}
+		*p = steps
+		return nil
+	}
+
+	var step TransformConfig
+	if err := value.Decode(&step); err != nil {
+		return fmt.Errorf("解析x-mcp-transform失败: %w", err)
+	}
+	*p = TransformPipeline{step}
+	return nil
+}
+
+// statusCodePattern 状态码 (如 207) 或状态码范围 (如 3XX)
+var statusCodePattern = regexp.MustCompile(`^[1-5]([0-9]{2}|[Xx]{2})$`)
+
+// StatusCodes 表示一组状态码或状态码范围，规范中可写为单个值或数组，数字和字符串均可
+type StatusCodes []string
+
+// UnmarshalJSON 支持单个值或数组形式
+func (c *StatusCodes) UnmarshalJSON(data []byte) error {
+	var values []interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("解析x-mcp-success-codes失败: %w", err)
+		}
+		values = []interface{}{value}
+	}
+	return c.set(values)
+}
+
+// UnmarshalYAML 支持单个值或数组形式
+func (c *StatusCodes) UnmarshalYAML(value *yaml.Node) error {
+	var values []interface{}
+	if value.Kind == yaml.SequenceNode {
+		if err := value.Decode(&values); err != nil {
+			return fmt.Errorf("解析x-mcp-success-codes失败: %w", err)
+		}
+	} else {
+		var single interface{}
+		if err := value.Decode(&single); err != nil {
+			return fmt.Errorf("解析x-mcp-success-codes失败: %w", err)
+		}
+		values = []interface{}{single}
+	}
+	return c.set(values)
+}
+
+// set 把解析得到的数字或字符串转换为状态码，范围统一为大写的 XX
+func (c *StatusCodes) set(values []interface{}) error {
+	codes := make(StatusCodes, 0, len(values))
+	for _, value := range values {
+		code := fmt.Sprintf("%v", value)
+		if !statusCodePattern.MatchString(code) {
+			return fmt.Errorf("x-mcp-success-codes 中的状态码无效: %q (应为 100-599 或 2XX 形式的范围)", code)
+		}
+		codes = append(codes, strings.ToUpper(code))
+	}
+	*c = codes
+	return nil
+}
+
+// ErrorMapping 表示错误响应的映射
+type ErrorMapping struct {
+	Message   string            `json:"message" yaml:"message"`     // 返回给客户端的错误消息，支持模板
+	Transform TransformPipeline `json:"transform" yaml:"transform"` // 对错误响应体执行的转换
+}
+
+// XMLConfig 表示 x-mcp-xml 扩展，用于以 XML 或 SOAP 信封发送请求体
+type XMLConfig struct {
+	Template    string `json:"template" yaml:"template"`         // 请求体模板，参数作为模板数据；SOAP 请求只需渲染 Body 内容
+	Root        string `json:"root" yaml:"root"`                 // 未配置模板时参数序列化的根元素名称，默认使用操作ID
+	Namespace   string `json:"namespace" yaml:"namespace"`       // 根元素的默认命名空间
+	SOAPAction  string `json:"soap_action" yaml:"soap_action"`   // 配置后使用 SOAP 信封
+	SOAPVersion string `json:"soap_version" yaml:"soap_version"` // "1.1" (默认) 或 "1.2"
+}
+
+// CSVConfig 表示 x-mcp-csv 扩展，控制 CSV 响应的解析
+type CSVConfig struct {
+	Delimiter string            `json:"delimiter" yaml:"delimiter"` // 分隔符，默认为逗号 (TSV 为制表符)
+	Columns   map[string]string `json:"columns" yaml:"columns"`     // 列名到类型的映射: string、integer、number 或 boolean
+	Infer     bool              `json:"infer" yaml:"infer"`         // 未在 columns 中声明的列按值推断为数字或布尔值
+}
+
+// IsSOAP 检查是否需要使用 SOAP 信封
+func (c *XMLConfig) IsSOAP() bool {
+	return c != nil && (c.SOAPAction != "" || c.SOAPVersion != "")
+}
+
+// PollConfig 表示返回 202 的异步操作的轮询配置
+type PollConfig struct {
+	StatusURL string `json:"status_url" yaml:"status_url"` // 从 202 响应体中提取状态地址的 jq 表达式，默认使用 Location 头
+	Done      string `json:"done" yaml:"done"`             // 判断操作完成的 jq 表达式，未配置时状态地址不再返回 202 即视为完成
+	Failed    string `json:"failed" yaml:"failed"`         // 判断操作失败的 jq 表达式
+	Interval  string `json:"interval" yaml:"interval"`     // 轮询间隔，默认 2s
+	MaxWait   string `json:"max_wait" yaml:"max_wait"`     // 最长等待时间，默认 5m
+}
+
 // OpenAPIComponents 表示组件
 type OpenAPIComponents struct {
 	Schemas         map[string]Schema         `json:"schemas" yaml:"schemas"`
@@ -119,42 +786,59 @@ type SecurityScheme struct {
 	Scheme string `json:"scheme" yaml:"scheme"`
 	Name   string `json:"name" yaml:"name"`
 	In     string `json:"in" yaml:"in"`
+	// Auth 覆盖从方案推导的认证配置，来自 global.auth 或认证配置文件中同名的条目
+	Auth *AuthConfig `json:"-" yaml:"-"`
+}
+
+// GRPCConfig 表示 gRPC 后端配置
+type GRPCConfig struct {
+	Target             string            `yaml:"target"`         // 目标地址，必须为 https://host:port
+	DescriptorSet      string            `yaml:"descriptor_set"` // protoc --include_imports --descriptor_set_out 生成的文件
+	Services           []string          `yaml:"services"`       // 暴露的服务全名，为空时暴露所有服务
+	ToolPrefix         string            `yaml:"tool_prefix"`
+	Metadata           map[string]string `yaml:"metadata"` // 附加到每次调用的元数据
+	InsecureSkipVerify bool              `yaml:"insecure_skip_verify"`
+	Auth               AuthConfig        `yaml:"auth"`
 }
 
 // AuthConfig 表示身份验证配置
 type AuthConfig struct {
-	Type       string `yaml:"type"`        // "bearer", "api_key", "basic", "oauth2"
-	TokenEnv   string `yaml:"token_env"`   // 环境变量名，用于获取令牌
-	HeaderName string `yaml:"header_name"` // 自定义头名称，用于API密钥
-	KeyEnv     string `yaml:"key_env"`     // 环境变量名，用于获取API密钥
-	Username   string `yaml:"username"`    // 用于基本身份验证
-	Password   string `yaml:"password"`    // 用于基本身份验证
+	Type        string `yaml:"type"`        // "bearer", "api_key", "basic", "oauth2", "ntlm", "negotiate"
+	TokenEnv    string `yaml:"token_env"`   // 环境变量名，用于获取令牌
+	HeaderName  string `yaml:"header_name"` // 自定义头名称，用于API密钥
+	QueryParam  string `yaml:"query_param"` // 查询参数名称，API密钥通过查询参数传递时使用
+	KeyEnv      string `yaml:"key_env"`     // 环境变量名，用于获取API密钥
+	Username    string `yaml:"username"`    // 用于基本身份验证
+	Password    string `yaml:"password"`    // 用于基本身份验证
+	Description string `yaml:"description"` // 说明，只用于文档和诊断输出
+	// negotiate 使用 Kerberos 时的 krb5.conf 路径、keytab 路径 (代替密码) 和服务主体名 (默认 HTTP/<主机名>)，
+	// 需要使用 kerberos 构建标签编译
+	Krb5Config string `yaml:"krb5_config"`
+	Keytab     string `yaml:"keytab"`
+	SPN        string `yaml:"spn"`
+	// Refresh 上游返回 401 或令牌即将过期时刷新凭据，未配置时认证失败直接返回给客户端
+	Refresh *AuthRefreshConfig `yaml:"refresh"`
 }
 
-// resolveConfigPath 解析配置文件路径，支持从可执行文件目录或上一级目录查找
-func resolveConfigPath(exeDir, configPath string) string {
-	// 如果已经是绝对路径，直接返回
-	if filepath.IsAbs(configPath) {
-		return configPath
-	}
-
-	// 优先检查可执行文件目录下的路径
-	joinedPath := filepath.Join(exeDir, configPath)
-	if _, err := os.Stat(joinedPath); err == nil {
-		return joinedPath
-	}
-
-	// 如果文件不存在且可执行文件位于 bin 目录，则尝试上一级目录
-	if filepath.Base(exeDir) == "bin" {
-		parentDir := filepath.Dir(exeDir)
-		joinedPath = filepath.Join(parentDir, configPath)
-		if _, err := os.Stat(joinedPath); err == nil {
-			return joinedPath
-		}
-	}
+// AuthRefreshConfig 表示凭据刷新配置，刷新后的凭据写入认证配置读取的环境变量
+type AuthRefreshConfig struct {
+	// Type "env" 重新读取 .env 和加密凭据文件，"command" 执行命令并把标准输出作为新凭据，
+	// "oauth2" 通过 client_credentials 流程获取新的访问令牌
+	Type    string   `yaml:"type"`
+	Command []string `yaml:"command"` // command 类型执行的命令和参数
+	// oauth2 类型的令牌端点和客户端凭据
+	TokenURL        string        `yaml:"token_url"`
+	ClientIDEnv     string        `yaml:"client_id_env"`
+	ClientSecretEnv string        `yaml:"client_secret_env"`
+	Scopes          []string      `yaml:"scopes"`
+	Timeout         time.Duration `yaml:"timeout"`      // 刷新的超时时间，默认 30s
+	MinInterval     time.Duration `yaml:"min_interval"` // 两次刷新的最小间隔，避免凭据确实无效时反复刷新，默认 10s
+}
 
-	// 如果仍未找到，返回原始路径（后续逻辑会处理文件不存在的情况）
-	return filepath.Join(exeDir, configPath)
+// ElicitationConfig 表示缺少必需参数时向客户端请求补充的配置
+type ElicitationConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	Timeout time.Duration `yaml:"timeout"` // 等待用户响应的超时时间
 }
 
 // GetDefaultServerConfig 返回默认的服务器配置
@@ -163,10 +847,13 @@ func GetDefaultServerConfig() (*ServerConfig, *GlobalConfig) {
 		Port: 8080,
 		Host: "0.0.0.0",
 		Mode: "sse",
+		SSE:  SSEConfig{HeartbeatInterval: 30 * time.Second},
 	}
 	
 	global := &GlobalConfig{
-		Timeout: 30 * time.Second,
+		Timeout:     30 * time.Second,
+		Approval:    ApprovalConfig{TTL: 5 * time.Minute},
+		Elicitation: ElicitationConfig{Timeout: 2 * time.Minute},
 	}
 	
 	return server, global
@@ -180,20 +867,28 @@ func LoadServerConfig(filePath string) (*ServerConfig, *GlobalConfig, error) {
 		return nil, nil, fmt.Errorf("服务器配置文件路径为空")
 	}
 
-	// 记录文件路径的绝对路径
-	absPath, err := filepath.Abs(filePath)
+	data, fromEnv, err := serverConfigFromEnv()
 	if err != nil {
-		return nil, nil, fmt.Errorf("获取文件绝对路径失败: %w", err)
-	}
-
-	// 检查文件是否存在
-	if _, err := os.Stat(absPath); err != nil {
-		return nil, nil, fmt.Errorf("服务器配置文件 %s 不存在: %w", absPath, err)
+		return nil, nil, err
 	}
+	if !fromEnv {
+		// 记录文件路径的绝对路径
+		absPath, err := filepath.Abs(paths.Resolve(filePath))
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取文件绝对路径失败: %w", err)
+		}
 
-	data, err := ioutil.ReadFile(absPath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("读取服务器配置文件失败: %w", err)
+		// 检查文件是否存在，设置了 MCP2REST_MODE 时允许完全通过环境变量配置
+		if _, err := os.Stat(absPath); err != nil {
+			if os.Getenv(ModeEnv) == "" {
+				return nil, nil, fmt.Errorf("服务器配置文件 %s 不存在: %w", absPath, err)
+			}
+		} else {
+			data, err = ioutil.ReadFile(absPath)
+			if err != nil {
+				return nil, nil, fmt.Errorf("读取服务器配置文件失败: %w", err)
+			}
+		}
 	}
 
 	var cfg struct {
@@ -214,9 +909,36 @@ func LoadServerConfig(filePath string) (*ServerConfig, *GlobalConfig, error) {
 	if cfg.Server.Mode == "" {
 		cfg.Server.Mode = "sse"
 	}
+	if cfg.Server.SSE.HeartbeatInterval == 0 {
+		cfg.Server.SSE.HeartbeatInterval = 30 * time.Second
+	}
 	if cfg.Global.Timeout == 0 {
 		cfg.Global.Timeout = 30 * time.Second
 	}
+	if cfg.Global.Approval.TTL == 0 {
+		cfg.Global.Approval.TTL = 5 * time.Minute
+	}
+	if cfg.Global.Elicitation.Timeout == 0 {
+		cfg.Global.Elicitation.Timeout = 2 * time.Minute
+	}
+
+	if err := ApplyEnvOverrides(&cfg.Server, &cfg.Global); err != nil {
+		return nil, nil, err
+	}
+	if err := loadAuthConfig(&cfg.Global); err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Global.MaxRequestSize != "" {
+		if _, err := ParseSize(cfg.Global.MaxRequestSize); err != nil {
+			return nil, nil, fmt.Errorf("无效的 max_request_size: %w", err)
+		}
+	}
+	if cfg.Global.MaxResponseSize != "" {
+		if _, err := ParseSize(cfg.Global.MaxResponseSize); err != nil {
+			return nil, nil, fmt.Errorf("无效的 max_response_size: %w", err)
+		}
+	}
 
 	return &cfg.Server, &cfg.Global, nil
 }
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/mcp2rest/internal/paths"
+	"gopkg.in/yaml.v3"
+)
+
+// toolNamePattern 覆盖后的工具名称，与常见 MCP 客户端对工具名称的要求一致
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// DescriptionOverride 描述覆盖文件中一个工具的覆盖项，空字段保留规范中的内容
+type DescriptionOverride struct {
+	Name        string            `yaml:"name"`        // 新的工具名称
+	Summary     string            `yaml:"summary"`     // 工具标题
+	Description string            `yaml:"description"` // 工具描述
+	Parameters  map[string]string `yaml:"parameters"`  // 参数名称到新的参数描述
+}
+
+// LoadDescriptionsFile 读取描述覆盖文件，返回按工具名称 (operationId 或根据方法和路径生成的名称) 索引的覆盖项:
+//
+//	operations:
+//	  getUserList:
+//	    name: listUsers
+//	    summary: List users
+//	    description: Returns the users visible to the caller
+//	    parameters:
+//	      page: Page number, starting from 1
+//
+// 也可以省略 operations: 直接以工具名称为键
+func LoadDescriptionsFile(path string) (map[string]*DescriptionOverride, error) {
+	data, err := os.ReadFile(paths.Resolve(path))
+	if err != nil {
+		return nil, fmt.Errorf("读取描述覆盖文件失败: %w", err)
+	}
+
+	var root map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("解析描述覆盖文件 %s 失败: %w", path, err)
+	}
+	if operations, ok := root["operations"]; ok {
+		root = nil
+		if err := operations.Decode(&root); err != nil {
+			return nil, fmt.Errorf("解析描述覆盖文件 %s 失败: operations 必须是以工具名称为键的映射: %w", path, err)
+		}
+	}
+
+	overrides := make(map[string]*DescriptionOverride, len(root))
+	for name, node := range root {
+		var override DescriptionOverride
+		if err := node.Decode(&override); err != nil {
+			return nil, fmt.Errorf("解析描述覆盖文件 %s 中的 %s 失败: %w", path, name, err)
+		}
+		if override.Name != "" && !toolNamePattern.MatchString(override.Name) {
+			return nil, fmt.Errorf("描述覆盖文件 %s 中 %s 的新名称 %q 无效: 只能包含字母、数字、_ 和 -，最长 64 个字符", path, name, override.Name)
+		}
+		overrides[name] = &override
+	}
+	return overrides, nil
+}
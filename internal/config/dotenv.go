@@ -0,0 +1,292 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EnvFileOverrideEnv 设置为 true 时 .env 中的值覆盖已有的环境变量，默认只设置尚未设置的变量
+const EnvFileOverrideEnv = "MCP2REST_ENV_OVERRIDE"
+
+// EnvEntry .env 文件中的一个变量
+type EnvEntry struct {
+	Key   string
+	Value string
+	Line  int // 变量所在的行号，多行值为起始行
+}
+
+// ParseEnv 解析 .env 文件内容，支持的语法:
+//
+//	# 注释                  # 以 # 开头的行
+//	KEY=value               # 未加引号: 去掉两端空白后原样保留，不展开变量，# 也是值的一部分
+//	export KEY=value        # 兼容 shell 的 export 前缀
+//	KEY="多行\n值 ${OTHER}"  # 双引号: 可跨行，支持 \n \t \r \" \\ \$ 转义和变量展开，引号后可以有注释
+//	KEY='原样 ${OTHER}'      # 单引号: 可跨行，内容原样保留，引号后可以有注释
+//
+// 双引号中的 ${VAR}、$VAR 和 ${VAR:-默认值} 按 lookup 展开，lookup 为 nil 时展开为空。
+// 每解析出一个变量就调用一次 set，调用方在 set 中记录取值后，之后的变量引用可以通过 lookup 看到它。
+// 键名不合法或缺少 = 的行被忽略；引号未闭合或引号后有多余内容的行被跳过，其余变量照常设置，
+// 跳过的行在返回的错误中列出
+func ParseEnv(data string, lookup func(key string) (string, bool), set func(EnvEntry)) error {
+	if lookup == nil {
+		lookup = func(string) (string, bool) { return "", false }
+	}
+	p := &envParser{src: strings.ReplaceAll(data, "\r\n", "\n"), line: 1}
+
+	var errs []error
+	for !p.eof() {
+		p.skipBlank()
+		if p.eof() {
+			break
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+
+		line := p.line
+		key, ok := p.readKey()
+		if !ok {
+			p.skipLine()
+			continue
+		}
+
+		var value string
+		var err error
+		pos := p.pos
+		switch p.peek() {
+		case '"':
+			value, err = p.readDoubleQuoted(lookup)
+		case '\'':
+			value, err = p.readSingleQuoted()
+		default:
+			value = p.readUnquoted()
+		}
+		if err != nil {
+			// 只跳过这一行，从下一行继续解析
+			errs = append(errs, fmt.Errorf("第 %d 行 %s: %w", line, key, err))
+			p.pos, p.line = pos, line
+			p.skipLine()
+			continue
+		}
+		p.skipLine()
+
+		set(EnvEntry{Key: key, Value: value, Line: line})
+	}
+	return errors.Join(errs...)
+}
+
+// envParser 按字符扫描 .env 内容，line 为当前行号
+type envParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *envParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *envParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *envParser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+// skipBlank 跳过空白和空行
+func (p *envParser) skipBlank() {
+	for !p.eof() && strings.IndexByte(" \t\n", p.peek()) >= 0 {
+		p.next()
+	}
+}
+
+// skipSpaces 跳过同一行内的空格和制表符
+func (p *envParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.next()
+	}
+}
+
+// skipLine 跳过到下一行开头
+func (p *envParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+// readKey 读取 [export ]KEY= 并停在值的第一个非空白字符，键名不合法或缺少 = 时返回 false
+func (p *envParser) readKey() (string, bool) {
+	start := p.pos
+	for !p.eof() && isEnvKeyChar(p.peek()) {
+		p.next()
+	}
+	key := p.src[start:p.pos]
+
+	// export KEY=value
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		start = p.pos
+		for !p.eof() && isEnvKeyChar(p.peek()) {
+			p.next()
+		}
+		key = p.src[start:p.pos]
+	}
+
+	p.skipSpaces()
+	if key == "" || key[0] >= '0' && key[0] <= '9' || p.peek() != '=' {
+		return "", false
+	}
+	p.next()
+	p.skipSpaces()
+	return key, true
+}
+
+func isEnvKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// readUnquoted 读取到行尾并去掉两端空白，内容原样保留
+func (p *envParser) readUnquoted() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		p.next()
+	}
+	return strings.TrimSpace(p.src[start:p.pos])
+}
+
+// readSingleQuoted 读取单引号中的内容，可跨行，不处理转义和变量
+func (p *envParser) readSingleQuoted() (string, error) {
+	p.next()
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		p.next()
+	}
+	if p.eof() {
+		return "", fmt.Errorf("单引号未闭合")
+	}
+	value := p.src[start:p.pos]
+	p.next()
+	return value, p.endOfValue()
+}
+
+// readDoubleQuoted 读取双引号中的内容，可跨行，处理转义并展开变量
+func (p *envParser) readDoubleQuoted(lookup func(string) (string, bool)) (string, error) {
+	p.next()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("双引号未闭合")
+		}
+		c := p.next()
+		switch c {
+		case '"':
+			return expandEnv(b.String(), lookup), p.endOfValue()
+		case '\\':
+			if p.eof() {
+				return "", fmt.Errorf("双引号未闭合")
+			}
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '$':
+				// 转义的 $ 不参与展开，先写入占位符
+				b.WriteByte(escapedDollar)
+			case '"', '\\':
+				b.WriteByte(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// endOfValue 检查引号之后只有空白或注释
+func (p *envParser) endOfValue() error {
+	p.skipSpaces()
+	if p.eof() || p.peek() == '\n' || p.peek() == '#' {
+		return nil
+	}
+	return fmt.Errorf("引号后有多余的内容")
+}
+
+// escapedDollar 双引号中 \$ 的占位符，展开变量后还原为 $
+const escapedDollar = '\x00'
+
+// expandEnv 展开 ${VAR}、$VAR 和 ${VAR:-默认值}，未定义的变量展开为空；
+// 不构成变量引用的 $ (如 $$、$1、行尾的 $) 原样保留
+func expandEnv(value string, lookup func(string) (string, bool)) string {
+	if !strings.ContainsRune(value, '$') && !strings.ContainsRune(value, escapedDollar) {
+		return value
+	}
+
+	resolve := func(name string) string {
+		if key, fallback, ok := strings.Cut(name, ":-"); ok {
+			if v, exists := lookup(key); exists && v != "" {
+				return v
+			}
+			return fallback
+		}
+		v, _ := lookup(name)
+		return v
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == escapedDollar:
+			b.WriteByte('$')
+			continue
+		case c != '$' || i+1 >= len(value):
+			b.WriteByte(c)
+			continue
+		}
+
+		if value[i+1] == '$' {
+			b.WriteString("$$")
+			i++
+			continue
+		}
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i+2:], '}')
+			if end <= 0 {
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteString(resolve(value[i+2 : i+2+end]))
+			i += 2 + end
+			continue
+		}
+
+		j := i + 1
+		for j < len(value) && (isEnvNameStart(value[j]) || j > i+1 && value[j] >= '0' && value[j] <= '9') {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString(resolve(value[i+1 : j]))
+		i = j - 1
+	}
+	return b.String()
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
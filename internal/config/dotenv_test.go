@@ -0,0 +1,122 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnv(t *testing.T) {
+	environment := map[string]string{"HOST": "env.example.com", "EMPTY": ""}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "export prefix",
+			data: "export TOKEN=abc\nexport\tOTHER=1\n",
+			want: map[string]string{"TOKEN": "abc", "OTHER": "1"},
+		},
+		{
+			name: "unquoted values are literal",
+			data: "API_KEY=ab$cd\nURL=http://x/#frag\nNOTE=a # b\nSPACED =  v  \n",
+			want: map[string]string{"API_KEY": "ab$cd", "URL": "http://x/#frag", "NOTE": "a # b", "SPACED": "v"},
+		},
+		{
+			name: "comments and blank lines",
+			data: "# comment\n\n  # indented comment\nA=1\n",
+			want: map[string]string{"A": "1"},
+		},
+		{
+			name: "multi-line double quoted",
+			data: "KEY=\"-----BEGIN-----\nMIIB\n-----END-----\" # trailing comment\nNEXT=2\n",
+			want: map[string]string{"KEY": "-----BEGIN-----\nMIIB\n-----END-----", "NEXT": "2"},
+		},
+		{
+			name: "multi-line single quoted",
+			data: "KEY='line1\nline2'\n",
+			want: map[string]string{"KEY": "line1\nline2"},
+		},
+		{
+			name: "escapes in double quotes",
+			data: `KEY="a\tb\nc \"q\" \\ \$HOST"` + "\n",
+			want: map[string]string{"KEY": "a\tb\nc \"q\" \\ $HOST"},
+		},
+		{
+			name: "expansion",
+			data: "BASE=api\nURL=\"https://${HOST}/$BASE/v1\"\nDEF=\"${MISSING:-fallback}\"\nEMPTYDEF=\"${EMPTY:-x}\"\nUNDEF=\"[$MISSING]\"\n",
+			want: map[string]string{
+				"BASE": "api", "URL": "https://env.example.com/api/v1",
+				"DEF": "fallback", "EMPTYDEF": "x", "UNDEF": "[]",
+			},
+		},
+		{
+			name: "dollar without variable name is kept",
+			data: "PRICE=\"$5 and $$ and end$\"\n",
+			want: map[string]string{"PRICE": "$5 and $$ and end$"},
+		},
+		{
+			name: "single quotes are not expanded",
+			data: "RAW='${HOST} \\n'\n",
+			want: map[string]string{"RAW": "${HOST} \\n"},
+		},
+		{
+			name: "crlf line endings",
+			data: "A=1\r\nB=\"2\"\r\n",
+			want: map[string]string{"A": "1", "B": "2"},
+		},
+		{
+			name: "invalid lines are ignored",
+			data: "not a pair\n1BAD=x\nGOOD=y\n",
+			want: map[string]string{"GOOD": "y"},
+		},
+		{
+			name:    "unclosed quote skips only that line",
+			data:    "A=1\nBROKEN=\"abc\nB=2\n",
+			want:    map[string]string{"A": "1", "B": "2"},
+			wantErr: "第 2 行 BROKEN",
+		},
+		{
+			name:    "content after closing quote skips the line",
+			data:    "BAD=\"x\" y\nOK=1\n",
+			want:    map[string]string{"OK": "1"},
+			wantErr: "第 1 行 BAD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			lookup := func(key string) (string, bool) {
+				if value, exists := got[key]; exists {
+					return value, true
+				}
+				value, exists := environment[key]
+				return value, exists
+			}
+			err := ParseEnv(tt.data, lookup, func(entry EnvEntry) { got[entry.Key] = entry.Value })
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ParseEnv() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ParseEnv() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEnvLineNumbers(t *testing.T) {
+	var lines []int
+	data := "A=1\nB=\"x\ny\"\n\nC=3\n"
+	if err := ParseEnv(data, nil, func(entry EnvEntry) { lines = append(lines, entry.Line) }); err != nil {
+		t.Fatalf("ParseEnv() error = %v", err)
+	}
+	if want := []int{1, 2, 5}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+}
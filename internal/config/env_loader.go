@@ -1,14 +1,16 @@
 package config
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"strconv"
+
+	"github.com/mcp2rest/internal/paths"
 )
 
-// LoadEnvFile 加载 .env 文件并设置环境变量
+// LoadEnvFile 加载 .env 文件并设置环境变量，语法见 ParseEnv
+// 已设置为非空值的环境变量不会被覆盖，除非 MCP2REST_ENV_OVERRIDE=true
 func LoadEnvFile(envPath string) error {
 	// 如果路径为空，尝试自动查找 .env 文件
 	if envPath == "" {
@@ -24,79 +26,67 @@ func LoadEnvFile(envPath string) error {
 		return fmt.Errorf("环境变量文件不存在: %s", envPath)
 	}
 
-	// 读取文件
-	file, err := os.Open(envPath)
+	data, err := os.ReadFile(envPath)
 	if err != nil {
-		return fmt.Errorf("打开环境变量文件失败: %w", err)
+		return fmt.Errorf("读取环境变量文件失败: %w", err)
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
+	// 默认已设置的环境变量优先，MCP2REST_ENV_OVERRIDE=true 时 .env 中的值优先
+	override, _ := strconv.ParseBool(os.Getenv(EnvFileOverrideEnv))
+	if err := applyEnv(string(data), override); err != nil {
+		return fmt.Errorf("解析环境变量文件 %s 失败: %w", envPath, err)
+	}
+	return nil
+}
 
-		// 跳过空行和注释
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+// applyEnv 解析 .env 格式的内容并设置环境变量，override 为 false 时不覆盖已设置为非空值的变量
+// 返回错误时有问题的行已被跳过，其余变量已经设置
+func applyEnv(data string, override bool) error {
+	// 变量展开使用最终生效的值: 已经处理过的 .env 变量，其次是环境变量
+	values := make(map[string]string)
+	lookup := func(key string) (string, bool) {
+		if value, exists := values[key]; exists {
+			return value, true
 		}
-
-		// 解析 key=value 格式
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue // 跳过格式不正确的行
+		return os.LookupEnv(key)
+	}
+	set := func(entry EnvEntry) {
+		if !override && os.Getenv(entry.Key) != "" {
+			values[entry.Key] = os.Getenv(entry.Key)
+			return
 		}
+		values[entry.Key] = entry.Value
+		os.Setenv(entry.Key, entry.Value)
+	}
+	return ParseEnv(data, lookup, set)
+}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		// 移除值两端的引号
-		if len(value) >= 2 && (value[0] == '"' && value[len(value)-1] == '"') {
-			value = value[1 : len(value)-1]
+// ReloadEnvFiles 重新读取 .env 和加密凭据文件，其中的值覆盖当前的环境变量，用于刷新已轮换的凭据
+func ReloadEnvFiles() error {
+	if envPath := findEnvFile(); envPath != "" {
+		data, err := os.ReadFile(envPath)
+		if err != nil {
+			return fmt.Errorf("读取环境变量文件失败: %w", err)
 		}
-
-		// 设置环境变量（如果尚未设置）
-		if os.Getenv(key) == "" {
-			os.Setenv(key, value)
+		if err := applyEnv(string(data), true); err != nil {
+			// 有问题的行已跳过，其余变量和加密凭据文件照常刷新
+			if secretsErr := loadSecrets(secretsPath(""), true); secretsErr != nil {
+				return secretsErr
+			}
+			return fmt.Errorf("解析环境变量文件 %s 失败: %w", envPath, err)
 		}
 	}
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("读取环境变量文件失败: %w", err)
-	}
-
-	return nil
+	return loadSecrets(secretsPath(""), true)
 }
 
-// findEnvFile 查找 .env 文件
+// findEnvFile 查找 .env 文件，配置了基础目录时只在基础目录及其 configs 目录中查找
 func findEnvFile() string {
-	// 获取可执行文件路径
-	exePath, err := os.Executable()
-	if err != nil {
-		exePath = ""
-	}
-
-	// 可能的 .env 文件路径
-	possiblePaths := []string{
-		".env",                    // 当前工作目录
-		"configs/.env",            // configs 目录
-	}
-
-	// 如果可执行文件路径可用，添加基于可执行文件的路径
-	if exePath != "" {
-		exeDir := filepath.Dir(exePath)
-		possiblePaths = append(possiblePaths,
-			filepath.Join(exeDir, ".env"),                    // 可执行文件同级目录
-			filepath.Join(exeDir, "configs", ".env"),         // 可执行文件同级 configs 目录
-			filepath.Join(filepath.Dir(exeDir), ".env"),      // 可执行文件上级目录
-			filepath.Join(filepath.Dir(exeDir), "configs", ".env"), // 可执行文件上级 configs 目录
-		)
-	}
+	return findInSearchDirs(".env")
+}
 
-	// 检查每个可能的路径
-	for _, path := range possiblePaths {
+// findInSearchDirs 在 .env 的查找目录中查找文件
+func findInSearchDirs(name string) string {
+	for _, dir := range paths.SearchDirs() {
+		path := filepath.Join(dir, name)
 		if _, err := os.Stat(path); err == nil {
 			return path
 		}
@@ -105,24 +95,32 @@ func findEnvFile() string {
 	return ""
 }
 
-// LoadEnvFileWithLog 加载 .env 文件并记录日志
+// LoadEnvFileWithLog 加载 .env 文件和加密的凭据文件并记录日志
+// 此时日志尚未初始化，提示写入标准错误，标准输出在 stdio 模式下只用于协议消息
 func LoadEnvFileWithLog(envPath string) error {
 	// 如果路径为空，尝试自动查找
 	if envPath == "" {
 		envPath = findEnvFile()
-		if envPath == "" {
-			// 没有找到 .env 文件，记录日志但不报错
-			fmt.Println("未找到 .env 文件，将使用系统环境变量")
-			return nil
+	}
+	if envPath == "" {
+		// 没有找到 .env 文件，记录日志但不报错
+		fmt.Fprintln(os.Stderr, "未找到 .env 文件，将使用系统环境变量")
+	} else {
+		fmt.Fprintf(os.Stderr, "正在加载环境变量文件: %s\n", envPath)
+		if err := LoadEnvFile(envPath); err != nil {
+			// 有问题的行已跳过，其余变量已设置，继续加载加密凭据文件
+			fmt.Fprintf(os.Stderr, "警告: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "环境变量文件加载成功: %s\n", envPath)
 		}
 	}
 
-	fmt.Printf("正在加载环境变量文件: %s\n", envPath)
-	err := LoadEnvFile(envPath)
+	secretsPath, err := LoadSecretsFile("")
 	if err != nil {
 		return err
 	}
-
-	fmt.Printf("环境变量文件加载成功: %s\n", envPath)
+	if secretsPath != "" {
+		fmt.Fprintf(os.Stderr, "加密凭据文件加载成功: %s\n", secretsPath)
+	}
 	return nil
 }
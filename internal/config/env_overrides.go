@@ -0,0 +1,221 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/embedded"
+	"github.com/mcp2rest/internal/i18n"
+)
+
+// 通过环境变量提供完整配置的变量名
+const (
+	SpecB64Env         = "MCP2REST_SPEC_B64"          // base64 编码的 OpenAPI 规范 (YAML 或 JSON)
+	ServerConfigB64Env = "MCP2REST_SERVER_CONFIG_B64" // base64 编码的服务器配置 (与 configs/stdio.yaml 格式相同)
+	ModeEnv            = "MCP2REST_MODE"
+)
+
+// EnvOverride 表示可以通过环境变量覆盖的服务器配置项
+type EnvOverride struct {
+	Name        string
+	Description string
+	apply       func(server *ServerConfig, global *GlobalConfig, value string) error
+}
+
+// EnvOverrides 按应用顺序列出所有环境变量覆盖项
+var EnvOverrides = []EnvOverride{
+	{ModeEnv, "服务器模式: stdio 或 sse", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		if v != "stdio" && v != "sse" {
+			return fmt.Errorf("不支持的模式: %s", v)
+		}
+		s.Mode = v
+		return nil
+	}},
+	{"MCP2REST_HOST", "SSE 监听地址", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		s.Host = v
+		return nil
+	}},
+	{"MCP2REST_PORT", "SSE 监听端口", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		s.Port = port
+		return nil
+	}},
+	{"MCP2REST_TIMEOUT", "上游请求超时，如 30s", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		g.Timeout = timeout
+		return nil
+	}},
+	{"MCP2REST_MAX_REQUEST_SIZE", "最大请求大小，如 10MB", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		if _, err := ParseSize(v); err != nil {
+			return err
+		}
+		g.MaxRequestSize = v
+		return nil
+	}},
+	{"MCP2REST_MAX_RESPONSE_SIZE", "上游响应的大小上限，如 50MB", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		if _, err := ParseSize(v); err != nil {
+			return err
+		}
+		g.MaxResponseSize = v
+		return nil
+	}},
+	{"MCP2REST_DEFAULT_HEADERS", `附加到每个上游请求的头，JSON 对象，如 {"X-Tenant":"acme"}`, func(s *ServerConfig, g *GlobalConfig, v string) error {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(v), &headers); err != nil {
+			return err
+		}
+		if g.DefaultHeaders == nil {
+			g.DefaultHeaders = make(map[string]string, len(headers))
+		}
+		for key, value := range headers {
+			g.DefaultHeaders[key] = value
+		}
+		return nil
+	}},
+	{"MCP2REST_SERVER_VARIABLES", `规范 servers 地址中变量的取值，JSON 对象，如 {"region":"eu"}`, func(s *ServerConfig, g *GlobalConfig, v string) error {
+		var variables map[string]string
+		if err := json.Unmarshal([]byte(v), &variables); err != nil {
+			return err
+		}
+		if g.ServerVariables == nil {
+			g.ServerVariables = make(map[string]string, len(variables))
+		}
+		for key, value := range variables {
+			g.ServerVariables[key] = value
+		}
+		return nil
+	}},
+	{"MCP2REST_USER_AGENT", "发送给上游的 User-Agent，可使用 {{.Version}} 等模板变量", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		g.Identity.UserAgent = v
+		return nil
+	}},
+	{"MCP2REST_EXPOSE_HEADERS", "包含在工具结果中的上游响应头，逗号分隔", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		g.ExposeHeaders = splitList(v)
+		return nil
+	}},
+	{"MCP2REST_TAGS", "只加载带有这些标签的操作，逗号分隔", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		g.Tags = splitList(v)
+		return nil
+	}},
+	{"MCP2REST_EXCLUDE_TAGS", "不加载带有这些标签的操作，逗号分隔", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		g.ExcludeTags = splitList(v)
+		return nil
+	}},
+	{"MCP2REST_TOOL_BUDGET", "工具数量上限，超出时按标签合并为分组工具", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		budget, err := strconv.Atoi(v)
+		g.ToolBudget = budget
+		return err
+	}},
+	{i18n.LocaleEnv, "错误消息的语言: zh 或 en", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		if _, ok := i18n.Normalize(v); !ok {
+			return fmt.Errorf("不支持的语言: %s", v)
+		}
+		g.Locale = v
+		return nil
+	}},
+	{"MCP2REST_DESCRIPTIONS", "描述覆盖文件，替换工具名称和描述", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		g.Descriptions = v
+		return nil
+	}},
+	{"MCP2REST_HIDE_DEPRECATED", "隐藏已弃用的操作 (true/false)", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		hide, err := strconv.ParseBool(v)
+		g.HideDeprecated = hide
+		return err
+	}},
+	{"MCP2REST_DRY_RUN", "不请求上游，返回规范中的示例响应 (true/false)", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		dryRun, err := strconv.ParseBool(v)
+		g.DryRun = dryRun
+		return err
+	}},
+	{"MCP2REST_FOLLOW_CREATED", "201 Created 后获取新建的资源 (true/false)", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		follow, err := strconv.ParseBool(v)
+		g.FollowCreated = follow
+		return err
+	}},
+	{"MCP2REST_FRAMING", "标准输入/输出分帧方式 (ndjson/content-length/auto)", func(s *ServerConfig, g *GlobalConfig, v string) error {
+		s.Framing = v
+		return nil
+	}},
+}
+
+// ApplyEnvOverrides 使用环境变量覆盖服务器配置
+func ApplyEnvOverrides(server *ServerConfig, global *GlobalConfig) error {
+	for _, override := range EnvOverrides {
+		value, exists := os.LookupEnv(override.Name)
+		if !exists || value == "" {
+			continue
+		}
+		if err := override.apply(server, global, value); err != nil {
+			return fmt.Errorf("环境变量 %s 无效: %w", override.Name, err)
+		}
+	}
+	return nil
+}
+
+// serverConfigFromEnv 返回通过环境变量提供的服务器配置内容
+func serverConfigFromEnv() ([]byte, bool, error) {
+	encoded := os.Getenv(ServerConfigB64Env)
+	if encoded == "" {
+		return nil, false, nil
+	}
+	data, err := decodeBase64(encoded)
+	if err != nil {
+		return nil, true, fmt.Errorf("环境变量 %s 不是有效的 base64: %w", ServerConfigB64Env, err)
+	}
+	return data, true, nil
+}
+
+// inlineSpec 返回环境变量或嵌入的规范，优先级高于规范文件路径
+func inlineSpec() ([]byte, string, error) {
+	if encoded := os.Getenv(SpecB64Env); encoded != "" {
+		data, err := decodeBase64(encoded)
+		if err != nil {
+			return nil, "", fmt.Errorf("环境变量 %s 不是有效的 base64: %w", SpecB64Env, err)
+		}
+		return data, "环境变量 " + SpecB64Env, nil
+	}
+	if len(embedded.Spec) > 0 {
+		return embedded.Spec, "编译时嵌入的文件", nil
+	}
+	return nil, "", nil
+}
+
+// decodeBase64 接受标准和 URL 安全的 base64，忽略换行 (base64 命令默认每 76 个字符换行)
+func decodeBase64(encoded string) ([]byte, error) {
+	encoded = strings.Join(strings.Fields(encoded), "")
+	if data, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+		return data, nil
+	}
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(encoded, "="))
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SetTagFilter 使用命令行参数覆盖标签过滤，参数为逗号分隔的标签，为空时保留配置文件和环境变量中的设置
+func (g *GlobalConfig) SetTagFilter(tags, excludeTags string) {
+	if tags != "" {
+		g.Tags = splitList(tags)
+	}
+	if excludeTags != "" {
+		g.ExcludeTags = splitList(excludeTags)
+	}
+}
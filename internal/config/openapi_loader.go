@@ -5,11 +5,13 @@ import (
 	"path/filepath"
 
 	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/paths"
 )
 
 // OpenAPILoader 接口定义了从OpenAPI规范加载配置的方法
 type OpenAPILoader interface {
 	LoadFromOpenAPI(filePath string) (*OpenAPISpec, error)
+	LoadFromOpenAPIData(data []byte) (*OpenAPISpec, error)
 }
 
 var openAPILoaderInstance OpenAPILoader
@@ -31,7 +33,7 @@ func LoadOpenAPISpec(filePath string) (*OpenAPISpec, error) {
 		return nil, fmt.Errorf("不支持的OpenAPI规范文件格式: %s", ext)
 	}
 
-	return openAPILoaderInstance.LoadFromOpenAPI(filePath)
+	return openAPILoaderInstance.LoadFromOpenAPI(paths.Resolve(filePath))
 }
 
 // LoadConfigWithOpenAPI 加载OpenAPI规范
@@ -43,6 +45,44 @@ func LoadConfigWithOpenAPI(openAPIPath string) (*Config, *OpenAPISpec, error) {
 		Global: *global,
 	}
 
+	if err := ApplyEnvOverrides(&cfg.Server, &cfg.Global); err != nil {
+		return nil, nil, err
+	}
+
+	// 环境变量或嵌入的规范优先于规范文件
+	data, source, err := inlineSpec()
+	if err != nil {
+		return nil, nil, err
+	}
+	if data != nil {
+		if openAPILoaderInstance == nil {
+			return nil, nil, fmt.Errorf("OpenAPI加载器未注册")
+		}
+		logging.Logger.Printf("使用来自%s的OpenAPI规范，忽略 %s", source, openAPIPath)
+		openAPISpec, err := openAPILoaderInstance.LoadFromOpenAPIData(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("加载来自%s的OpenAPI规范失败: %w", source, err)
+		}
+		return cfg, openAPISpec, nil
+	}
+
+	// 预置的规范 (preset:<名称>)
+	data, isPreset, err := PresetSpec(openAPIPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isPreset {
+		if openAPILoaderInstance == nil {
+			return nil, nil, fmt.Errorf("OpenAPI加载器未注册")
+		}
+		openAPISpec, err := openAPILoaderInstance.LoadFromOpenAPIData(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("加载预置规范 %s 失败: %w", openAPIPath, err)
+		}
+		logging.Logger.Printf("成功加载预置规范: %s", openAPIPath)
+		return cfg, openAPISpec, nil
+	}
+
 	// 加载OpenAPI规范
 	logging.Logger.Printf("开始加载OpenAPI规范: %s", openAPIPath)
 	
@@ -54,4 +94,4 @@ func LoadConfigWithOpenAPI(openAPIPath string) (*Config, *OpenAPISpec, error) {
 	logging.Logger.Printf("成功加载OpenAPI规范: %s", openAPIPath)
 
 	return cfg, openAPISpec, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mcp2rest/internal/presets"
+)
+
+// PresetSpec 规范路径为 preset:<名称> 时返回预置的规范，ok 表示路径是否引用了预置
+func PresetSpec(openAPIPath string) (data []byte, ok bool, err error) {
+	if !strings.HasPrefix(openAPIPath, presets.Prefix) {
+		return nil, false, nil
+	}
+	name := strings.TrimPrefix(openAPIPath, presets.Prefix)
+	preset, exists := presets.Get(name)
+	if !exists {
+		return nil, true, fmt.Errorf("未知的预置: %s", name)
+	}
+	data, err = preset.Spec()
+	return data, true, err
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MasterKeyEnv 解密凭据文件的主密钥，base64 编码的 32 字节密钥，由 mcp2rest secrets keygen 生成
+	MasterKeyEnv = "MCP2REST_MASTER_KEY"
+	// SecretsFileEnv 加密凭据文件的路径，未设置时在 .env 的查找目录中查找 .env.enc
+	SecretsFileEnv = "MCP2REST_SECRETS_FILE"
+
+	// secretsFileName 自动查找的加密凭据文件名
+	secretsFileName = ".env.enc"
+	// secretsHeader 加密凭据文件的格式标识，之后是 base64(nonce || AES-256-GCM 密文)
+	secretsHeader = "mcp2rest-secrets:v1:"
+	// secretsAAD 附加认证数据，防止把其他用途的同格式密文当作凭据文件解密
+	secretsAAD    = "mcp2rest-secrets-v1"
+	masterKeySize = 32
+)
+
+// GenerateMasterKey 生成新的主密钥，返回 base64 编码
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("生成主密钥失败: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseMasterKey 解析 base64 编码的主密钥
+func ParseMasterKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("主密钥不是有效的 base64: %w", err)
+	}
+	if len(key) != masterKeySize {
+		return nil, fmt.Errorf("主密钥长度为 %d 字节，需要 %d 字节", len(key), masterKeySize)
+	}
+	return key, nil
+}
+
+// EncryptEnv 用主密钥加密 .env 格式的内容，返回凭据文件的内容
+func EncryptEnv(plain []byte, key []byte) ([]byte, error) {
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成随机数失败: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, []byte(secretsAAD))
+	return []byte(secretsHeader + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// DecryptEnv 用主密钥解密凭据文件，返回 .env 格式的内容
+func DecryptEnv(data []byte, key []byte) ([]byte, error) {
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, secretsHeader) {
+		return nil, fmt.Errorf("不是 mcp2rest 加密凭据文件")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(text, secretsHeader))
+	if err != nil {
+		return nil, fmt.Errorf("凭据文件内容损坏: %w", err)
+	}
+
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("凭据文件内容损坏")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(secretsAAD))
+	if err != nil {
+		return nil, fmt.Errorf("解密失败，主密钥不正确或文件被修改")
+	}
+	return plain, nil
+}
+
+func newSecretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("无效的主密钥: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// LoadSecretsFile 解密凭据文件并设置其中的环境变量，规则与 .env 相同 (已设置的变量默认不覆盖)
+// path 为空时使用 MCP2REST_SECRETS_FILE，仍为空则自动查找 .env.enc；没有凭据文件时返回空路径。
+// 存在凭据文件但没有设置 MCP2REST_MASTER_KEY 时返回错误
+func LoadSecretsFile(path string) (string, error) {
+	path = secretsPath(path)
+	if path == "" {
+		return "", nil
+	}
+	override, _ := strconv.ParseBool(os.Getenv(EnvFileOverrideEnv))
+	if err := loadSecrets(path, override); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// secretsPath 返回凭据文件的路径，没有凭据文件时返回空
+func secretsPath(path string) string {
+	if path == "" {
+		path = os.Getenv(SecretsFileEnv)
+	}
+	if path == "" {
+		path = findInSearchDirs(secretsFileName)
+	}
+	return path
+}
+
+// loadSecrets 解密凭据文件并设置其中的环境变量，path 为空时不做任何事
+func loadSecrets(path string, override bool) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取加密凭据文件失败: %w", err)
+	}
+	encodedKey := os.Getenv(MasterKeyEnv)
+	if encodedKey == "" {
+		return fmt.Errorf("找到加密凭据文件 %s，但未设置 %s", path, MasterKeyEnv)
+	}
+	key, err := ParseMasterKey(encodedKey)
+	if err != nil {
+		return fmt.Errorf("%s: %w", MasterKeyEnv, err)
+	}
+
+	plain, err := DecryptEnv(data, key)
+	if err != nil {
+		return fmt.Errorf("解密凭据文件 %s 失败: %w", path, err)
+	}
+	if err := applyEnv(string(plain), override); err != nil {
+		return fmt.Errorf("解析凭据文件 %s 失败: %w", path, err)
+	}
+	return nil
+}
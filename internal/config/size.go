@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxRequestSize 未配置 max_request_size 时的默认限制
+const DefaultMaxRequestSize = 10 * 1024 * 1024
+
+// sizeUnits 支持的大小单位，按 1024 进制计算
+var sizeUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseSize 解析 "10MB"、"512KB"、"1048576" 这样的大小字符串，返回字节数
+func ParseSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	if text == "" {
+		return 0, fmt.Errorf("大小为空")
+	}
+
+	factor := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			factor = unit.factor
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("无效的大小: %s", value)
+	}
+	return int64(number * float64(factor)), nil
+}
+
+// MaxRequestBytes 返回 max_request_size 对应的字节数，未配置或无效时使用默认值
+func (g *GlobalConfig) MaxRequestBytes() int64 {
+	if g.MaxRequestSize == "" {
+		return DefaultMaxRequestSize
+	}
+	size, err := ParseSize(g.MaxRequestSize)
+	if err != nil {
+		return DefaultMaxRequestSize
+	}
+	return size
+}
+
+// MaxResponseBytes 返回 max_response_size 对应的字节数，未配置或无效时与 max_request_size 相同
+func (g *GlobalConfig) MaxResponseBytes() int64 {
+	if g.MaxResponseSize == "" {
+		return g.MaxRequestBytes()
+	}
+	size, err := ParseSize(g.MaxResponseSize)
+	if err != nil {
+		return g.MaxRequestBytes()
+	}
+	return size
+}
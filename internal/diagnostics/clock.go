@@ -0,0 +1,93 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// ntpEpochOffset 1900-01-01 到 1970-01-01 的秒数
+const ntpEpochOffset = 2208988800
+
+// checkClock 通过 SNTP 查询本地时钟的偏差，偏差超过 max_skew 或无法查询时给出警告
+func checkClock(ctx context.Context, cfg *config.ClockCheckConfig) Check {
+	maxSkew := cfg.AllowedSkew()
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	check := Check{Target: cfg.Server, Status: StatusOK}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	offset, err := queryClockOffset(ctx, cfg.Server)
+	if err != nil {
+		check.Status = StatusWarn
+		check.Message = fmt.Sprintf("无法查询时间服务器: %v", err)
+		return check
+	}
+
+	check.Details = map[string]interface{}{"offset_ms": offset.Milliseconds(), "max_skew_ms": maxSkew.Milliseconds()}
+	check.Message = fmt.Sprintf("本地时钟偏差 %s", offset.Round(time.Millisecond))
+	if offset > maxSkew || offset < -maxSkew {
+		check.Status = StatusWarn
+		check.Message = fmt.Sprintf("本地时钟偏差 %s，超过允许的 %s，请求签名和令牌有效期校验可能失败", offset.Round(time.Millisecond), maxSkew)
+	}
+	return check
+}
+
+// queryClockOffset 发送一次 SNTP 请求 (RFC 4330)，返回服务器时间减去本地时间的偏差
+func queryClockOffset(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// LI = 0，版本 4，客户端模式
+	request := make([]byte, 48)
+	request[0] = 0x23
+	sent := time.Now()
+	if _, err := conn.Write(request); err != nil {
+		return 0, err
+	}
+	response := make([]byte, 48)
+	n, err := conn.Read(response)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+	if n < 48 || response[0]&0x07 != 4 {
+		return 0, fmt.Errorf("无效的 SNTP 响应")
+	}
+
+	// 偏差 = ((服务器收到 - 本地发出) + (服务器发出 - 本地收到)) / 2
+	serverReceived := ntpTime(response[32:40])
+	serverSent := ntpTime(response[40:48])
+	if serverSent.IsZero() {
+		return 0, fmt.Errorf("SNTP 响应中没有时间")
+	}
+	return (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2, nil
+}
+
+// ntpTime 解析 64 位 NTP 时间戳
+func ntpTime(b []byte) time.Time {
+	seconds := binary.BigEndian.Uint32(b)
+	fraction := binary.BigEndian.Uint32(b[4:])
+	if seconds == 0 && fraction == 0 {
+		return time.Time{}
+	}
+	nanos := (int64(fraction) * 1e9) >> 32
+	return time.Unix(int64(seconds)-ntpEpochOffset, nanos)
+}
@@ -0,0 +1,287 @@
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/internal/paths"
+)
+
+// defaultTimeout 单个上游连接检查的超时时间
+const defaultTimeout = 5 * time.Second
+
+// Status 表示检查结果，报告的结果取所有检查中最严重的一项
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// severity 用于比较结果的严重程度
+func (s Status) severity() int {
+	switch s {
+	case StatusWarn:
+		return 1
+	case StatusFail:
+		return 2
+	}
+	return 0
+}
+
+// Check 表示一项检查的结果
+type Check struct {
+	Name       string                 `json:"name"`
+	Target     string                 `json:"target,omitempty"`
+	Status     Status                 `json:"status"`
+	Message    string                 `json:"message"`
+	DurationMs int64                  `json:"duration_ms"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// Report 表示启动自检报告
+type Report struct {
+	Status Status    `json:"status"`
+	Time   time.Time `json:"time"`
+	Checks []Check   `json:"checks"`
+}
+
+// Prober 连接上游但不发送请求，由请求处理器实现，与实际请求使用相同的连接方式
+type Prober interface {
+	UpstreamURLs() []string
+	ProbeUpstream(ctx context.Context, rawURL string) (map[string]interface{}, error)
+}
+
+// Options 表示自检的输入
+type Options struct {
+	// SpecPath 规范路径，只用于报告
+	SpecPath string
+	// Spec 已加载的规范，LoadErr 不为空时表示规范或配置加载失败，此时跳过依赖规范的检查
+	Spec    *config.OpenAPISpec
+	LoadErr error
+	Global  *config.GlobalConfig
+	// Prober 为 nil 时跳过上游连接检查
+	Prober Prober
+	// Timeout 单个上游连接检查的超时时间，默认 5 秒
+	Timeout time.Duration
+}
+
+// Run 依次检查规范、认证环境变量、上游连通性、时钟 (配置了 clock_check 时) 和日志目录
+func Run(ctx context.Context, opts Options) *Report {
+	report := &Report{Status: StatusOK, Time: time.Now()}
+	report.add(timed("spec", func() Check { return checkSpec(opts) }))
+	if opts.LoadErr == nil && opts.Spec != nil {
+		report.add(timed("auth_env", func() Check { return checkAuthEnv(opts.Spec, opts.Global) }))
+		if opts.Prober != nil {
+			for _, rawURL := range opts.Prober.UpstreamURLs() {
+				report.add(timed("upstream", func() Check { return checkUpstream(ctx, opts, rawURL) }))
+			}
+		}
+	}
+	if opts.Global != nil && opts.Global.ClockCheck != nil && opts.Global.ClockCheck.Server != "" {
+		report.add(timed("clock", func() Check { return checkClock(ctx, opts.Global.ClockCheck) }))
+	}
+	report.add(timed("log_dir", checkLogDir))
+	return report
+}
+
+func (r *Report) add(check Check) {
+	r.Checks = append(r.Checks, check)
+	if check.Status.severity() > r.Status.severity() {
+		r.Status = check.Status
+	}
+}
+
+// JSON 返回单行 JSON 形式的报告
+func (r *Report) JSON() []byte {
+	data, _ := json.Marshal(r)
+	return data
+}
+
+// Log 把报告写入日志，未通过的检查另外单独记录，便于检索
+func (r *Report) Log() {
+	logging.Logger.Printf("启动自检: %s", r.JSON())
+	for _, check := range r.Checks {
+		if check.Status == StatusOK {
+			continue
+		}
+		target := check.Name
+		if check.Target != "" {
+			target += " " + check.Target
+		}
+		logging.Logger.Printf("警告: 启动自检 %s 未通过 (%s): %s", target, check.Status, check.Message)
+	}
+}
+
+// Exit 把报告写入标准输出并退出进程，有检查失败时退出码为 1
+func Exit(r *Report) {
+	data, _ := json.MarshalIndent(r, "", "  ")
+	os.Stdout.Write(append(data, '\n'))
+	if r.Status == StatusFail {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// ExitWithError 在加载配置或创建服务器失败时输出报告并退出，报告只包含失败原因和日志目录检查
+func ExitWithError(specPath string, err error) {
+	Exit(Run(context.Background(), Options{SpecPath: specPath, LoadErr: err}))
+}
+
+// timed 执行检查并记录耗时
+func timed(name string, run func() Check) Check {
+	start := time.Now()
+	check := run()
+	check.Name = name
+	check.DurationMs = time.Since(start).Milliseconds()
+	return check
+}
+
+// checkSpec 报告规范的加载结果和操作数量
+func checkSpec(opts Options) Check {
+	if opts.LoadErr != nil {
+		return Check{Target: opts.SpecPath, Status: StatusFail, Message: opts.LoadErr.Error()}
+	}
+	if opts.Spec == nil {
+		return Check{Target: opts.SpecPath, Status: StatusFail, Message: "规范未加载"}
+	}
+
+	operations := 0
+	for _, pathItem := range opts.Spec.Paths {
+		operations += len(pathItem)
+	}
+	check := Check{
+		Target:  opts.SpecPath,
+		Status:  StatusOK,
+		Message: fmt.Sprintf("%s v%s，%d 个操作", opts.Spec.Info.Title, opts.Spec.Info.Version, operations),
+		Details: map[string]interface{}{"title": opts.Spec.Info.Title, "version": opts.Spec.Info.Version, "operations": operations},
+	}
+	if baseURL := openapi.GetBaseURL(opts.Spec); baseURL != "" {
+		check.Details["server"] = baseURL
+	} else if unreachable := operationsWithoutServer(opts.Spec); unreachable == operations && operations > 0 {
+		check.Status = StatusFail
+		check.Message = "规范中未定义服务器URL (servers)，所有操作都无法调用"
+	} else if unreachable > 0 {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("规范中未定义服务器URL (servers)，%d 个没有在操作或路径上声明 servers 的操作无法调用", unreachable)
+	}
+	return check
+}
+
+// operationsWithoutServer 返回没有可用服务器地址的操作数量
+func operationsWithoutServer(spec *config.OpenAPISpec) int {
+	count := 0
+	for _, pathItem := range spec.Paths {
+		for _, operation := range pathItem {
+			if openapi.OperationBaseURL(spec, &operation) == "" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// checkAuthEnv 检查规范和后端认证需要的环境变量是否已设置，只报告变量名，不输出取值
+func checkAuthEnv(spec *config.OpenAPISpec, global *config.GlobalConfig) Check {
+	required := RequiredEnv(spec, global)
+	missing := []string{}
+	for _, key := range required {
+		if os.Getenv(key) == "" {
+			missing = append(missing, key)
+		}
+	}
+
+	check := Check{
+		Status:  StatusOK,
+		Message: fmt.Sprintf("需要的 %d 个环境变量均已设置", len(required)),
+		Details: map[string]interface{}{"required": required, "missing": missing},
+	}
+	if len(required) == 0 {
+		check.Message = "不需要认证环境变量"
+	}
+	if len(missing) > 0 {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("缺少环境变量: %v，使用这些凭据的调用会失败", missing)
+	}
+	return check
+}
+
+// RequiredEnv 返回规范中的安全方案以及 GraphQL、gRPC 后端认证需要的环境变量，按名称排序
+func RequiredEnv(spec *config.OpenAPISpec, global *config.GlobalConfig) []string {
+	seen := make(map[string]bool)
+	for _, key := range openapi.RequiredEnvVars(spec) {
+		seen[key] = true
+	}
+
+	var auths []config.AuthConfig
+	if global != nil && global.GraphQL != nil {
+		auths = append(auths, global.GraphQL.Auth)
+	}
+	if global != nil && global.GRPC != nil {
+		auths = append(auths, global.GRPC.Auth)
+	}
+	for _, auth := range auths {
+		for _, key := range []string{auth.TokenEnv, auth.KeyEnv} {
+			if key != "" {
+				seen[key] = true
+			}
+		}
+	}
+
+	env := make([]string, 0, len(seen))
+	for key := range seen {
+		env = append(env, key)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// checkUpstream 检查上游的 TCP 连通性和 TLS 握手，证书即将到期时给出警告
+func checkUpstream(ctx context.Context, opts Options, rawURL string) Check {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	details, err := opts.Prober.ProbeUpstream(ctx, rawURL)
+	if err != nil {
+		return Check{Target: rawURL, Status: StatusFail, Message: err.Error(), Details: details}
+	}
+
+	check := Check{Target: rawURL, Status: StatusOK, Message: "连接成功", Details: details}
+	if daysLeft, ok := details["days_left"].(float64); ok {
+		check.Message = fmt.Sprintf("连接成功，证书剩余 %.1f 天", daysLeft)
+		if expiring, _ := details["expiring"].(bool); expiring {
+			check.Status = StatusWarn
+		}
+	}
+	return check
+}
+
+// checkLogDir 检查日志目录是否可写
+func checkLogDir() Check {
+	dir, err := paths.LogDir()
+	if err != nil {
+		return Check{Status: StatusFail, Message: fmt.Sprintf("无法确定日志目录: %v", err)}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Check{Target: dir, Status: StatusFail, Message: fmt.Sprintf("无法创建日志目录: %v", err)}
+	}
+	file, err := os.CreateTemp(dir, ".selfcheck-*")
+	if err != nil {
+		return Check{Target: dir, Status: StatusFail, Message: fmt.Sprintf("日志目录不可写: %v", err)}
+	}
+	file.Close()
+	os.Remove(file.Name())
+	return Check{Target: dir, Status: StatusOK, Message: "可写"}
+}
@@ -0,0 +1,161 @@
+package diagnostics
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/internal/paths"
+)
+
+// EnvVar 网关运行时读取的一个环境变量
+type EnvVar struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Source 引用该变量的配置，如 securitySchemes.bearerAuth、global.graphql.auth
+	Source string `json:"source"`
+	// Required 凭据类变量，未设置时使用它的调用或连接会失败；其他变量只用于覆盖配置
+	Required bool `json:"required"`
+	// Secret 取值是密钥，生成的示例文件和输出中不包含取值
+	Secret bool `json:"secret"`
+	Set    bool `json:"set"`
+}
+
+// EnvVars 返回规范和服务器配置需要的环境变量，凭据在前并按名称排序
+// includeOptional 为 true 时还包括覆盖配置的 MCP2REST_* 等可选变量
+func EnvVars(spec *config.OpenAPISpec, global *config.GlobalConfig, includeOptional bool) []EnvVar {
+	byName := make(map[string]*EnvVar)
+	add := func(v EnvVar) {
+		if v.Name == "" {
+			return
+		}
+		// 同一变量被多处引用时合并来源
+		if existing, exists := byName[v.Name]; exists {
+			existing.Source += ", " + v.Source
+			existing.Required = existing.Required || v.Required
+			return
+		}
+		v.Set = os.Getenv(v.Name) != ""
+		byName[v.Name] = &v
+	}
+
+	for _, usage := range openapi.UsedSchemes(spec) {
+		for _, v := range authEnvVars(usage.Auth, fmt.Sprintf("安全方案 %s", usage.Name)) {
+			v.Source = "securitySchemes." + usage.Name
+			if usage.Scheme.Auth != nil {
+				// 变量名来自 auth 绑定而不是默认的命名约定
+				v.Source += " (auth)"
+			}
+			v.Description += fmt.Sprintf("，%d 个操作使用", usage.Operations)
+			add(v)
+		}
+	}
+
+	if global != nil {
+		if global.GraphQL != nil {
+			for _, v := range authEnvVars(&global.GraphQL.Auth, "GraphQL 后端") {
+				v.Source = "global.graphql.auth"
+				add(v)
+			}
+		}
+		if global.GRPC != nil {
+			for _, v := range authEnvVars(&global.GRPC.Auth, "gRPC 后端") {
+				v.Source = "global.grpc.auth"
+				add(v)
+			}
+		}
+		if global.Access != nil {
+			for _, client := range global.Access.Clients {
+				add(EnvVar{
+					Name:        client.TokenEnv,
+					Description: fmt.Sprintf("入站客户端 %s 连接时使用的 Bearer 令牌", client.Name),
+					Source:      "global.access.clients",
+					Required:    client.Token == "",
+					Secret:      true,
+				})
+			}
+		}
+		if s := global.Summarize; s != nil && s.Sampling != "only" {
+			add(EnvVar{
+				Name:        s.APIKeyEnv,
+				Description: "结果摘要使用的对话接口 API 密钥",
+				Source:      "global.summarize",
+				Required:    s.APIKey == "",
+				Secret:      true,
+			})
+		}
+	}
+
+	if includeOptional {
+		for _, override := range config.EnvOverrides {
+			add(EnvVar{Name: override.Name, Description: override.Description, Source: "环境变量覆盖"})
+		}
+		add(EnvVar{Name: config.SpecB64Env, Description: "base64 编码的 OpenAPI 规范，设置后不读取规范文件", Source: "环境变量覆盖"})
+		add(EnvVar{Name: config.ServerConfigB64Env, Description: "base64 编码的服务器配置，设置后不读取配置文件", Source: "环境变量覆盖"})
+		add(EnvVar{Name: paths.HomeEnv, Description: "基础目录，配置、规范、.env 和日志的相对路径都基于该目录解析", Source: "运行环境"})
+		add(EnvVar{Name: config.MasterKeyEnv, Description: "解密 .env.enc 加密凭据文件的主密钥", Source: "运行环境", Secret: true})
+		add(EnvVar{Name: config.SecretsFileEnv, Description: "加密凭据文件的路径，默认在 .env 的查找目录中查找 .env.enc", Source: "运行环境"})
+		add(EnvVar{Name: config.EnvFileOverrideEnv, Description: "设置为 true 时 .env 中的值覆盖已设置的环境变量", Source: "运行环境"})
+		add(EnvVar{Name: i18n.LocaleEnv, Description: "错误消息和命令行输出的语言: zh 或 en", Source: "运行环境"})
+		add(EnvVar{Name: "DEBUG", Description: "设置为 true 时输出调试日志", Source: "运行环境"})
+	}
+
+	vars := make([]EnvVar, 0, len(byName))
+	for _, v := range byName {
+		vars = append(vars, *v)
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		if vars[i].Required != vars[j].Required {
+			return vars[i].Required
+		}
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
+
+// authEnvVars 返回认证配置读取的环境变量，已在配置中直接给出的基本认证用户名或密码不需要环境变量
+func authEnvVars(auth *config.AuthConfig, owner string) []EnvVar {
+	switch auth.Type {
+	case "bearer":
+		return []EnvVar{{Name: auth.TokenEnv, Description: owner + " 的 Bearer 令牌", Required: true, Secret: true}}
+	case "oauth2":
+		return []EnvVar{{Name: auth.TokenEnv, Description: owner + " 的 OAuth2 访问令牌", Required: true, Secret: true}}
+	case "api_key":
+		location := "请求头 " + auth.HeaderName
+		if auth.QueryParam != "" {
+			location = "查询参数 " + auth.QueryParam
+		}
+		return []EnvVar{{Name: auth.KeyEnv, Description: fmt.Sprintf("%s 的 API 密钥 (%s)", owner, location), Required: true, Secret: true}}
+	case "basic", "ntlm", "negotiate":
+		kind := map[string]string{"basic": "基本认证", "ntlm": "NTLM", "negotiate": "Negotiate"}[auth.Type]
+		var vars []EnvVar
+		if auth.Username == "" {
+			vars = append(vars, EnvVar{Name: auth.TokenEnv, Description: owner + " 的" + kind + "用户名", Required: true})
+		}
+		if auth.Password == "" && auth.Keytab == "" {
+			vars = append(vars, EnvVar{Name: auth.KeyEnv, Description: owner + " 的" + kind + "密码", Required: true, Secret: true})
+		}
+		return vars
+	}
+	return nil
+}
+
+// EnvExample 生成 .env.example 的内容：凭据写成空值，可选变量注释掉
+func EnvExample(title string, vars []EnvVar) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s 需要的环境变量，由 mcp2rest env 生成\n", title)
+	fmt.Fprintln(&b, "# 复制为 .env 并填写取值，已在环境中设置的变量不会被 .env 覆盖")
+	for _, v := range vars {
+		fmt.Fprintf(&b, "\n# %s (%s)\n", v.Description, v.Source)
+		if v.Required {
+			fmt.Fprintf(&b, "%s=\n", v.Name)
+		} else {
+			fmt.Fprintf(&b, "# %s=\n", v.Name)
+		}
+	}
+	return []byte(b.String())
+}
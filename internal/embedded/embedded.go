@@ -0,0 +1,8 @@
+// Package embedded 提供编译进二进制文件的 OpenAPI 规范
+//
+// 使用 embedspec 构建标签时嵌入 internal/embedded/spec.yaml (make build-embedded SPEC=...)，
+// 这样在不挂载配置文件的容器中也可以运行。
+package embedded
+
+// Spec 嵌入的规范内容，未使用 embedspec 构建标签时为空
+var Spec []byte
@@ -0,0 +1,12 @@
+//go:build embedspec
+
+package embedded
+
+import _ "embed"
+
+//go:embed spec.yaml
+var embeddedSpec []byte
+
+func init() {
+	Spec = embeddedSpec
+}
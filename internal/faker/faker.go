@@ -0,0 +1,397 @@
+// Package faker 按 OpenAPI 模式生成看起来真实的假数据，用于演练模式和 mock 后端
+//
+// 字符串按 format 和字段名生成 (邮箱、姓名、URL、日期等)，数值遵守 minimum/maximum，
+// 字符串和数组遵守长度约束，枚举随机选择其中一个值。相同的种子生成相同的数据，
+// 同一个请求多次演练时得到一致的结果。
+package faker
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// schemaRefPrefix 规范内部模式引用的前缀
+const schemaRefPrefix = "#/components/schemas/"
+
+// maxDepth 嵌套对象和引用的最大深度，超过时生成 null
+const maxDepth = 16
+
+// Generator 按模式生成假数据，不是并发安全的
+type Generator struct {
+	spec      *config.OpenAPISpec
+	rand      *rand.Rand
+	generated bool
+}
+
+// New 创建生成器，spec 用于解析 #/components/schemas 引用，可以为 nil
+func New(spec *config.OpenAPISpec, seed int64) *Generator {
+	return &Generator{spec: spec, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Seed 根据给定的字符串 (如方法和 URL) 计算种子
+func Seed(parts ...string) int64 {
+	hash := fnv.New64a()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return int64(hash.Sum64())
+}
+
+// Value 生成符合模式的值：模式中的 example 和 default 优先，其余按类型、format 和字段名生成
+func (g *Generator) Value(schema config.Schema) interface{} {
+	return g.value(schema, "", make(map[string]bool), 0)
+}
+
+// Generated 是否生成过值，全部来自 example/default 时为 false
+func (g *Generator) Generated() bool {
+	return g.generated
+}
+
+func (g *Generator) value(schema config.Schema, name string, visiting map[string]bool, depth int) interface{} {
+	if schema.Ref != "" {
+		resolved, ok := g.resolve(schema.Ref)
+		if !ok || visiting[schema.Ref] || depth >= maxDepth {
+			g.generated = true
+			return nil
+		}
+		visiting[schema.Ref] = true
+		defer delete(visiting, schema.Ref)
+		return g.value(resolved, name, visiting, depth+1)
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	}
+
+	g.generated = true
+	if len(schema.Enum) > 0 {
+		return schema.Enum[g.rand.Intn(len(schema.Enum))]
+	}
+
+	switch {
+	case schema.Type == "object" || (schema.Type == "" && len(schema.Properties) > 0):
+		if depth >= maxDepth {
+			return nil
+		}
+		// 按名称顺序生成，相同的种子得到相同的结果
+		names := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			names = append(names, property)
+		}
+		sort.Strings(names)
+		object := make(map[string]interface{}, len(names))
+		for _, property := range names {
+			object[property] = g.value(schema.Properties[property], property, visiting, depth+1)
+		}
+		return object
+	case schema.Type == "array":
+		if schema.Items == nil || depth >= maxDepth {
+			return []interface{}{}
+		}
+		count := g.between(intOr(schema.MinItems, 1), intOr(schema.MaxItems, 3))
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = g.value(*schema.Items, singular(name), visiting, depth+1)
+		}
+		return items
+	case schema.Type == "string":
+		return g.lengthBounded(g.stringValue(schema.Format, name), schema)
+	case schema.Type == "integer":
+		return g.integer(schema, name)
+	case schema.Type == "number":
+		return g.number(schema, name)
+	case schema.Type == "boolean":
+		return g.rand.Intn(2) == 0
+	}
+	return nil
+}
+
+// resolve 解析 #/components/schemas/<name> 形式的引用
+func (g *Generator) resolve(ref string) (config.Schema, bool) {
+	if g.spec == nil || !strings.HasPrefix(ref, schemaRefPrefix) {
+		return config.Schema{}, false
+	}
+	schema, exists := g.spec.Components.Schemas[strings.TrimPrefix(ref, schemaRefPrefix)]
+	return schema, exists
+}
+
+// stringValue 先按 format，再按字段名生成字符串
+func (g *Generator) stringValue(format, name string) string {
+	switch format {
+	case "date":
+		return g.time().Format("2006-01-02")
+	case "date-time":
+		return g.time().Format(time.RFC3339)
+	case "time":
+		return g.time().Format("15:04:05")
+	case "email":
+		return g.email()
+	case "uuid":
+		return g.uuid()
+	case "uri", "url":
+		return g.url()
+	case "hostname":
+		return g.pick(words) + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", g.between(1, 254))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", g.between(1, 0xffff))
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(g.pick(words)))
+	case "password":
+		return "********"
+	}
+
+	key := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	switch {
+	case strings.Contains(key, "email"):
+		return g.email()
+	case strings.HasSuffix(key, "url") || strings.HasSuffix(key, "uri") || strings.Contains(key, "link") || key == "href" || key == "website":
+		return g.url()
+	case strings.Contains(key, "phone") || strings.Contains(key, "mobile") || key == "tel":
+		return fmt.Sprintf("+1-555-%03d-%04d", g.between(100, 999), g.between(0, 9999))
+	case strings.Contains(key, "firstname") || key == "givenname":
+		return g.pick(firstNames)
+	case strings.Contains(key, "lastname") || key == "surname" || key == "familyname":
+		return g.pick(lastNames)
+	case key == "username" || key == "login" || key == "handle":
+		return strings.ToLower(g.pick(firstNames)) + fmt.Sprint(g.between(1, 99))
+	case key == "name" || key == "fullname" || key == "displayname" || key == "author" || key == "owner":
+		return g.pick(firstNames) + " " + g.pick(lastNames)
+	case strings.Contains(key, "company") || strings.Contains(key, "organization") || key == "org":
+		return g.pick(lastNames) + " " + g.pick(companySuffixes)
+	case key == "city":
+		return g.pick(cities)
+	case key == "country":
+		return g.pick(countries)
+	case key == "countrycode":
+		return g.pick(countryCodes)
+	case strings.Contains(key, "address") || key == "street":
+		return fmt.Sprintf("%d %s St", g.between(1, 999), g.pick(lastNames))
+	case strings.Contains(key, "zip") || strings.Contains(key, "postal"):
+		return fmt.Sprintf("%05d", g.between(10000, 99999))
+	case key == "currency":
+		return g.pick(currencies)
+	case key == "color" || key == "colour":
+		return g.pick(colors)
+	case key == "status" || key == "state":
+		return g.pick(statuses)
+	case key == "locale" || key == "language" || key == "lang":
+		return g.pick(locales)
+	case key == "version":
+		return fmt.Sprintf("%d.%d.%d", g.between(0, 5), g.between(0, 20), g.between(0, 50))
+	case strings.Contains(key, "token") || strings.Contains(key, "secret") || strings.HasSuffix(key, "key") || key == "hash":
+		return g.hex(32)
+	case key == "ip" || strings.HasSuffix(key, "ipaddress"):
+		return fmt.Sprintf("192.0.2.%d", g.between(1, 254))
+	case key == "host" || key == "hostname" || key == "domain":
+		return g.pick(words) + ".example.com"
+	case key == "id" || strings.HasSuffix(key, "id"):
+		return g.hex(12)
+	case strings.HasSuffix(name, "At") || strings.HasSuffix(key, "time") || strings.HasSuffix(key, "date") ||
+		strings.HasSuffix(strings.ToLower(name), "_at"):
+		return g.time().Format(time.RFC3339)
+	case key == "title" || key == "subject" || key == "label" || strings.HasSuffix(key, "name"):
+		return titleCase(g.words(g.between(2, 4)))
+	case strings.Contains(key, "description") || strings.Contains(key, "summary") || strings.Contains(key, "comment") ||
+		strings.Contains(key, "message") || key == "text" || key == "content" || key == "body" || key == "note":
+		return g.sentence()
+	case strings.HasPrefix(key, "tag") || key == "category" || key == "type" || key == "kind":
+		return g.pick(words)
+	}
+	return g.words(g.between(1, 3))
+}
+
+// lengthBounded 按 minLength/maxLength 补齐或截断字符串
+func (g *Generator) lengthBounded(value string, schema config.Schema) string {
+	runes := []rune(value)
+	if schema.MaxLength != nil && len(runes) > *schema.MaxLength {
+		runes = runes[:max(*schema.MaxLength, 0)]
+	}
+	if schema.MinLength != nil {
+		for len(runes) < *schema.MinLength {
+			runes = append(runes, rune('a'+g.rand.Intn(26)))
+		}
+	}
+	return string(runes)
+}
+
+// integer 生成 [minimum, maximum] 内的整数，未指定边界时按字段名选择合理的范围
+func (g *Generator) integer(schema config.Schema, name string) int64 {
+	low, high := integerRange(name)
+	if schema.Minimum != nil {
+		low = int64(math.Ceil(*schema.Minimum))
+		if schema.ExclusiveMinimum && float64(low) == *schema.Minimum {
+			low++
+		}
+		if schema.Maximum == nil && high < low {
+			high = low + 1000
+		}
+	}
+	if schema.Maximum != nil {
+		high = int64(math.Floor(*schema.Maximum))
+		if schema.ExclusiveMaximum && float64(high) == *schema.Maximum {
+			high--
+		}
+		if schema.Minimum == nil && low > high {
+			low = high - 1000
+		}
+	}
+	if high <= low {
+		return low
+	}
+	return low + g.rand.Int63n(high-low+1)
+}
+
+// number 生成 [minimum, maximum] 内保留两位小数的数
+func (g *Generator) number(schema config.Schema, name string) float64 {
+	low, high := 1.0, 1000.0
+	if lowInt, highInt := integerRange(name); lowInt != 1 || highInt != 1000 {
+		low, high = float64(lowInt), float64(highInt)
+	}
+	if schema.Minimum != nil {
+		low = *schema.Minimum
+		if schema.Maximum == nil && high < low {
+			high = low + 1000
+		}
+	}
+	if schema.Maximum != nil {
+		high = *schema.Maximum
+		if schema.Minimum == nil && low > high {
+			low = high - 1000
+		}
+	}
+	if high <= low {
+		return low
+	}
+	value := math.Round((low+g.rand.Float64()*(high-low))*100) / 100
+	// 舍入后可能落在开区间的边界上
+	if (schema.ExclusiveMinimum && value <= low) || (schema.ExclusiveMaximum && value >= high) || value < low || value > high {
+		value = (low + high) / 2
+	}
+	return value
+}
+
+// integerRange 按字段名选择整数的默认范围
+func integerRange(name string) (int64, int64) {
+	key := strings.ToLower(name)
+	switch {
+	case key == "age":
+		return 18, 80
+	case key == "year":
+		return 2000, 2025
+	case key == "port":
+		return 1024, 65535
+	case strings.Contains(key, "percent") || key == "progress":
+		return 0, 100
+	case key == "page":
+		return 1, 10
+	case strings.Contains(key, "count") || strings.Contains(key, "total") || strings.Contains(key, "quantity") ||
+		key == "qty" || key == "size" || key == "limit":
+		return 0, 100
+	case strings.Contains(key, "price") || strings.Contains(key, "amount") || strings.Contains(key, "cost"):
+		return 1, 1000
+	case key == "id" || strings.HasSuffix(key, "id"):
+		return 1, 100000
+	}
+	return 1, 1000
+}
+
+func (g *Generator) email() string {
+	return fmt.Sprintf("%s.%s@example.com", strings.ToLower(g.pick(firstNames)), strings.ToLower(g.pick(lastNames)))
+}
+
+func (g *Generator) url() string {
+	return fmt.Sprintf("https://example.com/%s/%d", g.pick(words), g.between(1, 9999))
+}
+
+func (g *Generator) uuid() string {
+	var b [16]byte
+	g.rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func (g *Generator) hex(length int) string {
+	b := make([]byte, (length+1)/2)
+	g.rand.Read(b)
+	return fmt.Sprintf("%x", b)[:length]
+}
+
+// time 返回 2023 至 2025 年之间的时间，精确到秒
+func (g *Generator) time() time.Time {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(g.rand.Int63n(3*365*24*3600)) * time.Second)
+}
+
+func (g *Generator) words(count int) string {
+	parts := make([]string, count)
+	for i := range parts {
+		parts[i] = g.pick(words)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (g *Generator) sentence() string {
+	text := g.words(g.between(6, 12))
+	return strings.ToUpper(text[:1]) + text[1:] + "."
+}
+
+// titleCase 把每个单词的首字母大写，词表中只有 ASCII 单词
+func titleCase(text string) string {
+	parts := strings.Fields(text)
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, " ")
+}
+
+func (g *Generator) pick(values []string) string {
+	return values[g.rand.Intn(len(values))]
+}
+
+// between 返回 [low, high] 内的整数
+func (g *Generator) between(low, high int) int {
+	if high <= low {
+		return low
+	}
+	return low + g.rand.Intn(high-low+1)
+}
+
+// singular 数组字段的元素使用单数形式的字段名，如 tags 的元素按 tag 生成
+func singular(name string) string {
+	if strings.HasSuffix(name, "ies") {
+		return strings.TrimSuffix(name, "ies") + "y"
+	}
+	if strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") {
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+func intOr(value *int, fallback int) int {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
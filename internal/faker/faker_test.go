@@ -0,0 +1,196 @@
+package faker
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+func float(v float64) *float64 { return &v }
+
+func integer(v int) *int { return &v }
+
+// seeds 对依赖随机数的约束检查多个种子
+const seeds = 200
+
+func userSchema() config.Schema {
+	return config.Schema{Type: "object", Properties: map[string]config.Schema{
+		"id":        {Type: "string", Format: "uuid"},
+		"email":     {Type: "string"},
+		"name":      {Type: "string"},
+		"age":       {Type: "integer"},
+		"createdAt": {Type: "string"},
+		"tags":      {Type: "array", Items: &config.Schema{Type: "string"}},
+		"role":      {Type: "string", Enum: []interface{}{"admin", "user"}},
+	}}
+}
+
+func TestValueDeterministic(t *testing.T) {
+	first := New(nil, 42).Value(userSchema())
+	second := New(nil, 42).Value(userSchema())
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("相同种子生成了不同的值:\n%v\n%v", first, second)
+	}
+	if other := New(nil, 43).Value(userSchema()); reflect.DeepEqual(first, other) {
+		t.Fatalf("不同种子生成了相同的值: %v", other)
+	}
+	if Seed("GET", "/users/1") != Seed("GET", "/users/1") || Seed("GET", "/users/1") == Seed("GET/", "users/1") {
+		t.Fatal("Seed 应由各部分及其边界决定")
+	}
+}
+
+func TestValueExampleAndDefault(t *testing.T) {
+	tests := []struct {
+		name          string
+		schema        config.Schema
+		want          interface{}
+		wantGenerated bool
+	}{
+		{"example wins", config.Schema{Type: "string", Example: "ex", Default: "def"}, "ex", false},
+		{"default", config.Schema{Type: "integer", Default: 7}, 7, false},
+		{"enum", config.Schema{Type: "string", Enum: []interface{}{"only"}}, "only", true},
+		{"unknown type", config.Schema{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New(nil, 1)
+			if got := g.Value(tt.schema); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Value() = %#v, want %#v", got, tt.want)
+			}
+			if g.Generated() != tt.wantGenerated {
+				t.Fatalf("Generated() = %v, want %v", g.Generated(), tt.wantGenerated)
+			}
+		})
+	}
+}
+
+func TestStringFormats(t *testing.T) {
+	tests := []struct {
+		format  string
+		name    string
+		pattern string
+	}{
+		{"email", "", `^[a-z]+\.[a-z]+@example\.com$`},
+		{"uuid", "", `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{"date", "", `^\d{4}-\d{2}-\d{2}$`},
+		{"time", "", `^\d{2}:\d{2}:\d{2}$`},
+		{"uri", "", `^https://example\.com/[a-z]+/\d+$`},
+		{"ipv4", "", `^192\.0\.2\.\d{1,3}$`},
+		{"password", "", `^\*+$`},
+		{"", "contactEmail", `@example\.com$`},
+		{"", "phone", `^\+1-555-\d{3}-\d{4}$`},
+		{"", "zip_code", `^\d{5}$`},
+		{"", "userId", `^[0-9a-f]{12}$`},
+		{"", "version", `^\d+\.\d+\.\d+$`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+tt.name, func(t *testing.T) {
+			pattern := regexp.MustCompile(tt.pattern)
+			schema := config.Schema{Type: "object", Properties: map[string]config.Schema{
+				"value": {Type: "string", Format: tt.format},
+			}}
+			if tt.name != "" {
+				schema.Properties = map[string]config.Schema{tt.name: {Type: "string"}}
+			}
+			for seed := int64(0); seed < seeds; seed++ {
+				object := New(nil, seed).Value(schema).(map[string]interface{})
+				for _, value := range object {
+					if !pattern.MatchString(value.(string)) {
+						t.Fatalf("seed %d: %q 不匹配 %s", seed, value, tt.pattern)
+					}
+				}
+			}
+		})
+	}
+
+	for seed := int64(0); seed < seeds; seed++ {
+		value := New(nil, seed).Value(config.Schema{Type: "string", Format: "date-time"}).(string)
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			t.Fatalf("seed %d: date-time %q: %v", seed, value, err)
+		}
+	}
+}
+
+func TestNumberBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    config.Schema
+		low, high float64
+	}{
+		{"integer inclusive", config.Schema{Type: "integer", Minimum: float(5), Maximum: float(7)}, 5, 7},
+		{"integer exclusive", config.Schema{Type: "integer", Minimum: float(5), Maximum: float(7), ExclusiveMinimum: true, ExclusiveMaximum: true}, 6, 6},
+		{"integer fractional bounds", config.Schema{Type: "integer", Minimum: float(1.5), Maximum: float(3.5)}, 2, 3},
+		{"integer minimum only", config.Schema{Type: "integer", Minimum: float(5000)}, 5000, 6000},
+		{"integer maximum only", config.Schema{Type: "integer", Maximum: float(-10)}, -1010, -10},
+		{"number inclusive", config.Schema{Type: "number", Minimum: float(0.5), Maximum: float(0.75)}, 0.5, 0.75},
+		{"number exclusive", config.Schema{Type: "number", Minimum: float(0), Maximum: float(0.01), ExclusiveMinimum: true, ExclusiveMaximum: true}, 0, 0.01},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(0); seed < seeds; seed++ {
+				var value float64
+				switch v := New(nil, seed).Value(tt.schema).(type) {
+				case int64:
+					value = float64(v)
+				case float64:
+					value = v
+				default:
+					t.Fatalf("Value() = %T", v)
+				}
+				if value < tt.low || value > tt.high {
+					t.Fatalf("seed %d: %v 超出 [%v, %v]", seed, value, tt.low, tt.high)
+				}
+				if tt.schema.ExclusiveMinimum && value == *tt.schema.Minimum || tt.schema.ExclusiveMaximum && value == *tt.schema.Maximum {
+					t.Fatalf("seed %d: %v 落在开区间的边界上", seed, value)
+				}
+			}
+		})
+	}
+}
+
+func TestLengthAndItemBounds(t *testing.T) {
+	stringSchema := config.Schema{Type: "string", MinLength: integer(20), MaxLength: integer(25)}
+	arraySchema := config.Schema{Type: "array", MinItems: integer(2), MaxItems: integer(4), Items: &config.Schema{Type: "boolean"}}
+	for seed := int64(0); seed < seeds; seed++ {
+		g := New(nil, seed)
+		if s := g.Value(stringSchema).(string); len([]rune(s)) < 20 || len([]rune(s)) > 25 {
+			t.Fatalf("seed %d: 字符串长度 %d 超出 [20, 25]", seed, len([]rune(s)))
+		}
+		if items := g.Value(arraySchema).([]interface{}); len(items) < 2 || len(items) > 4 {
+			t.Fatalf("seed %d: 数组长度 %d 超出 [2, 4]", seed, len(items))
+		}
+	}
+	if s := New(nil, 1).Value(config.Schema{Type: "string", Format: "email", MaxLength: integer(3)}).(string); len(s) != 3 {
+		t.Fatalf("maxLength 截断后 = %q", s)
+	}
+}
+
+func TestRefs(t *testing.T) {
+	spec := &config.OpenAPISpec{}
+	spec.Components.Schemas = map[string]config.Schema{
+		"Node": {Type: "object", Properties: map[string]config.Schema{
+			"label": {Type: "string", Example: "leaf"},
+			"next":  {Ref: "#/components/schemas/Node"},
+		}},
+	}
+	got := New(spec, 1).Value(config.Schema{Ref: "#/components/schemas/Node"})
+	// 递归引用在第二次进入时生成 null
+	want := map[string]interface{}{"label": "leaf", "next": nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Value() = %#v, want %#v", got, want)
+	}
+	if got := New(nil, 1).Value(config.Schema{Ref: "#/components/schemas/Missing"}); got != nil {
+		t.Fatalf("无法解析的引用 = %#v, want nil", got)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	for input, want := range map[string]string{"tags": "tag", "categories": "category", "address": "address", "data": "data"} {
+		if got := singular(input); got != want {
+			t.Errorf("singular(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
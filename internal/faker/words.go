@@ -0,0 +1,42 @@
+package faker
+
+// 生成假数据使用的词表
+
+var firstNames = []string{
+	"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Isabel", "Jack",
+	"Karen", "Liam", "Mia", "Noah", "Olivia", "Paul", "Quinn", "Ruby", "Sam", "Tina",
+}
+
+var lastNames = []string{
+	"Anderson", "Brown", "Clark", "Davis", "Evans", "Foster", "Garcia", "Harris", "Jackson", "King",
+	"Lee", "Martin", "Nelson", "Owens", "Parker", "Reed", "Smith", "Taylor", "Walker", "Young",
+}
+
+var companySuffixes = []string{"Inc", "LLC", "Group", "Labs", "Systems", "Partners"}
+
+var cities = []string{
+	"Amsterdam", "Berlin", "Chicago", "Dublin", "Hangzhou", "London", "Madrid", "Paris",
+	"Seattle", "Shanghai", "Singapore", "Sydney", "Tokyo", "Toronto",
+}
+
+var countries = []string{
+	"Australia", "Canada", "China", "France", "Germany", "Ireland", "Japan", "Netherlands",
+	"Singapore", "Spain", "United Kingdom", "United States",
+}
+
+var countryCodes = []string{"AU", "CA", "CN", "DE", "ES", "FR", "GB", "IE", "JP", "NL", "SG", "US"}
+
+var currencies = []string{"AUD", "CAD", "CNY", "EUR", "GBP", "JPY", "SGD", "USD"}
+
+var colors = []string{"red", "green", "blue", "orange", "purple", "teal", "black", "white"}
+
+var statuses = []string{"active", "pending", "completed", "inactive"}
+
+var locales = []string{"en-US", "en-GB", "zh-CN", "de-DE", "fr-FR", "ja-JP"}
+
+var words = []string{
+	"alpha", "amber", "anchor", "atlas", "beacon", "bridge", "canyon", "cedar", "cloud", "comet",
+	"delta", "ember", "falcon", "forest", "galaxy", "garden", "harbor", "horizon", "island", "jade",
+	"lagoon", "maple", "meadow", "nova", "orbit", "pearl", "pixel", "prairie", "quartz", "river",
+	"summit", "timber", "vertex", "willow", "zephyr",
+}
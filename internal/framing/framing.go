@@ -0,0 +1,228 @@
+// Package framing 实现标准输入/输出上的消息分帧
+//
+// 支持两种分帧方式: 每行一个 JSON 的 ndjson，以及 LSP 风格的 Content-Length 头部分帧。
+// auto 模式根据每条消息的首个非空白字符判断: '{' 或 '[' 为 ndjson，否则按头部解析。
+package framing
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mode 分帧方式
+type Mode string
+
+const (
+	NDJSON        Mode = "ndjson"
+	ContentLength Mode = "content-length"
+	Auto          Mode = "auto"
+)
+
+// ParseMode 解析分帧方式，空字符串视为 ndjson
+func ParseMode(value string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(value))) {
+	case "", NDJSON:
+		return NDJSON, nil
+	case ContentLength:
+		return ContentLength, nil
+	case Auto:
+		return Auto, nil
+	default:
+		return "", fmt.Errorf("不支持的分帧方式: %s (支持: ndjson, content-length, auto)", value)
+	}
+}
+
+// Encode 按指定方式为消息分帧，auto 按 ndjson 处理
+func Encode(mode Mode, message []byte) []byte {
+	if mode == ContentLength {
+		header := "Content-Length: " + strconv.Itoa(len(message)) + "\r\n\r\n"
+		return append([]byte(header), message...)
+	}
+	framed := make([]byte, 0, len(message)+1)
+	framed = append(framed, message...)
+	return append(framed, '\n')
+}
+
+// TooLargeError 消息超过大小限制，超出部分已被丢弃，读取器可以继续读取下一条消息
+type TooLargeError struct {
+	Limit int64
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("消息大小超过限制 (max_request_size=%d 字节)", e.Limit)
+}
+
+// Reader 从输入中读取完整消息
+type Reader struct {
+	reader  *bufio.Reader
+	mode    Mode
+	maxSize int64
+
+	mu       sync.Mutex
+	detected Mode
+}
+
+// NewReader 创建消息读取器
+func NewReader(reader *bufio.Reader, mode Mode) *Reader {
+	return &Reader{reader: reader, mode: mode}
+}
+
+// SetMaxSize 设置单条消息的最大字节数，0 表示不限制
+func (r *Reader) SetMaxSize(size int64) {
+	r.maxSize = size
+}
+
+// Detected 返回最近一条消息使用的分帧方式，用于以相同方式回复；尚未读到消息时返回 ndjson
+func (r *Reader) Detected() Mode {
+	if r.mode != Auto {
+		return r.mode
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.detected == "" {
+		return NDJSON
+	}
+	return r.detected
+}
+
+// ReadMessage 读取下一条消息，跳过空行
+func (r *Reader) ReadMessage() ([]byte, error) {
+	mode := r.mode
+	if mode == Auto {
+		var err error
+		if mode, err = r.detect(); err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		r.detected = mode
+		r.mu.Unlock()
+	}
+
+	if mode == ContentLength {
+		return r.readContentLength()
+	}
+	return r.readLine()
+}
+
+// detect 跳过空白字符后根据首字符判断分帧方式
+func (r *Reader) detect() (Mode, error) {
+	for {
+		b, err := r.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		if err := r.reader.UnreadByte(); err != nil {
+			return "", err
+		}
+		if b == '{' || b == '[' {
+			return NDJSON, nil
+		}
+		return ContentLength, nil
+	}
+}
+
+// readLine 读取一行非空 JSON
+func (r *Reader) readLine() ([]byte, error) {
+	for {
+		line, err := r.readBoundedLine()
+		if _, tooLarge := err.(*TooLargeError); tooLarge {
+			return nil, err
+		}
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 {
+			// 最后一行没有换行符时仍然返回内容，下次读取再报告 EOF
+			return trimmed, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readBoundedLine 读取一行，超过大小限制时不再缓存，丢弃到行尾后返回 TooLargeError
+func (r *Reader) readBoundedLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		if r.maxSize <= 0 || int64(len(line)+len(chunk)) <= r.maxSize+2 {
+			line = append(line, chunk...)
+		} else {
+			// 超出限制，继续读到行尾但不再保留内容
+			for err == bufio.ErrBufferFull {
+				_, err = r.reader.ReadSlice('\n')
+			}
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			return nil, &TooLargeError{Limit: r.maxSize}
+		}
+		if err != bufio.ErrBufferFull {
+			return line, err
+		}
+	}
+}
+
+// readContentLength 读取头部直到空行，再按 Content-Length 读取消息体
+func (r *Reader) readContentLength() ([]byte, error) {
+	length := -1
+	sawHeader := false
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && strings.TrimSpace(line) != "" {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			// 消息之间多余的空行
+			if !sawHeader {
+				continue
+			}
+			if length < 0 {
+				return nil, fmt.Errorf("消息头缺少 Content-Length")
+			}
+			break
+		}
+		sawHeader = true
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("无效的消息头: %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("无效的 Content-Length: %q", value)
+			}
+			length = n
+		}
+	}
+
+	if r.maxSize > 0 && int64(length) > r.maxSize {
+		// 按声明的长度丢弃消息体，保持后续消息的分帧正确
+		if _, err := io.CopyN(io.Discard, r.reader, int64(length)); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, &TooLargeError{Limit: r.maxSize}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r.reader, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("读取消息体失败: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mcp2rest/internal/auth"
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// Backend 将 GraphQL 操作暴露为 MCP 工具
+type Backend struct {
+	config     config.GraphQLConfig
+	httpClient *http.Client
+	auth       *auth.AuthManager
+	operations map[string]Operation // 键为工具名称
+	order      []string
+	identity   http.Header
+}
+
+// SetIdentity 设置附加到每个请求的客户端标识头，配置中的 headers 优先
+func (b *Backend) SetIdentity(header http.Header) {
+	b.identity = header
+}
+
+// Response 表示 GraphQL 响应
+type Response struct {
+	Data   interface{}              `json:"data"`
+	Errors []map[string]interface{} `json:"errors,omitempty"`
+}
+
+// NewBackend 加载操作文档并创建 GraphQL 后端
+func NewBackend(cfg config.GraphQLConfig, httpClient *http.Client, authManager *auth.AuthManager) (*Backend, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("GraphQL后端需要指定endpoint")
+	}
+
+	operations, err := LoadDocuments(cfg.Documents)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := &Backend{
+		config:     cfg,
+		httpClient: httpClient,
+		auth:       authManager,
+		operations: make(map[string]Operation, len(operations)),
+	}
+	for _, op := range operations {
+		name := cfg.ToolPrefix + op.Name
+		backend.operations[name] = op
+		backend.order = append(backend.order, name)
+	}
+
+	return backend, nil
+}
+
+// HasTool 检查工具是否由 GraphQL 后端提供
+func (b *Backend) HasTool(name string) bool {
+	_, exists := b.operations[name]
+	return exists
+}
+
+// Tools 返回 GraphQL 操作对应的工具定义
+func (b *Backend) Tools() []map[string]interface{} {
+	tools := make([]map[string]interface{}, 0, len(b.order))
+	for _, name := range b.order {
+		op := b.operations[name]
+
+		properties := make(map[string]interface{}, len(op.Variables))
+		required := make([]string, 0, len(op.Variables))
+		for _, v := range op.Variables {
+			properties[v.Name] = v.jsonSchema()
+			if v.Required {
+				required = append(required, v.Name)
+			}
+		}
+
+		description := op.Description
+		if description == "" {
+			description = fmt.Sprintf("GraphQL %s %s", op.Type, op.Name)
+		}
+
+		tools = append(tools, map[string]interface{}{
+			"name":        name,
+			"description": description,
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": properties,
+				"required":   required,
+			},
+		})
+	}
+	return tools
+}
+
+// Call 执行工具对应的 GraphQL 操作，只有传输失败时返回错误，GraphQL 错误包含在响应中
+func (b *Backend) Call(ctx context.Context, name string, params map[string]interface{}) (*Response, int, error) {
+	op, exists := b.operations[name]
+	if !exists {
+		return nil, 0, fmt.Errorf("未找到GraphQL操作: %s", name)
+	}
+
+	// 只提交操作声明的变量
+	variables := make(map[string]interface{}, len(op.Variables))
+	for _, v := range op.Variables {
+		if value, exists := params[v.Name]; exists {
+			variables[v.Name] = value
+		} else if v.Required {
+			return nil, 0, fmt.Errorf("缺少必需的GraphQL变量: %s", v.Name)
+		}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":         op.Document,
+		"operationName": op.Name,
+		"variables":     variables,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("序列化GraphQL请求失败: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", b.config.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, fmt.Errorf("创建GraphQL请求失败: %w", err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for key, values := range b.identity {
+		req.Header[key] = values
+	}
+	for key, value := range b.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	if err := b.auth.ApplyAuth(req, &b.config.Auth); err != nil {
+		return nil, 0, fmt.Errorf("应用身份验证失败: %w", err)
+	}
+
+	debug.LogHTTPRequest(ctx, map[string]interface{}{
+		"method":    req.Method,
+		"url":       req.URL.String(),
+		"operation": op.Name,
+		"variables": variables,
+	})
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("发送GraphQL请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("读取GraphQL响应失败: %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	debug.LogHTTPResponse(resp)
+
+	var result Response
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("解析GraphQL响应失败 (状态码 %d): %w", resp.StatusCode, err)
+	}
+	return &result, resp.StatusCode, nil
+}
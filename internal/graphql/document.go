@@ -0,0 +1,135 @@
+package graphql
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/mcp2rest/internal/paths"
+)
+
+// Operation 表示 GraphQL 文档中的具名操作
+type Operation struct {
+	Name        string     // 操作名称
+	Type        string     // "query" 或 "mutation"
+	Description string     // 操作前的注释
+	Document    string     // 操作所在的完整文档，发送时与 operationName 一起提交
+	Variables   []Variable // 变量定义
+}
+
+// Variable 表示操作的变量定义
+type Variable struct {
+	Name       string
+	Type       string // GraphQL 类型，如 "ID!"、"[String]"
+	Required   bool
+	HasDefault bool
+}
+
+var (
+	operationPattern = regexp.MustCompile(`(?m)^\s*(query|mutation)\s+([A-Za-z_][A-Za-z0-9_]*)\s*(\(([^)]*)\))?`)
+	variablePattern  = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)\s*:\s*([\[\]A-Za-z_0-9!]+)(\s*=)?`)
+)
+
+// LoadDocuments 从 .graphql 文件加载所有具名操作
+func LoadDocuments(files []string) ([]Operation, error) {
+	var operations []Operation
+	seen := make(map[string]string)
+
+	for _, path := range files {
+		data, err := ioutil.ReadFile(paths.Resolve(path))
+		if err != nil {
+			return nil, fmt.Errorf("读取GraphQL文档 %s 失败: %w", path, err)
+		}
+
+		parsed, err := ParseDocument(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("解析GraphQL文档 %s 失败: %w", path, err)
+		}
+
+		for _, op := range parsed {
+			if previous, exists := seen[op.Name]; exists {
+				return nil, fmt.Errorf("GraphQL操作 %s 重复定义: %s 和 %s", op.Name, previous, path)
+			}
+			seen[op.Name] = path
+			operations = append(operations, op)
+		}
+	}
+
+	return operations, nil
+}
+
+// ParseDocument 解析文档中的具名 query 和 mutation，匿名操作和 subscription 会被忽略
+func ParseDocument(document string) ([]Operation, error) {
+	matches := operationPattern.FindAllStringSubmatchIndex(document, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("未找到具名的 query 或 mutation")
+	}
+
+	operations := make([]Operation, 0, len(matches))
+	for _, m := range matches {
+		op := Operation{
+			Type:        document[m[2]:m[3]],
+			Name:        document[m[4]:m[5]],
+			Description: leadingComment(document[:m[0]]),
+			Document:    document,
+		}
+
+		if m[8] >= 0 {
+			for _, v := range variablePattern.FindAllStringSubmatch(document[m[8]:m[9]], -1) {
+				op.Variables = append(op.Variables, Variable{
+					Name:       v[1],
+					Type:       v[2],
+					Required:   strings.HasSuffix(v[2], "!") && v[3] == "",
+					HasDefault: v[3] != "",
+				})
+			}
+		}
+
+		operations = append(operations, op)
+	}
+
+	return operations, nil
+}
+
+// leadingComment 提取紧接在操作之前的 # 注释
+func leadingComment(before string) string {
+	lines := strings.Split(strings.TrimRight(before, " \t\r\n"), "\n")
+	var comments []string
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		comments = append([]string{strings.TrimSpace(strings.TrimPrefix(line, "#"))}, comments...)
+	}
+	return strings.Join(comments, " ")
+}
+
+// jsonSchema 将 GraphQL 变量类型转换为 JSON Schema
+func (v Variable) jsonSchema() map[string]interface{} {
+	typeName := strings.TrimSuffix(v.Type, "!")
+	if strings.HasPrefix(typeName, "[") && strings.HasSuffix(typeName, "]") {
+		item := Variable{Type: typeName[1 : len(typeName)-1]}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": item.jsonSchema(),
+		}
+	}
+
+	switch typeName {
+	case "Int":
+		return map[string]interface{}{"type": "integer"}
+	case "Float":
+		return map[string]interface{}{"type": "number"}
+	case "Boolean":
+		return map[string]interface{}{"type": "boolean"}
+	case "String", "ID":
+		return map[string]interface{}{"type": "string"}
+	default:
+		// 输入对象、枚举和自定义标量无法从文档推断，交由服务端校验
+		return map[string]interface{}{
+			"description": fmt.Sprintf("GraphQL 类型 %s", typeName),
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/auth"
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// gRPC 状态码名称
+var statusNames = []string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED",
+	"NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED",
+	"INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// Status 表示 gRPC 调用的状态
+type Status struct {
+	Code    int
+	Message string
+}
+
+// Name 返回状态码名称
+func (s *Status) Name() string {
+	if s.Code >= 0 && s.Code < len(statusNames) {
+		return statusNames[s.Code]
+	}
+	return "UNKNOWN"
+}
+
+type method struct {
+	path   string // /package.Service/Method
+	input  *messageDesc
+	output *messageDesc
+}
+
+// Backend 将 gRPC 一元方法暴露为 MCP 工具
+//
+// 标准库只在 TLS 上协商 HTTP/2，因此目标地址必须使用 https。
+// 流式方法会被跳过，描述符需要包含所有依赖的类型 (protoc --include_imports)。
+type Backend struct {
+	config     config.GRPCConfig
+	httpClient *http.Client
+	auth       *auth.AuthManager
+	registry   *registry
+	methods    map[string]*method // 键为工具名称
+	order      []string
+}
+
+// NewBackend 加载描述符并创建 gRPC 后端
+func NewBackend(cfg config.GRPCConfig, timeout time.Duration, authManager *auth.AuthManager) (*Backend, error) {
+	target, err := url.Parse(cfg.Target)
+	if err != nil {
+		return nil, fmt.Errorf("无效的gRPC目标地址: %w", err)
+	}
+	if target.Scheme != "https" {
+		return nil, fmt.Errorf("gRPC目标地址必须使用 https://，标准库不支持明文 HTTP/2: %s", cfg.Target)
+	}
+
+	registry, err := loadDescriptorSet(cfg.DescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+
+	exposed := make(map[string]bool, len(cfg.Services))
+	for _, name := range cfg.Services {
+		exposed[name] = true
+	}
+
+	backend := &Backend{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:             http.ProxyFromEnvironment,
+				ForceAttemptHTTP2: true,
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify},
+			},
+		},
+		auth:     authManager,
+		registry: registry,
+		methods:  make(map[string]*method),
+	}
+
+	for _, service := range registry.services {
+		if len(exposed) > 0 && !exposed[service.fullName] {
+			continue
+		}
+		delete(exposed, service.fullName)
+
+		shortName := service.fullName[strings.LastIndex(service.fullName, ".")+1:]
+		for _, m := range service.methods {
+			if m.clientStreaming || m.serverStreaming {
+				continue
+			}
+			input, err := registry.message(m.inputType)
+			if err != nil {
+				return nil, err
+			}
+			output, err := registry.message(m.outputType)
+			if err != nil {
+				return nil, err
+			}
+
+			name := cfg.ToolPrefix + shortName + "_" + m.name
+			backend.methods[name] = &method{
+				path:   "/" + service.fullName + "/" + m.name,
+				input:  input,
+				output: output,
+			}
+			backend.order = append(backend.order, name)
+		}
+	}
+
+	for name := range exposed {
+		return nil, fmt.Errorf("描述符中未找到gRPC服务: %s", name)
+	}
+
+	return backend, nil
+}
+
+// HasTool 检查工具是否由 gRPC 后端提供
+func (b *Backend) HasTool(name string) bool {
+	_, exists := b.methods[name]
+	return exists
+}
+
+// Tools 返回 gRPC 方法对应的工具定义
+func (b *Backend) Tools() []map[string]interface{} {
+	tools := make([]map[string]interface{}, 0, len(b.order))
+	for _, name := range b.order {
+		m := b.methods[name]
+		tools = append(tools, map[string]interface{}{
+			"name":        name,
+			"description": fmt.Sprintf("gRPC %s", m.path),
+			"inputSchema": b.registry.messageSchema(m.input, make(map[string]bool)),
+		})
+	}
+	return tools
+}
+
+// Call 调用一元方法，只有传输或编解码失败时返回错误，非 OK 状态通过 Status 返回
+func (b *Backend) Call(ctx context.Context, name string, params map[string]interface{}) (map[string]interface{}, *Status, error) {
+	m, exists := b.methods[name]
+	if !exists {
+		return nil, nil, fmt.Errorf("未找到gRPC方法: %s", name)
+	}
+
+	payload, err := b.registry.marshal(m.input, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("编码gRPC请求失败: %w", err)
+	}
+
+	// 长度前缀消息: 1 字节压缩标志 + 4 字节大端长度
+	frame := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	frame = append(frame, payload...)
+
+	req, err := http.NewRequest("POST", strings.TrimRight(b.config.Target, "/")+m.path, bytes.NewReader(frame))
+	if err != nil {
+		return nil, nil, fmt.Errorf("创建gRPC请求失败: %w", err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	req.Header.Set("TE", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set("grpc-timeout", FormatTimeout(time.Until(deadline)))
+	}
+	for key, value := range b.config.Metadata {
+		req.Header.Set(key, value)
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	if err := b.auth.ApplyAuth(req, &b.config.Auth); err != nil {
+		return nil, nil, fmt.Errorf("应用身份验证失败: %w", err)
+	}
+
+	debug.LogHTTPRequest(ctx, map[string]interface{}{
+		"method": req.Method,
+		"url":    req.URL.String(),
+		"params": params,
+	})
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("发送gRPC请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取gRPC响应失败: %w", err)
+	}
+	if resp.ProtoMajor != 2 {
+		return nil, nil, fmt.Errorf("gRPC上游未协商 HTTP/2 (%s)", resp.Proto)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("gRPC上游返回HTTP状态码 %d", resp.StatusCode)
+	}
+
+	// 状态在 trailer 中，仅有 trailer 的响应会放在头部
+	status := responseStatus(resp.Trailer)
+	if status == nil {
+		status = responseStatus(resp.Header)
+	}
+	if status == nil {
+		return nil, nil, fmt.Errorf("gRPC响应缺少 grpc-status")
+	}
+	if status.Code != 0 {
+		return nil, status, nil
+	}
+
+	if len(body) < 5 {
+		return nil, nil, fmt.Errorf("gRPC响应缺少消息")
+	}
+	if body[0] != 0 {
+		return nil, nil, fmt.Errorf("不支持压缩的gRPC响应")
+	}
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) < length {
+		return nil, nil, fmt.Errorf("gRPC响应消息被截断")
+	}
+
+	result, err := b.registry.unmarshal(m.output, body[5:5+length])
+	if err != nil {
+		return nil, nil, fmt.Errorf("解码gRPC响应失败: %w", err)
+	}
+	return result, status, nil
+}
+
+// responseStatus 读取 grpc-status 和 grpc-message
+func responseStatus(header http.Header) *Status {
+	code := header.Get("Grpc-Status")
+	if code == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		n = 2 // UNKNOWN
+	}
+	message, err := url.PathUnescape(header.Get("Grpc-Message"))
+	if err != nil {
+		message = header.Get("Grpc-Message")
+	}
+	return &Status{Code: n, Message: message}
+}
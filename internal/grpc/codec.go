@@ -0,0 +1,474 @@
+package grpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// marshal 按 proto3 JSON 映射将参数编码为 protobuf 消息
+func (r *registry) marshal(msg *messageDesc, value map[string]interface{}) ([]byte, error) {
+	var b []byte
+	for _, field := range msg.fields {
+		v, exists := value[field.jsonName]
+		if !exists {
+			v, exists = value[field.name]
+		}
+		if !exists || v == nil {
+			continue
+		}
+
+		var err error
+		b, err = r.marshalField(b, msg, field, v)
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s: %w", field.jsonName, err)
+		}
+	}
+	return b, nil
+}
+
+func (r *registry) marshalField(b []byte, msg *messageDesc, field *fieldDesc, v interface{}) ([]byte, error) {
+	if !field.repeated() {
+		return r.appendValue(b, field, v)
+	}
+
+	// map 字段是 repeated 的 map entry 消息
+	if entry := r.mapEntry(field); entry != nil {
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("需要对象，实际为 %T", v)
+		}
+		keys := make([]string, 0, len(object))
+		for k := range object {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			data, err := r.marshalMapEntry(entry, k, object[k])
+			if err != nil {
+				return nil, err
+			}
+			b = appendLengthDelimited(b, field.number, data)
+		}
+		return b, nil
+	}
+
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("需要数组，实际为 %T", v)
+	}
+
+	// proto3 默认对数值类型使用 packed 编码
+	wireType := wireTypeOf(field.typ)
+	if msg.proto3 && wireType != wireBytes {
+		var packed []byte
+		for _, item := range items {
+			bits, err := r.scalarBits(field, item)
+			if err != nil {
+				return nil, err
+			}
+			packed = appendScalar(packed, wireType, bits)
+		}
+		return appendLengthDelimited(b, field.number, packed), nil
+	}
+
+	for _, item := range items {
+		var err error
+		b, err = r.appendValue(b, field, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (r *registry) marshalMapEntry(entry *messageDesc, key string, value interface{}) ([]byte, error) {
+	keyField, valueField := entry.byNumber[1], entry.byNumber[2]
+	if keyField == nil || valueField == nil {
+		return nil, fmt.Errorf("无效的 map 类型 %s", entry.fullName)
+	}
+
+	// JSON 对象的键总是字符串，需要按键类型转换
+	var keyValue interface{} = key
+	if keyField.typ == typeBool {
+		parsed, err := strconv.ParseBool(key)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 map 键 %q", key)
+		}
+		keyValue = parsed
+	}
+
+	b, err := r.appendValue(nil, keyField, keyValue)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return b, nil
+	}
+	return r.appendValue(b, valueField, value)
+}
+
+// appendValue 编码单个值（包括标签）
+func (r *registry) appendValue(b []byte, field *fieldDesc, v interface{}) ([]byte, error) {
+	switch field.typ {
+	case typeMessage:
+		nested, err := r.message(field.typeName)
+		if err != nil {
+			return nil, err
+		}
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("需要对象，实际为 %T", v)
+		}
+		data, err := r.marshal(nested, object)
+		if err != nil {
+			return nil, err
+		}
+		return appendLengthDelimited(b, field.number, data), nil
+	case typeString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("需要字符串，实际为 %T", v)
+		}
+		return appendLengthDelimited(b, field.number, []byte(s)), nil
+	case typeBytes:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("需要 base64 字符串，实际为 %T", v)
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			if data, err = base64.URLEncoding.DecodeString(s); err != nil {
+				return nil, fmt.Errorf("无效的 base64 数据: %w", err)
+			}
+		}
+		return appendLengthDelimited(b, field.number, data), nil
+	}
+
+	bits, err := r.scalarBits(field, v)
+	if err != nil {
+		return nil, err
+	}
+	wireType := wireTypeOf(field.typ)
+	return appendScalar(appendTag(b, field.number, wireType), wireType, bits), nil
+}
+
+// scalarBits 将 JSON 值转换为数值类型字段的位模式
+func (r *registry) scalarBits(field *fieldDesc, v interface{}) (uint64, error) {
+	switch field.typ {
+	case typeDouble:
+		f, err := toFloat(v)
+		return math.Float64bits(f), err
+	case typeFloat:
+		f, err := toFloat(v)
+		return uint64(math.Float32bits(float32(f))), err
+	case typeBool:
+		switch b := v.(type) {
+		case bool:
+			if b {
+				return 1, nil
+			}
+			return 0, nil
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return 0, fmt.Errorf("需要布尔值，实际为 %q", b)
+			}
+			if parsed {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return 0, fmt.Errorf("需要布尔值，实际为 %T", v)
+	case typeEnum:
+		n, err := r.enumNumber(field.typeName, v)
+		return uint64(int64(n)), err
+	case typeInt32, typeInt64, typeSfixed64:
+		n, err := toInt(v)
+		return uint64(n), err
+	case typeSfixed32:
+		n, err := toInt(v)
+		return uint64(uint32(int32(n))), err
+	case typeSint32, typeSint64:
+		n, err := toInt(v)
+		return uint64(n<<1) ^ uint64(n>>63), err
+	case typeUint32, typeUint64, typeFixed32, typeFixed64:
+		return toUint(v)
+	}
+	return 0, fmt.Errorf("不支持的字段类型 %d", field.typ)
+}
+
+// unmarshal 将 protobuf 消息解码为 proto3 JSON 映射，只输出出现在消息中的字段
+func (r *registry) unmarshal(msg *messageDesc, data []byte) (map[string]interface{}, error) {
+	fields, err := parseWire(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{})
+	for _, wf := range fields {
+		field, exists := msg.byNumber[wf.number]
+		if !exists {
+			continue // 未知字段
+		}
+
+		if entry := r.mapEntry(field); entry != nil {
+			object, _ := result[field.jsonName].(map[string]interface{})
+			if object == nil {
+				object = make(map[string]interface{})
+				result[field.jsonName] = object
+			}
+			entryValue, err := r.unmarshal(entry, wf.data)
+			if err != nil {
+				return nil, err
+			}
+			key := fmt.Sprint(entryValue[entry.byNumber[1].jsonName])
+			if _, hasKey := entryValue[entry.byNumber[1].jsonName]; !hasKey {
+				key = zeroKey(entry.byNumber[1])
+			}
+			object[key] = entryValue[entry.byNumber[2].jsonName]
+			continue
+		}
+
+		values, err := r.decodeValues(field, wf)
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s: %w", field.jsonName, err)
+		}
+		if field.repeated() {
+			list, _ := result[field.jsonName].([]interface{})
+			result[field.jsonName] = append(list, values...)
+		} else if len(values) > 0 {
+			result[field.jsonName] = values[len(values)-1]
+		}
+	}
+	return result, nil
+}
+
+// decodeValues 解码一个线格式字段，packed 编码时可能包含多个值
+func (r *registry) decodeValues(field *fieldDesc, wf wireField) ([]interface{}, error) {
+	switch field.typ {
+	case typeMessage:
+		nested, err := r.message(field.typeName)
+		if err != nil {
+			return nil, err
+		}
+		value, err := r.unmarshal(nested, wf.data)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{value}, nil
+	case typeString:
+		return []interface{}{string(wf.data)}, nil
+	case typeBytes:
+		return []interface{}{base64.StdEncoding.EncodeToString(wf.data)}, nil
+	}
+
+	expected := wireTypeOf(field.typ)
+	if wf.wireType != wireBytes {
+		return []interface{}{r.scalarValue(field, wf.bits)}, nil
+	}
+
+	// packed 编码的数值列表
+	var values []interface{}
+	data := wf.data
+	for len(data) > 0 {
+		var bits uint64
+		var n int
+		var err error
+		switch expected {
+		case wireVarint:
+			bits, n, err = consumeVarint(data)
+		case wireFixed64:
+			bits, err = consumeFixed(data, 8)
+			n = 8
+		case wireFixed32:
+			bits, err = consumeFixed(data, 4)
+			n = 4
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, r.scalarValue(field, bits))
+		data = data[n:]
+	}
+	return values, nil
+}
+
+// scalarValue 将位模式转换为 proto3 JSON 值，64 位整数按规范输出为字符串
+func (r *registry) scalarValue(field *fieldDesc, bits uint64) interface{} {
+	switch field.typ {
+	case typeDouble:
+		return jsonFloat(math.Float64frombits(bits))
+	case typeFloat:
+		return jsonFloat(float64(math.Float32frombits(uint32(bits))))
+	case typeBool:
+		return bits != 0
+	case typeEnum:
+		if enum, exists := r.enums[field.typeName]; exists {
+			if name, exists := enum.names[int32(bits)]; exists {
+				return name
+			}
+		}
+		return int32(bits)
+	case typeInt32:
+		return int32(bits)
+	case typeSfixed32:
+		return int32(uint32(bits))
+	case typeSint32:
+		return int32(uint32(bits>>1) ^ -uint32(bits&1))
+	case typeUint32, typeFixed32:
+		return uint32(bits)
+	case typeInt64, typeSfixed64:
+		return strconv.FormatInt(int64(bits), 10)
+	case typeSint64:
+		return strconv.FormatInt(int64(bits>>1)^-int64(bits&1), 10)
+	case typeUint64, typeFixed64:
+		return strconv.FormatUint(bits, 10)
+	}
+	return bits
+}
+
+func (r *registry) message(name string) (*messageDesc, error) {
+	msg, exists := r.messages[name]
+	if !exists {
+		return nil, fmt.Errorf("描述符中缺少消息类型 %s，生成描述符时请使用 --include_imports", name)
+	}
+	return msg, nil
+}
+
+// mapEntry 返回 map 字段的 entry 消息，非 map 字段返回 nil
+func (r *registry) mapEntry(field *fieldDesc) *messageDesc {
+	if !field.repeated() || field.typ != typeMessage {
+		return nil
+	}
+	if msg, exists := r.messages[field.typeName]; exists && msg.mapEntry {
+		return msg
+	}
+	return nil
+}
+
+func (r *registry) enumNumber(name string, v interface{}) (int32, error) {
+	if s, ok := v.(string); ok {
+		if enum, exists := r.enums[name]; exists {
+			if n, exists := enum.numbers[s]; exists {
+				return n, nil
+			}
+		}
+		return 0, fmt.Errorf("枚举 %s 没有值 %q", name, s)
+	}
+	n, err := toInt(v)
+	return int32(n), err
+}
+
+func wireTypeOf(typ int32) int {
+	switch typ {
+	case typeDouble, typeFixed64, typeSfixed64:
+		return wireFixed64
+	case typeFloat, typeFixed32, typeSfixed32:
+		return wireFixed32
+	case typeString, typeBytes, typeMessage:
+		return wireBytes
+	default:
+		return wireVarint
+	}
+}
+
+func appendScalar(b []byte, wireType int, bits uint64) []byte {
+	switch wireType {
+	case wireFixed64:
+		return appendFixed(b, bits, 8)
+	case wireFixed32:
+		return appendFixed(b, bits, 4)
+	default:
+		return appendVarint(b, bits)
+	}
+}
+
+// jsonFloat 将 NaN 和无穷大按 proto3 JSON 规范输出为字符串
+func jsonFloat(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return f
+}
+
+func zeroKey(field *fieldDesc) string {
+	if field.typ == typeString {
+		return ""
+	}
+	if field.typ == typeBool {
+		return "false"
+	}
+	return "0"
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		switch n {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		return strconv.ParseFloat(n, 64)
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	}
+	return 0, fmt.Errorf("需要数字，实际为 %T", v)
+}
+
+func toInt(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("需要整数，实际为 %v", n)
+		}
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	}
+	return 0, fmt.Errorf("需要整数，实际为 %T", v)
+}
+
+func toUint(v interface{}) (uint64, error) {
+	switch n := v.(type) {
+	case float64:
+		if n < 0 || n != math.Trunc(n) {
+			return 0, fmt.Errorf("需要非负整数，实际为 %v", n)
+		}
+		return uint64(n), nil
+	case json.Number:
+		return strconv.ParseUint(n.String(), 10, 64)
+	case string:
+		return strconv.ParseUint(n, 10, 64)
+	case int:
+		if n < 0 {
+			return 0, fmt.Errorf("需要非负整数，实际为 %d", n)
+		}
+		return uint64(n), nil
+	}
+	return 0, fmt.Errorf("需要非负整数，实际为 %T", v)
+}
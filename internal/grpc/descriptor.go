@@ -0,0 +1,329 @@
+package grpc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mcp2rest/internal/paths"
+)
+
+// FieldDescriptorProto 中的字段类型
+const (
+	typeDouble   = 1
+	typeFloat    = 2
+	typeInt64    = 3
+	typeUint64   = 4
+	typeInt32    = 5
+	typeFixed64  = 6
+	typeFixed32  = 7
+	typeBool     = 8
+	typeString   = 9
+	typeGroup    = 10
+	typeMessage  = 11
+	typeBytes    = 12
+	typeUint32   = 13
+	typeEnum     = 14
+	typeSfixed32 = 15
+	typeSfixed64 = 16
+	typeSint32   = 17
+	typeSint64   = 18
+
+	labelRepeated = 3
+)
+
+type fieldDesc struct {
+	name     string
+	jsonName string
+	number   int32
+	label    int32
+	typ      int32
+	typeName string // 消息或枚举的全名，不含前导点
+}
+
+func (f *fieldDesc) repeated() bool {
+	return f.label == labelRepeated
+}
+
+type messageDesc struct {
+	fullName string
+	fields   []*fieldDesc
+	byNumber map[int32]*fieldDesc
+	mapEntry bool
+	proto3   bool
+}
+
+type enumDesc struct {
+	fullName string
+	numbers  map[string]int32
+	names    map[int32]string
+}
+
+type methodDesc struct {
+	name            string
+	inputType       string
+	outputType      string
+	clientStreaming bool
+	serverStreaming bool
+}
+
+type serviceDesc struct {
+	fullName string
+	methods  []methodDesc
+}
+
+// registry 保存从 FileDescriptorSet 中解析出的类型
+type registry struct {
+	messages map[string]*messageDesc
+	enums    map[string]*enumDesc
+	services []*serviceDesc
+}
+
+// loadDescriptorSet 读取 protoc --descriptor_set_out 生成的文件
+func loadDescriptorSet(path string) (*registry, error) {
+	data, err := ioutil.ReadFile(paths.Resolve(path))
+	if err != nil {
+		return nil, fmt.Errorf("读取描述符文件 %s 失败: %w", path, err)
+	}
+
+	r := &registry{
+		messages: make(map[string]*messageDesc),
+		enums:    make(map[string]*enumDesc),
+	}
+
+	fields, err := parseWire(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析描述符文件 %s 失败: %w", path, err)
+	}
+	for _, f := range fields {
+		if f.number == 1 && f.wireType == wireBytes {
+			if err := r.addFile(f.data); err != nil {
+				return nil, fmt.Errorf("解析描述符文件 %s 失败: %w", path, err)
+			}
+		}
+	}
+	return r, nil
+}
+
+// addFile 解析 FileDescriptorProto
+func (r *registry) addFile(data []byte) error {
+	fields, err := parseWire(data)
+	if err != nil {
+		return err
+	}
+
+	var pkg string
+	proto3 := false
+	for _, f := range fields {
+		switch f.number {
+		case 2:
+			pkg = string(f.data)
+		case 12:
+			proto3 = string(f.data) == "proto3"
+		}
+	}
+
+	for _, f := range fields {
+		switch f.number {
+		case 4:
+			err = r.addMessage(pkg, f.data, proto3)
+		case 5:
+			err = r.addEnum(pkg, f.data)
+		case 6:
+			err = r.addService(pkg, f.data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMessage 解析 DescriptorProto 及其嵌套类型
+func (r *registry) addMessage(scope string, data []byte, proto3 bool) error {
+	fields, err := parseWire(data)
+	if err != nil {
+		return err
+	}
+
+	msg := &messageDesc{byNumber: make(map[int32]*fieldDesc), proto3: proto3}
+	for _, f := range fields {
+		if f.number == 1 {
+			msg.fullName = qualify(scope, string(f.data))
+		}
+	}
+
+	for _, f := range fields {
+		switch f.number {
+		case 2:
+			field, err := parseField(f.data)
+			if err != nil {
+				return err
+			}
+			msg.fields = append(msg.fields, field)
+			msg.byNumber[field.number] = field
+		case 3:
+			err = r.addMessage(msg.fullName, f.data, proto3)
+		case 4:
+			err = r.addEnum(msg.fullName, f.data)
+		case 7:
+			msg.mapEntry = hasBoolOption(f.data, 7)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	r.messages[msg.fullName] = msg
+	return nil
+}
+
+// parseField 解析 FieldDescriptorProto
+func parseField(data []byte) (*fieldDesc, error) {
+	fields, err := parseWire(data)
+	if err != nil {
+		return nil, err
+	}
+
+	field := &fieldDesc{}
+	for _, f := range fields {
+		switch f.number {
+		case 1:
+			field.name = string(f.data)
+		case 3:
+			field.number = int32(f.bits)
+		case 4:
+			field.label = int32(f.bits)
+		case 5:
+			field.typ = int32(f.bits)
+		case 6:
+			field.typeName = strings.TrimPrefix(string(f.data), ".")
+		case 10:
+			field.jsonName = string(f.data)
+		}
+	}
+	if field.jsonName == "" {
+		field.jsonName = lowerCamel(field.name)
+	}
+	if field.typ == typeGroup {
+		return nil, fmt.Errorf("字段 %s 使用了不支持的 group 类型", field.name)
+	}
+	return field, nil
+}
+
+// addEnum 解析 EnumDescriptorProto
+func (r *registry) addEnum(scope string, data []byte) error {
+	fields, err := parseWire(data)
+	if err != nil {
+		return err
+	}
+
+	enum := &enumDesc{numbers: make(map[string]int32), names: make(map[int32]string)}
+	for _, f := range fields {
+		switch f.number {
+		case 1:
+			enum.fullName = qualify(scope, string(f.data))
+		case 2:
+			valueFields, err := parseWire(f.data)
+			if err != nil {
+				return err
+			}
+			var name string
+			var number int32
+			for _, vf := range valueFields {
+				switch vf.number {
+				case 1:
+					name = string(vf.data)
+				case 2:
+					number = int32(vf.bits)
+				}
+			}
+			enum.numbers[name] = number
+			if _, exists := enum.names[number]; !exists {
+				enum.names[number] = name
+			}
+		}
+	}
+
+	r.enums[enum.fullName] = enum
+	return nil
+}
+
+// addService 解析 ServiceDescriptorProto
+func (r *registry) addService(pkg string, data []byte) error {
+	fields, err := parseWire(data)
+	if err != nil {
+		return err
+	}
+
+	service := &serviceDesc{}
+	for _, f := range fields {
+		switch f.number {
+		case 1:
+			service.fullName = qualify(pkg, string(f.data))
+		case 2:
+			methodFields, err := parseWire(f.data)
+			if err != nil {
+				return err
+			}
+			var method methodDesc
+			for _, mf := range methodFields {
+				switch mf.number {
+				case 1:
+					method.name = string(mf.data)
+				case 2:
+					method.inputType = strings.TrimPrefix(string(mf.data), ".")
+				case 3:
+					method.outputType = strings.TrimPrefix(string(mf.data), ".")
+				case 5:
+					method.clientStreaming = mf.bits != 0
+				case 6:
+					method.serverStreaming = mf.bits != 0
+				}
+			}
+			service.methods = append(service.methods, method)
+		}
+	}
+
+	r.services = append(r.services, service)
+	return nil
+}
+
+// hasBoolOption 检查选项消息中的布尔字段
+func hasBoolOption(data []byte, number int32) bool {
+	fields, err := parseWire(data)
+	if err != nil {
+		return false
+	}
+	for _, f := range fields {
+		if f.number == number && f.wireType == wireVarint {
+			return f.bits != 0
+		}
+	}
+	return false
+}
+
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// lowerCamel 按 protoc 的规则生成 JSON 字段名
+func lowerCamel(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, c := range name {
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
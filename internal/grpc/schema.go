@@ -0,0 +1,73 @@
+package grpc
+
+import "sort"
+
+// messageSchema 根据消息描述符生成 JSON Schema，递归引用的消息只生成 object
+func (r *registry) messageSchema(msg *messageDesc, visiting map[string]bool) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object"}
+	if visiting[msg.fullName] {
+		return schema
+	}
+	visiting[msg.fullName] = true
+	defer delete(visiting, msg.fullName)
+
+	properties := make(map[string]interface{}, len(msg.fields))
+	for _, field := range msg.fields {
+		properties[field.jsonName] = r.fieldSchema(field, visiting)
+	}
+	schema["properties"] = properties
+	return schema
+}
+
+func (r *registry) fieldSchema(field *fieldDesc, visiting map[string]bool) map[string]interface{} {
+	if entry := r.mapEntry(field); entry != nil {
+		schema := map[string]interface{}{"type": "object"}
+		if valueField := entry.byNumber[2]; valueField != nil {
+			schema["additionalProperties"] = r.singleSchema(valueField, visiting)
+		}
+		return schema
+	}
+
+	if field.repeated() {
+		return map[string]interface{}{
+			"type":  "array",
+			"items": r.singleSchema(field, visiting),
+		}
+	}
+	return r.singleSchema(field, visiting)
+}
+
+func (r *registry) singleSchema(field *fieldDesc, visiting map[string]bool) map[string]interface{} {
+	switch field.typ {
+	case typeMessage:
+		if msg, exists := r.messages[field.typeName]; exists {
+			return r.messageSchema(msg, visiting)
+		}
+		return map[string]interface{}{"type": "object"}
+	case typeEnum:
+		schema := map[string]interface{}{"type": "string"}
+		if enum, exists := r.enums[field.typeName]; exists {
+			values := make([]string, 0, len(enum.numbers))
+			for name := range enum.numbers {
+				values = append(values, name)
+			}
+			sort.Slice(values, func(i, j int) bool {
+				return enum.numbers[values[i]] < enum.numbers[values[j]]
+			})
+			schema["enum"] = values
+		}
+		return schema
+	case typeString:
+		return map[string]interface{}{"type": "string"}
+	case typeBytes:
+		return map[string]interface{}{"type": "string", "description": "base64 编码"}
+	case typeBool:
+		return map[string]interface{}{"type": "boolean"}
+	case typeDouble, typeFloat:
+		return map[string]interface{}{"type": "number"}
+	case typeInt64, typeUint64, typeSint64, typeFixed64, typeSfixed64:
+		return map[string]interface{}{"type": "integer", "description": "64 位整数，也可以传字符串"}
+	default:
+		return map[string]interface{}{"type": "integer"}
+	}
+}
@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"strconv"
+	"time"
+)
+
+// FormatTimeout 按 grpc-timeout 头的格式编码时长，使用不超过 8 位数字的最精细单位
+func FormatTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "1n"
+	}
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"n", time.Nanosecond}, {"u", time.Microsecond}, {"m", time.Millisecond},
+		{"S", time.Second}, {"M", time.Minute}, {"H", time.Hour},
+	}
+	for _, unit := range units {
+		value := (d + unit.size - 1) / unit.size
+		if value < 1e8 {
+			return strconv.FormatInt(int64(value), 10) + unit.suffix
+		}
+	}
+	return "99999999H"
+}
@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// protobuf 线格式类型
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("protobuf 数据被截断")
+
+// wireField 表示线格式中的一个字段，定长和变长整数都存放在 bits 中
+type wireField struct {
+	number   int32
+	wireType int
+	bits     uint64
+	data     []byte
+}
+
+// consumeVarint 读取一个变长整数，返回值和消耗的字节数
+func consumeVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * uint(i))
+		if b[i] < 0x80 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, errTruncated
+}
+
+// consumeFixed 读取小端序的定长整数
+func consumeFixed(b []byte, size int) (uint64, error) {
+	if len(b) < size {
+		return 0, errTruncated
+	}
+	var v uint64
+	for i := size - 1; i >= 0; i-- {
+		v = v<<8 | uint64(b[i])
+	}
+	return v, nil
+}
+
+// parseWire 将消息拆分为字段序列
+func parseWire(b []byte) ([]wireField, error) {
+	var fields []wireField
+	for len(b) > 0 {
+		key, n, err := consumeVarint(b)
+		if err != nil {
+			return nil, err
+		}
+		b = b[n:]
+
+		f := wireField{number: int32(key >> 3), wireType: int(key & 7)}
+		switch f.wireType {
+		case wireVarint:
+			f.bits, n, err = consumeVarint(b)
+		case wireFixed64:
+			f.bits, err = consumeFixed(b, 8)
+			n = 8
+		case wireFixed32:
+			f.bits, err = consumeFixed(b, 4)
+			n = 4
+		case wireBytes:
+			var length uint64
+			length, n, err = consumeVarint(b)
+			if err == nil {
+				if uint64(len(b)-n) < length {
+					err = errTruncated
+				} else {
+					f.data = b[n : n+int(length)]
+					n += int(length)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("不支持的 protobuf 线格式类型 %d (字段 %d)", f.wireType, f.number)
+		}
+		if err != nil {
+			return nil, err
+		}
+		b = b[n:]
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendFixed(b []byte, v uint64, size int) []byte {
+	for i := 0; i < size; i++ {
+		b = append(b, byte(v>>(8*uint(i))))
+	}
+	return b
+}
+
+func appendTag(b []byte, number int32, wireType int) []byte {
+	return appendVarint(b, uint64(number)<<3|uint64(wireType))
+}
+
+func appendLengthDelimited(b []byte, number int32, data []byte) []byte {
+	b = appendTag(b, number, wireBytes)
+	b = appendVarint(b, uint64(len(data)))
+	return append(b, data...)
+}
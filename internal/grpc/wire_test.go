@@ -0,0 +1,220 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		value uint64
+		want  string
+	}{
+		{0, "00"},
+		{1, "01"},
+		{127, "7f"},
+		{128, "8001"},
+		{150, "9601"},
+		{300, "ac02"},
+		{16384, "808001"},
+		{math.MaxUint32, "ffffffff0f"},
+		{math.MaxUint64, "ffffffffffffffffff01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got := appendVarint(nil, tt.value)
+			if hex.EncodeToString(got) != tt.want {
+				t.Fatalf("appendVarint(%d) = %x, want %s", tt.value, got, tt.want)
+			}
+			v, n, err := consumeVarint(append(got, 0xaa))
+			if err != nil || v != tt.value || n != len(got) {
+				t.Fatalf("consumeVarint(%x) = %d, %d, %v", got, v, n, err)
+			}
+		})
+	}
+}
+
+func TestConsumeVarintTruncated(t *testing.T) {
+	for _, input := range []string{"", "80", "ffff", "ffffffffffffffffffff01"} {
+		b, _ := hex.DecodeString(input)
+		if _, _, err := consumeVarint(b); err != errTruncated {
+			t.Errorf("consumeVarint(%s) error = %v, want errTruncated", input, err)
+		}
+	}
+}
+
+func TestAppendTag(t *testing.T) {
+	tests := []struct {
+		number   int32
+		wireType int
+		want     string
+	}{
+		{1, wireVarint, "08"},
+		{2, wireBytes, "12"},
+		{3, wireFixed64, "19"},
+		{4, wireFixed32, "25"},
+		{15, wireBytes, "7a"},
+		{16, wireVarint, "8001"},
+		{536870911, wireVarint, "f8ffffff0f"},
+	}
+	for _, tt := range tests {
+		if got := appendTag(nil, tt.number, tt.wireType); hex.EncodeToString(got) != tt.want {
+			t.Errorf("appendTag(%d, %d) = %x, want %s", tt.number, tt.wireType, got, tt.want)
+		}
+	}
+}
+
+func TestParseWire(t *testing.T) {
+	// protobuf 编码文档中的示例: a = 150、b = "testing"，加上两个定长字段
+	var b []byte
+	b = appendTag(b, 1, wireVarint)
+	b = appendVarint(b, 150)
+	b = appendLengthDelimited(b, 2, []byte("testing"))
+	b = appendTag(b, 3, wireFixed64)
+	b = appendFixed(b, 0x0102030405060708, 8)
+	b = appendTag(b, 4, wireFixed32)
+	b = appendFixed(b, 0xdeadbeef, 4)
+	if want := "089601120774657374696e67190807060504030201" + "25efbeadde"; hex.EncodeToString(b) != want {
+		t.Fatalf("encoded = %x, want %s", b, want)
+	}
+
+	fields, err := parseWire(b)
+	if err != nil {
+		t.Fatalf("parseWire() error = %v", err)
+	}
+	want := []wireField{
+		{number: 1, wireType: wireVarint, bits: 150},
+		{number: 2, wireType: wireBytes, data: []byte("testing")},
+		{number: 3, wireType: wireFixed64, bits: 0x0102030405060708},
+		{number: 4, wireType: wireFixed32, bits: 0xdeadbeef},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("parseWire() = %+v, want %+v", fields, want)
+	}
+}
+
+func TestParseWireErrors(t *testing.T) {
+	tests := map[string]string{
+		"truncated length":  "1207746573",
+		"truncated fixed64": "190102",
+		"truncated fixed32": "2501",
+		"truncated key":     "80",
+		"group wire type":   "0b",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, _ := hex.DecodeString(input)
+			if _, err := parseWire(b); err == nil {
+				t.Fatalf("parseWire(%s) error = nil", input)
+			}
+		})
+	}
+}
+
+// testRegistry 构造一个 proto3 消息:
+//
+//	message Test { int32 a = 1; string b = 2; sint32 c = 3; repeated int32 d = 4; int64 e = 5; Kind kind = 6; map<string, int32> m = 7; }
+func testRegistry() (*registry, *messageDesc) {
+	entry := &messageDesc{fullName: "test.Test.MEntry", mapEntry: true, proto3: true}
+	entry.fields = []*fieldDesc{
+		{name: "key", jsonName: "key", number: 1, typ: typeString},
+		{name: "value", jsonName: "value", number: 2, typ: typeInt32},
+	}
+	msg := &messageDesc{fullName: "test.Test", proto3: true}
+	msg.fields = []*fieldDesc{
+		{name: "a", jsonName: "a", number: 1, typ: typeInt32},
+		{name: "b", jsonName: "b", number: 2, typ: typeString},
+		{name: "c", jsonName: "c", number: 3, typ: typeSint32},
+		{name: "d", jsonName: "d", number: 4, typ: typeInt32, label: labelRepeated},
+		{name: "e", jsonName: "e", number: 5, typ: typeInt64},
+		{name: "kind", jsonName: "kind", number: 6, typ: typeEnum, typeName: "test.Kind"},
+		{name: "m", jsonName: "m", number: 7, typ: typeMessage, typeName: "test.Test.MEntry", label: labelRepeated},
+	}
+	for _, m := range []*messageDesc{entry, msg} {
+		m.byNumber = make(map[int32]*fieldDesc)
+		for _, f := range m.fields {
+			m.byNumber[f.number] = f
+		}
+	}
+	r := &registry{
+		messages: map[string]*messageDesc{entry.fullName: entry, msg.fullName: msg},
+		enums: map[string]*enumDesc{"test.Kind": {
+			fullName: "test.Kind",
+			numbers:  map[string]int32{"KIND_UNSPECIFIED": 0, "KIND_BLUE": 2},
+			names:    map[int32]string{0: "KIND_UNSPECIFIED", 2: "KIND_BLUE"},
+		}},
+	}
+	return r, msg
+}
+
+func TestMarshalKnownEncoding(t *testing.T) {
+	r, msg := testRegistry()
+	tests := []struct {
+		name  string
+		value map[string]interface{}
+		want  string
+	}{
+		{"varint", map[string]interface{}{"a": float64(150)}, "089601"},
+		{"string", map[string]interface{}{"b": "testing"}, "120774657374696e67"},
+		{"zigzag", map[string]interface{}{"c": float64(-1)}, "1801"},
+		{"zigzag negative two", map[string]interface{}{"c": float64(-2)}, "1803"},
+		// 文档中的 packed 示例 [3, 270, 86942]
+		{"packed", map[string]interface{}{"d": []interface{}{float64(3), float64(270), float64(86942)}}, "2206038e029ea705"},
+		{"int64 string", map[string]interface{}{"e": "-2"}, "28feffffffffffffffff01"},
+		{"enum name", map[string]interface{}{"kind": "KIND_BLUE"}, "3002"},
+		{"map", map[string]interface{}{"m": map[string]interface{}{"x": float64(1)}}, "3a050a0178" + "1001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.marshal(msg, tt.value)
+			if err != nil {
+				t.Fatalf("marshal() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Fatalf("marshal() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	r, msg := testRegistry()
+	value := map[string]interface{}{
+		"a":    float64(-7),
+		"b":    "héllo",
+		"c":    float64(-300),
+		"d":    []interface{}{float64(1), float64(2)},
+		"e":    "9007199254740993",
+		"kind": "KIND_BLUE",
+		"m":    map[string]interface{}{"one": float64(1), "two": float64(2)},
+	}
+	data, err := r.marshal(msg, value)
+	if err != nil {
+		t.Fatalf("marshal() error = %v", err)
+	}
+	got, err := r.unmarshal(msg, data)
+	if err != nil {
+		t.Fatalf("unmarshal() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"a":    int32(-7),
+		"b":    "héllo",
+		"c":    int32(-300),
+		"d":    []interface{}{int32(1), int32(2)},
+		"e":    "9007199254740993",
+		"kind": "KIND_BLUE",
+		"m":    map[string]interface{}{"one": int32(1), "two": int32(2)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unmarshal() = %#v, want %#v", got, want)
+	}
+
+	// 未知字段被忽略
+	unknown := appendLengthDelimited(bytes.Clone(data), 99, []byte("x"))
+	if got, err := r.unmarshal(msg, unknown); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("unmarshal() with unknown field = %#v, %v", got, err)
+	}
+}
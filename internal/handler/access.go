@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// ToolFilter 决定发起调用的客户端能否使用工具，readOnly 表示工具只读取数据
+type ToolFilter func(name string, readOnly bool) bool
+
+type toolFilterContextKey struct{}
+
+// WithToolFilter 将客户端的工具过滤器附加到上下文，未附加时可以使用全部工具
+func WithToolFilter(ctx context.Context, filter ToolFilter) context.Context {
+	return context.WithValue(ctx, toolFilterContextKey{}, filter)
+}
+
+// toolAllowed 按上下文中的过滤器检查工具能否使用
+func toolAllowed(ctx context.Context, name string, readOnly bool) bool {
+	filter, _ := ctx.Value(toolFilterContextKey{}).(ToolFilter)
+	return filter == nil || filter(name, readOnly)
+}
+
+// readOnlyMethod 检查 HTTP 方法是否只读取数据，与工具注解中的 readOnlyHint 一致
+func readOnlyMethod(method string) bool {
+	method = strings.ToUpper(method)
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
+
+// toolReadOnly 检查工具是否只读取数据
+// 操作目录工具只读取规范；分组工具和 callOperation 视为只读，所选操作在转换后另行检查；
+// 组合工具、GraphQL、gRPC 和确认工具无法确定，视为会修改数据
+func (h *RequestHandler) toolReadOnly(name string) bool {
+	if name == SearchToolName || name == DescribeToolName || name == CallToolName {
+		return true
+	}
+	if _, exists := h.toolGroups[name]; exists {
+		return true
+	}
+	if _, exists := h.workflows[name]; exists || isBuiltinTool(name) {
+		return false
+	}
+	_, method, _, err := openapi.GetOperationByID(h.openAPISpec, name)
+	return err == nil && readOnlyMethod(method)
+}
+
+// ToolAllowed 检查上下文中的客户端能否使用工具
+func (h *RequestHandler) ToolAllowed(ctx context.Context, name string) bool {
+	return toolAllowed(ctx, name, h.toolReadOnly(name))
+}
+
+// AllowedTools 返回上下文中的客户端可以使用的工具
+func (h *RequestHandler) AllowedTools(ctx context.Context) []map[string]interface{} {
+	tools := h.GetAvailableTools()
+	if ctx.Value(toolFilterContextKey{}) == nil {
+		return tools
+	}
+	allowed := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		if name, _ := tool["name"].(string); h.ToolAllowed(ctx, name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
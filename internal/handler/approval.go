@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// ConfirmToolName 确认待执行操作的内置工具名称
+const ConfirmToolName = "confirmOperation"
+
+// Caller 发起工具调用的调用方，由服务器层注入上下文
+type Caller struct {
+	// Session 会话ID，标准输入/输出和进程内调用为空
+	Session string
+	// Client 入站访问控制中的客户端名称，未启用访问控制时为空
+	Client string
+}
+
+type callerContextKey struct{}
+
+// WithCaller 将调用方附加到上下文，确认令牌只能由登记它的同一调用方使用
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// CallerFromContext 从上下文获取调用方，未附加时为零值
+func CallerFromContext(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerContextKey{}).(Caller)
+	return caller
+}
+
+// pendingApproval 表示等待确认的工具调用
+type pendingApproval struct {
+	params      *mcp.ToolCallParams
+	description string
+	expiresAt   time.Time
+	// owner 触发确认的调用方，只有同一会话和客户端可以确认
+	owner Caller
+}
+
+// approvalGate 管理需要人工确认的破坏性操作
+type approvalGate struct {
+	config  config.ApprovalConfig
+	mutex   sync.Mutex
+	pending map[string]*pendingApproval
+}
+
+// newApprovalGate 创建新的确认门控
+func newApprovalGate(cfg config.ApprovalConfig) *approvalGate {
+	if len(cfg.Methods) == 0 && len(cfg.Tools) == 0 {
+		cfg.Methods = []string{"DELETE", "PUT", "POST"}
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+	return &approvalGate{
+		config:  cfg,
+		pending: make(map[string]*pendingApproval),
+	}
+}
+
+// requiresApproval 检查工具调用是否需要确认
+func (g *approvalGate) requiresApproval(toolName, method string) bool {
+	if !g.config.Enabled {
+		return false
+	}
+	for _, name := range g.config.Tools {
+		if name == toolName {
+			return true
+		}
+	}
+	for _, m := range g.config.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// request 登记待确认的调用并返回确认令牌，令牌绑定到调用方 owner
+func (g *approvalGate) request(params *mcp.ToolCallParams, description string, owner Caller) (string, time.Time) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.purgeExpired()
+
+	token := uuid.New().String()
+	expiresAt := time.Now().Add(g.config.TTL)
+	g.pending[token] = &pendingApproval{
+		params:      params,
+		description: description,
+		expiresAt:   expiresAt,
+		owner:       owner,
+	}
+	return token, expiresAt
+}
+
+// confirm 取出令牌对应的待确认调用，令牌只能使用一次
+// 令牌属于其他调用方时与无效令牌一样返回错误，且令牌仍可由其所属的调用方使用
+func (g *approvalGate) confirm(token string, caller Caller) (*pendingApproval, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.purgeExpired()
+
+	approval, exists := g.pending[token]
+	if !exists || approval.owner != caller {
+		return nil, fmt.Errorf("确认令牌无效或已过期: %s", token)
+	}
+	delete(g.pending, token)
+	return approval, nil
+}
+
+// purgeExpired 清理过期的待确认调用，调用方需持有锁
+func (g *approvalGate) purgeExpired() {
+	now := time.Now()
+	for token, approval := range g.pending {
+		if now.After(approval.expiresAt) {
+			delete(g.pending, token)
+		}
+	}
+}
+
+// describeRequest 生成待执行操作的描述
+func describeRequest(method, url string, params map[string]interface{}) string {
+	description := fmt.Sprintf("将执行 %s %s", method, url)
+	if len(params) > 0 {
+		if paramBytes, err := json.Marshal(params); err == nil {
+			description += fmt.Sprintf("，参数: %s", string(paramBytes))
+		}
+	}
+	return description
+}
+
+// confirmToolDefinition 返回确认工具的定义
+func confirmToolDefinition() map[string]interface{} {
+	return map[string]interface{}{
+		"name":        ConfirmToolName,
+		"description": "确认并执行一个等待人工确认的破坏性操作。令牌由需要确认的工具调用返回。",
+		"inputSchema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"token": map[string]interface{}{
+					"type":        "string",
+					"description": "待确认操作返回的确认令牌",
+				},
+			},
+			"required": []string{"token"},
+		},
+	}
+}
+
+// requestApproval 请求确认需要确认的调用
+// 客户端支持 elicitation 时直接向用户确认，否则返回确认令牌和操作描述
+func (h *RequestHandler) requestApproval(ctx context.Context, params *mcp.ToolCallParams, operation *config.Operation, method, path string) (*mcp.ToolCallResult, error) {
+	// 预先构建请求，以便校验参数并描述将要执行的操作
+	req, err := h.buildHTTPRequest(ctx, operation, method, path, params.Parameters)
+	if err != nil {
+		debug.LogError(ctx, "构建HTTP请求失败", err)
+		return nil, i18n.Errorf(ctx, "handler.build_request_failed", err)
+	}
+
+	description := describeRequest(req.Method, req.URL.String(), params.Parameters)
+	return h.approve(ctx, params, description, func() (*mcp.ToolCallResult, error) {
+		return h.executeWithQuota(ctx, params.Name, operation, method, path, params.Parameters)
+	})
+}
+
+// approve 通过 elicitation 确认后执行 execute，无法通过 elicitation 确认时登记调用并返回确认令牌
+func (h *RequestHandler) approve(ctx context.Context, params *mcp.ToolCallParams, description string, execute func() (*mcp.ToolCallResult, error)) (*mcp.ToolCallResult, error) {
+	if confirmed, answered := h.elicitApproval(ctx, description); answered {
+		if !confirmed {
+			logging.FromContext(ctx).Printf("用户拒绝执行工具 %s", params.Name)
+			return &mcp.ToolCallResult{
+				Type:   "error",
+				Status: "rejected",
+				Result: map[string]interface{}{
+					"message":     "用户拒绝执行该操作",
+					"description": description,
+				},
+			}, nil
+		}
+		return execute()
+	}
+
+	token, expiresAt := h.approval.request(params, description, CallerFromContext(ctx))
+	logging.FromContext(ctx).Printf("工具 %s 需要确认，已生成确认令牌: %s", params.Name, token)
+
+	return &mcp.ToolCallResult{
+		Type:   "success",
+		Status: "pending_approval",
+		Result: map[string]interface{}{
+			"status":       "pending_approval",
+			"token":        token,
+			"description":  description,
+			"expires_at":   expiresAt.Format(time.RFC3339),
+			"confirm_tool": ConfirmToolName,
+			"message":      fmt.Sprintf("该操作需要确认，请在用户同意后调用 %s 并传入 token", ConfirmToolName),
+		},
+	}, nil
+}
+
+// elicitApproval 通过 elicitation 向用户确认操作，answered 为 false 表示无法通过 elicitation 确认
+func (h *RequestHandler) elicitApproval(ctx context.Context, description string) (confirmed bool, answered bool) {
+	if !h.config.Global.Elicitation.Enabled {
+		return false, false
+	}
+
+	client := clientWith(ctx, mcp.CapabilityElicitation)
+	if client == nil {
+		return false, false
+	}
+
+	elicitCtx, cancel := context.WithTimeout(ctx, h.config.Global.Elicitation.Timeout)
+	defer cancel()
+
+	raw, err := client.Request(elicitCtx, "elicitation/create", map[string]interface{}{
+		"message": description + "，是否确认执行？",
+		"requestedSchema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"confirm": map[string]interface{}{
+					"type":        "boolean",
+					"description": "确认执行该操作",
+				},
+			},
+			"required": []string{"confirm"},
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Printf("通过elicitation确认操作失败，改用确认令牌: %v", err)
+		return false, false
+	}
+
+	var result elicitationResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		logging.FromContext(ctx).Printf("解析elicitation响应失败，改用确认令牌: %v", err)
+		return false, false
+	}
+
+	confirm, _ := result.Content["confirm"].(bool)
+	return result.Action == "accept" && confirm, true
+}
+
+// handleConfirm 执行已确认的调用
+func (h *RequestHandler) handleConfirm(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+	token, _ := params.Parameters["token"].(string)
+	if token == "" {
+		return nil, fmt.Errorf("缺少确认令牌参数: token")
+	}
+
+	approval, err := h.approval.confirm(token, CallerFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// 组合工具整体确认后执行全部步骤
+	if wf, exists := h.workflows[approval.params.Name]; exists {
+		logging.FromContext(ctx).Printf("确认令牌 %s 已确认: %s", token, approval.description)
+		return h.runWorkflow(ctx, wf, approval.params.Parameters)
+	}
+
+	operation, method, path, err := openapi.GetOperationByID(h.openAPISpec, approval.params.Name)
+	if err != nil {
+		debug.LogError(ctx, "查找操作失败", err)
+		return nil, fmt.Errorf("查找操作失败: %w", err)
+	}
+
+	logging.FromContext(ctx).Printf("确认令牌 %s 已确认: %s", token, approval.description)
+	return h.executeWithQuota(ctx, approval.params.Name, operation, method, path, approval.params.Parameters)
+}
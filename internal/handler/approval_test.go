@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+func TestApprovalTokenBoundToCaller(t *testing.T) {
+	gate := newApprovalGate(config.ApprovalConfig{Enabled: true, TTL: time.Minute})
+	owner := Caller{Session: "session-a", Client: "agent"}
+	token, _ := gate.request(&mcp.ToolCallParams{Name: "deleteUser"}, "将执行 DELETE /users/1", owner)
+
+	for _, other := range []Caller{
+		{Session: "session-b", Client: "agent"},
+		{Session: "session-a", Client: "other"},
+		{},
+	} {
+		if _, err := gate.confirm(token, other); err == nil {
+			t.Errorf("confirm() by %+v succeeded, want error", other)
+		}
+	}
+
+	approval, err := gate.confirm(token, owner)
+	if err != nil {
+		t.Fatalf("confirm() by owner error = %v", err)
+	}
+	if approval.params.Name != "deleteUser" {
+		t.Errorf("confirmed tool = %s, want deleteUser", approval.params.Name)
+	}
+	if _, err := gate.confirm(token, owner); err == nil {
+		t.Errorf("token confirmed twice")
+	}
+}
+
+func TestApprovalTokenExpires(t *testing.T) {
+	gate := newApprovalGate(config.ApprovalConfig{Enabled: true, TTL: time.Millisecond})
+	token, _ := gate.request(&mcp.ToolCallParams{Name: "deleteUser"}, "", Caller{})
+	time.Sleep(5 * time.Millisecond)
+	if _, err := gate.confirm(token, Caller{}); err == nil {
+		t.Errorf("expired token confirmed")
+	}
+}
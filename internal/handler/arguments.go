@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// ParamViolation 一个不符合规范的参数，客户端据此修正参数后重试
+type ParamViolation struct {
+	Param string `json:"param"`
+	// In 参数位置: path、query、header、cookie 或 body，组合工具和内置工具的参数为空
+	In string `json:"in,omitempty"`
+	// Path 不匹配之处在参数值中的位置 (如 $.items[0].id)，参数本身不匹配时为空
+	Path string `json:"path,omitempty"`
+	// Constraint 违反的约束: required、type、enum、range、length、format 或 path
+	Constraint string `json:"constraint"`
+	Message    string `json:"message"`
+	// Schema 规范中该参数模式的摘录，嵌套的属性和数组元素只给出类型
+	Schema map[string]interface{} `json:"schema,omitempty"`
+}
+
+// ValidationError 工具参数不符合规范，Violations 列出每个有问题的参数
+// MCP 服务器把它作为 -32602 错误返回，error.data.violations 中包含 Violations
+type ValidationError struct {
+	Violations []ParamViolation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// invalidParam 返回只有一个参数违反约束的 *ValidationError，消息使用上下文中的语言
+func invalidParam(ctx context.Context, param config.Parameter, constraint, code string, args ...interface{}) error {
+	return &ValidationError{Violations: []ParamViolation{{
+		Param:      param.Name,
+		In:         param.In,
+		Constraint: constraint,
+		Message:    i18n.Tc(ctx, code, args...),
+		Schema:     schemaExcerpt(nil, param.Schema, 0),
+	}}}
+}
+
+// validateArguments 在构建请求之前按规范检查已提供的参数，所有问题一次返回；缺少的必需参数在构建请求时检查
+// 路径、查询、请求头和 Cookie 参数最终都以文本发送，可以解析为对应类型的字符串和任意标量都视为匹配；
+// 请求体参数按 JSON 类型严格检查，对象和数组还检查嵌套的字段
+func (h *RequestHandler) validateArguments(ctx context.Context, operation *config.Operation, params map[string]interface{}) error {
+	var violations []ParamViolation
+	check := func(name, in string, schema config.Schema) {
+		value, exists := params[name]
+		if !exists || value == nil {
+			return
+		}
+		schema = openapi.ResolveSchema(h.openAPISpec, schema)
+		for _, v := range h.checkArgument(ctx, name, schema, value, in != "body") {
+			v.In = in
+			v.Schema = schemaExcerpt(h.openAPISpec, schema, 0)
+			violations = append(violations, v)
+		}
+	}
+
+	declared := make(map[string]bool, len(operation.Parameters))
+	for _, param := range operation.Parameters {
+		declared[param.Name] = true
+		check(param.Name, param.In, param.Schema)
+	}
+	if schema, exists := jsonSchema(operation.RequestBody.Content); exists {
+		schema = openapi.ResolveSchema(h.openAPISpec, schema)
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			if !declared[name] {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			check(name, "body", schema.Properties[name])
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+// checkArgument 检查一个参数值的类型、枚举值、取值范围和长度，textual 表示参数以文本发送
+func (h *RequestHandler) checkArgument(ctx context.Context, name string, schema config.Schema, value interface{}, textual bool) []ParamViolation {
+	violation := func(path, constraint, detail string) ParamViolation {
+		return ParamViolation{
+			Param:      name,
+			Path:       path,
+			Constraint: constraint,
+			Message:    i18n.Tc(ctx, "validation.invalid_param", name+strings.TrimPrefix(path, "$"), detail),
+		}
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		if schema.Type != "" && schema.Type != "object" && schema.Type != "array" {
+			return []ParamViolation{violation("", "type", i18n.Tc(ctx, "validation.type_mismatch", schema.Type, jsonTypeName(value)))}
+		}
+		var violations []ParamViolation
+		for _, m := range openapi.ValidateValue(h.openAPISpec, schema, value, i18n.FromContext(ctx)) {
+			path := m.Path
+			if path == "$" {
+				path = ""
+			}
+			violations = append(violations, violation(path, m.Constraint, m.Message))
+		}
+		return violations
+	}
+
+	if !scalarMatches(schema.Type, value, textual) {
+		return []ParamViolation{violation("", "type", i18n.Tc(ctx, "validation.type_mismatch", schema.Type, jsonTypeName(value)))}
+	}
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		allowed := make([]string, len(schema.Enum))
+		for i, item := range schema.Enum {
+			allowed[i] = fmt.Sprintf("%v", item)
+		}
+		return []ParamViolation{violation("", "enum", i18n.Tc(ctx, "validation.enum_mismatch", value, strings.Join(allowed, ", ")))}
+	}
+	if n, ok := numericValue(value); ok && !inRange(n, schema.Minimum, schema.Maximum, schema.ExclusiveMinimum, schema.ExclusiveMaximum) {
+		return []ParamViolation{violation("", "range", i18n.Tc(ctx, "validation.out_of_range", value,
+			rangeText(schema.Minimum, schema.Maximum, schema.ExclusiveMinimum, schema.ExclusiveMaximum)))}
+	}
+	if text, ok := value.(string); ok && (schema.MinLength != nil || schema.MaxLength != nil) {
+		length := float64(utf8.RuneCountInString(text))
+		min, max := intBound(schema.MinLength), intBound(schema.MaxLength)
+		if !inRange(length, min, max, false, false) {
+			return []ParamViolation{violation("", "length", i18n.Tc(ctx, "validation.length_out_of_range", int(length), rangeText(min, max, false, false)))}
+		}
+	}
+	return nil
+}
+
+// scalarMatches 检查标量是否符合模式类型，textual 时接受可以解析为该类型的字符串，任意标量都视为匹配字符串
+func scalarMatches(schemaType string, value interface{}, textual bool) bool {
+	text, isString := value.(string)
+	switch schemaType {
+	case "string":
+		return isString || textual
+	case "integer", "number":
+		if n, ok := value.(float64); ok {
+			return schemaType == "number" || n == float64(int64(n))
+		}
+		if isString && textual {
+			if schemaType == "integer" {
+				_, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+				return err == nil
+			}
+			_, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+			return err == nil
+		}
+		return false
+	case "boolean":
+		if _, ok := value.(bool); ok {
+			return true
+		}
+		if isString && textual {
+			_, err := strconv.ParseBool(strings.TrimSpace(text))
+			return err == nil
+		}
+		return false
+	case "object", "array":
+		return false
+	}
+	return true
+}
+
+// enumContains 按文本形式比较，"5" 与 5 视为相同的取值
+func enumContains(enum []interface{}, value interface{}) bool {
+	text := fmt.Sprintf("%v", value)
+	for _, item := range enum {
+		if fmt.Sprintf("%v", item) == text {
+			return true
+		}
+	}
+	return false
+}
+
+// numericValue 返回数值或数值字符串的值
+func numericValue(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func inRange(n float64, min, max *float64, exclusiveMin, exclusiveMax bool) bool {
+	if min != nil && (n < *min || exclusiveMin && n == *min) {
+		return false
+	}
+	if max != nil && (n > *max || exclusiveMax && n == *max) {
+		return false
+	}
+	return true
+}
+
+func intBound(bound *int) *float64 {
+	if bound == nil {
+		return nil
+	}
+	n := float64(*bound)
+	return &n
+}
+
+// rangeText 用区间表示取值范围，如 [1, 100]、(0, ∞)
+func rangeText(min, max *float64, exclusiveMin, exclusiveMax bool) string {
+	lower, upper := "[", "]"
+	low, high := "-∞", "∞"
+	if min != nil {
+		low = strconv.FormatFloat(*min, 'g', -1, 64)
+		if exclusiveMin {
+			lower = "("
+		}
+	} else {
+		lower = "("
+	}
+	if max != nil {
+		high = strconv.FormatFloat(*max, 'g', -1, 64)
+		if exclusiveMax {
+			upper = ")"
+		}
+	} else {
+		upper = ")"
+	}
+	return lower + low + ", " + high + upper
+}
+
+// jsonTypeName 返回 JSON 解码得到的值的类型名称
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaExcerpt 返回模式中约束取值的部分，depth 为 0 时包括属性和数组元素的类型，没有约束时返回 nil
+func schemaExcerpt(spec *config.OpenAPISpec, schema config.Schema, depth int) map[string]interface{} {
+	schema = openapi.ResolveSchema(spec, schema)
+	excerpt := make(map[string]interface{})
+	if schema.Ref != "" {
+		excerpt["$ref"] = schema.Ref
+	}
+	if schema.Type != "" {
+		excerpt["type"] = schema.Type
+	}
+	if schema.Format != "" {
+		excerpt["format"] = schema.Format
+	}
+	if len(schema.Enum) > 0 {
+		excerpt["enum"] = schema.Enum
+	}
+	if schema.Nullable {
+		excerpt["nullable"] = true
+	}
+	if depth > 0 {
+		return excerpt
+	}
+
+	if schema.Minimum != nil {
+		excerpt["minimum"] = *schema.Minimum
+		if schema.ExclusiveMinimum {
+			excerpt["exclusiveMinimum"] = true
+		}
+	}
+	if schema.Maximum != nil {
+		excerpt["maximum"] = *schema.Maximum
+		if schema.ExclusiveMaximum {
+			excerpt["exclusiveMaximum"] = true
+		}
+	}
+	if schema.MinLength != nil {
+		excerpt["minLength"] = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		excerpt["maxLength"] = *schema.MaxLength
+	}
+	if schema.MinItems != nil {
+		excerpt["minItems"] = *schema.MinItems
+	}
+	if schema.MaxItems != nil {
+		excerpt["maxItems"] = *schema.MaxItems
+	}
+	if len(schema.Required) > 0 {
+		excerpt["required"] = schema.Required
+	}
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = schemaExcerpt(spec, property, depth+1)
+		}
+		excerpt["properties"] = properties
+	}
+	if schema.Items != nil {
+		excerpt["items"] = schemaExcerpt(spec, *schema.Items, depth+1)
+	}
+	if len(excerpt) == 0 {
+		return nil
+	}
+	return excerpt
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mcp2rest/internal/auth"
+	"github.com/mcp2rest/internal/config"
+)
+
+// Backend 执行操作对应的请求，返回的响应与上游响应一样经过解压、错误映射和转换
+// 默认直接把请求发送给上游；操作通过 x-mcp-backend 选用其他后端，如模拟、录制回放，
+// 或嵌入方注册的 gRPC、数据库等执行器
+type Backend interface {
+	Execute(ctx context.Context, call *BackendCall) (*http.Response, error)
+}
+
+// BackendFunc 把函数适配为 Backend
+type BackendFunc func(ctx context.Context, call *BackendCall) (*http.Response, error)
+
+// Execute 调用 f(ctx, call)
+func (f BackendFunc) Execute(ctx context.Context, call *BackendCall) (*http.Response, error) {
+	return f(ctx, call)
+}
+
+// BackendCall 一次需要执行的请求
+type BackendCall struct {
+	// Tool 工具名称，Method 和 Path 为规范中的方法和路径模板
+	Tool       string
+	Method     string
+	Path       string
+	Operation  *config.Operation
+	Parameters map[string]interface{}
+	// Request 已完成身份验证和请求头设置的上游请求，轮询和获取新建资源时为对应的后续请求
+	Request *http.Request
+}
+
+// backendRegistry 具名的执行后端
+type backendRegistry struct {
+	mu       sync.RWMutex
+	backends map[string]Backend
+}
+
+type backendCallContextKey struct{}
+
+// backendCall 工具调用中不随后续请求变化的部分
+type backendCall struct {
+	tool       string
+	method     string
+	path       string
+	parameters map[string]interface{}
+}
+
+// newBackendRegistry 创建 global.backends 中配置的后端
+func newBackendRegistry(cfg map[string]config.BackendConfig, spec *config.OpenAPISpec, upstream Backend) (*backendRegistry, error) {
+	registry := &backendRegistry{backends: make(map[string]Backend)}
+	for name, backendCfg := range cfg {
+		var backend Backend
+		switch backendCfg.Type {
+		case "mock":
+			backend = newMockBackend(backendCfg, spec)
+		case "example":
+			backend = &exampleBackend{spec: spec}
+		case "recorded":
+			recorded, err := newRecordedBackend(backendCfg, upstream)
+			if err != nil {
+				return nil, fmt.Errorf("执行后端 %s: %w", name, err)
+			}
+			backend = recorded
+		default:
+			return nil, fmt.Errorf("执行后端 %s 的类型 %q 无效，应为 mock、example 或 recorded", name, backendCfg.Type)
+		}
+		registry.backends[name] = backend
+	}
+	return registry, nil
+}
+
+// RegisterBackend 注册具名的执行后端，操作通过 x-mcp-backend 选用，与配置中的后端同名时替换之
+// 应在开始处理工具调用之前调用
+func (h *RequestHandler) RegisterBackend(name string, backend Backend) {
+	h.backends.mu.Lock()
+	defer h.backends.mu.Unlock()
+	h.backends.backends[name] = backend
+}
+
+// backendFor 返回操作选用的执行后端，未选用时返回 nil；dry_run 时所有操作都返回示例
+func (h *RequestHandler) backendFor(operation *config.Operation) (Backend, error) {
+	if h.config.Global.DryRun {
+		return h.examples, nil
+	}
+	if operation == nil || operation.Backend == "" {
+		return nil, nil
+	}
+	h.backends.mu.RLock()
+	defer h.backends.mu.RUnlock()
+	backend, ok := h.backends.backends[operation.Backend]
+	if !ok {
+		return nil, fmt.Errorf("未知的执行后端: %s", operation.Backend)
+	}
+	return backend, nil
+}
+
+// backendName 返回执行操作的后端名称，用于日志和来源信息，直接请求上游时为空
+func (h *RequestHandler) backendName(operation *config.Operation) string {
+	if h.config.Global.DryRun {
+		return "dry_run"
+	}
+	return operation.Backend
+}
+
+// needsCredentials 检查操作的请求是否会发往上游，不会时不应用身份验证，未设置凭据也可以调用
+func (h *RequestHandler) needsCredentials(operation *config.Operation) bool {
+	backend, err := h.backendFor(operation)
+	if err != nil || backend == nil {
+		return true
+	}
+	switch typed := backend.(type) {
+	case *mockBackend, *exampleBackend:
+		return false
+	case *recordedBackend:
+		return typed.record
+	}
+	return true
+}
+
+// withBackendCall 记录当前工具调用，供执行后端使用
+func withBackendCall(ctx context.Context, call *backendCall) context.Context {
+	return context.WithValue(ctx, backendCallContextKey{}, call)
+}
+
+// newBackendCall 根据上下文中的工具调用和实际的请求生成 BackendCall
+func newBackendCall(req *http.Request, operation *config.Operation) *BackendCall {
+	call := &BackendCall{Method: req.Method, Operation: operation, Request: req}
+	if current, ok := req.Context().Value(backendCallContextKey{}).(*backendCall); ok {
+		call.Tool = current.tool
+		call.Method = current.method
+		call.Path = current.path
+		call.Parameters = current.parameters
+	}
+	return call
+}
+
+// readRequestBody 读取请求体并恢复，供计算摘要使用
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// upstreamBackend 直接把请求发送给上游
+type upstreamBackend struct {
+	client *http.Client
+}
+
+// Execute 发送请求，使用 NTLM/Negotiate 认证时先与上游握手
+func (b *upstreamBackend) Execute(ctx context.Context, call *BackendCall) (*http.Response, error) {
+	if handshake := auth.HandshakeFromContext(ctx); handshake != nil {
+		return handshake.Do(b.client, call.Request)
+	}
+	return b.client.Do(call.Request)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// benchSpec 基准测试使用的规范，服务器地址在运行时替换为本地模拟上游
+const benchSpec = `
+openapi: 3.0.0
+info: {title: bench, version: "1.0"}
+paths:
+  /items/{id}:
+    get:
+      operationId: getItem
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: limit, in: query, schema: {type: integer}}
+      responses:
+        "200": {description: ok}
+  /items/{id}/summary:
+    get:
+      operationId: getItemSummary
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      x-mcp-transform: {type: fields, fields: [data.id, data.title]}
+      responses:
+        "200": {description: ok}
+`
+
+// newTestHandler 创建请求发往本地模拟上游的处理器，configure 可以修改全局配置
+func newTestHandler(tb testing.TB, configure func(*config.GlobalConfig)) *RequestHandler {
+	tb.Helper()
+	// 日志写入丢弃，避免日志 I/O 影响结果
+	logging.Logger = log.New(io.Discard, "", 0)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":0,"data":{"id":"1","title":"bench","items":[1,2,3]}}`))
+	}))
+	tb.Cleanup(upstream.Close)
+
+	spec, err := openapi.ParseOpenAPISpecData([]byte(benchSpec), "yaml")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	spec.Servers = []config.OpenAPIServer{{URL: upstream.URL}}
+
+	server, global := config.GetDefaultServerConfig()
+	if configure != nil {
+		configure(global)
+	}
+	h, err := NewRequestHandler(&config.Config{Server: *server, Global: *global}, spec)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return h
+}
+
+func benchmarkHandleRequest(b *testing.B, params *mcp.ToolCallParams) {
+	h := newTestHandler(b, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := h.HandleRequest(context.Background(), params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHandleRequest(b *testing.B) {
+	benchmarkHandleRequest(b, &mcp.ToolCallParams{Name: "getItem", Parameters: map[string]interface{}{"id": "1", "limit": float64(10)}})
+}
+
+func BenchmarkHandleRequestTransform(b *testing.B) {
+	benchmarkHandleRequest(b, &mcp.ToolCallParams{Name: "getItemSummary", Parameters: map[string]interface{}{"id": "1"}})
+}
+
+func BenchmarkGetAvailableTools(b *testing.B) {
+	h := newTestHandler(b, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.GetAvailableTools()
+	}
+}
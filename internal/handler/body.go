@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/transformer"
+)
+
+// isLocatedParam 检查参数是否在路径、查询字符串、请求头或 Cookie 中传递，这些参数不属于请求体
+func isLocatedParam(param config.Parameter) bool {
+	switch param.In {
+	case "path", "query", "header", "cookie":
+		return true
+	}
+	return false
+}
+
+// hasRequestBody 检查方法是否发送请求体，只有这些方法发送 in: body 的参数
+func hasRequestBody(method string) bool {
+	return method == "POST" || method == "PUT" || method == "PATCH"
+}
+
+// paramValue 返回发送给上游的参数值，ok 为 false 表示没有可发送的值
+// 路径、查询、请求头和 Cookie 参数未提供或为 null 时使用规范中的默认值；请求体参数不使用默认值，null 原样发送
+func paramValue(param config.Parameter, params map[string]interface{}) (interface{}, bool) {
+	value, exists := params[param.Name]
+	if param.In == "body" {
+		return value, exists
+	}
+	if exists && value != nil {
+		return value, true
+	}
+	if param.Schema.Default != nil {
+		return param.Schema.Default, true
+	}
+	return nil, false
+}
+
+// bodyFields 返回 JSON 请求体的字段
+// 声明了 in: body 的参数时只使用这些参数，否则使用除路径、查询、请求头和 Cookie 参数之外的全部参数
+func bodyFields(ctx context.Context, operation *config.Operation, params map[string]interface{}) (map[string]interface{}, error) {
+	declared := false
+	fields := make(map[string]interface{})
+	for _, param := range operation.Parameters {
+		if param.In != "body" {
+			continue
+		}
+		declared = true
+		if value, ok := paramValue(param, params); ok {
+			fields[param.Name] = value
+		} else if param.Required {
+			return nil, invalidParam(ctx, param, "required", "validation.missing_body_param", param.Name)
+		}
+	}
+	if declared {
+		return fields, nil
+	}
+	return bodyParameters(operation, params), nil
+}
+
+// applyLocatedParams 把 in: header 和 in: cookie 的参数设置到请求中
+func applyLocatedParams(ctx context.Context, req *http.Request, operation *config.Operation, params map[string]interface{}) error {
+	for _, param := range operation.Parameters {
+		if param.In != "header" && param.In != "cookie" {
+			continue
+		}
+		value, ok := paramValue(param, params)
+		if !ok {
+			if param.Required {
+				return invalidParam(ctx, param, "required", "validation.missing_param", param.Name)
+			}
+			continue
+		}
+		if param.In == "header" {
+			req.Header.Set(param.Name, fmt.Sprintf("%v", value))
+		} else {
+			req.AddCookie(&http.Cookie{Name: param.Name, Value: fmt.Sprintf("%v", value)})
+		}
+	}
+	return nil
+}
+
+// buildTemplateBody 使用 x-mcp-body-template 把扁平的工具参数渲染为上游要求的 JSON 请求体
+// 参数作为模板数据，值需要保持类型时使用 {{ toJSON .name }}
+func (h *RequestHandler) buildTemplateBody(operation *config.Operation, params map[string]interface{}) ([]byte, error) {
+	rendered, err := h.transformer.RenderMessage(operation.BodyTemplate, params, &transformer.ResponseContext{Params: params})
+	if err != nil {
+		return nil, fmt.Errorf("渲染请求体模板失败: %w", err)
+	}
+
+	var body bytes.Buffer
+	if err := json.Compact(&body, []byte(strings.TrimSpace(rendered))); err != nil {
+		return nil, fmt.Errorf("请求体模板的渲染结果不是有效的 JSON: %w", err)
+	}
+	return body.Bytes(), nil
+}
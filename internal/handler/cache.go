@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mcp2rest/internal/cache"
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// cacheKey 返回 GET 请求的缓存键，不缓存时返回空字符串
+// 键包含 URL 和请求头的摘要，认证设置的任何请求头 (如 API 密钥头) 或参数头不同的请求不会复用彼此的响应；
+// 每次调用都不同的关联ID、剩余时间和条件请求头不计入
+func (h *RequestHandler) cacheKey(req *http.Request, operation *config.Operation) string {
+	if h.cache == nil || req.Method != http.MethodGet {
+		return ""
+	}
+	if operation.Cache != nil && !*operation.Cache {
+		return ""
+	}
+
+	return req.URL.String() + "#" + headerDigest(req.Header, logging.RequestIDHeader, h.deadlineHeader(), "If-None-Match", "If-Modified-Since")
+}
+
+// applyValidators 缓存中有该请求的响应时附加 If-None-Match / If-Modified-Since
+func (h *RequestHandler) applyValidators(req *http.Request, key string) *cache.Entry {
+	if key == "" {
+		return nil
+	}
+	entry, exists := h.cache.Get(key)
+	if !exists {
+		return nil
+	}
+	if entry.ETag != "" && req.Header.Get("If-None-Match") == "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" && req.Header.Get("If-Modified-Since") == "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+	return entry
+}
+
+// revalidate 处理条件请求的响应：304 时返回使用缓存响应体的响应，
+// 2xx 且带有 ETag 或 Last-Modified 时更新缓存
+func (h *RequestHandler) revalidate(key string, entry *cache.Entry, resp *http.Response, body []byte) (*http.Response, []byte) {
+	if key == "" {
+		return resp, body
+	}
+	logger := logging.FromContext(requestContext(resp))
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		h.cache.RecordValidation(true)
+		logger.Printf("上游返回 304，使用缓存的响应: %s", resp.Request.URL.String())
+
+		// 304 中携带的头 (如新的 ETag、Date) 覆盖缓存的头
+		header := entry.Header.Clone()
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		cached := *resp
+		cached.StatusCode = entry.StatusCode
+		cached.Status = http.StatusText(entry.StatusCode)
+		cached.Header = header
+		return &cached, entry.Body
+	}
+	if entry != nil {
+		h.cache.RecordValidation(false)
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	noStore := strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || noStore || (etag == "" && lastModified == "") {
+		if entry != nil {
+			h.cache.Delete(key)
+		}
+		return resp, body
+	}
+
+	h.cache.Put(key, &cache.Entry{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         body,
+		ETag:         etag,
+		LastModified: lastModified,
+	})
+	return resp, body
+}
+
+// CacheStats 返回响应缓存的统计信息，未启用缓存时返回 nil
+func (h *RequestHandler) CacheStats() map[string]interface{} {
+	if h.cache == nil {
+		return nil
+	}
+	entries, hits, misses := h.cache.Stats()
+	return map[string]interface{}{
+		"entries":      entries,
+		"not_modified": hits,
+		"modified":     misses,
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mcp2rest/internal/cache"
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+func TestCacheKeyHeaders(t *testing.T) {
+	h := &RequestHandler{cache: cache.New(10), config: &config.Config{}}
+	operation := &config.Operation{}
+	key := func(header map[string]string) string {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/users", nil)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		return h.cacheKey(req, operation)
+	}
+
+	base := key(map[string]string{"X-API-Key": "key-a", "Accept": "application/json"})
+	tests := []struct {
+		name   string
+		header map[string]string
+		same   bool
+	}{
+		{"other api key", map[string]string{"X-API-Key": "key-b", "Accept": "application/json"}, false},
+		{"other accept", map[string]string{"X-API-Key": "key-a", "Accept": "text/csv"}, false},
+		{"extra cookie", map[string]string{"X-API-Key": "key-a", "Accept": "application/json", "Cookie": "sid=1"}, false},
+		{"request id", map[string]string{"X-API-Key": "key-a", "Accept": "application/json", logging.RequestIDHeader: "abc"}, true},
+		{"deadline", map[string]string{"X-API-Key": "key-a", "Accept": "application/json", defaultDeadlineHeader: "4.750"}, true},
+		{"validators", map[string]string{"X-API-Key": "key-a", "Accept": "application/json", "If-None-Match": `"v1"`}, true},
+	}
+	for _, tt := range tests {
+		if got := key(tt.header); (got == base) != tt.same {
+			t.Errorf("%s: cacheKey() same = %v, want %v", tt.name, got == base, tt.same)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// 操作目录工具的名称
+const (
+	SearchToolName   = "searchOperations"
+	DescribeToolName = "describeOperation"
+	CallToolName     = "callOperation"
+)
+
+// 搜索结果数量的默认值和上限
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// catalogTools 返回操作目录工具的定义，未启用时返回 nil
+// 按需模式 (catalog.lazy) 下额外提供 callOperation，用于调用不在工具列表中的操作
+func (h *RequestHandler) catalogTools() []map[string]interface{} {
+	catalog := h.config.Global.Catalog
+	if !catalog.Enabled && !catalog.Lazy {
+		return nil
+	}
+
+	tools := []map[string]interface{}{
+		{
+			"name":        SearchToolName,
+			"description": "按关键字搜索 API 操作，返回匹配的操作名称、方法、路径和说明。多个关键字需要同时匹配名称、说明、路径、标签或参数名。",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "关键字，以空格分隔",
+					},
+					"tag": map[string]interface{}{
+						"type":        "string",
+						"description": "只搜索带有该标签的操作",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("最多返回的结果数，默认 %d，最多 %d", defaultSearchLimit, maxSearchLimit),
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		{
+			"name":        DescribeToolName,
+			"description": "返回 API 操作的完整定义：参数、请求体和响应的模式，以及调用方式。",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"operation": map[string]interface{}{
+						"type":        "string",
+						"description": "searchOperations 返回的操作名称",
+					},
+				},
+				"required": []string{"operation"},
+			},
+		},
+	}
+	if catalog.Lazy {
+		tools = append(tools, map[string]interface{}{
+			"name":        CallToolName,
+			"description": "调用 API 操作。先用 searchOperations 查找操作，再用 describeOperation 查看参数。",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"operation": map[string]interface{}{
+						"type":        "string",
+						"description": "要调用的操作名称",
+					},
+					"arguments": map[string]interface{}{
+						"type":        "object",
+						"description": "操作的参数，见 describeOperation 返回的 inputSchema",
+					},
+				},
+				"required": []string{"operation"},
+			},
+		})
+	}
+	return tools
+}
+
+// isBuiltinTool 检查名称是否为内置工具 (确认工具和操作目录工具)，无论是否启用
+func isBuiltinTool(name string) bool {
+	switch name {
+	case ConfirmToolName, SearchToolName, DescribeToolName, CallToolName:
+		return true
+	}
+	return false
+}
+
+// isCatalogTool 检查是否为 searchOperations 或 describeOperation
+func (h *RequestHandler) isCatalogTool(name string) bool {
+	catalog := h.config.Global.Catalog
+	return (catalog.Enabled || catalog.Lazy) && (name == SearchToolName || name == DescribeToolName)
+}
+
+// handleCatalog 执行 searchOperations 或 describeOperation
+func (h *RequestHandler) handleCatalog(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+	var result interface{}
+	switch params.Name {
+	case SearchToolName:
+		query, _ := params.Parameters["query"].(string)
+		if strings.TrimSpace(query) == "" {
+			return nil, invalidParam(ctx, config.Parameter{Name: "query", Schema: config.Schema{Type: "string"}}, "required", "validation.missing_argument", SearchToolName, "query")
+		}
+		tag, _ := params.Parameters["tag"].(string)
+		limit := defaultSearchLimit
+		if value, ok := params.Parameters["limit"].(float64); ok && value > 0 {
+			limit = int(value)
+		}
+		if limit > maxSearchLimit {
+			limit = maxSearchLimit
+		}
+		result = h.searchOperations(ctx, query, tag, limit)
+	case DescribeToolName:
+		name, _ := params.Parameters["operation"].(string)
+		if name == "" {
+			return nil, invalidParam(ctx, config.Parameter{Name: "operation", Schema: config.Schema{Type: "string"}}, "required", "validation.missing_argument", DescribeToolName, "operation")
+		}
+		description, err := h.describeOperation(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		result = description
+	}
+
+	return &mcp.ToolCallResult{
+		Type:   "success",
+		Status: "success",
+		Result: result,
+	}, nil
+}
+
+// searchOperations 按关键字搜索操作，每个关键字都需要匹配，按匹配程度排序，不列出客户端无权调用的操作
+func (h *RequestHandler) searchOperations(ctx context.Context, query, tag string, limit int) map[string]interface{} {
+	type match struct {
+		score int
+		entry map[string]interface{}
+	}
+
+	keywords := strings.Fields(strings.ToLower(query))
+	var matches []match
+	total := 0
+	for path, pathItem := range h.openAPISpec.Paths {
+		for method, operation := range pathItem {
+			if !isHTTPMethod(method) || (operation.Deprecated && h.config.Global.HideDeprecated) {
+				continue
+			}
+			if tag != "" && !hasTag(operation.Tags, tag) {
+				continue
+			}
+			name := toolName(method, path, &operation)
+			if !toolAllowed(ctx, name, readOnlyMethod(method)) {
+				continue
+			}
+			score := matchScore(keywords, name, path, &operation)
+			if score == 0 {
+				continue
+			}
+			total++
+
+			entry := map[string]interface{}{
+				"name":    name,
+				"method":  strings.ToUpper(method),
+				"path":    path,
+				"summary": operationSummary(&operation),
+			}
+			if len(operation.Tags) > 0 {
+				entry["tags"] = operation.Tags
+			}
+			if operation.Deprecated {
+				entry["deprecated"] = true
+			}
+			if call := h.callHint(name); call != nil {
+				entry["call"] = call
+			}
+			matches = append(matches, match{score: score, entry: entry})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].entry["name"].(string) < matches[j].entry["name"].(string)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	operations := make([]map[string]interface{}, 0, len(matches))
+	for _, m := range matches {
+		operations = append(operations, m.entry)
+	}
+	return map[string]interface{}{
+		"total":      total,
+		"operations": operations,
+	}
+}
+
+// matchScore 计算操作与关键字的匹配程度，任一关键字没有匹配时返回 0
+// 名称的权重最高，其次是摘要和标签、路径，描述和参数名最低
+func matchScore(keywords []string, name, path string, operation *config.Operation) int {
+	lowerName := strings.ToLower(name)
+	summary := strings.ToLower(operation.Summary)
+	description := strings.ToLower(operation.Description)
+	lowerPath := strings.ToLower(path)
+	tags := strings.ToLower(strings.Join(operation.Tags, " "))
+	params := make([]string, 0, len(operation.Parameters))
+	for _, param := range operation.Parameters {
+		params = append(params, strings.ToLower(param.Name))
+	}
+	paramNames := strings.Join(params, " ")
+
+	total := 0
+	for _, keyword := range keywords {
+		score := 0
+		if lowerName == keyword {
+			score += 10
+		} else if strings.Contains(lowerName, keyword) {
+			score += 5
+		}
+		if strings.Contains(summary, keyword) {
+			score += 3
+		}
+		if strings.Contains(tags, keyword) {
+			score += 3
+		}
+		if strings.Contains(lowerPath, keyword) {
+			score += 2
+		}
+		if strings.Contains(description, keyword) {
+			score++
+		}
+		if strings.Contains(paramNames, keyword) {
+			score++
+		}
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
+
+// describeOperation 返回操作的完整定义
+func (h *RequestHandler) describeOperation(ctx context.Context, name string) (map[string]interface{}, error) {
+	operation, method, path, err := openapi.GetOperationByID(h.openAPISpec, name)
+	if err != nil {
+		return nil, i18n.Errorf(ctx, "handler.operation_not_found", name)
+	}
+
+	parameters := make([]map[string]interface{}, 0, len(operation.Parameters))
+	for _, param := range operation.Parameters {
+		parameter := map[string]interface{}{
+			"name":     param.Name,
+			"in":       param.In,
+			"required": param.Required,
+			"schema":   openapi.SchemaToJSON(h.openAPISpec, param.Schema),
+		}
+		if param.Description != "" {
+			parameter["description"] = param.Description
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	description := map[string]interface{}{
+		"name":        name,
+		"method":      method,
+		"path":        path,
+		"summary":     operation.Summary,
+		"description": operation.Description,
+		"parameters":  parameters,
+		"inputSchema": operationInputSchema(operation),
+	}
+	if len(operation.Tags) > 0 {
+		description["tags"] = operation.Tags
+	}
+	if operation.Deprecated {
+		description["deprecated"] = true
+	}
+	if len(operation.RequestBody.Content) > 0 {
+		description["requestBody"] = h.contentSchemas(operation.RequestBody.Content)
+	}
+	if len(operation.Responses) > 0 {
+		responses := make(map[string]interface{}, len(operation.Responses))
+		for status, response := range operation.Responses {
+			entry := map[string]interface{}{"description": response.Description}
+			if len(response.Content) > 0 {
+				entry["content"] = h.contentSchemas(response.Content)
+			}
+			responses[status] = entry
+		}
+		description["responses"] = responses
+	}
+	if call := h.callHint(name); call != nil {
+		description["call"] = call
+	}
+	return description, nil
+}
+
+// contentSchemas 把各媒体类型的模式转换为 JSON Schema
+func (h *RequestHandler) contentSchemas(content map[string]config.MediaType) map[string]interface{} {
+	schemas := make(map[string]interface{}, len(content))
+	for contentType, mediaType := range content {
+		schemas[contentType] = openapi.SchemaToJSON(h.openAPISpec, mediaType.Schema)
+	}
+	return schemas
+}
+
+// callHint 返回不能直接按名称调用的操作的调用方式：按需模式下通过 callOperation，已合并时通过分组工具
+func (h *RequestHandler) callHint(name string) map[string]interface{} {
+	if h.config.Global.Catalog.Lazy {
+		return map[string]interface{}{"tool": CallToolName, "operation": name}
+	}
+	for _, group := range h.toolGroups {
+		if group.contains(name) {
+			return map[string]interface{}{"tool": group.name, "operation": name}
+		}
+	}
+	return nil
+}
+
+// hasOperation 检查操作是否存在
+func (h *RequestHandler) hasOperation(name string) bool {
+	_, _, _, err := openapi.GetOperationByID(h.openAPISpec, name)
+	return err == nil
+}
+
+// operationSummary 返回操作的摘要，未声明时使用描述的第一行
+func operationSummary(operation *config.Operation) string {
+	if operation.Summary != "" {
+		return operation.Summary
+	}
+	summary, _, _ := strings.Cut(operation.Description, "\n")
+	return summary
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mcp2rest/internal/logging"
+)
+
+// Client 表示发起工具调用的 MCP 客户端，由服务器层注入上下文
+type Client interface {
+	// Request 向客户端发送请求并等待响应结果
+	Request(ctx context.Context, method string, params interface{}) (json.RawMessage, error)
+	// Notify 向客户端发送通知
+	Notify(method string, params interface{}) error
+	// Supports 检查客户端是否在 initialize 中声明了能力，name 见 mcp.Capability* 常量
+	Supports(name string) bool
+}
+
+type clientContextKey struct{}
+
+type progressTokenContextKey struct{}
+
+// WithClient 将客户端附加到上下文
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// clientFromContext 从上下文获取客户端，不存在时返回 nil
+func clientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientContextKey{}).(Client)
+	return client
+}
+
+// clientWith 返回声明了能力的客户端，没有客户端或客户端未声明该能力时返回 nil
+func clientWith(ctx context.Context, name string) Client {
+	client := clientFromContext(ctx)
+	if client == nil || !client.Supports(name) {
+		return nil
+	}
+	return client
+}
+
+// withProgressToken 将客户端提供的进度令牌附加到上下文
+func withProgressToken(ctx context.Context, token interface{}) context.Context {
+	if token == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressTokenContextKey{}, token)
+}
+
+// notifyProgress 向客户端发送进度通知，客户端未请求进度时不发送
+// 进度没有对应的客户端能力，请求中的 progressToken 即表示客户端支持该请求的进度通知
+func notifyProgress(ctx context.Context, progress, total float64, message string) {
+	token := ctx.Value(progressTokenContextKey{})
+	client := clientFromContext(ctx)
+	if token == nil || client == nil {
+		return
+	}
+
+	params := map[string]interface{}{
+		"progressToken": token,
+		"progress":      progress,
+		"message":       message,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if err := client.Notify("notifications/progress", params); err != nil {
+		logging.FromContext(ctx).Printf("发送进度通知失败: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// coalescer 合并正在进行的相同 GET 请求，同时到达的调用共用一次上游请求和同一个响应
+type coalescer struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+
+	// upstream 实际发出的请求数，shared 复用了其他调用的响应的请求数
+	upstream atomic.Int64
+	shared   atomic.Int64
+}
+
+// flight 一次正在进行的上游请求，done 关闭后结果可读
+type flight struct {
+	done chan struct{}
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{flights: make(map[string]*flight)}
+}
+
+// coalesceKey 返回请求的合并键，不合并时返回空字符串
+// 键包含方法、URL 和除关联ID外的全部请求头的摘要，凭据不同的请求不会合并
+func (h *RequestHandler) coalesceKey(req *http.Request, operation *config.Operation) string {
+	if h.coalescer == nil || req.Method != http.MethodGet {
+		return ""
+	}
+	if operation.Coalesce != nil && !*operation.Coalesce {
+		return ""
+	}
+
+	return req.Method + " " + req.URL.String() + "#" + headerDigest(req.Header, logging.RequestIDHeader)
+}
+
+// headerDigest 返回除 skip 之外全部请求头的摘要
+func headerDigest(header http.Header, skip ...string) string {
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		skipped[http.CanonicalHeaderKey(name)] = true
+	}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if !skipped[http.CanonicalHeaderKey(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	digest := sha256.New()
+	for _, name := range names {
+		for _, value := range header[name] {
+			io.WriteString(digest, name+": "+value+"\n")
+		}
+	}
+	return hex.EncodeToString(digest.Sum(nil)[:8])
+}
+
+// do 按键合并请求，已有相同请求正在进行时等待其结果，否则调用 send 发送，shared 表示复用了其他调用的结果
+// 发起请求的调用被取消时，仍在等待的调用各自重新发送，不受他人取消的影响
+func (c *coalescer) do(ctx context.Context, key string, send func() (*http.Response, []byte, error)) (resp *http.Response, body []byte, shared bool, err error) {
+	c.mu.Lock()
+	if f, exists := c.flights[key]; exists {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, nil, false, ctx.Err()
+		}
+		if f.err != nil && (errors.Is(f.err, context.Canceled) || errors.Is(f.err, context.DeadlineExceeded)) && ctx.Err() == nil {
+			return c.do(ctx, key, send)
+		}
+		c.shared.Add(1)
+		resp, body, err = f.share()
+		return resp, body, true, err
+	}
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	c.mu.Unlock()
+
+	c.upstream.Add(1)
+	f.resp, f.body, f.err = send()
+
+	c.mu.Lock()
+	delete(c.flights, key)
+	c.mu.Unlock()
+	close(f.done)
+	resp, body, err = f.share()
+	return resp, body, false, err
+}
+
+// share 返回结果的副本，每个调用方得到独立的响应头和响应体读取器
+func (f *flight) share() (*http.Response, []byte, error) {
+	if f.err != nil || f.resp == nil {
+		return f.resp, f.body, f.err
+	}
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+	return &resp, f.body, nil
+}
+
+// CoalesceStats 返回请求合并的统计信息，未启用时返回 nil
+func (h *RequestHandler) CoalesceStats() map[string]interface{} {
+	if h.coalescer == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"upstream": h.coalescer.upstream.Load(),
+		"shared":   h.coalescer.shared.Load(),
+	}
+}
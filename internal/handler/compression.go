@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/mcp2rest/internal/config"
+)
+
+// acceptEncoding 请求上游压缩响应时发送的 Accept-Encoding
+const acceptEncoding = "gzip, deflate, br"
+
+// defaultCompressMinSize 未配置 compression.min_size 时压缩请求体的最小大小
+const defaultCompressMinSize = 1024
+
+// decodeBody 根据 Content-Encoding 返回解压后的响应体读取器，按编码的相反顺序逐层解压
+// 解压后移除 Content-Encoding 和 Content-Length，以免后续处理误用
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	header := resp.Header.Get("Content-Encoding")
+	if header == "" {
+		return resp.Body, nil
+	}
+
+	encodings := strings.Split(header, ",")
+	var reader io.Reader = resp.Body
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		switch encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(reader)
+			if err != nil {
+				return nil, fmt.Errorf("解压gzip响应失败: %w", err)
+			}
+			reader = gz
+		case "deflate":
+			reader = newDeflateReader(reader)
+		case "br":
+			reader = brotli.NewReader(reader)
+		default:
+			return nil, fmt.Errorf("不支持的响应编码: %s", encoding)
+		}
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return reader, nil
+}
+
+// newDeflateReader 解码 deflate 响应，按规范应为 zlib 格式，但部分服务器发送不带 zlib 头的原始 deflate 数据
+func newDeflateReader(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err == nil && len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		if zr, err := zlib.NewReader(buffered); err == nil {
+			return zr
+		}
+	}
+	return flate.NewReader(buffered)
+}
+
+// compressRequestBody 按配置使用 gzip 压缩 JSON 请求体，返回压缩后的请求体以及是否进行了压缩
+func (h *RequestHandler) compressRequestBody(operation *config.Operation, contentType string, body []byte) ([]byte, bool) {
+	enabled := h.config.Global.Compression.Requests
+	if operation.CompressRequest != nil {
+		enabled = *operation.CompressRequest
+	}
+	if !enabled || !strings.Contains(contentType, "json") {
+		return body, false
+	}
+
+	minSize := int64(defaultCompressMinSize)
+	if h.config.Global.Compression.MinSize != "" {
+		if size, err := config.ParseSize(h.config.Global.Compression.MinSize); err == nil {
+			minSize = size
+		}
+	}
+	if int64(len(body)) < minSize {
+		return body, false
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(body); err != nil {
+		return body, false
+	}
+	if err := gz.Close(); err != nil {
+		return body, false
+	}
+	return buf.Bytes(), true
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestDecodeBody(t *testing.T) {
+	const payload = `{"message":"hello"}`
+
+	var gz bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	gw.Write([]byte(payload))
+	gw.Close()
+
+	var br bytes.Buffer
+	bw := brotli.NewWriter(&br)
+	bw.Write([]byte(payload))
+	bw.Close()
+
+	// gzip 之后再 brotli，按相反顺序解压
+	var layered bytes.Buffer
+	lw := brotli.NewWriter(&layered)
+	lw.Write(gz.Bytes())
+	lw.Close()
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"identity", "", []byte(payload)},
+		{"gzip", "gzip", gz.Bytes()},
+		{"brotli", "br", br.Bytes()},
+		{"layered", "gzip, br", layered.Bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(tt.body))}
+			if tt.encoding != "" {
+				resp.Header.Set("Content-Encoding", tt.encoding)
+			}
+			reader, err := decodeBody(resp)
+			if err != nil {
+				t.Fatalf("decodeBody() error = %v", err)
+			}
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("read error = %v", err)
+			}
+			if string(got) != payload {
+				t.Errorf("decodeBody() = %q, want %q", got, payload)
+			}
+			if resp.Header.Get("Content-Encoding") != "" {
+				t.Errorf("Content-Encoding not removed")
+			}
+		})
+	}
+}
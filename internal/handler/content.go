@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/transformer"
+)
+
+// acceptHeader 根据规范中声明的响应内容类型生成 Accept 头，JSON 类型排在前面
+// 未声明任何内容类型时返回空字符串
+func acceptHeader(operation *config.Operation) string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, response := range operation.Responses {
+		for contentType := range response.Content {
+			if !seen[contentType] {
+				seen[contentType] = true
+				types = append(types, contentType)
+			}
+		}
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		iJSON, jJSON := isJSONMediaType(types[i]), isJSONMediaType(types[j])
+		if iJSON != jJSON {
+			return iJSON
+		}
+		return types[i] < types[j]
+	})
+	return strings.Join(types, ", ")
+}
+
+// isJSONMediaType 检查媒体类型是否为 JSON (包括 +json 类型)
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// normalizeBody 根据响应的 Content-Type 选择解析方式，把响应体转换为 JSON 后再交给转换器
+// XML 转换为对象，CSV 和 NDJSON 转换为数组，Docker 日志流和不是 JSON 的纯文本转换为字符串，其他类型原样返回
+func normalizeBody(resp *http.Response, body []byte, operation *config.Operation) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body
+	}
+
+	switch {
+	case isJSONMediaType(mediaType):
+		return body
+	case transformer.IsXMLContentType(contentType):
+		return normalizeXMLBody(resp, body)
+	case transformer.IsCSVContentType(contentType):
+		rows, err := transformer.CSVToJSON(body, contentType, operation.CSV)
+		if err != nil {
+			debug.LogError(requestContext(resp), "转换CSV响应失败", err)
+			return body
+		}
+		return marshalOr(rows, body)
+	case transformer.IsNDJSONContentType(contentType):
+		values, err := transformer.NDJSONToJSON(body)
+		if err != nil {
+			debug.LogError(requestContext(resp), "转换NDJSON响应失败", err)
+			return body
+		}
+		return marshalOr(values, body)
+	case transformer.IsDockerStreamContentType(contentType):
+		text, err := transformer.DockerStreamToText(body, contentType)
+		if err != nil {
+			debug.LogError(requestContext(resp), "解析Docker日志流失败", err)
+			return body
+		}
+		return marshalOr(text, body)
+	case strings.HasPrefix(mediaType, "text/"):
+		// 部分上游以 text/plain 返回 JSON，这类响应仍按 JSON 处理
+		if json.Valid(body) {
+			return body
+		}
+		return marshalOr(string(body), body)
+	}
+	return body
+}
+
+// marshalOr 序列化为 JSON，失败时返回 fallback
+func marshalOr(value interface{}, fallback []byte) []byte {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fallback
+	}
+	return data
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// inputLayouts 除 RFC 3339 外接受的日期时间写法，没有时区的按 UTC 解释
+var inputLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// dateFormat 返回参数或字段发送给上游的日期时间格式，不是日期时间时返回空字符串
+func dateFormat(explicit string, schema config.Schema) string {
+	if explicit != "" {
+		return explicit
+	}
+	if schema.DateFormat != "" {
+		return schema.DateFormat
+	}
+	if schema.Format == "date" || schema.Format == "date-time" {
+		return schema.Format
+	}
+	return ""
+}
+
+// normalizeDates 把 format 为 date/date-time (或配置了 x-mcp-format) 的参数和请求体顶层字段
+// 从 Unix 时间戳、RFC 3339、"2006-01-02" 等写法统一转换为规范要求的格式
+// 有需要转换的值时返回新的参数表，不修改 params
+func normalizeDates(ctx context.Context, operation *config.Operation, params map[string]interface{}) (map[string]interface{}, error) {
+	formats := make(map[string]string)
+	for _, mediaType := range operation.RequestBody.Content {
+		for name, property := range mediaType.Schema.Properties {
+			if format := dateFormat("", property); format != "" {
+				formats[name] = format
+			}
+		}
+	}
+	for _, param := range operation.Parameters {
+		if format := dateFormat(param.DateFormat, param.Schema); format != "" {
+			formats[param.Name] = format
+		}
+	}
+
+	var normalized map[string]interface{}
+	for name, format := range formats {
+		value, exists := params[name]
+		if !exists || value == nil {
+			continue
+		}
+		t, err := parseDateTime(value)
+		if err != nil {
+			return nil, invalidParam(ctx, config.Parameter{Name: name}, "format", "validation.invalid_datetime", name, value)
+		}
+		if normalized == nil {
+			normalized = make(map[string]interface{}, len(params))
+			for key, value := range params {
+				normalized[key] = value
+			}
+		}
+		normalized[name] = formatDateTime(t, format)
+	}
+	if normalized == nil {
+		return params, nil
+	}
+	return normalized, nil
+}
+
+// parseDateTime 解析日期时间，数字按 Unix 时间戳处理，超过 1e12 的视为毫秒
+func parseDateTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		return fromEpoch(v), nil
+	case int:
+		return fromEpoch(float64(v)), nil
+	case int64:
+		return fromEpoch(float64(v)), nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return fromEpoch(f), nil
+	case time.Time:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return fromEpoch(f), nil
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			return t, nil
+		}
+		for _, layout := range inputLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, strconv.ErrSyntax
+}
+
+func fromEpoch(value float64) time.Time {
+	if math.Abs(value) >= 1e12 {
+		return time.UnixMilli(int64(value)).UTC()
+	}
+	seconds, fraction := math.Modf(value)
+	return time.Unix(int64(seconds), int64(fraction*1e9)).UTC()
+}
+
+// formatDateTime 按格式输出日期时间，unix 和 unix-ms 输出数字，其余格式输出字符串
+func formatDateTime(t time.Time, format string) interface{} {
+	switch format {
+	case "date":
+		return t.Format("2006-01-02")
+	case "date-time":
+		return t.Format(time.RFC3339)
+	case "unix":
+		return t.Unix()
+	case "unix-ms":
+		return t.UnixMilli()
+	default:
+		return t.Format(format)
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mcp2rest/internal/grpc"
+)
+
+// defaultDeadlineHeader 传递剩余时间的默认请求头
+const defaultDeadlineHeader = "X-Request-Timeout"
+
+// withCallDeadline 启用 deadline.propagate 时以 global.timeout 作为工具调用的截止时间，已有更早的截止时间时保留
+func (h *RequestHandler) withCallDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if !h.config.Global.Deadline.Propagate || h.config.Global.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, h.config.Global.Timeout)
+}
+
+// applyDeadline 把工具调用的剩余时间 (减去 margin) 写入请求头，并作为上游请求的截止时间
+// 剩余时间已经用完时不再请求上游，返回的请求与 cancel 需要在读取完响应后调用
+func (h *RequestHandler) applyDeadline(req *http.Request) (*http.Request, context.CancelFunc, error) {
+	cfg := h.config.Global.Deadline
+	deadline, ok := req.Context().Deadline()
+	if !cfg.Propagate || !ok {
+		return req, func() {}, nil
+	}
+	remaining := time.Until(deadline) - cfg.Margin
+	if remaining <= 0 {
+		return nil, nil, fmt.Errorf("工具调用的剩余时间不足，不再请求上游: %w", context.DeadlineExceeded)
+	}
+
+	req.Header.Set(h.deadlineHeader(), formatTimeout(remaining, cfg.Format))
+
+	ctx, cancel := context.WithTimeout(req.Context(), remaining)
+	return req.WithContext(ctx), cancel, nil
+}
+
+// deadlineHeader 返回传递剩余时间的请求头名称
+func (h *RequestHandler) deadlineHeader() string {
+	if header := h.config.Global.Deadline.Header; header != "" {
+		return header
+	}
+	return defaultDeadlineHeader
+}
+
+// formatTimeout 按格式编码剩余时间: seconds (默认，如 4.75)、ms 或 grpc (如 4750m)
+func formatTimeout(d time.Duration, format string) string {
+	switch format {
+	case "ms":
+		return strconv.FormatInt(d.Milliseconds(), 10)
+	case "grpc":
+		return grpc.FormatTimeout(d)
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
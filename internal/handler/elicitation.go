@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// elicitationResult 表示 elicitation/create 的响应结果
+type elicitationResult struct {
+	Action  string                 `json:"action"` // "accept"、"decline" 或 "cancel"
+	Content map[string]interface{} `json:"content"`
+}
+
+// missingRequiredParameters 返回调用中缺少且没有默认值的必需参数，与 buildHTTPRequest 一样通过 paramValue 取值，
+// 因此请求头、Cookie 参数同样会被询问，有默认值的参数不会被询问；in: body 的参数只在带请求体的方法中检查
+func missingRequiredParameters(operation *config.Operation, method string, params map[string]interface{}) []config.Parameter {
+	var missing []config.Parameter
+	for _, param := range operation.Parameters {
+		if !param.Required || (param.In == "body" && !hasRequestBody(method)) {
+			continue
+		}
+		if _, ok := paramValue(param, params); !ok {
+			missing = append(missing, param)
+		}
+	}
+	return missing
+}
+
+// elicitMissingParameters 通过 MCP elicitation 向用户请求缺少的必需参数
+// 未启用、客户端不可用或客户端不支持时返回原始参数，由后续流程报告缺少参数
+func (h *RequestHandler) elicitMissingParameters(ctx context.Context, toolName string, operation *config.Operation, method string, params map[string]interface{}) (map[string]interface{}, error) {
+	if !h.config.Global.Elicitation.Enabled {
+		return params, nil
+	}
+
+	client := clientWith(ctx, mcp.CapabilityElicitation)
+	if client == nil {
+		return params, nil
+	}
+
+	missing := missingRequiredParameters(operation, method, params)
+	if len(missing) == 0 {
+		return params, nil
+	}
+
+	// 构建请求的参数模式，elicitation 只支持扁平的基本类型
+	properties := make(map[string]interface{}, len(missing))
+	required := make([]string, 0, len(missing))
+	names := make([]string, 0, len(missing))
+	for _, param := range missing {
+		properties[param.Name] = map[string]interface{}{
+			"type":        getElicitationType(param.Schema),
+			"description": param.Description,
+		}
+		required = append(required, param.Name)
+		names = append(names, param.Name)
+	}
+
+	elicitCtx, cancel := context.WithTimeout(ctx, h.config.Global.Elicitation.Timeout)
+	defer cancel()
+
+	logging.FromContext(ctx).Printf("工具 %s 缺少必需参数 %v，向客户端请求补充", toolName, names)
+	raw, err := client.Request(elicitCtx, "elicitation/create", map[string]interface{}{
+		"message": fmt.Sprintf("调用工具 %s 需要以下参数: %s", toolName, strings.Join(names, ", ")),
+		"requestedSchema": map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		},
+	})
+	if err != nil {
+		logging.FromContext(ctx).Printf("请求客户端补充参数失败: %v", err)
+		return params, nil
+	}
+
+	var result elicitationResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("解析elicitation响应失败: %w", err)
+	}
+	if result.Action != "accept" {
+		return nil, fmt.Errorf("用户未提供缺少的参数 (%s): %s", result.Action, strings.Join(names, ", "))
+	}
+
+	merged := make(map[string]interface{}, len(params)+len(result.Content))
+	for key, value := range params {
+		merged[key] = value
+	}
+	for key, value := range result.Content {
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+// getElicitationType 将参数模式转换为 elicitation 支持的基本类型
+func getElicitationType(schema config.Schema) string {
+	switch schemaType := getSchemaType(schema); schemaType {
+	case "string", "number", "integer", "boolean":
+		return schemaType
+	default:
+		return "string"
+	}
+}
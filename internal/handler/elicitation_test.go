@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+func TestMissingRequiredParameters(t *testing.T) {
+	operation := &config.Operation{Parameters: []config.Parameter{
+		{Name: "id", In: "path", Required: true},
+		{Name: "limit", In: "query", Required: true, Schema: config.Schema{Default: 20}},
+		{Name: "q", In: "query", Required: true},
+		{Name: "X-Tenant", In: "header", Required: true},
+		{Name: "session", In: "cookie", Required: true},
+		{Name: "name", In: "body", Required: true},
+		{Name: "filter", In: "query"},
+	}}
+
+	tests := []struct {
+		name   string
+		method string
+		params map[string]interface{}
+		want   []string
+	}{
+		{"all missing on POST", "POST", map[string]interface{}{}, []string{"id", "q", "X-Tenant", "session", "name"}},
+		{"body not sent on GET", "GET", map[string]interface{}{}, []string{"id", "q", "X-Tenant", "session"}},
+		{"null counts as missing", "GET", map[string]interface{}{"id": "1", "q": nil, "X-Tenant": "acme", "session": "s"}, []string{"q"}},
+		{"all provided", "PUT", map[string]interface{}{"id": "1", "q": "x", "X-Tenant": "acme", "session": "s", "name": nil}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, param := range missingRequiredParameters(operation, tt.method, tt.params) {
+				got = append(got, param.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingRequiredParameters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
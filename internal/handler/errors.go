@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/transformer"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// buildErrorResult 根据错误状态码构建工具调用错误结果，优先使用操作上配置的错误映射
+func (h *RequestHandler) buildErrorResult(operation *config.Operation, resp *http.Response, body []byte, parameters map[string]interface{}) *mcp.ToolCallResult {
+	ctx := requestContext(resp)
+	errorMsg := i18n.Tc(ctx, "upstream.error_status", resp.StatusCode)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		errorMsg = i18n.Tc(ctx, "upstream.client_error")
+	} else if resp.StatusCode >= 500 {
+		errorMsg = i18n.Tc(ctx, "upstream.server_error")
+	}
+
+	// 使用规范中为该状态码声明的描述
+	if response, exists := findResponse(operation.Responses, resp.StatusCode); exists && response.Description != "" {
+		errorMsg = response.Description
+	}
+
+	// SOAP 故障信息比状态码描述更具体
+	if faultMsg := soapFaultMessage(body); faultMsg != "" {
+		errorMsg = faultMsg
+	}
+
+	var errorBody interface{} = string(body)
+	// 问题详情 (RFC 9457/7807) 的标准成员放在 problem 中，body 只保留扩展成员，不再返回原始文本
+	problem, extensions, isProblem := parseProblem(resp, body)
+	if isProblem {
+		errorMsg = problemMessage(problem, errorMsg)
+		errorBody = extensions
+	}
+	if mapping, exists := findErrorMapping(operation.Errors, resp.StatusCode); exists {
+		errorMsg, errorBody = h.applyErrorMapping(mapping, errorMsg, resp, body, parameters)
+	}
+
+	result := map[string]interface{}{
+		"message": errorMsg,
+		"code":    resp.StatusCode,
+		"body":    errorBody,
+	}
+	if isProblem {
+		result["problem"] = problem
+		if extensions, ok := errorBody.(map[string]interface{}); ok && len(extensions) == 0 {
+			delete(result, "body")
+		}
+	}
+	if expected := successCodes(operation); len(expected) > 0 {
+		result["expected_status"] = expected
+	}
+	if headers := h.exposedHeaders(operation, resp.Header); len(headers) > 0 {
+		result["headers"] = headers
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		result["request_id"] = id
+	}
+	// 认证失败且上游时钟与本地相差较大时提示时钟偏差，签名和令牌有效期校验常因此失败
+	// 执行后端返回的响应 (如录制的响应) 不反映上游当前的时钟
+	if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && h.backendName(operation) == "" {
+		if skew, ok := h.upstreamClockSkew(resp); ok {
+			result["clock_skew"] = i18n.Tc(ctx, "upstream.clock_skew", skew.Round(time.Second).String())
+			logging.FromContext(ctx).Printf("上游返回 %d，上游时钟与本地相差 %s，可能是时钟偏差导致认证失败", resp.StatusCode, skew.Round(time.Second))
+		}
+	}
+
+	// 参数错误时从常见的错误格式中提取逐个参数的修正提示，智能体可据此调整参数后重试
+	if hints := h.fixHints(operation, resp.StatusCode, body); len(hints) > 0 {
+		result["fix_hints"] = hints
+	}
+
+	debug.LogError(ctx, "API返回错误状态码", fmt.Errorf("状态码: %d, 消息: %s", resp.StatusCode, errorMsg))
+	return &mcp.ToolCallResult{
+		Type:   "error",
+		Status: "error",
+		Result: result,
+	}
+}
+
+// isSuccessStatus 检查状态码是否表示成功
+// 操作设置了 x-mcp-success-codes 时只有其中的状态码表示成功，可以包括 207、302 等 2xx 以外的状态码；
+// 否则规范声明了 2xx 响应时以声明为准，未声明的 2xx 状态码仍视为成功但会记录警告
+func isSuccessStatus(ctx context.Context, operation *config.Operation, statusCode int) bool {
+	if matched, configured := matchSuccessCodes(operation, statusCode); configured {
+		return matched
+	}
+
+	expected := expectedSuccessCodes(operation.Responses)
+	for _, key := range expected {
+		if matchStatusKey(key, statusCode) {
+			return true
+		}
+	}
+
+	if statusCode >= 200 && statusCode < 300 {
+		if len(expected) > 0 {
+			logging.FromContext(ctx).Printf("警告: 状态码 %d 不在规范声明的成功状态码 %v 中", statusCode, expected)
+		}
+		return true
+	}
+	return false
+}
+
+// isFollowUpSuccess 检查轮询状态地址或跟随 Location 得到的响应是否表示成功: 任意 2xx 或 x-mcp-success-codes 中的状态码
+// 这些响应来自其他地址，规范中没有为它们声明响应，因此不要求 2xx 出现在 x-mcp-success-codes 中
+func isFollowUpSuccess(operation *config.Operation, statusCode int) bool {
+	if statusCode >= 200 && statusCode < 300 {
+		return true
+	}
+	matched, _ := matchSuccessCodes(operation, statusCode)
+	return matched
+}
+
+// matchSuccessCodes 检查状态码是否在 x-mcp-success-codes 中，configured 表示操作是否设置了该扩展
+func matchSuccessCodes(operation *config.Operation, statusCode int) (matched, configured bool) {
+	if len(operation.SuccessCodes) == 0 {
+		return false, false
+	}
+	for _, key := range operation.SuccessCodes {
+		if matchStatusKey(key, statusCode) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+// successCodes 返回错误结果中列出的期望状态码: x-mcp-success-codes，未设置时为规范中声明的 2xx 状态码
+func successCodes(operation *config.Operation) []string {
+	if len(operation.SuccessCodes) > 0 {
+		return operation.SuccessCodes
+	}
+	return expectedSuccessCodes(operation.Responses)
+}
+
+// expectedSuccessCodes 返回规范中声明的成功状态码 (2xx)，按字母顺序排列
+func expectedSuccessCodes(responses map[string]config.Response) []string {
+	var codes []string
+	for key := range responses {
+		if strings.HasPrefix(key, "2") {
+			codes = append(codes, key)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// findResponse 按精确状态码、状态码范围、default 的顺序查找规范中的响应定义
+func findResponse(responses map[string]config.Response, statusCode int) (config.Response, bool) {
+	for _, key := range statusKeyCandidates(statusCode) {
+		if response, exists := responses[key]; exists {
+			return response, true
+		}
+	}
+	return config.Response{}, false
+}
+
+// matchStatusKey 检查响应键 (如 "201"、"2XX") 是否匹配状态码
+func matchStatusKey(key string, statusCode int) bool {
+	for _, candidate := range statusKeyCandidates(statusCode)[:3] {
+		if key == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// statusKeyCandidates 返回状态码可能对应的响应键，按优先级排列
+func statusKeyCandidates(statusCode int) []string {
+	return []string{
+		strconv.Itoa(statusCode),
+		fmt.Sprintf("%dXX", statusCode/100),
+		fmt.Sprintf("%dxx", statusCode/100),
+		"default",
+	}
+}
+
+// applyErrorMapping 使用错误映射生成消息和错误详情，映射执行失败时保留原始内容
+func (h *RequestHandler) applyErrorMapping(mapping config.ErrorMapping, errorMsg string, resp *http.Response, body []byte, parameters map[string]interface{}) (string, interface{}) {
+	ctx := requestContext(resp)
+	var errorBody interface{} = string(body)
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		errorBody = parsed
+	}
+
+	respCtx := &transformer.ResponseContext{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Params:     parameters,
+	}
+
+	if mapping.Message != "" {
+		if message, err := h.transformer.RenderMessage(mapping.Message, errorBody, respCtx); err == nil {
+			errorMsg = message
+		} else {
+			debug.LogError(ctx, "渲染错误消息失败", err)
+		}
+	}
+
+	if len(mapping.Transform) > 0 {
+		if transformed, err := h.transformer.TransformValue(errorBody, mapping.Transform, respCtx); err == nil {
+			errorBody = transformed
+		} else {
+			debug.LogError(ctx, "转换错误响应失败", err)
+		}
+	}
+
+	return errorMsg, errorBody
+}
+
+// findErrorMapping 按精确状态码、状态码范围 (如 "4XX")、default 的顺序查找错误映射
+func findErrorMapping(mappings map[string]config.ErrorMapping, statusCode int) (config.ErrorMapping, bool) {
+	if len(mappings) == 0 {
+		return config.ErrorMapping{}, false
+	}
+
+	for _, key := range statusKeyCandidates(statusCode) {
+		if mapping, exists := mappings[key]; exists {
+			return mapping, true
+		}
+	}
+	return config.ErrorMapping{}, false
+}
+
+// upstreamClockSkew 根据响应的 Date 头计算上游时钟与本地的偏差，超过 clock_check.max_skew 时 ok 为 true
+func (h *RequestHandler) upstreamClockSkew(resp *http.Response) (time.Duration, bool) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	skew := date.Sub(time.Now())
+	limit := h.config.Global.ClockCheck.AllowedSkew()
+	// Date 头只精确到秒
+	return skew, skew > limit+time.Second || skew < -limit-time.Second
+}
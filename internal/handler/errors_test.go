@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+func TestIsSuccessStatus(t *testing.T) {
+	logging.Logger = log.New(io.Discard, "", 0)
+
+	declared := &config.Operation{Responses: map[string]config.Response{"201": {}, "400": {}}}
+	custom := &config.Operation{SuccessCodes: config.StatusCodes{"207", "3XX"}}
+	none := &config.Operation{}
+
+	tests := []struct {
+		name      string
+		operation *config.Operation
+		status    int
+		want      bool
+		followUp  bool
+	}{
+		{"declared 2xx", declared, 201, true, true},
+		{"undeclared 2xx still succeeds", declared, 200, true, true},
+		{"client error", declared, 400, false, false},
+		{"no responses", none, 204, true, true},
+		{"redirect without success codes", none, 302, false, false},
+		{"success code 207", custom, 207, true, true},
+		{"success code range 3XX", custom, 302, true, true},
+		{"2xx not listed", custom, 200, false, true},
+		{"server error", custom, 500, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSuccessStatus(context.Background(), tt.operation, tt.status); got != tt.want {
+				t.Errorf("isSuccessStatus(%d) = %v, want %v", tt.status, got, tt.want)
+			}
+			if got := isFollowUpSuccess(tt.operation, tt.status); got != tt.followUp {
+				t.Errorf("isFollowUpSuccess(%d) = %v, want %v", tt.status, got, tt.followUp)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+const (
+	// defaultExampleResponseChars 示例响应默认的最大字符数
+	defaultExampleResponseChars = 300
+	// maxExampleStringChars 示例中单个字符串值的最大字符数
+	maxExampleStringChars = 60
+	// exampleSeed 生成示例响应使用的固定种子，保证工具描述不随重启变化
+	exampleSeed = 1
+)
+
+// buildDescriptionExamples 为有示例的操作生成附加到工具描述末尾的调用示例，按工具名称索引
+// 未配置 description_examples 时返回 nil
+func buildDescriptionExamples(spec *config.OpenAPISpec, cfg *config.DescriptionExamplesConfig) map[string]string {
+	if cfg == nil {
+		return nil
+	}
+	examples := make(map[string]string)
+	for path, pathItem := range spec.Paths {
+		for method, operation := range pathItem {
+			if !isHTTPMethod(method) || (operation.DescriptionExample != nil && !*operation.DescriptionExample) {
+				continue
+			}
+			if example := descriptionExample(spec, &operation, cfg); example != "" {
+				examples[toolName(method, path, &operation)] = example
+			}
+		}
+	}
+	return examples
+}
+
+// descriptionExample 生成一个操作的调用示例: 参数来自参数或参数模式的 example，响应来自规范中的示例响应，
+// 数组只保留第一个元素、过长的字符串被截断；参数和响应都没有示例时返回空字符串
+func descriptionExample(spec *config.OpenAPISpec, operation *config.Operation, cfg *config.DescriptionExamplesConfig) string {
+	locale := i18n.Default()
+	var lines []string
+
+	arguments := make(map[string]interface{})
+	for _, param := range operation.Parameters {
+		value := param.Example
+		if value == nil {
+			value = param.Schema.Example
+		}
+		if value != nil {
+			arguments[param.Name] = trimExample(value)
+		}
+	}
+	if len(arguments) > 0 {
+		if data, err := json.Marshal(arguments); err == nil {
+			lines = append(lines, i18n.T(locale, "mcp.example_call", string(data)))
+		}
+	}
+
+	limit := cfg.MaxResponseChars
+	if limit == 0 {
+		limit = defaultExampleResponseChars
+	}
+	if limit > 0 {
+		response := openapi.OperationExample(spec, operation, exampleSeed)
+		if response.Body != nil && (!response.Synthesized || cfg.Synthesized) {
+			if data, err := json.Marshal(trimExample(response.Body)); err == nil {
+				lines = append(lines, i18n.T(locale, "mcp.example_response", truncateRunes(string(data), limit)))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// trimExample 精简示例值: 数组只保留第一个元素，过长的字符串截断
+func trimExample(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case []interface{}:
+		if len(typed) == 0 {
+			return typed
+		}
+		return []interface{}{trimExample(typed[0])}
+	case map[string]interface{}:
+		trimmed := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			trimmed[key] = trimExample(item)
+		}
+		return trimmed
+	case string:
+		return truncateRunes(typed, maxExampleStringChars)
+	}
+	return value
+}
+
+// truncateRunes 超过 limit 个字符时截断并以 … 结尾
+func truncateRunes(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit]) + "…"
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// shouldFollowLocation 检查是否需要根据 Location 获取新建的资源
+func (h *RequestHandler) shouldFollowLocation(operation *config.Operation, method string, resp *http.Response, body []byte) bool {
+	enabled := h.config.Global.FollowCreated
+	if operation.FollowLocation != nil {
+		enabled = *operation.FollowLocation
+	}
+	if !enabled || method != "POST" || resp.StatusCode != http.StatusCreated {
+		return false
+	}
+	return resp.Header.Get("Location") != "" && len(bytes.TrimSpace(body)) == 0
+}
+
+// followLocation 使用 GET 获取 Location 指向的资源，失败时返回原始响应
+func (h *RequestHandler) followLocation(req *http.Request, resp *http.Response, body []byte, operation *config.Operation) (*http.Response, []byte) {
+	ctx := req.Context()
+	logger := logging.FromContext(ctx)
+	location, err := req.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		debug.LogError(ctx, "解析Location失败", err)
+		return resp, body
+	}
+
+	getReq, err := http.NewRequestWithContext(ctx, "GET", location.String(), nil)
+	if err != nil {
+		debug.LogError(ctx, "创建获取新资源的请求失败", err)
+		return resp, body
+	}
+
+	logger.Printf("资源已创建，获取新资源: %s", location.String())
+	getResp, getBody, err := h.sendRequest(getReq, operation)
+	if err != nil {
+		logger.Printf("获取新资源失败: %v", err)
+		return resp, body
+	}
+	if !isFollowUpSuccess(operation, getResp.StatusCode) {
+		logger.Printf("获取新资源返回状态码 %d，使用原始响应", getResp.StatusCode)
+		return resp, body
+	}
+
+	return getResp, getBody
+}
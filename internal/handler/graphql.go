@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// handleGraphQL 执行 GraphQL 后端提供的工具
+func (h *RequestHandler) handleGraphQL(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+	resp, statusCode, err := h.graphql.Call(ctx, params.Name, params.Parameters)
+	if err != nil {
+		debug.LogError(ctx, "执行GraphQL操作失败", err)
+		return nil, fmt.Errorf("执行GraphQL操作失败: %w", err)
+	}
+
+	// GraphQL 在 errors 中报告错误，HTTP 状态码可能仍为 200
+	if len(resp.Errors) > 0 || statusCode < 200 || statusCode >= 300 {
+		result := map[string]interface{}{
+			"message": fmt.Sprintf("GraphQL操作 %s 返回错误", params.Name),
+			"code":    statusCode,
+			"errors":  resp.Errors,
+		}
+		if resp.Data != nil {
+			result["data"] = resp.Data
+		}
+		return &mcp.ToolCallResult{
+			Type:   "error",
+			Status: "error",
+			Result: result,
+		}, nil
+	}
+
+	return &mcp.ToolCallResult{
+		Type:   "success",
+		Status: "success",
+		Result: resp.Data,
+	}, nil
+}
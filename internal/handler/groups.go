@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// untaggedGroup 没有标签的操作所在的分组
+const untaggedGroup = "未分类"
+
+// toolGroup 表示按标签合并的分组工具，通过 operation 参数选择要调用的操作
+type toolGroup struct {
+	name       string
+	tag        string
+	operations []groupOperation
+}
+
+// groupOperation 表示分组中的一个操作
+type groupOperation struct {
+	name      string
+	operation config.Operation
+}
+
+// buildToolGroups 在工具数量超出 global.tool_budget 时把 REST 操作按第一个标签合并为分组工具
+// 只有一个操作的标签保持为独立工具；未超出上限或未配置上限时返回 nil
+func (h *RequestHandler) buildToolGroups() (map[string]*toolGroup, map[string]bool) {
+	budget := h.config.Global.ToolBudget
+	if budget <= 0 || h.config.Global.Catalog.Lazy {
+		return nil, nil
+	}
+
+	byTag := make(map[string][]groupOperation)
+	restCount := 0
+	for path, pathItem := range h.openAPISpec.Paths {
+		for method, operation := range pathItem {
+			if !isHTTPMethod(method) || (operation.Deprecated && h.config.Global.HideDeprecated) {
+				continue
+			}
+			tag := untaggedGroup
+			if len(operation.Tags) > 0 {
+				tag = operation.Tags[0]
+			}
+			byTag[tag] = append(byTag[tag], groupOperation{name: toolName(method, path, &operation), operation: operation})
+			restCount++
+		}
+	}
+
+	otherCount := len(h.workflows)
+	if h.graphql != nil {
+		otherCount += len(h.graphql.Tools())
+	}
+	if h.grpc != nil {
+		otherCount += len(h.grpc.Tools())
+	}
+	if h.approval.config.Enabled {
+		otherCount++
+	}
+	otherCount += len(h.catalogTools())
+	if restCount+otherCount <= budget {
+		return nil, nil
+	}
+
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	groups := make(map[string]*toolGroup)
+	grouped := make(map[string]bool)
+	for _, tag := range tags {
+		operations := byTag[tag]
+		if len(operations) < 2 {
+			continue
+		}
+		sort.Slice(operations, func(i, j int) bool { return operations[i].name < operations[j].name })
+
+		group := &toolGroup{name: groupToolName(tag, groups), tag: tag, operations: operations}
+		groups[group.name] = group
+		for _, op := range operations {
+			grouped[op.name] = true
+		}
+	}
+
+	total := restCount - len(grouped) + len(groups) + otherCount
+	logging.Logger.Printf("工具数量 %d 超出上限 %d，已按标签合并为 %d 个分组工具，合并后共 %d 个工具", restCount+otherCount, budget, len(groups), total)
+	if total > budget {
+		logging.Logger.Printf("警告: 合并后的工具数量 %d 仍超出上限 %d，可以使用 -tags/-exclude-tags 减少加载的操作", total, budget)
+	}
+	return groups, grouped
+}
+
+// groupToolName 根据标签生成工具名称，只保留字母和数字，如 "Pull Request" 生成 pull_request_operations；
+// 标签中没有可用字符时使用 group，名称重复时添加序号
+func groupToolName(tag string, existing map[string]*toolGroup) string {
+	base := strings.Join(strings.FieldsFunc(strings.ToLower(tag), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), "_")
+	switch {
+	case tag == untaggedGroup:
+		base = "other"
+	case base == "":
+		base = "group"
+	}
+
+	name := base + "_operations"
+	for i := 2; existing[name] != nil; i++ {
+		name = fmt.Sprintf("%s_%d_operations", base, i)
+	}
+	return name
+}
+
+// groupTools 返回分组工具的定义，按名称排序
+func (h *RequestHandler) groupTools() []map[string]interface{} {
+	names := make([]string, 0, len(h.toolGroups))
+	for name := range h.toolGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tools := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		tools = append(tools, h.toolGroups[name].definition())
+	}
+	return tools
+}
+
+// definition 返回分组工具的定义，描述中列出每个操作的参数 (* 表示必需) 和说明
+func (g *toolGroup) definition() map[string]interface{} {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s 相关的 %d 个操作。通过 operation 选择要调用的操作，arguments 为该操作的参数。\n可用操作:", g.tag, len(g.operations))
+
+	names := make([]string, 0, len(g.operations))
+	for _, op := range g.operations {
+		names = append(names, op.name)
+
+		params := make([]string, 0, len(op.operation.Parameters))
+		for _, param := range op.operation.Parameters {
+			name := param.Name
+			if param.Required {
+				name += "*"
+			}
+			params = append(params, name)
+		}
+		summary := op.operation.Summary
+		if summary == "" {
+			summary = op.operation.Description
+		}
+		fmt.Fprintf(&sb, "\n- %s(%s): %s", op.name, strings.Join(params, ", "), summary)
+	}
+
+	return map[string]interface{}{
+		"name":        g.name,
+		"description": sb.String(),
+		"inputSchema": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"operation": map[string]interface{}{
+					"type":        "string",
+					"enum":        names,
+					"description": "要调用的操作",
+				},
+				"arguments": map[string]interface{}{
+					"type":        "object",
+					"description": "操作的参数，参数名见工具描述",
+				},
+			},
+			"required": []string{"operation"},
+		},
+	}
+}
+
+// route 把分组工具的调用转换为对所选操作的调用
+func (g *toolGroup) route(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallParams, error) {
+	return routeCall(ctx, params, g.contains)
+}
+
+// routeCall 把 {operation, arguments} 形式的调用 (分组工具、callOperation) 转换为对所选操作的调用
+// 参数通常放在 arguments 中，也接受与 operation 并列传入的参数
+func routeCall(ctx context.Context, params *mcp.ToolCallParams, allowed func(name string) bool) (*mcp.ToolCallParams, error) {
+	name, _ := params.Parameters["operation"].(string)
+	if name == "" {
+		return nil, invalidParam(ctx, config.Parameter{Name: "operation"}, "required", "validation.missing_argument", params.Name, "operation")
+	}
+	if !allowed(name) {
+		return nil, invalidParam(ctx, config.Parameter{Name: "operation"}, "enum", "validation.unknown_operation", params.Name, name)
+	}
+
+	arguments := make(map[string]interface{})
+	for key, value := range params.Parameters {
+		if key != "operation" && key != "arguments" {
+			arguments[key] = value
+		}
+	}
+	switch nested := params.Parameters["arguments"].(type) {
+	case map[string]interface{}:
+		for key, value := range nested {
+			arguments[key] = value
+		}
+	case nil:
+	default:
+		return nil, invalidParam(ctx, config.Parameter{Name: "arguments", Schema: config.Schema{Type: "object"}}, "type", "validation.arguments_not_object", params.Name)
+	}
+
+	return &mcp.ToolCallParams{Name: name, Parameters: arguments, Meta: params.Meta}, nil
+}
+
+func (g *toolGroup) contains(name string) bool {
+	for _, op := range g.operations {
+		if op.name == name {
+			return true
+		}
+	}
+	return false
+}
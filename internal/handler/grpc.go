@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// handleGRPC 执行 gRPC 后端提供的工具
+func (h *RequestHandler) handleGRPC(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+	result, status, err := h.grpc.Call(ctx, params.Name, params.Parameters)
+	if err != nil {
+		debug.LogError(ctx, "执行gRPC方法失败", err)
+		return nil, fmt.Errorf("执行gRPC方法失败: %w", err)
+	}
+
+	if status.Code != 0 {
+		message := status.Message
+		if message == "" {
+			message = fmt.Sprintf("gRPC方法 %s 返回 %s", params.Name, status.Name())
+		}
+		return &mcp.ToolCallResult{
+			Type:   "error",
+			Status: "error",
+			Result: map[string]interface{}{
+				"message": message,
+				"code":    status.Code,
+				"status":  status.Name(),
+			},
+		}, nil
+	}
+
+	return &mcp.ToolCallResult{
+		Type:   "success",
+		Status: "success",
+		Result: result,
+	}, nil
+}
@@ -2,18 +2,27 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mcp2rest/internal/auth"
+	"github.com/mcp2rest/internal/cache"
 	"github.com/mcp2rest/internal/config"
 	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/graphql"
+	"github.com/mcp2rest/internal/grpc"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
 	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/internal/quota"
 	"github.com/mcp2rest/internal/transformer"
 	"github.com/mcp2rest/pkg/mcp"
 )
@@ -25,10 +34,53 @@ type RequestHandler struct {
 	httpClient  *http.Client
 	transformer *transformer.ResponseTransformer
 	auth        *auth.AuthManager
+	approval    *approvalGate
+	graphql     *graphql.Backend
+	grpc        *grpc.Backend
+	tls         *tlsMonitor
+	identity    *identity
+	// upstream 直接请求上游的执行后端，backends 为操作通过 x-mcp-backend 选用的后端
+	upstream Backend
+	backends *backendRegistry
+	// examples dry_run 时代替上游返回规范中的示例响应
+	examples Backend
+	// cache 带校验器的 GET 响应缓存，未启用时为 nil
+	cache *cache.Store
+	// coalescer 合并同时进行的相同 GET 请求，未启用时为 nil
+	coalescer *coalescer
+	// workflows 组合工具，workflowOrder 保持配置中的顺序
+	workflows     map[string]*workflow
+	workflowOrder []string
+	// toolGroups 工具数量超出上限时按标签合并的分组工具，groupedOperations 为已合并的操作
+	toolGroups        map[string]*toolGroup
+	groupedOperations map[string]bool
+	// quotas 工具调用配额，未配置时为 nil
+	quotas *quota.Tracker
+	// descriptionExamples 按工具名称索引的调用示例，附加在工具描述末尾，未配置 description_examples 时为 nil
+	descriptionExamples map[string]string
+	// middleware 工具调用中间件，chain 为包装后的处理函数，未使用中间件时为 nil
+	middleware      []Middleware
+	middlewareMutex sync.Mutex
+	chain           atomic.Pointer[ToolHandler]
 }
 
 // NewRequestHandler 创建新的请求处理器
 func NewRequestHandler(cfg *config.Config, spec *config.OpenAPISpec) (*RequestHandler, error) {
+	// 加载自定义转换插件
+	for _, pluginPath := range cfg.Global.TransformPlugins {
+		if err := transformer.LoadPlugin(pluginPath); err != nil {
+			return nil, fmt.Errorf("加载转换插件失败: %w", err)
+		}
+	}
+
+	// global.auth 和认证配置文件中的条目覆盖同名安全方案的认证配置
+	openapi.ApplyAuthConfigs(spec, cfg.Global.Auth)
+
+	// 服务器地址中的变量按 global.server_variables 和规范中的默认值替换
+	if err := openapi.ApplyServerVariables(spec, cfg.Global.ServerVariables); err != nil {
+		return nil, err
+	}
+
 	transformer, err := transformer.NewResponseTransformer()
 	if err != nil {
 		return nil, fmt.Errorf("创建响应转换器失败: %w", err)
@@ -39,48 +91,309 @@ func NewRequestHandler(cfg *config.Config, spec *config.OpenAPISpec) (*RequestHa
 		return nil, fmt.Errorf("创建身份验证管理器失败: %w", err)
 	}
 
-	return &RequestHandler{
+	transport, tlsMonitor, err := newTransport(cfg.Global)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Timeout: cfg.Global.Timeout, Transport: transport}
+
+	identity, err := newIdentity(cfg.Global.Identity, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// 可选的 GraphQL 后端
+	var graphqlBackend *graphql.Backend
+	if cfg.Global.GraphQL != nil {
+		graphqlBackend, err = graphql.NewBackend(*cfg.Global.GraphQL, httpClient, authManager)
+		if err != nil {
+			return nil, fmt.Errorf("创建GraphQL后端失败: %w", err)
+		}
+		graphqlBackend.SetIdentity(identity.header())
+	}
+
+	// 可选的 gRPC 后端
+	var grpcBackend *grpc.Backend
+	if cfg.Global.GRPC != nil {
+		grpcBackend, err = grpc.NewBackend(*cfg.Global.GRPC, cfg.Global.Timeout, authManager)
+		if err != nil {
+			return nil, fmt.Errorf("创建gRPC后端失败: %w", err)
+		}
+	}
+
+	upstream := &upstreamBackend{client: httpClient}
+	backends, err := newBackendRegistry(cfg.Global.Backends, spec, upstream)
+	if err != nil {
+		return nil, fmt.Errorf("创建执行后端失败: %w", err)
+	}
+
+	h := &RequestHandler{
 		config:      cfg,
 		openAPISpec: spec,
-		httpClient:  &http.Client{Timeout: cfg.Global.Timeout},
+		httpClient:  httpClient,
 		transformer: transformer,
 		auth:        authManager,
-	}, nil
+		approval:    newApprovalGate(cfg.Global.Approval),
+		graphql:     graphqlBackend,
+		grpc:        grpcBackend,
+		tls:         tlsMonitor,
+		identity:    identity,
+		upstream:    upstream,
+		backends:    backends,
+		examples:    &exampleBackend{spec: spec},
+		// 工具描述中的调用示例只在启动时生成一次
+		descriptionExamples: buildDescriptionExamples(spec, cfg.Global.DescriptionExamples),
+	}
+	if cfg.Global.Cache.Enabled {
+		h.cache = cache.New(cfg.Global.Cache.MaxEntries)
+	}
+	if cfg.Global.Coalesce {
+		h.coalescer = newCoalescer()
+	}
+	if cfg.Global.DryRun {
+		logging.Logger.Printf("演练模式: REST 操作不请求上游，返回规范中的示例响应")
+	}
+
+	// 组合工具引用的操作需要在所有后端创建后检查
+	h.workflows, h.workflowOrder, err = h.loadWorkflows(cfg.Global.Workflows)
+	if err != nil {
+		return nil, fmt.Errorf("加载组合工具失败: %w", err)
+	}
+
+	h.toolGroups, h.groupedOperations = h.buildToolGroups()
+
+	// 重定向按请求的策略处理，GraphQL 后端共用同一客户端时使用全局策略
+	httpClient.CheckRedirect = h.checkRedirect
+
+	if h.quotas, err = quota.New(cfg.Global.Quotas); err != nil {
+		return nil, fmt.Errorf("创建调用配额失败: %w", err)
+	}
+
+	return h, nil
 }
 
-// HandleRequest 处理工具调用请求
-func (h *RequestHandler) HandleRequest(params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+// handleRequest 处理工具调用请求，是中间件链的最内层
+func (h *RequestHandler) handleRequest(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
 	// 记录调试信息
-	debug.LogInfo("开始处理MCP工具调用", map[string]interface{}{
+	debug.LogInfo(ctx, "开始处理MCP工具调用", map[string]interface{}{
 		"tool_name": params.Name,
 		"params":    params.Parameters,
 	})
+	ctx = withProgressToken(ctx, params.ProgressToken())
+
+	// 确认等待人工确认的操作
+	if params.Name == ConfirmToolName && h.approval.config.Enabled {
+		return h.handleConfirm(ctx, params)
+	}
+
+	// 组合工具
+	if wf, exists := h.workflows[params.Name]; exists {
+		return h.handleWorkflow(ctx, wf, params)
+	}
+
+	// GraphQL 后端提供的工具
+	if h.graphql != nil && h.graphql.HasTool(params.Name) {
+		if err := h.takeQuota(ctx, params.Name); err != nil {
+			return nil, err
+		}
+		return h.handleGraphQL(ctx, params)
+	}
+
+	// gRPC 后端提供的工具
+	if h.grpc != nil && h.grpc.HasTool(params.Name) {
+		if err := h.takeQuota(ctx, params.Name); err != nil {
+			return nil, err
+		}
+		return h.handleGRPC(ctx, params)
+	}
+
+	// 操作目录工具
+	if h.isCatalogTool(params.Name) {
+		if err := h.takeQuota(ctx, params.Name); err != nil {
+			return nil, err
+		}
+		return h.handleCatalog(ctx, params)
+	}
+
+	// 分组工具和 callOperation 转换为对所选操作的调用
+	if group, exists := h.toolGroups[params.Name]; exists {
+		routed, err := group.route(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		params = routed
+	} else if params.Name == CallToolName && h.config.Global.Catalog.Lazy {
+		routed, err := routeCall(ctx, params, h.hasOperation)
+		if err != nil {
+			return nil, err
+		}
+		params = routed
+	}
 
 	// 根据操作ID查找操作
 	operation, method, path, err := openapi.GetOperationByID(h.openAPISpec, params.Name)
 	if err != nil {
-		debug.LogError("查找操作失败", err)
-		return nil, fmt.Errorf("查找操作失败: %w", err)
+		debug.LogError(ctx, "查找操作失败", err)
+		return nil, i18n.Errorf(ctx, "handler.operation_not_found", params.Name)
+	}
+
+	// 分组工具和 callOperation 所选的操作同样受客户端的访问控制限制
+	if !toolAllowed(ctx, params.Name, readOnlyMethod(method)) {
+		return nil, i18n.Errorf(ctx, "handler.tool_forbidden", params.Name)
 	}
 
+	// 已弃用的操作仍然可以调用，但需要记录警告
+	if operation.Deprecated {
+		logging.FromContext(ctx).Printf("警告: 工具 %s 对应的操作 %s %s 已弃用", params.Name, method, path)
+	}
+
+	// 向客户端请求缺少的必需参数
+	params.Parameters, err = h.elicitMissingParameters(ctx, params.Name, operation, method, params.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建请求之前检查参数，所有问题一次返回给客户端
+	if err := h.validateArguments(ctx, operation, params.Parameters); err != nil {
+		return nil, err
+	}
+
+	// 破坏性操作需要先获得确认
+	if h.approval.requiresApproval(params.Name, method) {
+		return h.requestApproval(ctx, params, operation, method, path)
+	}
+
+	// 分组工具和 callOperation 按所选的操作计入配额
+	return h.executeWithQuota(ctx, params.Name, operation, method, path, params.Parameters)
+}
+
+// executeOperation 执行OpenAPI操作对应的HTTP请求
+func (h *RequestHandler) executeOperation(ctx context.Context, operation *config.Operation, method, path string, parameters map[string]interface{}) (*mcp.ToolCallResult, error) {
+	ctx = withBackendCall(ctx, &backendCall{
+		tool:       toolName(method, path, operation),
+		method:     method,
+		path:       path,
+		parameters: parameters,
+	})
+	ctx = h.withProvenance(ctx)
+
 	// 构建HTTP请求
-	req, err := h.buildHTTPRequest(operation, method, path, params.Parameters)
+	req, err := h.buildHTTPRequest(ctx, operation, method, path, parameters)
 	if err != nil {
-		debug.LogError("构建HTTP请求失败", err)
-		return nil, fmt.Errorf("构建HTTP请求失败: %w", err)
+		debug.LogError(ctx, "构建HTTP请求失败", err)
+		return nil, i18n.Errorf(ctx, "handler.build_request_failed", err)
+	}
+	req = req.WithContext(ctx)
+
+	// 缺少必需参数等构建失败的调用不计入配额
+	if err := h.chargeQuota(ctx); err != nil {
+		return nil, err
+	}
+
+	sentAt := time.Now()
+	resp, body, err := h.sendRequest(req, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	// 凭据过期时刷新后用新凭据重新构建并发送一次请求
+	if resp.StatusCode == http.StatusUnauthorized && h.refreshCredentials(ctx, operation, sentAt) {
+		req, err = h.buildHTTPRequest(ctx, operation, method, path, parameters)
+		if err != nil {
+			return nil, i18n.Errorf(ctx, "handler.build_request_failed", err)
+		}
+		req = req.WithContext(ctx)
+		resp, body, err = h.sendRequest(req, operation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 检查状态码
+	if !isSuccessStatus(ctx, operation, resp.StatusCode) || (operation.XML.IsSOAP() && soapFaultMessage(body) != "") {
+		return h.attachProvenance(ctx, h.buildErrorResult(operation, resp, body, parameters), resp, operation), nil
+	}
+
+	// 轮询异步操作直到完成
+	if shouldPoll(operation, resp) {
+		var failed bool
+		resp, body, failed, err = h.pollOperation(ctx, req, resp, body, operation)
+		if err != nil {
+			return nil, err
+		}
+		if failed || !isFollowUpSuccess(operation, resp.StatusCode) {
+			return h.attachProvenance(ctx, h.buildErrorResult(operation, resp, body, parameters), resp, operation), nil
+		}
+	}
+
+	// 创建资源后根据 Location 获取新建的资源
+	if h.shouldFollowLocation(operation, req.Method, resp, body) {
+		resp, body = h.followLocation(req, resp, body, operation)
 	}
 
+	// HEAD 和 OPTIONS 操作的结果是响应元数据，不经过响应模式校验和转换
+	if method == "HEAD" || method == "OPTIONS" {
+		return h.attachProvenance(ctx, &mcp.ToolCallResult{
+			Type:   "success",
+			Status: "success",
+			Result: metadataResult(method, resp, body),
+		}, resp, operation), nil
+	}
+
+	// 校验响应模式，在转换之前进行
+	mismatches := h.validateResponse(ctx, operation, resp, body)
+
+	// 转换响应
+	result, err := h.transformer.TransformResponse(body, operation.Transform, &transformer.ResponseContext{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Params:     parameters,
+		Sample:     templateSampler(ctx),
+	})
+	if err != nil {
+		debug.LogError(ctx, "转换响应失败", err)
+		return nil, fmt.Errorf("转换响应失败: %w", err)
+	}
+
+	toolResult := &mcp.ToolCallResult{
+		Type:   "success",
+		Status: "success",
+		Result: withHeaders(result, h.exposedHeaders(operation, resp.Header)),
+	}
+	if len(mismatches) > 0 {
+		toolResult.Meta = map[string]interface{}{"schemaMismatches": mismatches}
+	}
+	return h.attachProvenance(ctx, toolResult, resp, operation), nil
+}
+
+// sendRequest 应用身份验证和默认头后发送请求，返回响应和完整的响应体
+func (h *RequestHandler) sendRequest(req *http.Request, operation *config.Operation) (*http.Response, []byte, error) {
+	ctx := req.Context()
+
 	// 记录HTTP请求详情
-	debug.LogHTTPRequest(map[string]interface{}{
+	debug.LogHTTPRequest(ctx, map[string]interface{}{
 		"method":  req.Method,
 		"url":     req.URL.String(),
 		"headers": req.Header,
 	})
 
-	// 添加身份验证
-	if err := h.applyAuthentication(req, operation); err != nil {
-		debug.LogError("应用身份验证失败", err)
-		return nil, fmt.Errorf("应用身份验证失败: %w", err)
+	// 添加身份验证，不访问上游的执行后端不需要凭据
+	if h.needsCredentials(operation) {
+		if err := h.applyAuthentication(req, operation); err != nil {
+			debug.LogError(ctx, "应用身份验证失败", err)
+			return nil, nil, fmt.Errorf("应用身份验证失败: %w", err)
+		}
+		// NTLM/Negotiate 需要与上游握手，凭据随请求上下文交给上游执行后端
+		if _, authConfig, _ := h.operationAuth(operation); auth.UsesHandshake(authConfig) {
+			handshake, err := auth.NewHandshake(authConfig)
+			if err != nil {
+				return nil, nil, fmt.Errorf("应用身份验证失败: %w", err)
+			}
+			req = req.WithContext(auth.WithHandshake(ctx, handshake))
+		}
+		if names := h.queryCredentials(operation); len(names) > 0 {
+			req = req.WithContext(withQueryCredentials(req.Context(), names))
+		}
 	}
 
 	// 添加默认头
@@ -88,122 +401,215 @@ func (h *RequestHandler) HandleRequest(params *mcp.ToolCallParams) (*mcp.ToolCal
 		req.Header.Set(key, value)
 	}
 
-	// 发送请求
-	resp, err := h.httpClient.Do(req)
+	// 客户端标识，未配置时只补充 mcp2rest 的 User-Agent，不使用 Go 的默认值
+	h.identity.apply(req.Header)
+
+	// 按规范中声明的响应类型协商内容，优先于默认头中的 Accept
+	if accept := acceptHeader(operation); accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	// 显式请求压缩响应并自行解压，未通过默认头配置时使用 gzip 和 deflate
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	// 携带关联ID，便于在上游日志中追踪同一次工具调用
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+
+	// 剩余时间通过请求头告知上游，并作为本次请求的截止时间
+	req, cancel, err := h.applyDeadline(req)
 	if err != nil {
-		debug.LogError("发送HTTP请求失败", err)
-		return nil, fmt.Errorf("发送HTTP请求失败: %w", err)
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
+	defer cancel()
 
-	// 读取响应体
-	body, err := ioutil.ReadAll(resp.Body)
+	// unix:// 上游改写为经由套接字发送的请求
+	if err := rewriteUnixRequest(req); err != nil {
+		return nil, nil, err
+	}
+
+	// 相同的 GET 请求正在进行时等待并复用其响应
+	key := h.coalesceKey(req, operation)
+	if key == "" {
+		return h.doRequest(req, operation)
+	}
+	resp, body, shared, err := h.coalescer.do(ctx, key, func() (*http.Response, []byte, error) {
+		return h.doRequest(req, operation)
+	})
+	if shared {
+		logging.FromContext(ctx).Printf("复用正在进行的相同请求的响应: %s %s", req.Method, redactQuery(req.URL, h.queryCredentials(operation)))
+		if p := provenanceFrom(ctx); p != nil {
+			p.shared = true
+		}
+	}
+	return resp, body, err
+}
+
+// doRequest 发送已完成身份验证和请求头设置的请求，读取、解压并规范化响应体
+func (h *RequestHandler) doRequest(req *http.Request, operation *config.Operation) (*http.Response, []byte, error) {
+	ctx := req.Context()
+
+	// 缓存中有该请求的响应时发送条件请求
+	key := h.cacheKey(req, operation)
+	entry := h.applyValidators(req, key)
+
+	// 由操作选用的执行后端处理，未选用时发送给上游
+	backend, err := h.backendFor(operation)
+	if err != nil {
+		return nil, nil, err
+	}
+	if backend == nil {
+		backend = h.upstream
+	} else {
+		debug.LogInfo(ctx, "使用执行后端", map[string]interface{}{"backend": h.backendName(operation)})
+	}
+	req = req.WithContext(withRedirectPolicy(ctx, h.redirectPolicyFor(operation)))
+	resp, err := backend.Execute(req.Context(), newBackendCall(req, operation))
+	if err != nil && backend != h.upstream {
+		debug.LogError(ctx, "执行后端处理请求失败", err)
+		return nil, nil, fmt.Errorf("执行后端 %s 处理请求失败: %w", h.backendName(operation), err)
+	}
 	if err != nil {
-		debug.LogError("读取响应体失败", err)
-		return nil, fmt.Errorf("读取响应体失败: %w", err)
+		debug.LogError(ctx, "发送HTTP请求失败", err)
+		return nil, nil, fmt.Errorf("发送HTTP请求失败: %w", err)
 	}
+	defer resp.Body.Close()
+	countTraffic(ctx, req.ContentLength, resp)
+
+	// 解压后读取响应体，超过大小上限的部分不再读取，限制的是解压后的大小；
+	// 未压缩的响应声明的长度已经超过上限时不再读取。HEAD 响应和 204/304 没有响应体，
+	// 其 Content-Length 和 Content-Encoding 描述的是对应 GET 的响应体，原样保留
+	var body []byte
+	if responseHasBody(req.Method, resp.StatusCode) {
+		limit, setting := h.responseLimit(operation)
+		if resp.ContentLength > limit && resp.Header.Get("Content-Encoding") == "" {
+			debug.LogError(ctx, "读取响应体失败", responseTooLarge(limit, setting))
+			return nil, nil, fmt.Errorf("读取响应体失败: %w", responseTooLarge(limit, setting))
+		}
+		reader, err := decodeBody(resp)
+		if err != nil {
+			debug.LogError(ctx, "解压响应体失败", err)
+			return nil, nil, err
+		}
+		body, err = readLimited(reader, limit, setting)
+		if err != nil {
+			debug.LogError(ctx, "读取响应体失败", err)
+			return nil, nil, fmt.Errorf("读取响应体失败: %w", err)
+		}
+	}
+	if p := provenanceFrom(ctx); p != nil {
+		p.backend = h.backendName(operation)
+		if key != "" {
+			p.cache = "miss"
+			if entry != nil && resp.StatusCode == http.StatusNotModified {
+				p.cache = "hit"
+			}
+		}
+	}
+	resp, body = h.revalidate(key, entry, resp, body)
+
 	// 记录HTTP响应详情
 	if resp != nil {
 		resp.Body = io.NopCloser(bytes.NewBuffer(body))
 		debug.LogHTTPResponse(resp)
 	}
 
-	// 检查状态码
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		errorMsg := fmt.Sprintf("API返回错误状态码: %d", resp.StatusCode)
-		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-			errorMsg = "客户端错误"
-		} else if resp.StatusCode >= 500 {
-			errorMsg = "服务器错误"
-		}
-		debug.LogError("API返回错误状态码", fmt.Errorf("状态码: %d, 消息: %s", resp.StatusCode, errorMsg))
-		return &mcp.ToolCallResult{
-			Type:   "error",
-			Status: "error",
-			Result: map[string]interface{}{
-				"message": errorMsg,
-				"code":    resp.StatusCode,
-				"body":    string(body),
-			},
-		}, nil
-	}
+	// 按实际的 Content-Type 把 XML、CSV、NDJSON 和纯文本响应转换为 JSON 后再交给转换器
+	body = normalizeBody(resp, body, operation)
 
-	// 转换响应
-	result, err := h.transformer.TransformResponse(body, operation.Responses)
-	if err != nil {
-		debug.LogError("转换响应失败", err)
-		return nil, fmt.Errorf("转换响应失败: %w", err)
-	}
-
-	return &mcp.ToolCallResult{
-		Type:   "success",
-		Status: "success",
-		Result: result,
-	}, nil
+	return resp, body, nil
 }
 
-// buildHTTPRequest 构建HTTP请求
-func (h *RequestHandler) buildHTTPRequest(operation *config.Operation, method, path string, params map[string]interface{}) (*http.Request, error) {
+// buildHTTPRequest 构建HTTP请求，缺少必需参数的错误按上下文中的语言返回
+func (h *RequestHandler) buildHTTPRequest(ctx context.Context, operation *config.Operation, method, path string, params map[string]interface{}) (*http.Request, error) {
 	// 获取基础URL
-	baseURL := openapi.GetBaseURL(h.openAPISpec)
+	baseURL := openapi.OperationBaseURL(h.openAPISpec, operation)
 	if baseURL == "" {
 		return nil, fmt.Errorf("OpenAPI规范中未定义服务器URL")
 	}
 
-	// 构建完整URL
-	fullURL := baseURL + path
+	// 构建完整URL，服务器地址中的路径前缀保留在操作路径之前
+	fullURL := openapi.JoinURL(baseURL, path)
+
+	// 去掉值为空的可选参数，查询字符串、请求头和请求体都不再包含这些参数
+	params = h.pruneParams(operation, params)
+
+	// 日期时间参数转换为规范要求的格式
+	params, err := normalizeDates(ctx, operation, params)
+	if err != nil {
+		return nil, err
+	}
 
 	// 处理路径参数
 	for _, param := range operation.Parameters {
 		if param.In == "path" {
-			if value, exists := params[param.Name]; exists {
-				fullURL = strings.ReplaceAll(fullURL, "{"+param.Name+"}", fmt.Sprintf("%v", value))
+			if value, ok := paramValue(param, params); ok {
+				encoded, err := encodePathParam(ctx, param, value, h.config.Global.StrictPathParams)
+				if err != nil {
+					return nil, err
+				}
+				fullURL = strings.ReplaceAll(fullURL, "{"+param.Name+"}", encoded)
 			} else if param.Required {
-				return nil, fmt.Errorf("缺少必需的路径参数: %s", param.Name)
+				return nil, invalidParam(ctx, param, "required", "validation.missing_path_param", param.Name)
 			}
 		}
 	}
 
-	// 处理查询参数
-	if method == "GET" || method == "DELETE" {
-		queryParams := url.Values{}
-		for _, param := range operation.Parameters {
-			if param.In == "query" {
-				if value, exists := params[param.Name]; exists {
-					queryParams.Set(param.Name, fmt.Sprintf("%v", value))
-				} else if param.Required {
-					return nil, fmt.Errorf("缺少必需的查询参数: %s", param.Name)
-				}
+	// 处理查询参数，写操作的查询参数同样放在 URL 中，不进入请求体
+	queryParams := url.Values{}
+	for _, param := range operation.Parameters {
+		if param.In == "query" {
+			// 未提供时使用规范中声明的默认值，上游的默认值可能与规范不同
+			if value, ok := paramValue(param, params); ok {
+				queryParams.Set(param.Name, fmt.Sprintf("%v", value))
+			} else if param.Required {
+				return nil, invalidParam(ctx, param, "required", "validation.missing_query_param", param.Name)
 			}
 		}
-		if len(queryParams) > 0 {
-			fullURL += "?" + queryParams.Encode()
+	}
+	if len(queryParams) > 0 {
+		separator := "?"
+		if strings.Contains(fullURL, "?") {
+			// 服务器地址自带查询字符串
+			separator = "&"
 		}
+		fullURL += separator + queryParams.Encode()
 	}
 
 	// 创建请求
 	var req *http.Request
-	var err error
 
-	if method == "POST" || method == "PUT" || method == "PATCH" {
+	if hasRequestBody(method) {
 		// 处理请求体
 		var body []byte
-		if operation.RequestBody.Content != nil {
-			// 构建请求体
-			requestBody := make(map[string]interface{})
-			for _, param := range operation.Parameters {
-				if param.In == "body" {
-					if value, exists := params[param.Name]; exists {
-						requestBody[param.Name] = value
-					} else if param.Required {
-						return nil, fmt.Errorf("缺少必需的请求体参数: %s", param.Name)
-					}
-				}
+		contentType := "application/json"
+		if operation.XML != nil {
+			body, contentType, err = h.buildXMLBody(operation, method, path, params)
+			if err != nil {
+				return nil, err
 			}
-
-			// 如果没有从参数中获取到请求体，尝试使用整个参数对象
-			if len(requestBody) == 0 && len(params) > 0 {
-				requestBody = params
+		} else if operation.BodyTemplate != "" {
+			if body, err = h.buildTemplateBody(operation, params); err != nil {
+				return nil, err
+			}
+		} else if format := patchFormat(operation); format != "" {
+			if body, contentType, err = buildPatchBody(ctx, operation, format, params); err != nil {
+				return nil, err
+			}
+		} else if mediaType := rawBodyMediaType(operation); mediaType != "" {
+			// 文本、XML 和二进制请求体原样发送
+			if body, err = buildRawBody(ctx, operation, mediaType, params); err != nil {
+				return nil, err
+			}
+			contentType = mediaType
+		} else if operation.RequestBody.Content != nil {
+			// 构建请求体，只包含真正的请求体字段
+			requestBody, err := bodyFields(ctx, operation, params)
+			if err != nil {
+				return nil, err
 			}
 
 			body, err = json.Marshal(requestBody)
@@ -212,13 +618,20 @@ func (h *RequestHandler) buildHTTPRequest(operation *config.Operation, method, p
 			}
 		}
 
+		body, compressed := h.compressRequestBody(operation, contentType, body)
 		req, err = http.NewRequest(method, fullURL, bytes.NewBuffer(body))
 		if err != nil {
 			return nil, fmt.Errorf("创建HTTP请求失败: %w", err)
 		}
+		if compressed {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
 
 		// 设置Content-Type
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
+		if operation.XML.IsSOAP() && operation.XML.SOAPVersion != "1.2" {
+			req.Header.Set("SOAPAction", fmt.Sprintf(`"%s"`, operation.XML.SOAPAction))
+		}
 	} else {
 		req, err = http.NewRequest(method, fullURL, nil)
 		if err != nil {
@@ -226,13 +639,38 @@ func (h *RequestHandler) buildHTTPRequest(operation *config.Operation, method, p
 		}
 	}
 
+	// 处理请求头和 Cookie 参数
+	if err := applyLocatedParams(ctx, req, operation, params); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
-// applyAuthentication 应用身份验证
+// applyAuthentication 应用身份验证，凭据即将过期且配置了刷新时先刷新
 func (h *RequestHandler) applyAuthentication(req *http.Request, operation *config.Operation) error {
+	schemeName, authConfig, err := h.operationAuth(operation)
+	if err != nil || authConfig == nil {
+		return err
+	}
+
+	if h.auth.Expired(schemeName, authConfig) {
+		ctx := req.Context()
+		if err := h.auth.Refresh(ctx, schemeName, authConfig, time.Now()); err != nil {
+			logging.FromContext(ctx).Printf("%s 的凭据即将过期，%v", schemeName, err)
+		} else {
+			logging.FromContext(ctx).Printf("%s 的凭据即将过期，已刷新", schemeName)
+		}
+	}
+
+	// 应用认证
+	return h.auth.ApplyAuth(req, authConfig)
+}
+
+// operationAuth 返回操作第一个安全要求中的安全方案名称和认证配置，无需身份验证时配置为 nil
+func (h *RequestHandler) operationAuth(operation *config.Operation) (string, *config.AuthConfig, error) {
 	if len(operation.Security) == 0 {
-		return nil // 无需身份验证
+		return "", nil, nil // 无需身份验证
 	}
 
 	// 获取第一个安全要求
@@ -241,35 +679,52 @@ func (h *RequestHandler) applyAuthentication(req *http.Request, operation *confi
 		// 获取安全方案
 		securityScheme, err := openapi.GetSecurityScheme(h.openAPISpec, schemeName)
 		if err != nil {
-			return fmt.Errorf("获取安全方案失败: %w", err)
+			return "", nil, fmt.Errorf("获取安全方案失败: %w", err)
 		}
 
 		// 创建认证配置
-		authConfig := &config.AuthConfig{}
-		switch securityScheme.Type {
-		case "apiKey":
-			authConfig.Type = "api_key"
-			authConfig.HeaderName = securityScheme.Name
-			authConfig.KeyEnv = fmt.Sprintf("%s_API_KEY", strings.ToUpper(schemeName))
-		case "http":
-			if securityScheme.Scheme == "bearer" {
-				authConfig.Type = "bearer"
-				authConfig.TokenEnv = fmt.Sprintf("%s_TOKEN", strings.ToUpper(schemeName))
-			} else if securityScheme.Scheme == "basic" {
-				authConfig.Type = "basic"
-				authConfig.Username = ""
-				authConfig.Password = ""
-			}
-		case "oauth2":
-			authConfig.Type = "oauth2"
-			authConfig.TokenEnv = fmt.Sprintf("%s_TOKEN", strings.ToUpper(schemeName))
-		}
+		return schemeName, openapi.AuthConfigForScheme(schemeName, securityScheme), nil
+	}
+
+	return "", nil, nil
+}
 
-		// 应用认证
-		return h.auth.ApplyAuth(req, authConfig)
+// refreshCredentials 上游返回 401 后刷新操作使用的凭据，成功时返回 true，调用方用新凭据重试一次
+// sentAt 为被拒绝的请求的发出时间，期间已被其他调用刷新过时直接重试
+func (h *RequestHandler) refreshCredentials(ctx context.Context, operation *config.Operation, sentAt time.Time) bool {
+	if !h.needsCredentials(operation) {
+		return false
 	}
+	schemeName, authConfig, err := h.operationAuth(operation)
+	if err != nil || !auth.CanRefresh(authConfig) {
+		return false
+	}
+
+	logger := logging.FromContext(ctx)
+	if err := h.auth.Refresh(ctx, schemeName, authConfig, sentAt); err != nil {
+		logger.Printf("上游返回 401，%v", err)
+		return false
+	}
+	logger.Printf("上游返回 401，已刷新 %s 的凭据，重试请求", schemeName)
+	return true
+}
 
-	return nil
+// operationAnnotations 按 HTTP 方法生成工具注解 (2025-03-26 起的协议版本)，提示客户端操作是否只读、可重复执行
+func operationAnnotations(method string, operation *config.Operation) map[string]interface{} {
+	method = strings.ToUpper(method)
+	readOnly := readOnlyMethod(method)
+	annotations := map[string]interface{}{
+		"readOnlyHint":   readOnly,
+		"idempotentHint": readOnly || method == "PUT" || method == "DELETE",
+		"openWorldHint":  true,
+	}
+	if !readOnly {
+		annotations["destructiveHint"] = method == "DELETE"
+	}
+	if operation.Summary != "" {
+		annotations["title"] = operation.Summary
+	}
+	return annotations
 }
 
 // GetAvailableTools 获取可用的工具列表
@@ -286,45 +741,60 @@ func (h *RequestHandler) GetAvailableTools() []map[string]interface{} {
 				continue
 			}
 
-			// 生成操作 ID
-			operationID := generateOperationID(method, path)
+			// 根据配置隐藏已弃用的操作
+			if operation.Deprecated && h.config.Global.HideDeprecated {
+				continue
+			}
 
-			// 预分配 map 容量
-			tool := make(map[string]interface{}, 3)
-			inputSchema := make(map[string]interface{}, 3)
+			operationID := toolName(method, path, &operation)
+
+			// 超出工具数量上限时按标签合并到分组工具中，按需模式下通过目录工具发现和调用
+			if h.groupedOperations[operationID] || h.config.Global.Catalog.Lazy {
+				continue
+			}
 
 			// 构建工具信息
+			tool := make(map[string]interface{}, 3)
 			tool["name"] = operationID
 			tool["description"] = operation.Description
+			if operation.Deprecated {
+				tool["description"] = "[已弃用] " + operation.Description
+			}
+			if example := h.descriptionExamples[operationID]; example != "" {
+				tool["description"] = tool["description"].(string) + "\n\n" + example
+			}
+			tool["inputSchema"] = operationInputSchema(&operation)
+			if operation.Summary != "" {
+				tool["title"] = operation.Summary
+			}
+			tool["annotations"] = operationAnnotations(method, &operation)
 
-			inputSchema["type"] = "object"
-			inputSchema["properties"] = make(map[string]interface{})
-			inputSchema["required"] = make([]string, 0)
+			tools = append(tools, tool)
+		}
+	}
 
-			tool["inputSchema"] = inputSchema
+	// 按标签合并的分组工具
+	tools = append(tools, h.groupTools()...)
 
-			// 添加参数信息
-			if len(operation.Parameters) > 0 {
-				properties := make(map[string]interface{}, len(operation.Parameters))
-				required := make([]string, 0, len(operation.Parameters))
+	// 操作目录工具
+	tools = append(tools, h.catalogTools()...)
 
-				for _, param := range operation.Parameters {
-					properties[param.Name] = map[string]interface{}{
-						"type":        getSchemaType(param.Schema),
-						"description": param.Description,
-					}
+	// GraphQL 后端提供的工具
+	if h.graphql != nil {
+		tools = append(tools, h.graphql.Tools()...)
+	}
 
-					if param.Required {
-						required = append(required, param.Name)
-					}
-				}
+	// gRPC 后端提供的工具
+	if h.grpc != nil {
+		tools = append(tools, h.grpc.Tools()...)
+	}
 
-				inputSchema["properties"] = properties
-				inputSchema["required"] = required
-			}
+	// 组合工具
+	tools = append(tools, h.workflowTools()...)
 
-			tools = append(tools, tool)
-		}
+	// 启用确认门控时提供确认工具
+	if h.approval.config.Enabled {
+		tools = append(tools, confirmToolDefinition())
 	}
 
 	return tools
@@ -337,6 +807,47 @@ func isHTTPMethod(method string) bool {
 		method == "PATCH" || method == "HEAD" || method == "OPTIONS" || method == "TRACE"
 }
 
+// operationInputSchema 根据操作参数生成工具的输入 Schema
+func operationInputSchema(operation *config.Operation) map[string]interface{} {
+	properties := make(map[string]interface{}, len(operation.Parameters))
+	required := make([]string, 0, len(operation.Parameters))
+
+	for _, param := range operation.Parameters {
+		property := map[string]interface{}{
+			"type":        getSchemaType(param.Schema),
+			"description": param.Description,
+		}
+		if param.Schema.Default != nil {
+			property["default"] = param.Schema.Default
+		}
+		properties[param.Name] = property
+
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+	if name, property := rawBodyProperty(operation); property != nil {
+		properties[name] = property
+		if operation.RequestBody.Required {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// toolName 返回操作对应的工具名称：规范中的 operationId，未声明时根据方法和路径生成
+func toolName(method, path string, operation *config.Operation) string {
+	if operation.OperationID != "" {
+		return operation.OperationID
+	}
+	return generateOperationID(method, path)
+}
+
 // generateOperationID 根据HTTP方法和路径生成操作ID
 func generateOperationID(method, path string) string {
 	// 移除路径开头的斜杠
@@ -387,3 +898,24 @@ func getSchemaType(schema config.Schema) string {
 	// 默认类型
 	return "string"
 }
+
+// requestContext 返回响应对应请求的上下文，用于在只持有响应的地方记录关联ID
+func requestContext(resp *http.Response) context.Context {
+	if resp != nil && resp.Request != nil {
+		return resp.Request.Context()
+	}
+	return context.Background()
+}
+
+// readLimited 读取响应体，超过 limit 时停止读取并返回错误，避免把超大响应整体读入内存
+// setting 为限制来源的配置项，用于错误消息
+func readLimited(body io.Reader, limit int64, setting string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, responseTooLarge(limit, setting)
+	}
+	return data, nil
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// exposedHeaders 返回全局和操作上配置为暴露给客户端的响应头，未返回的响应头会被忽略
+func (h *RequestHandler) exposedHeaders(operation *config.Operation, header http.Header) map[string]string {
+	names := append(append([]string{}, h.config.Global.ExposeHeaders...), operation.ExposeHeaders...)
+	if len(names) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(names))
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// withHeaders 将暴露的响应头附加到结果中，结果变为 {"data": ..., "headers": {...}}
+func withHeaders(result interface{}, headers map[string]string) interface{} {
+	if len(headers) == 0 {
+		return result
+	}
+	return map[string]interface{}{
+		"data":    result,
+		"headers": headers,
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// maxFixHints 一个错误结果中最多附带的修正提示数量
+const maxFixHints = 20
+
+// fieldErrorKeys 常见错误格式中保存字段错误的键:
+// RFC 9457 扩展 (invalid-params、errors)、JSON:API、Laravel/Rails (errors 对象)、Google API (details[].fieldViolations)、
+// FastAPI (detail[].loc) 等
+var fieldErrorKeys = []string{
+	"invalid-params", "invalid_params", "invalidParams",
+	"errors", "violations", "fieldErrors", "field_errors",
+	"validation_errors", "validationErrors", "details", "detail",
+	"fieldViolations", "field_violations",
+}
+
+// fieldNameKeys 字段错误对象中表示出错字段的键，按优先级排列
+var fieldNameKeys = []string{"name", "field", "param", "parameter", "property", "path", "pointer", "loc"}
+
+// messageKeys 字段错误对象中表示错误原因的键，按优先级排列
+var messageKeys = []string{"reason", "message", "defaultMessage", "msg", "detail", "description", "title"}
+
+// fixHints 上游返回 400 或 422 时解析错误响应体，返回逐个参数的修正提示
+// 每条提示包括 param (点分路径)、message、上游的 code，param 对应工具参数时还给出 in 和规范中的模式摘录
+// 未启用 fix_hints、响应不是 JSON 或没有可识别的字段错误时返回 nil
+func (h *RequestHandler) fixHints(operation *config.Operation, statusCode int, body []byte) []map[string]interface{} {
+	enabled := h.config.Global.FixHints
+	if operation.FixHints != nil {
+		enabled = *operation.FixHints
+	}
+	if !enabled || (statusCode != http.StatusBadRequest && statusCode != http.StatusUnprocessableEntity) {
+		return nil
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil
+	}
+
+	var hints []map[string]interface{}
+	for _, hint := range collectFieldErrors(parsed, 0) {
+		if len(hints) >= maxFixHints {
+			break
+		}
+		if hint["param"] != nil {
+			h.describeHintParam(operation, hint)
+		}
+		hints = append(hints, hint)
+	}
+	return hints
+}
+
+// collectFieldErrors 在错误响应中查找字段错误，支持顶层数组、fieldErrorKeys 中的数组或字段到消息的对象、嵌套在 error 中的同样结构，
+// 以及整个响应就是单个字段错误的情况
+func collectFieldErrors(value interface{}, depth int) []map[string]interface{} {
+	if depth > 5 {
+		return nil
+	}
+
+	var hints []map[string]interface{}
+	switch typed := value.(type) {
+	case []interface{}:
+		for _, item := range typed {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if hint := fieldErrorHint(obj); hint != nil {
+				hints = append(hints, hint)
+			} else {
+				// Google API 的 details 中每项可能包含 fieldViolations
+				hints = append(hints, collectFieldErrors(obj, depth+1)...)
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range fieldErrorKeys {
+			switch nested := typed[key].(type) {
+			case []interface{}:
+				hints = append(hints, collectFieldErrors(nested, depth+1)...)
+			case map[string]interface{}:
+				hints = append(hints, fieldMessageHints(nested)...)
+			}
+		}
+		if nested, ok := typed["error"].(map[string]interface{}); ok {
+			hints = append(hints, collectFieldErrors(nested, depth+1)...)
+		}
+		// 整个响应就是单个字段错误，如 {"field": "email", "message": "..."}
+		// 不使用 path 等键判断，很多框架的错误响应中 path 是请求路径
+		if len(hints) == 0 && firstString(typed, []string{"field", "param", "parameter", "property"}) != "" {
+			if hint := fieldErrorHint(typed); hint != nil {
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return hints
+}
+
+// fieldErrorHint 把 {field, message, code} 形式的字段错误对象转换为提示，没有错误原因时返回 nil
+func fieldErrorHint(obj map[string]interface{}) map[string]interface{} {
+	message := firstString(obj, messageKeys)
+	if message == "" {
+		return nil
+	}
+
+	hint := map[string]interface{}{"message": message}
+	field := hintFieldName(obj)
+	if source, ok := obj["source"].(map[string]interface{}); ok && field == "" {
+		// JSON:API: {"source": {"pointer": "/data/attributes/name"}} 或 {"source": {"parameter": "page"}}
+		field = hintFieldName(source)
+	}
+	if field != "" {
+		hint["param"] = field
+	}
+	if code := firstString(obj, []string{"code", "type", "rule"}); code != "" {
+		hint["code"] = code
+	}
+	return hint
+}
+
+// fieldMessageHints 把 {"字段": "消息"} 或 {"字段": ["消息", ...]} 形式的对象转换为提示
+func fieldMessageHints(obj map[string]interface{}) []map[string]interface{} {
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var hints []map[string]interface{}
+	for _, name := range names {
+		field := normalizeHintField(name)
+		switch messages := obj[name].(type) {
+		case string:
+			hints = append(hints, map[string]interface{}{"param": field, "message": messages})
+		case []interface{}:
+			for _, message := range messages {
+				if text, ok := message.(string); ok {
+					hints = append(hints, map[string]interface{}{"param": field, "message": text})
+				}
+			}
+		case map[string]interface{}:
+			if hint := fieldErrorHint(messages); hint != nil {
+				if hint["param"] == nil {
+					hint["param"] = field
+				}
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return hints
+}
+
+// hintFieldName 返回字段错误对象中的字段名，FastAPI 的 loc 数组去掉开头的参数位置
+func hintFieldName(obj map[string]interface{}) string {
+	for _, key := range fieldNameKeys {
+		switch value := obj[key].(type) {
+		case string:
+			if value != "" {
+				return normalizeHintField(value)
+			}
+		case []interface{}:
+			var parts []string
+			for i, part := range value {
+				text := fmt.Sprintf("%v", part)
+				if i == 0 && (text == "body" || text == "query" || text == "path" || text == "header") {
+					continue
+				}
+				parts = append(parts, text)
+			}
+			if len(parts) > 0 {
+				return strings.Join(parts, ".")
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeHintField 把 JSON Pointer (/data/attributes/name) 和 JSONPath ($.name) 形式的字段转换为点分路径
+func normalizeHintField(field string) string {
+	field = strings.TrimPrefix(field, "#")
+	field = strings.TrimPrefix(field, "$.")
+	if strings.HasPrefix(field, "/") {
+		field = strings.TrimPrefix(field, "/")
+		field = strings.TrimPrefix(field, "data/attributes/")
+		field = strings.ReplaceAll(field, "/", ".")
+	}
+	return field
+}
+
+// describeHintParam 提示的字段对应工具参数或请求体属性时，补充参数位置和规范中的模式摘录
+func (h *RequestHandler) describeHintParam(operation *config.Operation, hint map[string]interface{}) {
+	field, _ := hint["param"].(string)
+	name := field
+	if i := strings.IndexAny(name, ".["); i > 0 {
+		name = name[:i]
+	}
+
+	for _, param := range operation.Parameters {
+		if param.Name == name {
+			hint["in"] = param.In
+			if excerpt := schemaExcerpt(h.openAPISpec, param.Schema, 0); excerpt != nil {
+				hint["schema"] = excerpt
+			}
+			return
+		}
+	}
+	if schema, exists := jsonSchema(operation.RequestBody.Content); exists {
+		schema = openapi.ResolveSchema(h.openAPISpec, schema)
+		if property, exists := schema.Properties[name]; exists {
+			hint["in"] = "body"
+			if excerpt := schemaExcerpt(h.openAPISpec, property, 0); excerpt != nil {
+				hint["schema"] = excerpt
+			}
+		}
+	}
+}
+
+// firstString 返回对象中第一个非空的字符串值
+func firstString(obj map[string]interface{}, keys []string) string {
+	for _, key := range keys {
+		if value, ok := obj[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
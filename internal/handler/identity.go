@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/version"
+)
+
+// defaultUserAgent 未配置 identity.user_agent 时使用的 User-Agent
+const defaultUserAgent = "mcp2rest/{{.Version}}"
+
+// identity 渲染后的客户端标识，附加到每个上游请求
+type identity struct {
+	userAgent string
+	// explicit 是否配置了 user_agent，未配置时 default_headers 中的 User-Agent 优先
+	explicit bool
+	headers  http.Header
+}
+
+// identityData 标识模板可以引用的数据
+type identityData struct {
+	Version    string
+	API        string
+	APIVersion string
+	GoVersion  string
+	OS         string
+	Arch       string
+}
+
+// newIdentity 启动时渲染 global.identity 中的模板
+func newIdentity(cfg config.IdentityConfig, spec *config.OpenAPISpec) (*identity, error) {
+	data := identityData{
+		Version:   version.Version,
+		GoVersion: strings.TrimPrefix(runtime.Version(), "go"),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if spec != nil {
+		data.API = spec.Info.Title
+		data.APIVersion = spec.Info.Version
+	}
+
+	id := &identity{explicit: cfg.UserAgent != "", headers: make(http.Header, len(cfg.Headers))}
+	userAgent := cfg.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	var err error
+	if id.userAgent, err = renderIdentity("User-Agent", userAgent, data); err != nil {
+		return nil, err
+	}
+	for name, value := range cfg.Headers {
+		rendered, err := renderIdentity(name, value, data)
+		if err != nil {
+			return nil, err
+		}
+		id.headers.Set(name, rendered)
+	}
+	return id, nil
+}
+
+func renderIdentity(name, text string, data identityData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("解析标识头 %s 的模板失败: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("渲染标识头 %s 失败: %w", name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// apply 设置标识头，配置的 user_agent 覆盖已有的 User-Agent，默认值只在未设置时使用
+func (id *identity) apply(header http.Header) {
+	if id.explicit || header.Get("User-Agent") == "" {
+		header.Set("User-Agent", id.userAgent)
+	}
+	for name, values := range id.headers {
+		header[name] = values
+	}
+}
+
+// header 返回全部标识头，供 GraphQL 后端使用
+func (id *identity) header() http.Header {
+	header := id.headers.Clone()
+	header.Set("User-Agent", id.userAgent)
+	return header
+}
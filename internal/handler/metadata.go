@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// metadataHeaderExclusions 不包含在 HEAD 和 OPTIONS 结果中的响应头: 逐跳头和会话 Cookie
+var metadataHeaderExclusions = map[string]bool{
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Transfer-Encoding": true,
+	"Set-Cookie":        true,
+}
+
+// responseHasBody 判断响应是否带有响应体，HEAD 响应以及 1xx、204、304 响应没有响应体
+func responseHasBody(method string, statusCode int) bool {
+	if method == http.MethodHead {
+		return false
+	}
+	return statusCode >= 200 && statusCode != http.StatusNoContent && statusCode != http.StatusNotModified
+}
+
+// metadataResult 返回 HEAD 和 OPTIONS 操作的结果: 状态码和全部响应头 (多个值以 ", " 连接)，
+// 可用于检查资源是否存在、获取大小和修改时间；OPTIONS 还包括允许的方法 (Allow，没有时使用 CORS 的
+// Access-Control-Allow-Methods) 和响应体，JSON 响应体解析后返回
+func metadataResult(method string, resp *http.Response, body []byte) map[string]interface{} {
+	headers := make(map[string]string, len(resp.Header))
+	for name, values := range resp.Header {
+		if !metadataHeaderExclusions[http.CanonicalHeaderKey(name)] {
+			headers[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+		}
+	}
+	result := map[string]interface{}{
+		"status":  resp.StatusCode,
+		"headers": headers,
+	}
+	if method != http.MethodOptions {
+		return result
+	}
+
+	allow := resp.Header.Values("Allow")
+	if len(allow) == 0 {
+		allow = resp.Header.Values("Access-Control-Allow-Methods")
+	}
+	if methods := splitMethods(allow); len(methods) > 0 {
+		result["allow"] = methods
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		var parsed interface{}
+		if err := json.Unmarshal(body, &parsed); err == nil {
+			result["body"] = parsed
+		} else {
+			result["body"] = string(body)
+		}
+	}
+	return result
+}
+
+// splitMethods 把逗号分隔的方法列表转换为去重后排序的大写方法名
+func splitMethods(values []string) []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, value := range values {
+		for _, method := range strings.Split(value, ",") {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if method != "" && !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// ToolHandler 处理一次工具调用
+type ToolHandler func(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error)
+
+// Middleware 包装工具调用，可以在调用前后执行逻辑、修改参数，或不调用 next 直接返回结果或错误
+type Middleware func(next ToolHandler) ToolHandler
+
+// Use 追加工具调用中间件，先追加的在外层；应在开始处理工具调用之前调用
+func (h *RequestHandler) Use(middleware ...Middleware) {
+	h.middlewareMutex.Lock()
+	defer h.middlewareMutex.Unlock()
+
+	h.middleware = append(h.middleware, middleware...)
+	chain := ToolHandler(h.recoveringHandleRequest)
+	for i := len(h.middleware) - 1; i >= 0; i-- {
+		chain = h.middleware[i](chain)
+	}
+	h.chain.Store(&chain)
+}
+
+// HandleRequest 经过中间件处理工具调用请求，处理中 (包括中间件中) 的 panic 作为 *PanicError 返回
+func (h *RequestHandler) HandleRequest(ctx context.Context, params *mcp.ToolCallParams) (result *mcp.ToolCallResult, err error) {
+	defer recoverToolCall(ctx, params, &err)
+	ctx, cancel := h.withCallDeadline(ctx)
+	defer cancel()
+	if chain := h.chain.Load(); chain != nil {
+		return (*chain)(ctx, params)
+	}
+	return h.handleRequest(ctx, params)
+}
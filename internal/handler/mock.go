@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/faker"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// mockBackend 不访问上游，返回配置的固定响应
+type mockBackend struct {
+	spec      *config.OpenAPISpec
+	fallback  config.MockResponse
+	responses map[string]config.MockResponse
+}
+
+func newMockBackend(cfg config.BackendConfig, spec *config.OpenAPISpec) *mockBackend {
+	return &mockBackend{spec: spec, fallback: cfg.MockResponse, responses: cfg.Responses}
+}
+
+// Execute 返回工具对应的响应，没有单独配置时返回默认响应；
+// 配置了 fake 且没有 body 时返回规范中的示例或按响应模式生成的假数据
+func (b *mockBackend) Execute(ctx context.Context, call *BackendCall) (*http.Response, error) {
+	mock, ok := b.responses[call.Tool]
+	if !ok {
+		mock = b.fallback
+	}
+	if mock.Fake && mock.Body == nil {
+		example, err := operationExample(b.spec, call)
+		if err != nil {
+			return nil, err
+		}
+		status := mock.Status
+		if status == 0 {
+			status = example.Status
+		}
+		return staticResponse(call.Request, status, example.ContentType, mock.Headers, example.Body)
+	}
+	return staticResponse(call.Request, mock.Status, "", mock.Headers, mock.Body)
+}
+
+// exampleBackend 不访问上游，返回规范中成功响应的示例，没有示例时按响应模式生成假数据
+type exampleBackend struct {
+	spec *config.OpenAPISpec
+}
+
+// Execute 返回操作的示例响应
+func (b *exampleBackend) Execute(ctx context.Context, call *BackendCall) (*http.Response, error) {
+	example, err := operationExample(b.spec, call)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{"X-Mcp2rest-Example": "spec"}
+	if example.Synthesized {
+		headers["X-Mcp2rest-Example"] = "synthesized"
+	}
+	return staticResponse(call.Request, example.Status, example.ContentType, headers, example.Body)
+}
+
+// operationExample 返回操作的示例响应，假数据以方法、URL 和请求体为种子，相同的请求得到相同的数据
+func operationExample(spec *config.OpenAPISpec, call *BackendCall) (openapi.ExampleResponse, error) {
+	body, err := readRequestBody(call.Request)
+	if err != nil {
+		return openapi.ExampleResponse{}, err
+	}
+	seed := faker.Seed(call.Request.Method, call.Request.URL.String(), string(body))
+	return openapi.OperationExample(spec, call.Operation, seed), nil
+}
+
+// staticResponse 构造不经过网络的响应：字符串响应体原样返回 (默认为纯文本)，其他值编码为 JSON
+// contentType 为规范中声明的媒体类型，为空时按响应体选择
+func staticResponse(req *http.Request, status int, contentType string, headers map[string]string, value interface{}) (*http.Response, error) {
+	header := make(http.Header)
+	var body []byte
+	switch typed := value.(type) {
+	case nil:
+	case string:
+		body = []byte(typed)
+		if contentType == "" || isJSONMediaType(contentType) {
+			contentType = "text/plain; charset=utf-8"
+		}
+	default:
+		data, err := json.Marshal(typed)
+		if err != nil {
+			return nil, fmt.Errorf("编码模拟响应失败: %w", err)
+		}
+		body = data
+		if contentType == "" || !isJSONMediaType(contentType) {
+			contentType = "application/json"
+		}
+	}
+	if contentType != "" && body != nil {
+		header.Set("Content-Type", contentType)
+	}
+	for key, value := range headers {
+		header.Set(key, value)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+const (
+	// PatchMerge 以 JSON Merge Patch (RFC 7396) 发送请求体
+	PatchMerge = "merge"
+	// PatchJSON 以 JSON Patch (RFC 6902) 发送请求体
+	PatchJSON = "json-patch"
+
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// patchFormat 返回操作请求体使用的补丁格式，x-mcp-patch 优先；
+// 未配置时规范只声明了一种补丁媒体类型的请求体按该类型发送，否则返回空字符串
+func patchFormat(operation *config.Operation) string {
+	if operation.Patch != "" {
+		return operation.Patch
+	}
+	if len(operation.RequestBody.Content) != 1 {
+		return ""
+	}
+	for mediaType := range operation.RequestBody.Content {
+		switch mediaType {
+		case mergePatchContentType:
+			return PatchMerge
+		case jsonPatchContentType:
+			return PatchJSON
+		}
+	}
+	return ""
+}
+
+// buildPatchBody 根据提供的字段生成补丁请求体
+// merge 直接发送字段，值为 null 表示删除；json-patch 为每个字段生成 replace 操作，
+// 嵌套对象按 JSON Pointer 展开到叶子字段，只修改提供的子字段，值为 null 时生成 remove 操作
+func buildPatchBody(ctx context.Context, operation *config.Operation, format string, params map[string]interface{}) ([]byte, string, error) {
+	fields, err := bodyFields(ctx, operation, params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case PatchMerge:
+		body, err := json.Marshal(fields)
+		if err != nil {
+			return nil, "", fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		return body, mergePatchContentType, nil
+	case PatchJSON:
+		operations := make([]map[string]interface{}, 0, len(fields))
+		appendPatchOperations(&operations, "", fields)
+		body, err := json.Marshal(operations)
+		if err != nil {
+			return nil, "", fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		return body, jsonPatchContentType, nil
+	default:
+		return nil, "", fmt.Errorf("不支持的 x-mcp-patch: %s (支持: merge、json-patch)", format)
+	}
+}
+
+// appendPatchOperations 按键名顺序为对象的字段生成 JSON Patch 操作
+func appendPatchOperations(operations *[]map[string]interface{}, prefix string, fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := prefix + "/" + escapePointer(key)
+		switch value := fields[key].(type) {
+		case nil:
+			*operations = append(*operations, map[string]interface{}{"op": "remove", "path": path})
+		case map[string]interface{}:
+			if len(value) > 0 {
+				appendPatchOperations(operations, path, value)
+				continue
+			}
+			*operations = append(*operations, map[string]interface{}{"op": "replace", "path": path, "value": value})
+		default:
+			*operations = append(*operations, map[string]interface{}{"op": "replace", "path": path, "value": value})
+		}
+	}
+}
+
+// escapePointer 按 RFC 6901 转义 JSON Pointer 中的一段
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// reservedChars RFC 3986 的保留字符，allowReserved 的路径参数保留这些字符不编码
+const reservedChars = ":/?#[]@!$&'()*+,;="
+
+// encodePathParam 按 RFC 3986 编码路径参数值，避免 / 和空格等字符破坏路径
+// allowReserved 时保留字符原样发送，用于本身就包含多段路径的参数
+// 值会改变路径结构 (空值、"." 或 ".."，或 allowReserved 时包含 /、?、#) 时，strict 模式返回错误，否则记录警告后照常发送
+func encodePathParam(ctx context.Context, param config.Parameter, value interface{}, strict bool) (string, error) {
+	raw := fmt.Sprintf("%v", value)
+
+	if problem := pathStructureProblem(raw, param.AllowReserved); problem != "" {
+		if strict {
+			return "", invalidParam(ctx, param, "path", "validation.unsafe_path_param", param.Name, raw, i18n.Tc(ctx, problem))
+		}
+		logging.FromContext(ctx).Printf("警告: 路径参数 %s 的值 %q 会改变请求路径: %s", param.Name, raw, i18n.T(i18n.Chinese, problem))
+	}
+
+	if !param.AllowReserved {
+		return url.PathEscape(raw), nil
+	}
+	var sb strings.Builder
+	for _, b := range []byte(raw) {
+		if isUnreserved(b) || strings.IndexByte(reservedChars, b) >= 0 {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String(), nil
+}
+
+// pathStructureProblem 返回值替换到路径后会改变路径结构的原因 (消息代码)，没有问题时返回空字符串
+func pathStructureProblem(value string, allowReserved bool) string {
+	if value == "" {
+		return "validation.path_empty_value"
+	}
+	if !allowReserved {
+		// / 会被编码，只有整个值为相对路径段时才会改变路径
+		if value == "." || value == ".." {
+			return "validation.path_dot_segment"
+		}
+		return ""
+	}
+	if strings.ContainsAny(value, "?#") {
+		return "validation.path_query_or_fragment"
+	}
+	for _, segment := range strings.Split(value, "/") {
+		if segment == "." || segment == ".." {
+			return "validation.path_dot_segment"
+		}
+		if segment == "" {
+			return "validation.path_empty_segment"
+		}
+	}
+	return ""
+}
+
+// isUnreserved 检查字节是否为 RFC 3986 的非保留字符
+func isUnreserved(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' ||
+		b == '-' || b == '.' || b == '_' || b == '~'
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// shouldPoll 检查响应是否为需要轮询的异步操作
+func shouldPoll(operation *config.Operation, resp *http.Response) bool {
+	return operation.Poll != nil && resp.StatusCode == http.StatusAccepted
+}
+
+// pollOperation 轮询异步操作的状态地址直到完成、失败或超时，期间发送进度通知
+func (h *RequestHandler) pollOperation(ctx context.Context, req *http.Request, resp *http.Response, body []byte, operation *config.Operation) (*http.Response, []byte, bool, error) {
+	poll := operation.Poll
+
+	interval, err := parsePollDuration(poll.Interval, 2*time.Second)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("无效的轮询间隔: %w", err)
+	}
+	maxWait, err := parsePollDuration(poll.MaxWait, 5*time.Minute)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("无效的最长等待时间: %w", err)
+	}
+
+	statusURL, err := h.resolveStatusURL(req, resp, body, poll)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	logging.FromContext(ctx).Printf("异步操作已接受，开始轮询状态地址: %s", statusURL)
+	deadline := time.Now().Add(maxWait)
+	total := float64(maxWait / interval)
+
+	for attempt := 1; ; attempt++ {
+		if time.Now().Add(interval).After(deadline) {
+			return nil, nil, false, fmt.Errorf("等待异步操作完成超时 (%v)", maxWait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, false, fmt.Errorf("轮询异步操作被取消: %w", ctx.Err())
+		case <-time.After(interval):
+		}
+
+		statusReq, err := http.NewRequest("GET", statusURL, nil)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("创建状态查询请求失败: %w", err)
+		}
+		statusResp, statusBody, err := h.sendRequest(statusReq.WithContext(ctx), operation)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		// 状态地址以 2xx 返回状态文档，x-mcp-success-codes 中的状态码 (如 303) 同样是有效的状态响应，其他状态码结束轮询
+		if !isFollowUpSuccess(operation, statusResp.StatusCode) {
+			return statusResp, statusBody, false, nil
+		}
+
+		// 未配置完成条件时，状态地址不再返回 202 即视为完成
+		if poll.Done == "" && poll.Failed == "" {
+			if statusResp.StatusCode != http.StatusAccepted {
+				logging.FromContext(ctx).Printf("异步操作完成，共轮询 %d 次", attempt)
+				return statusResp, statusBody, false, nil
+			}
+			notifyProgress(ctx, float64(attempt), total, fmt.Sprintf("等待异步操作完成，已轮询 %d 次", attempt))
+			continue
+		}
+
+		var status interface{}
+		if err := json.Unmarshal(statusBody, &status); err != nil {
+			return nil, nil, false, fmt.Errorf("解析状态响应失败: %w", err)
+		}
+
+		if poll.Failed != "" && h.evaluatePredicate(ctx, status, poll.Failed) {
+			logging.FromContext(ctx).Printf("异步操作失败: %s", statusURL)
+			return statusResp, statusBody, true, nil
+		}
+		if h.evaluatePredicate(ctx, status, poll.Done) {
+			logging.FromContext(ctx).Printf("异步操作完成，共轮询 %d 次", attempt)
+			return statusResp, statusBody, false, nil
+		}
+
+		notifyProgress(ctx, float64(attempt), total, fmt.Sprintf("等待异步操作完成，已轮询 %d 次", attempt))
+	}
+}
+
+// resolveStatusURL 从 202 响应中确定状态地址
+func (h *RequestHandler) resolveStatusURL(req *http.Request, resp *http.Response, body []byte, poll *config.PollConfig) (string, error) {
+	location := resp.Header.Get("Location")
+	if poll.StatusURL != "" {
+		var accepted interface{}
+		if err := json.Unmarshal(body, &accepted); err != nil {
+			return "", fmt.Errorf("解析202响应失败: %w", err)
+		}
+		value, err := h.transformer.TransformValue(accepted, config.TransformPipeline{{Type: "jq", Expression: poll.StatusURL}}, nil)
+		if err != nil {
+			return "", fmt.Errorf("提取状态地址失败: %w", err)
+		}
+		location, _ = value.(string)
+	}
+	if location == "" {
+		return "", fmt.Errorf("202响应中未找到状态地址")
+	}
+
+	statusURL, err := req.URL.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("解析状态地址失败: %w", err)
+	}
+	return statusURL.String(), nil
+}
+
+// evaluatePredicate 使用 jq 表达式判断条件，表达式为空或出错时返回 false
+func (h *RequestHandler) evaluatePredicate(ctx context.Context, value interface{}, expression string) bool {
+	if expression == "" {
+		return false
+	}
+	result, err := h.transformer.TransformValue(value, config.TransformPipeline{{Type: "jq", Expression: expression}}, nil)
+	if err != nil {
+		logging.FromContext(ctx).Printf("执行轮询条件 %s 失败: %v", expression, err)
+		return false
+	}
+	matched, _ := result.(bool)
+	return matched
+}
+
+// parsePollDuration 解析轮询时间配置，为空时使用默认值
+func parsePollDuration(value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("时间必须大于0: %s", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// UpstreamURLs 返回自检时需要连接的上游地址：规范中的服务器、操作或路径上声明的其他服务器和 GraphQL 端点
+func (h *RequestHandler) UpstreamURLs() []string {
+	var urls []string
+	if len(h.openAPISpec.Servers) > 0 && h.openAPISpec.Servers[0].URL != "" {
+		urls = append(urls, h.openAPISpec.Servers[0].URL)
+	}
+	seen := map[string]bool{openapi.GetBaseURL(h.openAPISpec): true}
+	var others []string
+	for _, pathItem := range h.openAPISpec.Paths {
+		for _, operation := range pathItem {
+			if baseURL := openapi.OperationBaseURL(h.openAPISpec, &operation); !seen[baseURL] {
+				seen[baseURL] = true
+				others = append(others, baseURL)
+			}
+		}
+	}
+	sort.Strings(others)
+	urls = append(urls, others...)
+	if h.config.Global.GraphQL != nil && h.config.Global.GraphQL.Endpoint != "" {
+		urls = append(urls, h.config.Global.GraphQL.Endpoint)
+	}
+	return urls
+}
+
+// ProbeUpstream 连接上游但不发送请求，https 上游同时完成 TLS 握手
+// 与实际请求使用相同的连接方式，Unix 域套接字、主机覆盖和 TLS 设置都会生效
+func (h *RequestHandler) ProbeUpstream(ctx context.Context, rawURL string) (map[string]interface{}, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的上游地址: %w", err)
+	}
+
+	network, addr := "tcp", target.Host
+	switch target.Scheme {
+	case unixScheme:
+		socket, _, err := splitUnixPath(target.Path)
+		if err != nil {
+			return nil, err
+		}
+		network, addr = "unix", socket
+	case "http", "https":
+		if target.Port() == "" {
+			port := "80"
+			if target.Scheme == "https" {
+				port = "443"
+			}
+			addr = net.JoinHostPort(target.Hostname(), port)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的上游协议: %s", target.Scheme)
+	}
+
+	transport := h.transport()
+	details := map[string]interface{}{"address": addr}
+	start := time.Now()
+	var conn net.Conn
+	if network == "unix" {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, network, addr)
+	} else {
+		conn, err = transport.DialContext(ctx, network, addr)
+	}
+	if err != nil {
+		return details, fmt.Errorf("连接 %s 失败: %w", addr, err)
+	}
+	defer conn.Close()
+	details["connect_ms"] = time.Since(start).Milliseconds()
+	if remote := conn.RemoteAddr(); remote != nil && remote.String() != "" {
+		details["remote"] = remote.String()
+	}
+
+	if target.Scheme != "https" {
+		return details, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = target.Hostname()
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return details, fmt.Errorf("TLS 握手失败: %w", err)
+	}
+	state := tlsConn.ConnectionState()
+	details["tls_version"] = tlsVersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		now := time.Now()
+		certificateStats(cert.NotAfter, now, details)
+		details["subject"] = cert.Subject.CommonName
+		// 与 TLS 监控使用相同的到期警告阈值 (global.tls.expiry_warning)
+		details["expiring"] = cert.NotAfter.Sub(now) <= h.tls.expiryWarning
+	}
+	return details, nil
+}
+
+// tlsVersionName 返回 TLS 版本名称
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
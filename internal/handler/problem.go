@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// problemMediaType RFC 9457 (原 RFC 7807) 问题详情的媒体类型
+const problemMediaType = "application/problem+json"
+
+// problemMembers 问题详情的标准成员，其余成员是扩展成员
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// parseProblem 解析 RFC 9457 问题详情，返回标准成员和扩展成员，响应不是问题详情时 ok 为 false
+// Content-Type 为 application/problem+json 时按问题详情解析；部分上游以 application/json 返回，
+// 这时要求同时包含 title 和与状态码一致的 status
+func parseProblem(resp *http.Response, body []byte) (problem, extensions map[string]interface{}, ok bool) {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !isJSONMediaType(mediaType) {
+		return nil, nil, false
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, nil, false
+	}
+	if mediaType != problemMediaType {
+		title, _ := parsed["title"].(string)
+		status, _ := parsed["status"].(float64)
+		if title == "" || int(status) != resp.StatusCode {
+			return nil, nil, false
+		}
+	}
+
+	problem = make(map[string]interface{})
+	for _, member := range problemMembers {
+		value, exists := parsed[member]
+		if !exists {
+			continue
+		}
+		// about:blank 表示没有额外的语义，与省略 type 相同
+		if member == "type" && value == "about:blank" {
+			continue
+		}
+		problem[member] = value
+		delete(parsed, member)
+	}
+	return problem, parsed, true
+}
+
+// problemMessage 用问题详情的 title 和 detail 组成错误消息，都没有时返回 fallback
+func problemMessage(problem map[string]interface{}, fallback string) string {
+	title, _ := problem["title"].(string)
+	detail, _ := problem["detail"].(string)
+	switch {
+	case title != "" && detail != "" && detail != title:
+		return title + ": " + detail
+	case detail != "":
+		return detail
+	case title != "":
+		return title
+	}
+	return fallback
+}
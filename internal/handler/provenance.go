@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// ProvenanceMetaKey 工具结果 _meta 中来源信息的键
+const ProvenanceMetaKey = "provenance"
+
+// provenance 一次操作调用的来源信息，由 executeOperation 放入上下文，发送请求的各环节补充
+type provenance struct {
+	started time.Time
+	// cache 条件请求缓存的结果: "hit" 使用了缓存的响应，"miss" 未命中，未启用缓存时为空
+	cache string
+	// shared 复用了正在进行的相同请求的响应
+	shared  bool
+	backend string
+}
+
+type provenanceContextKey struct{}
+
+// withProvenance 开始记录来源信息，未启用 global.provenance 时返回原上下文
+func (h *RequestHandler) withProvenance(ctx context.Context) context.Context {
+	if enabled := h.config.Global.Provenance; enabled != nil && !*enabled {
+		return ctx
+	}
+	return context.WithValue(ctx, provenanceContextKey{}, &provenance{started: time.Now()})
+}
+
+// provenanceFrom 返回上下文中的来源信息，未记录时返回 nil
+func provenanceFrom(ctx context.Context) *provenance {
+	p, _ := ctx.Value(provenanceContextKey{}).(*provenance)
+	return p
+}
+
+// attachProvenance 把最终响应的来源信息写入工具结果的 _meta，截断标记由输出结果的一方更新
+func (h *RequestHandler) attachProvenance(ctx context.Context, result *mcp.ToolCallResult, resp *http.Response, operation *config.Operation) *mcp.ToolCallResult {
+	p := provenanceFrom(ctx)
+	if p == nil || result == nil || resp == nil {
+		return result
+	}
+	meta := map[string]interface{}{
+		"status":    resp.StatusCode,
+		"latencyMs": time.Since(p.started).Milliseconds(),
+		"truncated": false,
+	}
+	if resp.Request != nil {
+		meta["method"] = resp.Request.Method
+		meta["url"] = redactQuery(resp.Request.URL, h.queryCredentials(operation))
+	}
+	if p.cache != "" {
+		meta["cache"] = p.cache
+	}
+	if p.shared {
+		meta["shared"] = true
+	}
+	if p.backend != "" {
+		meta["backend"] = p.backend
+	}
+
+	if result.Meta == nil {
+		result.Meta = make(map[string]interface{})
+	}
+	result.Meta[ProvenanceMetaKey] = meta
+	return result
+}
+
+// queryCredentials 返回操作的安全方案放在查询字符串中的 API 密钥参数名，包括认证配置中的 query_param
+func (h *RequestHandler) queryCredentials(operation *config.Operation) []string {
+	var names []string
+	for _, requirement := range operation.Security {
+		for schemeName := range requirement {
+			scheme, err := openapi.GetSecurityScheme(h.openAPISpec, schemeName)
+			if err == nil && scheme.Type == "apiKey" && scheme.In == "query" && scheme.Name != "" {
+				names = append(names, scheme.Name)
+			}
+		}
+	}
+	if _, authConfig, err := h.operationAuth(operation); err == nil && authConfig != nil && authConfig.QueryParam != "" {
+		names = append(names, authConfig.QueryParam)
+	}
+	return names
+}
+
+type queryCredentialsContextKey struct{}
+
+// withQueryCredentials 记录请求的查询字符串中携带凭据的参数名，日志、重定向和录制文件中的 URL 据此隐去取值
+func withQueryCredentials(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, queryCredentialsContextKey{}, names)
+}
+
+// redactedURL 返回去掉用户密码、并隐藏查询字符串中凭据的请求 URL
+func redactedURL(req *http.Request) string {
+	names, _ := req.Context().Value(queryCredentialsContextKey{}).([]string)
+	return redactQuery(req.URL, names)
+}
+
+// redactQuery 返回去掉用户密码、并隐藏指定查询参数的值的 URL
+func redactQuery(u *url.URL, names []string) string {
+	if len(names) == 0 || u.RawQuery == "" {
+		return u.Redacted()
+	}
+	query := u.Query()
+	for _, name := range names {
+		if query.Has(name) {
+			query.Set(name, "xxxxx")
+		}
+	}
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.Redacted()
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"github.com/mcp2rest/internal/config"
+)
+
+const (
+	// PruneEmpty 去掉值为 null、空字符串、空数组或空对象的可选参数
+	PruneEmpty = "empty"
+	// PruneZero 在 PruneEmpty 的基础上去掉值为 0 和 false 的可选参数
+	PruneZero = "zero"
+	// PruneOff 不去掉任何参数，用于在操作上关闭全局设置
+	PruneOff = "off"
+)
+
+// pruneMode 返回操作使用的空值参数处理方式，x-mcp-prune-empty 优先于 global.prune_empty
+// 补丁请求体中的 null 和空值有明确含义，未在操作上显式配置时不处理
+func (h *RequestHandler) pruneMode(operation *config.Operation) string {
+	if operation.PruneEmpty != "" {
+		return operation.PruneEmpty
+	}
+	if patchFormat(operation) != "" {
+		return PruneOff
+	}
+	return h.config.Global.PruneEmpty
+}
+
+// pruneParams 去掉值为空的可选参数，避免上游把空字符串、0 等当作过滤条件
+// 必需参数 (包括请求体模式中的 required 字段) 始终保留，返回新的参数表，不修改 params
+func (h *RequestHandler) pruneParams(operation *config.Operation, params map[string]interface{}) map[string]interface{} {
+	mode := h.pruneMode(operation)
+	if mode != PruneEmpty && mode != PruneZero {
+		return params
+	}
+
+	required := make(map[string]bool)
+	for _, param := range operation.Parameters {
+		if param.Required {
+			required[param.Name] = true
+		}
+	}
+	for _, mediaType := range operation.RequestBody.Content {
+		for _, name := range mediaType.Schema.Required {
+			required[name] = true
+		}
+	}
+
+	pruned := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		if !required[name] && emptyValue(value, mode == PruneZero) {
+			continue
+		}
+		pruned[name] = value
+	}
+	return pruned
+}
+
+// emptyValue 检查参数值是否为空，zero 为 true 时 0 和 false 也视为空
+func emptyValue(value interface{}, zero bool) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case float64:
+		return zero && v == 0
+	case int:
+		return zero && v == 0
+	case bool:
+		return zero && !v
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/quota"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+type quotaSubjectContextKey struct{}
+
+// WithQuotaSubject 将调用方附加到上下文，工具调用按其计入 global.quotas 中的配额
+// 未附加时 (例如定时任务) 不检查配额
+func WithQuotaSubject(ctx context.Context, subject quota.Subject) context.Context {
+	return context.WithValue(ctx, quotaSubjectContextKey{}, subject)
+}
+
+// takeQuota 为调用方记录一次工具调用，超过配额时返回 *quota.ExceededError
+func (h *RequestHandler) takeQuota(ctx context.Context, name string) error {
+	subject, ok := ctx.Value(quotaSubjectContextKey{}).(quota.Subject)
+	if h.quotas == nil || !ok {
+		return nil
+	}
+	return h.quotas.Take(subject, name, time.Now())
+}
+
+type quotaToolContextKey struct{}
+
+// executeWithQuota 在访问控制、参数检查和确认都通过后执行操作，请求构建成功、即将发送时为 name 记录一次调用；
+// 被拒绝、参数不完整或未确认的调用不消耗配额，配额用完时不发送请求
+func (h *RequestHandler) executeWithQuota(ctx context.Context, name string, operation *config.Operation, method, path string, parameters map[string]interface{}) (*mcp.ToolCallResult, error) {
+	return h.executeOperation(context.WithValue(ctx, quotaToolContextKey{}, name), operation, method, path, parameters)
+}
+
+// chargeQuota 为 executeWithQuota 标记的工具记录一次调用，组合工具的步骤等未标记的调用不计数
+func (h *RequestHandler) chargeQuota(ctx context.Context) error {
+	name, ok := ctx.Value(quotaToolContextKey{}).(string)
+	if !ok {
+		return nil
+	}
+	return h.takeQuota(ctx, name)
+}
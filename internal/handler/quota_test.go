@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/quota"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+func quotaExceeded(err error) bool {
+	var e *quota.ExceededError
+	return errors.As(err, &e)
+}
+
+func TestQuotaNotChargedForRejectedCalls(t *testing.T) {
+	h := newTestHandler(t, func(global *config.GlobalConfig) {
+		global.Quotas = []config.QuotaConfig{{Name: "items", Tools: []string{"getItem"}, Hourly: 1}}
+	})
+	ctx := WithQuotaSubject(context.Background(), quota.Subject{Session: "s1"})
+
+	rejected := []map[string]interface{}{
+		{},                           // 缺少必需参数
+		{"id": "1", "limit": "many"}, // 参数类型错误
+	}
+	for _, arguments := range rejected {
+		_, err := h.HandleRequest(ctx, &mcp.ToolCallParams{Name: "getItem", Parameters: arguments})
+		if err == nil || quotaExceeded(err) {
+			t.Fatalf("HandleRequest(%v) error = %v, want validation error", arguments, err)
+		}
+	}
+	if _, err := h.HandleRequest(ctx, &mcp.ToolCallParams{Name: "missingTool"}); err == nil || quotaExceeded(err) {
+		t.Fatalf("未知工具 error = %v", err)
+	}
+
+	valid := &mcp.ToolCallParams{Name: "getItem", Parameters: map[string]interface{}{"id": "1"}}
+	if _, err := h.HandleRequest(ctx, valid); err != nil {
+		t.Fatalf("被拒绝的调用不应消耗配额: %v", err)
+	}
+	if _, err := h.HandleRequest(ctx, valid); !quotaExceeded(err) {
+		t.Fatalf("HandleRequest() error = %v, want quota exceeded", err)
+	}
+}
+
+func TestQuotaChargedOnConfirm(t *testing.T) {
+	h := newTestHandler(t, func(global *config.GlobalConfig) {
+		global.Quotas = []config.QuotaConfig{{Name: "items", Tools: []string{"getItem"}, Hourly: 1}}
+		global.Approval = config.ApprovalConfig{Enabled: true, Tools: []string{"getItem"}}
+	})
+	ctx := WithCaller(WithQuotaSubject(context.Background(), quota.Subject{Session: "s1"}), Caller{Session: "s1"})
+
+	// 等待确认的调用不计数
+	var tokens []string
+	for i := 0; i < 2; i++ {
+		result, err := h.HandleRequest(ctx, &mcp.ToolCallParams{Name: "getItem", Parameters: map[string]interface{}{"id": "1"}})
+		if err != nil {
+			t.Fatalf("HandleRequest() error = %v", err)
+		}
+		token, _ := result.Result.(map[string]interface{})["token"].(string)
+		if token == "" {
+			t.Fatalf("HandleRequest() = %+v, want approval token", result)
+		}
+		tokens = append(tokens, token)
+	}
+
+	confirm := func(token string) error {
+		_, err := h.HandleRequest(ctx, &mcp.ToolCallParams{Name: ConfirmToolName, Parameters: map[string]interface{}{"token": token}})
+		return err
+	}
+	if err := confirm(tokens[0]); err != nil {
+		t.Fatalf("确认第一个令牌 error = %v", err)
+	}
+	if err := confirm(tokens[1]); !quotaExceeded(err) {
+		t.Fatalf("确认第二个令牌 error = %v, want quota exceeded", err)
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// rawBodyParam 原始请求体默认使用的工具参数名
+const rawBodyParam = "body"
+
+// rawBodyMediaType 返回需要原样发送请求体的媒体类型，请求体可以是 JSON 或表单时返回空字符串
+// 只声明了 text/*、XML 或二进制等类型时选用其中之一，优先选择文本，其次 XML，最后是二进制类型
+func rawBodyMediaType(operation *config.Operation) string {
+	if len(operation.RequestBody.Content) == 0 {
+		return ""
+	}
+	mediaTypes := make([]string, 0, len(operation.RequestBody.Content))
+	for mediaType := range operation.RequestBody.Content {
+		base := strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+		if isJSONMediaType(base) || isFormMediaType(base) || base == "*/*" {
+			return ""
+		}
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Slice(mediaTypes, func(i, j int) bool {
+		ri, rj := rawMediaTypeRank(mediaTypes[i]), rawMediaTypeRank(mediaTypes[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return mediaTypes[i] < mediaTypes[j]
+	})
+	return mediaTypes[0]
+}
+
+// rawMediaTypeRank 原始请求体媒体类型的优先级，数值越小越优先
+func rawMediaTypeRank(mediaType string) int {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return 0
+	case isXMLMediaType(mediaType):
+		return 1
+	}
+	return 2
+}
+
+// isFormMediaType 检查媒体类型是否为表单
+func isFormMediaType(mediaType string) bool {
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+// isXMLMediaType 检查媒体类型是否为 XML
+func isXMLMediaType(mediaType string) bool {
+	base := strings.ToLower(strings.Split(mediaType, ";")[0])
+	return base == "application/xml" || base == "text/xml" || strings.HasSuffix(base, "+xml")
+}
+
+// isBinaryMediaType 检查原始请求体是否为二进制，二进制内容通过 base64 传入
+func isBinaryMediaType(mediaType string) bool {
+	return rawMediaTypeRank(mediaType) == 2
+}
+
+// rawBodyParamName 返回传入原始请求体的参数名：操作声明了 in: body 参数时使用第一个，否则为 body
+func rawBodyParamName(operation *config.Operation) string {
+	for _, param := range operation.Parameters {
+		if param.In == "body" {
+			return param.Name
+		}
+	}
+	return rawBodyParam
+}
+
+// rawBodyProperty 返回原始请求体参数在工具输入 Schema 中的定义，操作已声明该参数时返回 nil
+func rawBodyProperty(operation *config.Operation) (string, map[string]interface{}) {
+	mediaType := rawBodyMediaType(operation)
+	if mediaType == "" {
+		return "", nil
+	}
+	name := rawBodyParamName(operation)
+	for _, param := range operation.Parameters {
+		if param.Name == name {
+			return "", nil
+		}
+	}
+
+	description := operation.RequestBody.Description
+	if description != "" {
+		description += "\n"
+	}
+	property := map[string]interface{}{"type": "string"}
+	if isBinaryMediaType(mediaType) {
+		property["description"] = description + fmt.Sprintf("请求体，base64 编码的 %s 内容", mediaType)
+		property["contentEncoding"] = "base64"
+	} else {
+		property["description"] = description + fmt.Sprintf("请求体，按 %s 原样发送", mediaType)
+	}
+	property["contentMediaType"] = mediaType
+	return name, property
+}
+
+// buildRawBody 把单个参数作为请求体原样发送，二进制类型的参数按 base64 解码
+// 参数不是字符串时文本类型编码为 JSON 发送
+func buildRawBody(ctx context.Context, operation *config.Operation, mediaType string, params map[string]interface{}) ([]byte, error) {
+	name := rawBodyParamName(operation)
+	value, exists := params[name]
+	if !exists || value == nil {
+		if operation.RequestBody.Required {
+			return nil, invalidParam(ctx, config.Parameter{Name: name, In: "body"}, "required", "validation.missing_body_param", name)
+		}
+		return nil, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		text = string(data)
+	}
+	if !isBinaryMediaType(mediaType) {
+		return []byte(text), nil
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(text); err == nil {
+			return data, nil
+		}
+	}
+	return nil, invalidParam(ctx, config.Parameter{Name: name, In: "body"}, "format", "validation.invalid_base64", name)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/paths"
+)
+
+// defaultRecordingDir recorded 后端默认的录制目录
+const defaultRecordingDir = "recordings"
+
+// recordedBackend 回放录制的上游响应，录制模式下请求上游并保存响应
+// 录制文件为 <dir>/<工具名称>/<请求摘要>.json，摘要由方法、URL 和请求体计算；
+// 查询字符串中的凭据不计入摘要也不写入文件，更换密钥后仍能回放
+type recordedBackend struct {
+	dir      string
+	record   bool
+	upstream Backend
+}
+
+// recording 一个录制的响应，响应体不是 UTF-8 文本时保存在 BodyBase64 中
+type recording struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Status     int         `json:"status"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 []byte      `json:"body_base64,omitempty"`
+}
+
+func newRecordedBackend(cfg config.BackendConfig, upstream Backend) (*recordedBackend, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = defaultRecordingDir
+	}
+	backend := &recordedBackend{dir: paths.Resolve(dir), upstream: upstream}
+	switch cfg.Mode {
+	case "", "replay":
+	case "record":
+		backend.record = true
+	default:
+		return nil, fmt.Errorf("录制模式 %q 无效，应为 replay 或 record", cfg.Mode)
+	}
+	return backend, nil
+}
+
+// Execute 回放或录制请求的响应
+func (b *recordedBackend) Execute(ctx context.Context, call *BackendCall) (*http.Response, error) {
+	file, err := b.recordingFile(call)
+	if err != nil {
+		return nil, err
+	}
+	if b.record {
+		return b.recordResponse(ctx, call, file)
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("没有录制的响应: %s %s", call.Request.Method, redactedURL(call.Request))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取录制的响应失败: %w", err)
+	}
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("解析录制的响应 %s 失败: %w", file, err)
+	}
+
+	body := rec.BodyBase64
+	if body == nil {
+		body = []byte(rec.Body)
+	}
+	header := rec.Headers
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       call.Request,
+	}, nil
+}
+
+// recordResponse 请求上游并保存解压后的响应，保存失败只记录日志
+func (b *recordedBackend) recordResponse(ctx context.Context, call *BackendCall, file string) (*http.Response, error) {
+	resp, err := b.upstream.Execute(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	reader, err := decodeBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应体失败: %w", err)
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+
+	rec := recording{
+		Method:  call.Request.Method,
+		URL:     redactedURL(call.Request),
+		Status:  resp.StatusCode,
+		Headers: resp.Header.Clone(),
+	}
+	rec.Headers.Del("Set-Cookie")
+	rec.Headers.Del("Content-Length")
+	if utf8.Valid(body) {
+		rec.Body = string(body)
+	} else {
+		rec.BodyBase64 = body
+	}
+
+	logger := logging.FromContext(ctx)
+	if err := writeRecording(file, &rec); err != nil {
+		logger.Printf("保存录制的响应失败: %v", err)
+	} else {
+		logger.Printf("已录制响应: %s %s -> %s", rec.Method, rec.URL, file)
+	}
+	return resp, nil
+}
+
+// recordingFile 返回请求对应的录制文件，读取请求体后会恢复
+func (b *recordedBackend) recordingFile(call *BackendCall) (string, error) {
+	req := call.Request
+	digest := sha256.New()
+	digest.Write([]byte(req.Method + " " + redactedURL(req) + "\n"))
+	body, err := readRequestBody(req)
+	if err != nil {
+		return "", err
+	}
+	digest.Write(body)
+
+	tool := call.Tool
+	if tool == "" {
+		tool = "_"
+	}
+	name := hex.EncodeToString(digest.Sum(nil))[:16] + ".json"
+	return filepath.Join(b.dir, filepath.Base(tool), name), nil
+}
+
+func writeRecording(file string, rec *recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
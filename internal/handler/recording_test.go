@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+func TestRecordingRedactsQueryCredentials(t *testing.T) {
+	logging.Logger = log.New(io.Discard, "", 0)
+	dir := t.TempDir()
+	upstream := BackendFunc(func(ctx context.Context, call *BackendCall) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(`{"ok":true}`)), Request: call.Request}, nil
+	})
+	recorder, err := newRecordedBackend(config.BackendConfig{Dir: dir, Mode: "record"}, upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(key string) *BackendCall {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/weather?q=Paris&appid="+key, nil)
+		req = req.WithContext(withQueryCredentials(req.Context(), []string{"appid"}))
+		return &BackendCall{Tool: "currentWeather", Request: req}
+	}
+	resp, err := recorder.Execute(context.Background(), call("secret-key"))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "currentWeather", "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("录制文件 = %v, want 1", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if strings.Contains(string(data), "secret-key") {
+		t.Fatalf("录制文件包含凭据: %s", data)
+	}
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.URL != "https://api.example.com/weather?appid=xxxxx&q=Paris" {
+		t.Fatalf("URL = %q", rec.URL)
+	}
+
+	// 使用其他密钥回放同一个请求
+	replayer, _ := newRecordedBackend(config.BackendConfig{Dir: dir}, nil)
+	resp, err = replayer.Execute(context.Background(), call("another-key"))
+	if err != nil {
+		t.Fatalf("回放 error = %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// PanicError 工具调用处理中发生的 panic，调用栈已记录到日志
+// 一次调用的 panic 不会影响其他调用和工作协程
+type PanicError struct {
+	Tool  string
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("处理工具 %s 时发生意外错误，详细信息已记录到日志", e.Tool)
+}
+
+// recoverToolCall 把工具调用中的 panic 转换为 *PanicError，需要在 defer 中直接调用
+func recoverToolCall(ctx context.Context, params *mcp.ToolCallParams, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	tool := ""
+	if params != nil {
+		tool = params.Name
+	}
+	logging.FromContext(ctx).Printf("处理工具 %s 时发生panic: %v\n%s", tool, r, debug.Stack())
+	*err = &PanicError{Tool: tool, Value: r}
+}
+
+// recoveringHandleRequest 处理工具调用，panic 作为错误返回，外层的中间件可以照常记录
+func (h *RequestHandler) recoveringHandleRequest(ctx context.Context, params *mcp.ToolCallParams) (result *mcp.ToolCallResult, err error) {
+	defer recoverToolCall(ctx, params, &err)
+	return h.handleRequest(ctx, params)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// defaultMaxRedirects 与 net/http 默认跟随的次数一致
+const defaultMaxRedirects = 10
+
+// credentialHeaders 始终视为凭据的请求头，跨主机重定向时去掉
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Cookie2"}
+
+// redirectPolicy 单个请求生效的重定向策略
+type redirectPolicy struct {
+	follow         bool
+	max            int
+	preserveMethod bool
+	stripAuth      bool
+	// authHeaders 操作的安全方案使用的 API 密钥头，跨主机时同样去掉
+	authHeaders []string
+}
+
+type redirectPolicyContextKey struct{}
+
+// redirectPolicyFor 合并 global.redirects 和操作的 x-mcp-redirects，operation 为 nil 时只使用全局设置
+func (h *RequestHandler) redirectPolicyFor(operation *config.Operation) *redirectPolicy {
+	cfg := h.config.Global.Redirects
+	if operation != nil && operation.Redirects != nil {
+		override := operation.Redirects
+		if override.Follow != nil {
+			cfg.Follow = override.Follow
+		}
+		if override.Max > 0 {
+			cfg.Max = override.Max
+		}
+		if override.PreserveMethod != nil {
+			cfg.PreserveMethod = override.PreserveMethod
+		}
+		if override.StripAuth != nil {
+			cfg.StripAuth = override.StripAuth
+		}
+	}
+
+	policy := &redirectPolicy{
+		follow:         cfg.Follow == nil || *cfg.Follow,
+		max:            cfg.Max,
+		preserveMethod: cfg.PreserveMethod == nil || *cfg.PreserveMethod,
+		stripAuth:      cfg.StripAuth == nil || *cfg.StripAuth,
+	}
+	if policy.max <= 0 {
+		policy.max = defaultMaxRedirects
+	}
+	if operation != nil && len(operation.Security) > 0 {
+		for schemeName := range operation.Security[0] {
+			if scheme, err := openapi.GetSecurityScheme(h.openAPISpec, schemeName); err == nil {
+				if name := openapi.AuthConfigForScheme(schemeName, scheme).HeaderName; name != "" {
+					policy.authHeaders = append(policy.authHeaders, name)
+				}
+			}
+		}
+	}
+	return policy
+}
+
+// withRedirectPolicy 把重定向策略附加到请求的上下文
+func withRedirectPolicy(ctx context.Context, policy *redirectPolicy) context.Context {
+	return context.WithValue(ctx, redirectPolicyContextKey{}, policy)
+}
+
+// checkRedirect 作为 http.Client.CheckRedirect，按请求上下文中的策略 (没有时使用全局设置) 处理重定向
+func (h *RequestHandler) checkRedirect(req *http.Request, via []*http.Request) error {
+	policy, _ := req.Context().Value(redirectPolicyContextKey{}).(*redirectPolicy)
+	if policy == nil {
+		policy = h.redirectPolicyFor(nil)
+	}
+	if !policy.follow {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > policy.max {
+		return fmt.Errorf("重定向次数超过 %d 次", policy.max)
+	}
+
+	// 307/308 默认保留方法和请求体，不保留时与 303 一样改为不带请求体的 GET
+	previous := via[len(via)-1]
+	if !policy.preserveMethod && req.Response != nil && req.Method != http.MethodGet && req.Method != http.MethodHead &&
+		(req.Response.StatusCode == http.StatusTemporaryRedirect || req.Response.StatusCode == http.StatusPermanentRedirect) {
+		req.Method = http.MethodGet
+		req.Body, req.GetBody, req.ContentLength = nil, nil, 0
+		req.Header.Del("Content-Type")
+		req.Header.Del("Content-Encoding")
+	}
+
+	// 按协议、主机和端口判断是否跨主机，比 net/http 只比较域名更严格，且覆盖 API 密钥头
+	original := via[0].URL
+	crossHost := req.URL.Scheme != original.Scheme || req.URL.Host != original.Host
+	if crossHost {
+		if policy.stripAuth {
+			for _, name := range append(credentialHeaders, policy.authHeaders...) {
+				req.Header.Del(name)
+			}
+		} else {
+			// net/http 会去掉其他域名的凭据头，明确不去掉时从上一个请求复制回来
+			for _, name := range append(credentialHeaders, policy.authHeaders...) {
+				if value := previous.Header.Values(name); len(value) > 0 && req.Header.Get(name) == "" {
+					req.Header[http.CanonicalHeaderKey(name)] = value
+				}
+			}
+		}
+	}
+	logging.FromContext(req.Context()).Printf("跟随重定向: %s %s (跨主机: %v)", req.Method, redactedURL(req), crossHost)
+	return nil
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// ErrSamplingUnsupported 上下文中没有客户端，或客户端未在 initialize 中声明 sampling 能力
+var ErrSamplingUnsupported = errors.New("客户端不支持 sampling")
+
+// defaultSamplingMaxTokens 未指定 MaxTokens 时请求的最大令牌数，sampling/createMessage 要求该字段
+const defaultSamplingMaxTokens = 1024
+
+// SamplingRequest 表示一次通过客户端生成回复的请求
+type SamplingRequest struct {
+	SystemPrompt string
+	Prompt       string   // 作为单条用户消息发送
+	MaxTokens    int      // 默认 1024
+	ModelHints   []string // 希望使用的模型名称，客户端可以忽略
+}
+
+// samplingResult 表示 sampling/createMessage 的响应结果
+type samplingResult struct {
+	Role    string `json:"role"`
+	Content struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Model      string `json:"model"`
+	StopReason string `json:"stopReason"`
+}
+
+// SamplingAvailable 检查上下文中的客户端是否支持 sampling/createMessage
+func SamplingAvailable(ctx context.Context) bool {
+	return clientWith(ctx, mcp.CapabilitySampling) != nil
+}
+
+// Sample 通过 sampling/createMessage 请求发起工具调用的客户端生成回复，使用客户端的模型，不需要单独的 API 密钥
+// 客户端不支持时返回 ErrSamplingUnsupported；客户端可能需要用户确认，调用方应为上下文设置超时
+func Sample(ctx context.Context, request SamplingRequest) (string, error) {
+	client := clientWith(ctx, mcp.CapabilitySampling)
+	if client == nil {
+		return "", ErrSamplingUnsupported
+	}
+
+	maxTokens := request.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultSamplingMaxTokens
+	}
+	params := map[string]interface{}{
+		"messages": []map[string]interface{}{{
+			"role":    "user",
+			"content": map[string]interface{}{"type": "text", "text": request.Prompt},
+		}},
+		"maxTokens":      maxTokens,
+		"includeContext": "none",
+	}
+	if request.SystemPrompt != "" {
+		params["systemPrompt"] = request.SystemPrompt
+	}
+	if len(request.ModelHints) > 0 {
+		hints := make([]map[string]interface{}, 0, len(request.ModelHints))
+		for _, name := range request.ModelHints {
+			hints = append(hints, map[string]interface{}{"name": name})
+		}
+		params["modelPreferences"] = map[string]interface{}{"hints": hints}
+	}
+
+	raw, err := client.Request(ctx, "sampling/createMessage", params)
+	if err != nil {
+		return "", fmt.Errorf("请求客户端生成回复失败: %w", err)
+	}
+	var result samplingResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", fmt.Errorf("解析 sampling/createMessage 响应失败: %w", err)
+	}
+	if result.Content.Type != "text" {
+		return "", fmt.Errorf("客户端返回了不支持的内容类型: %s", result.Content.Type)
+	}
+
+	logging.FromContext(ctx).Printf("客户端通过 sampling 生成了回复: model=%s, stopReason=%s", result.Model, result.StopReason)
+	return result.Content.Text, nil
+}
+
+// ClientSampler 通过发起工具调用的客户端生成回复，可用于结果摘要等需要模型的处理
+type ClientSampler struct {
+	// ModelHints 希望客户端使用的模型名称
+	ModelHints []string
+}
+
+// Available 检查上下文中的客户端是否支持 sampling
+func (s ClientSampler) Available(ctx context.Context) bool {
+	return SamplingAvailable(ctx)
+}
+
+// Complete 按系统提示词和输入请求客户端生成回复
+func (s ClientSampler) Complete(ctx context.Context, system, input string, maxTokens int) (string, error) {
+	return Sample(ctx, SamplingRequest{
+		SystemPrompt: system,
+		Prompt:       input,
+		MaxTokens:    maxTokens,
+		ModelHints:   s.ModelHints,
+	})
+}
+
+// templateSampler 返回转换模板中 sample 函数使用的回调，客户端不支持 sampling 时返回 nil
+func templateSampler(ctx context.Context) func(prompt, input string) (string, error) {
+	if !SamplingAvailable(ctx) {
+		return nil
+	}
+	return func(prompt, input string) (string, error) {
+		return Sample(ctx, SamplingRequest{SystemPrompt: prompt, Prompt: input})
+	}
+}
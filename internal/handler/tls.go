@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/paths"
+)
+
+const (
+	// defaultExpiryWarning 未配置 expiry_warning 时证书到期前开始警告的时间
+	defaultExpiryWarning = 30 * 24 * time.Hour
+	// expiryWarningInterval 同一证书的到期警告最多每隔该时间记录一次
+	expiryWarningInterval = 24 * time.Hour
+	// tlsSessionCacheSize TLS 会话缓存的容量，用于会话恢复
+	tlsSessionCacheSize = 128
+)
+
+// tlsMonitor 统计上游 TLS 握手和会话复用情况，并检查证书有效期
+type tlsMonitor struct {
+	expiryWarning time.Duration
+	clientCert    *x509.Certificate
+
+	mu         sync.Mutex
+	handshakes int64
+	resumed    int64
+	upstreams  map[string]time.Time // 主机名 -> 上游证书到期时间
+	warnedAt   map[string]time.Time // 证书标识 -> 上次警告时间
+}
+
+// newTLSConfig 根据 global.tls 创建访问上游的 TLS 配置，启用 TLS 会话缓存以便复用会话
+func newTLSConfig(cfg *config.UpstreamTLSConfig) (*tls.Config, *tlsMonitor, error) {
+	monitor := &tlsMonitor{
+		expiryWarning: defaultExpiryWarning,
+		upstreams:     make(map[string]time.Time),
+		warnedAt:      make(map[string]time.Time),
+	}
+	tlsConfig := &tls.Config{
+		ClientSessionCache: tls.NewLRUClientSessionCache(tlsSessionCacheSize),
+		VerifyConnection:   monitor.verifyConnection,
+	}
+	if cfg == nil {
+		return tlsConfig, monitor, nil
+	}
+
+	if cfg.ExpiryWarning > 0 {
+		monitor.expiryWarning = cfg.ExpiryWarning
+	}
+	tlsConfig.InsecureSkipVerify = cfg.InsecureSkipVerify
+
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(paths.Resolve(cfg.ClientCert), paths.Resolve(cfg.ClientKey))
+		if err != nil {
+			return nil, nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("解析客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		monitor.clientCert = leaf
+		monitor.checkExpiry("客户端证书", "client", leaf, time.Now())
+	}
+
+	if cfg.CACert != "" {
+		data, err := os.ReadFile(paths.Resolve(cfg.CACert))
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取CA证书失败: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, nil, fmt.Errorf("CA证书 %s 中没有有效的 PEM 证书", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, monitor, nil
+}
+
+// verifyConnection 在每次握手 (包括会话恢复) 后调用，只用于统计，不影响证书校验
+func (m *tlsMonitor) verifyConnection(state tls.ConnectionState) error {
+	m.mu.Lock()
+	m.handshakes++
+	if state.DidResume {
+		m.resumed++
+	}
+	if len(state.PeerCertificates) > 0 {
+		m.upstreams[state.ServerName] = state.PeerCertificates[0].NotAfter
+	}
+	m.mu.Unlock()
+
+	now := time.Now()
+	if len(state.PeerCertificates) > 0 {
+		m.checkExpiry("上游 "+state.ServerName+" 的证书", "upstream:"+state.ServerName, state.PeerCertificates[0], now)
+	}
+	if m.clientCert != nil {
+		m.checkExpiry("客户端证书", "client", m.clientCert, now)
+	}
+	return nil
+}
+
+// checkExpiry 证书已过期或即将到期时记录警告，同一证书每天最多警告一次
+func (m *tlsMonitor) checkExpiry(what, id string, cert *x509.Certificate, now time.Time) {
+	remaining := cert.NotAfter.Sub(now)
+	if remaining > m.expiryWarning {
+		return
+	}
+
+	m.mu.Lock()
+	last, warned := m.warnedAt[id]
+	if warned && now.Sub(last) < expiryWarningInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.warnedAt[id] = now
+	m.mu.Unlock()
+
+	if remaining <= 0 {
+		logging.Logger.Printf("警告: %s (%s) 已于 %s 过期", what, cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339))
+		return
+	}
+	logging.Logger.Printf("警告: %s (%s) 将于 %s 过期，剩余 %.1f 天",
+		what, cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339), daysLeft(cert.NotAfter, now))
+}
+
+// stats 返回握手次数、会话恢复次数和证书到期时间
+func (m *tlsMonitor) stats() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	hosts := make([]string, 0, len(m.upstreams))
+	for host := range m.upstreams {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	upstreams := make([]map[string]interface{}, 0, len(hosts))
+	for _, host := range hosts {
+		upstreams = append(upstreams, certificateStats(m.upstreams[host], now, map[string]interface{}{"host": host}))
+	}
+
+	result := map[string]interface{}{
+		"handshakes": m.handshakes,
+		"resumed":    m.resumed,
+		"upstreams":  upstreams,
+	}
+	if m.handshakes > 0 {
+		result["resume_ratio"] = float64(m.resumed) / float64(m.handshakes)
+	}
+	if m.clientCert != nil {
+		result["client_cert"] = certificateStats(m.clientCert.NotAfter, now, map[string]interface{}{"subject": m.clientCert.Subject.CommonName})
+	}
+	return result
+}
+
+// certificateStats 在 fields 中加入到期时间和剩余天数
+func certificateStats(notAfter, now time.Time, fields map[string]interface{}) map[string]interface{} {
+	fields["not_after"] = notAfter.Format(time.RFC3339)
+	fields["days_left"] = daysLeft(notAfter, now)
+	return fields
+}
+
+// daysLeft 返回剩余天数，保留一位小数并向下取整
+func daysLeft(notAfter, now time.Time) float64 {
+	return math.Floor(notAfter.Sub(now).Hours()/24*10) / 10
+}
+
+// TLSStats 返回上游 TLS 握手、会话复用和证书有效期的统计
+func (h *RequestHandler) TLSStats() map[string]interface{} {
+	return h.tls.stats()
+}
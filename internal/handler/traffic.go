@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// Traffic 一次工具调用与上游之间传输的字节数，包括轮询、获取新建资源等后续请求
+type Traffic struct {
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+type trafficContextKey struct{}
+
+// WithTraffic 返回记录上游流量的上下文，调用结束后从 Traffic 读取字节数
+func WithTraffic(ctx context.Context) (context.Context, *Traffic) {
+	traffic := &Traffic{}
+	return context.WithValue(ctx, trafficContextKey{}, traffic), traffic
+}
+
+// Sent 返回发送的请求体字节数
+func (t *Traffic) Sent() int64 {
+	return t.sent.Load()
+}
+
+// Received 返回接收的响应体字节数 (按传输的原始字节，压缩的响应为压缩后的大小)
+func (t *Traffic) Received() int64 {
+	return t.received.Load()
+}
+
+func trafficFrom(ctx context.Context) *Traffic {
+	traffic, _ := ctx.Value(trafficContextKey{}).(*Traffic)
+	return traffic
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	io.ReadCloser
+	traffic *Traffic
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.traffic.received.Add(int64(n))
+	return n, err
+}
+
+// countTraffic 记录请求体的大小，并统计之后读取的响应体字节数
+func countTraffic(ctx context.Context, sent int64, resp *http.Response) {
+	traffic := trafficFrom(ctx)
+	if traffic == nil {
+		return
+	}
+	if sent > 0 {
+		traffic.sent.Add(sent)
+	}
+	if resp != nil && resp.Body != nil {
+		resp.Body = &countingReader{ReadCloser: resp.Body, traffic: traffic}
+	}
+}
+
+// responseLimit 返回操作的上游响应大小上限和配置项名称，用于错误消息
+// x-mcp-max-response-size 在加载规范时已经检查过
+func (h *RequestHandler) responseLimit(operation *config.Operation) (int64, string) {
+	if operation != nil && operation.MaxResponseSize != "" {
+		if size, err := config.ParseSize(operation.MaxResponseSize); err == nil {
+			return size, "x-mcp-max-response-size"
+		}
+	}
+	if h.config.Global.MaxResponseSize != "" {
+		return h.config.Global.MaxResponseBytes(), "max_response_size"
+	}
+	return h.config.Global.MaxResponseBytes(), "max_request_size"
+}
+
+// responseTooLarge 上游响应超过大小上限的错误
+func responseTooLarge(limit int64, setting string) error {
+	return fmt.Errorf("上游响应超过大小限制 (%s=%d 字节)，已停止读取；可以通过 x-mcp-max-response-size 或 global.max_response_size 调整", setting, limit)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// newTransport 创建访问上游的 Transport：TLS 设置来自 global.tls，
+// 自定义 DialContext 把 unix:// 上游连接到套接字，并按 host_overrides 把主机名连接到指定的地址
+func newTransport(global config.GlobalConfig) (*http.Transport, *tlsMonitor, error) {
+	tlsConfig, monitor, err := newTLSConfig(global.TLS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	overrides, err := parseHostOverrides(global.HostOverrides)
+	if err != nil {
+		return nil, nil, err
+	}
+	dialer := &net.Dialer{Timeout: dialTimeout, KeepAlive: dialKeepAlive}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if socket, exists := unixSocketFor(addr); exists {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+		if target, exists := overrides.resolve(addr); exists {
+			logging.FromContext(ctx).Printf("主机覆盖: %s -> %s", addr, target)
+			addr = target
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return transport, monitor, nil
+}
+
+// transport 返回访问上游使用的 Transport
+func (h *RequestHandler) transport() *http.Transport {
+	return h.httpClient.Transport.(*http.Transport)
+}
+
+const (
+	// dialTimeout 与 dialKeepAlive 与 http.DefaultTransport 的默认值一致
+	dialTimeout   = 30 * time.Second
+	dialKeepAlive = 30 * time.Second
+)
+
+// hostOverrides 主机名到连接地址的映射，键为 "host:port" 或 "host"
+type hostOverrides map[string]string
+
+// parseHostOverrides 校验并规范化 host_overrides，主机名不区分大小写
+func parseHostOverrides(raw map[string]string) (hostOverrides, error) {
+	overrides := make(hostOverrides, len(raw))
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		target := strings.TrimSpace(raw[key])
+		if key == "" || target == "" {
+			return nil, fmt.Errorf("无效的主机覆盖: %q -> %q", key, target)
+		}
+		if strings.Contains(target, "://") || strings.Contains(target, "/") {
+			return nil, fmt.Errorf("主机覆盖 %s 的目标只能是地址或地址:端口: %s", key, target)
+		}
+		overrides[strings.ToLower(key)] = target
+		logging.Logger.Printf("主机覆盖: %s -> %s", key, target)
+	}
+	return overrides, nil
+}
+
+// resolve 返回连接地址，优先匹配 "host:port"，其次匹配 "host"；
+// 目标未指定端口时沿用原始端口
+func (o hostOverrides) resolve(addr string) (string, bool) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", false
+	}
+	host = strings.ToLower(host)
+
+	if target, exists := o[net.JoinHostPort(host, port)]; exists {
+		return withPort(target, port), true
+	}
+	if target, exists := o[host]; exists {
+		return withPort(target, port), true
+	}
+	return "", false
+}
+
+// withPort 目标没有端口时加上 port
+func withPort(target, port string) string {
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target
+	}
+	return net.JoinHostPort(strings.Trim(target, "[]"), port)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	// unixScheme 通过 Unix 域套接字访问上游的 URL 前缀，如 unix:///var/run/docker.sock/v1.43
+	unixScheme = "unix"
+	// unixHostSuffix 改写后的请求使用的主机名后缀，每个套接字对应一个主机名，连接池按套接字区分
+	unixHostSuffix = ".unix.localhost"
+)
+
+// unixSockets 改写后的主机名到套接字路径的映射，由 DialContext 查询
+var unixSockets sync.Map
+
+// rewriteUnixRequest 把 unix:// 请求改写为发往套接字专用主机名的 http 请求
+// 套接字路径与请求路径的分界优先使用实际存在的套接字文件，其次使用以 .sock 结尾的路径段
+func rewriteUnixRequest(req *http.Request) error {
+	if req.URL.Scheme != unixScheme {
+		return nil
+	}
+
+	socket, path, err := splitUnixPath(req.URL.Path)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(socket))
+	host := hex.EncodeToString(sum[:6]) + unixHostSuffix
+	unixSockets.Store(host, socket)
+
+	req.URL.Scheme = "http"
+	req.URL.Host = host
+	req.URL.Path = path
+	req.URL.RawPath = ""
+	// 本地服务 (如 Docker) 通常不关心 Host，使用 localhost 避免暴露内部主机名
+	req.Host = "localhost"
+	return nil
+}
+
+// unixSocketFor 返回改写后的主机地址对应的套接字路径
+func unixSocketFor(addr string) (string, bool) {
+	host := addr
+	if i := strings.LastIndex(addr, ":"); i >= 0 {
+		host = addr[:i]
+	}
+	if !strings.HasSuffix(host, unixHostSuffix) {
+		return "", false
+	}
+	socket, exists := unixSockets.Load(host)
+	if !exists {
+		return "", false
+	}
+	return socket.(string), true
+}
+
+// splitUnixPath 把 /var/run/service.sock/v1/items 拆分为套接字路径和请求路径
+func splitUnixPath(path string) (string, string, error) {
+	for i := 1; i <= len(path); i++ {
+		if i < len(path) && path[i] != '/' {
+			continue
+		}
+		if info, err := os.Stat(path[:i]); err == nil && info.Mode()&os.ModeSocket != 0 {
+			return path[:i], requestPath(path[i:]), nil
+		}
+	}
+
+	// 套接字文件不存在时按 .sock 拆分，连接时再报告错误
+	if i := strings.Index(path, ".sock/"); i >= 0 {
+		return path[:i+len(".sock")], requestPath(path[i+len(".sock"):]), nil
+	}
+	if strings.HasSuffix(path, ".sock") {
+		return path, "/", nil
+	}
+	return "", "", fmt.Errorf("无法从 %s 中确定 Unix 套接字路径 (套接字不存在且路径中没有 .sock)", path)
+}
+
+// requestPath 空路径使用 "/"
+func requestPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
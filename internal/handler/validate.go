@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+)
+
+// validateResponse 按规范中为该状态码声明的 JSON 模式校验响应体，返回不匹配之处
+// 未启用校验、未声明模式或响应不是 JSON 时返回 nil
+func (h *RequestHandler) validateResponse(ctx context.Context, operation *config.Operation, resp *http.Response, body []byte) []openapi.Mismatch {
+	enabled := h.config.Global.ValidateResponses
+	if operation.ValidateResponse != nil {
+		enabled = *operation.ValidateResponse
+	}
+	if !enabled {
+		return nil
+	}
+
+	response, exists := findResponse(operation.Responses, resp.StatusCode)
+	if !exists {
+		return nil
+	}
+	schema, exists := jsonSchema(response.Content)
+	if !exists {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil
+	}
+
+	mismatches := openapi.ValidateValue(h.openAPISpec, schema, data, i18n.FromContext(ctx))
+	if len(mismatches) > 0 {
+		logging.FromContext(ctx).Printf("警告: 响应与规范中声明的模式不匹配 (状态码 %d): %d 处，首个: %s %s",
+			resp.StatusCode, len(mismatches), mismatches[0].Path, mismatches[0].Message)
+	}
+	return mismatches
+}
+
+// jsonSchema 返回响应内容中 JSON 媒体类型的模式，优先使用 application/json
+func jsonSchema(content map[string]config.MediaType) (config.Schema, bool) {
+	if mediaType, exists := content["application/json"]; exists {
+		return mediaType.Schema, true
+	}
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	for _, contentType := range types {
+		if strings.Contains(contentType, "json") {
+			return content[contentType].Schema, true
+		}
+	}
+	return config.Schema{}, false
+}
@@ -0,0 +1,391 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// workflow 已编译的组合工具
+type workflow struct {
+	config config.WorkflowConfig
+	steps  []workflowStep
+	output *gojq.Code
+}
+
+// workflowStep 组合工具中的一个步骤
+type workflowStep struct {
+	name       string
+	call       workflowCall
+	compensate *workflowCall
+}
+
+// workflowCall 一次操作调用，参数由 jq 表达式生成
+type workflowCall struct {
+	operation string
+	method    string // REST 操作的 HTTP 方法，GraphQL 和 gRPC 工具为空
+	params    map[string]*gojq.Code
+}
+
+// loadWorkflows 编译配置的组合工具，引用的操作不存在或表达式无效时返回错误
+func (h *RequestHandler) loadWorkflows(configs []config.WorkflowConfig) (map[string]*workflow, []string, error) {
+	workflows := make(map[string]*workflow, len(configs))
+	order := make([]string, 0, len(configs))
+
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, nil, fmt.Errorf("组合工具缺少 name")
+		}
+		if _, exists := workflows[cfg.Name]; exists {
+			return nil, nil, fmt.Errorf("组合工具名称重复: %s", cfg.Name)
+		}
+		if _, err := h.resolveOperation(cfg.Name); err == nil || isBuiltinTool(cfg.Name) {
+			return nil, nil, fmt.Errorf("组合工具 %s 与已有工具同名", cfg.Name)
+		}
+		if len(cfg.Steps) == 0 {
+			return nil, nil, fmt.Errorf("组合工具 %s 没有配置步骤", cfg.Name)
+		}
+		for _, param := range cfg.Parameters {
+			if param.Name == "" {
+				return nil, nil, fmt.Errorf("组合工具 %s 的参数缺少 name", cfg.Name)
+			}
+		}
+
+		wf := &workflow{config: cfg}
+		seen := make(map[string]bool, len(cfg.Steps))
+		for i, stepCfg := range cfg.Steps {
+			name := stepCfg.Name
+			if name == "" {
+				name = stepCfg.Operation
+			}
+			if seen[name] {
+				return nil, nil, fmt.Errorf("组合工具 %s 的步骤名称重复: %s", cfg.Name, name)
+			}
+			seen[name] = true
+
+			call, err := h.compileWorkflowCall(stepCfg.Operation, stepCfg.Params)
+			if err != nil {
+				return nil, nil, fmt.Errorf("组合工具 %s 的第 %d 个步骤 (%s) 无效: %w", cfg.Name, i+1, name, err)
+			}
+			step := workflowStep{name: name, call: *call}
+			if stepCfg.Compensate != nil {
+				if step.compensate, err = h.compileWorkflowCall(stepCfg.Compensate.Operation, stepCfg.Compensate.Params); err != nil {
+					return nil, nil, fmt.Errorf("组合工具 %s 的步骤 %s 的补偿操作无效: %w", cfg.Name, name, err)
+				}
+			}
+			wf.steps = append(wf.steps, step)
+		}
+
+		if cfg.Output != "" {
+			output, err := compileJQ(cfg.Output)
+			if err != nil {
+				return nil, nil, fmt.Errorf("组合工具 %s 的 output 无效: %w", cfg.Name, err)
+			}
+			wf.output = output
+		}
+
+		workflows[cfg.Name] = wf
+		order = append(order, cfg.Name)
+	}
+	return workflows, order, nil
+}
+
+// compileWorkflowCall 检查操作是否存在并编译参数表达式
+func (h *RequestHandler) compileWorkflowCall(operation string, params map[string]string) (*workflowCall, error) {
+	if operation == "" {
+		return nil, fmt.Errorf("缺少 operation")
+	}
+	method, err := h.resolveOperation(operation)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &workflowCall{operation: operation, method: method, params: make(map[string]*gojq.Code, len(params))}
+	for name, expression := range params {
+		code, err := compileJQ(expression)
+		if err != nil {
+			return nil, fmt.Errorf("参数 %s 的表达式无效: %w", name, err)
+		}
+		call.params[name] = code
+	}
+	return call, nil
+}
+
+// resolveOperation 查找步骤引用的工具，返回 REST 操作的 HTTP 方法
+func (h *RequestHandler) resolveOperation(name string) (string, error) {
+	if h.graphql != nil && h.graphql.HasTool(name) {
+		return "", nil
+	}
+	if h.grpc != nil && h.grpc.HasTool(name) {
+		return "", nil
+	}
+	_, method, _, err := openapi.GetOperationByID(h.openAPISpec, name)
+	return method, err
+}
+
+// compileJQ 解析并编译 jq 表达式
+func compileJQ(expression string) (*gojq.Code, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("解析JQ表达式失败: %w", err)
+	}
+	return gojq.Compile(query)
+}
+
+// evalJQ 执行 jq 表达式并返回第一个结果，没有结果时返回 nil
+func evalJQ(code *gojq.Code, input interface{}) (interface{}, error) {
+	v, ok := code.Run(input).Next()
+	if !ok {
+		return nil, nil
+	}
+	if err, ok := v.(error); ok {
+		return nil, fmt.Errorf("执行JQ表达式失败: %w", err)
+	}
+	return v, nil
+}
+
+// workflowTools 返回组合工具的定义
+func (h *RequestHandler) workflowTools() []map[string]interface{} {
+	tools := make([]map[string]interface{}, 0, len(h.workflowOrder))
+	for _, name := range h.workflowOrder {
+		wf := h.workflows[name]
+
+		description := wf.config.Description
+		if description == "" {
+			operations := make([]string, 0, len(wf.steps))
+			for _, step := range wf.steps {
+				operations = append(operations, step.call.operation)
+			}
+			description = "组合工具: 依次调用 " + strings.Join(operations, " → ")
+		}
+
+		properties := make(map[string]interface{}, len(wf.config.Parameters))
+		required := make([]string, 0, len(wf.config.Parameters))
+		for _, param := range wf.config.Parameters {
+			paramType := param.Type
+			if paramType == "" {
+				paramType = "string"
+			}
+			properties[param.Name] = map[string]interface{}{
+				"type":        paramType,
+				"description": param.Description,
+			}
+			if param.Required {
+				required = append(required, param.Name)
+			}
+		}
+
+		tools = append(tools, map[string]interface{}{
+			"name":        name,
+			"description": description,
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": properties,
+				"required":   required,
+			},
+		})
+	}
+	return tools
+}
+
+// handleWorkflow 执行组合工具，任一步骤需要确认时整个组合工具需要先获得确认
+func (h *RequestHandler) handleWorkflow(ctx context.Context, wf *workflow, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+	for _, param := range wf.config.Parameters {
+		if _, exists := params.Parameters[param.Name]; param.Required && !exists {
+			return nil, invalidParam(ctx, config.Parameter{Name: param.Name, Schema: config.Schema{Type: param.Type}}, "required", "validation.missing_param", param.Name)
+		}
+	}
+
+	if h.workflowRequiresApproval(wf) {
+		operations := make([]string, 0, len(wf.steps))
+		for _, step := range wf.steps {
+			operations = append(operations, step.call.operation)
+		}
+		description := fmt.Sprintf("将依次执行 %s", strings.Join(operations, " → "))
+		if len(params.Parameters) > 0 {
+			if paramBytes, err := json.Marshal(params.Parameters); err == nil {
+				description += fmt.Sprintf("，参数: %s", string(paramBytes))
+			}
+		}
+		return h.approve(ctx, params, description, func() (*mcp.ToolCallResult, error) {
+			return h.runWorkflow(ctx, wf, params.Parameters)
+		})
+	}
+
+	return h.runWorkflow(ctx, wf, params.Parameters)
+}
+
+// workflowRequiresApproval 检查组合工具本身或其中任一步骤是否需要确认
+func (h *RequestHandler) workflowRequiresApproval(wf *workflow) bool {
+	if h.approval.requiresApproval(wf.config.Name, "") {
+		return true
+	}
+	for _, step := range wf.steps {
+		if h.approval.requiresApproval(step.call.operation, step.call.method) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWorkflow 依次执行各步骤，某一步骤失败时按相反顺序执行已完成步骤的补偿操作，
+// 并返回包含每个步骤状态的错误结果
+func (h *RequestHandler) runWorkflow(ctx context.Context, wf *workflow, parameters map[string]interface{}) (*mcp.ToolCallResult, error) {
+	// 组合工具整体计入一次配额，各步骤不单独计算
+	if err := h.takeQuota(ctx, wf.config.Name); err != nil {
+		return nil, err
+	}
+	logger := logging.FromContext(ctx)
+	if parameters == nil {
+		parameters = map[string]interface{}{}
+	}
+
+	stepResults := map[string]interface{}{}
+	state := map[string]interface{}{
+		"params": normalizeJSON(parameters),
+		"steps":  stepResults,
+	}
+	reports := make([]map[string]interface{}, 0, len(wf.steps))
+
+	for i, step := range wf.steps {
+		logger.Printf("组合工具 %s 执行第 %d 个步骤 %s (%s)", wf.config.Name, i+1, step.name, step.call.operation)
+		result, failure := h.callWorkflow(ctx, step.call, state)
+		if failure != nil {
+			logger.Printf("组合工具 %s 的步骤 %s 失败", wf.config.Name, step.name)
+			reports = append(reports, map[string]interface{}{
+				"step":      step.name,
+				"operation": step.call.operation,
+				"status":    "error",
+				"error":     failure,
+			})
+			for _, skipped := range wf.steps[i+1:] {
+				reports = append(reports, map[string]interface{}{
+					"step":      skipped.name,
+					"operation": skipped.call.operation,
+					"status":    "skipped",
+				})
+			}
+
+			errorResult := map[string]interface{}{
+				"message":     fmt.Sprintf("组合工具 %s 在第 %d 个步骤 %s 失败", wf.config.Name, i+1, step.name),
+				"failed_step": step.name,
+				"error":       failure,
+				"steps":       reports,
+			}
+			if compensations := h.compensateWorkflow(ctx, wf, i, state); len(compensations) > 0 {
+				errorResult["compensations"] = compensations
+			}
+			if id := logging.RequestID(ctx); id != "" {
+				errorResult["request_id"] = id
+			}
+			return &mcp.ToolCallResult{
+				Type:   "error",
+				Status: "error",
+				Result: errorResult,
+			}, nil
+		}
+
+		stepResults[step.name] = result
+		reports = append(reports, map[string]interface{}{
+			"step":      step.name,
+			"operation": step.call.operation,
+			"status":    "success",
+		})
+	}
+
+	var output interface{} = stepResults
+	if wf.output != nil {
+		var err error
+		if output, err = evalJQ(wf.output, state); err != nil {
+			return nil, fmt.Errorf("生成组合工具 %s 的结果失败: %w", wf.config.Name, err)
+		}
+	}
+
+	return &mcp.ToolCallResult{
+		Type:   "success",
+		Status: "success",
+		Result: output,
+	}, nil
+}
+
+// compensateWorkflow 按相反顺序执行 failed 之前已完成步骤的补偿操作，返回每个补偿操作的结果
+func (h *RequestHandler) compensateWorkflow(ctx context.Context, wf *workflow, failed int, state map[string]interface{}) []map[string]interface{} {
+	var compensations []map[string]interface{}
+	for i := failed - 1; i >= 0; i-- {
+		step := wf.steps[i]
+		if step.compensate == nil {
+			continue
+		}
+
+		logging.FromContext(ctx).Printf("组合工具 %s 补偿步骤 %s (%s)", wf.config.Name, step.name, step.compensate.operation)
+		report := map[string]interface{}{
+			"step":      step.name,
+			"operation": step.compensate.operation,
+			"status":    "success",
+		}
+		if _, failure := h.callWorkflow(ctx, *step.compensate, state); failure != nil {
+			report["status"] = "error"
+			report["error"] = failure
+		}
+		compensations = append(compensations, report)
+	}
+	return compensations
+}
+
+// callWorkflow 生成参数并调用操作，成功时返回结果，失败时返回错误详情
+// 表达式结果为 null 的参数不会传给操作
+func (h *RequestHandler) callWorkflow(ctx context.Context, call workflowCall, state map[string]interface{}) (interface{}, interface{}) {
+	params := make(map[string]interface{}, len(call.params))
+	for name, code := range call.params {
+		value, err := evalJQ(code, state)
+		if err != nil {
+			return nil, fmt.Sprintf("生成参数 %s 失败: %v", name, err)
+		}
+		if value != nil {
+			params[name] = value
+		}
+	}
+
+	var result *mcp.ToolCallResult
+	var err error
+	switch {
+	case h.graphql != nil && h.graphql.HasTool(call.operation):
+		result, err = h.handleGraphQL(ctx, &mcp.ToolCallParams{Name: call.operation, Parameters: params})
+	case h.grpc != nil && h.grpc.HasTool(call.operation):
+		result, err = h.handleGRPC(ctx, &mcp.ToolCallParams{Name: call.operation, Parameters: params})
+	default:
+		var operation *config.Operation
+		var method, path string
+		operation, method, path, err = openapi.GetOperationByID(h.openAPISpec, call.operation)
+		if err == nil {
+			result, err = h.executeOperation(ctx, operation, method, path, params)
+		}
+	}
+	if err != nil {
+		return nil, err.Error()
+	}
+	if result.Type == "error" {
+		return nil, normalizeJSON(result.Result)
+	}
+	return normalizeJSON(result.Result), nil
+}
+
+// normalizeJSON 把值转换为 JSON 解码得到的通用类型，以便在 jq 表达式中使用
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
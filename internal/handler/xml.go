@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/transformer"
+)
+
+const (
+	soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// buildXMLBody 根据 x-mcp-xml 构建 XML 或 SOAP 请求体，返回请求体和 Content-Type
+func (h *RequestHandler) buildXMLBody(operation *config.Operation, method, path string, params map[string]interface{}) ([]byte, string, error) {
+	cfg := operation.XML
+
+	var content string
+	if cfg.Template != "" {
+		rendered, err := h.transformer.RenderMessage(cfg.Template, params, &transformer.ResponseContext{Params: params})
+		if err != nil {
+			return nil, "", fmt.Errorf("渲染XML请求体失败: %w", err)
+		}
+		content = strings.TrimSpace(rendered)
+	} else {
+		root := cfg.Root
+		if root == "" {
+			root = operation.OperationID
+		}
+		if root == "" {
+			root = generateOperationID(method, path)
+		}
+
+		var sb strings.Builder
+		writeXMLElement(&sb, root, cfg.Namespace, bodyParameters(operation, params))
+		content = sb.String()
+	}
+
+	if !cfg.IsSOAP() {
+		if !strings.HasPrefix(content, "<?xml") {
+			content = xml.Header + content
+		}
+		return []byte(content), "application/xml; charset=utf-8", nil
+	}
+
+	if cfg.SOAPVersion == "1.2" {
+		contentType := "application/soap+xml; charset=utf-8"
+		if cfg.SOAPAction != "" {
+			contentType += fmt.Sprintf(`; action="%s"`, cfg.SOAPAction)
+		}
+		return []byte(soapEnvelope(soap12Namespace, content)), contentType, nil
+	}
+	return []byte(soapEnvelope(soap11Namespace, content)), "text/xml; charset=utf-8", nil
+}
+
+func soapEnvelope(namespace, content string) string {
+	return xml.Header + `<soap:Envelope xmlns:soap="` + namespace + `"><soap:Body>` + content + `</soap:Body></soap:Envelope>`
+}
+
+// bodyParameters 排除路径、查询、请求头和 Cookie 参数后的参数
+func bodyParameters(operation *config.Operation, params map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		result[key] = value
+	}
+	for _, param := range operation.Parameters {
+		if isLocatedParam(param) {
+			delete(result, param.Name)
+		}
+	}
+	return result
+}
+
+// writeXMLElement 将 JSON 值序列化为 XML 元素，"@" 前缀的键作为属性，"#text" 作为文本
+func writeXMLElement(sb *strings.Builder, name, namespace string, value interface{}) {
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			writeXMLElement(sb, name, namespace, item)
+		}
+		return
+	}
+
+	sb.WriteString("<" + name)
+	if namespace != "" {
+		sb.WriteString(` xmlns="` + escapeXML(namespace) + `"`)
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		sb.WriteString(">")
+		if value != nil {
+			sb.WriteString(escapeXML(xmlScalar(value)))
+		}
+		sb.WriteString("</" + name + ">")
+		return
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, "@") {
+			sb.WriteString(" " + key[1:] + `="` + escapeXML(xmlScalar(object[key])) + `"`)
+		}
+	}
+	sb.WriteString(">")
+	if text, exists := object["#text"]; exists {
+		sb.WriteString(escapeXML(xmlScalar(text)))
+	}
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "@") && key != "#text" {
+			writeXMLElement(sb, key, "", object[key])
+		}
+	}
+	sb.WriteString("</" + name + ">")
+}
+
+func xmlScalar(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func escapeXML(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// normalizeXMLBody 将 XML 响应转换为 JSON，SOAP 响应只保留 Body 内容
+func normalizeXMLBody(resp *http.Response, body []byte) []byte {
+	if len(body) == 0 || !transformer.IsXMLContentType(resp.Header.Get("Content-Type")) {
+		return body
+	}
+
+	value, err := transformer.XMLToJSON(body)
+	if err != nil {
+		debug.LogError(requestContext(resp), "转换XML响应失败", err)
+		return body
+	}
+
+	if root, ok := value.(map[string]interface{}); ok {
+		if envelope, ok := root["Envelope"].(map[string]interface{}); ok {
+			if soapBody, exists := envelope["Body"]; exists {
+				value = soapBody
+			}
+		}
+	}
+
+	converted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return converted
+}
+
+// soapFaultMessage 从转换后的 SOAP 响应中提取故障信息，不是故障时返回空字符串
+func soapFaultMessage(body []byte) string {
+	var value map[string]interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return ""
+	}
+	fault, ok := value["Fault"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	// SOAP 1.1 使用 faultstring，SOAP 1.2 使用 Reason/Text
+	if message, ok := fault["faultstring"].(string); ok && message != "" {
+		return message
+	}
+	if reason, ok := fault["Reason"].(map[string]interface{}); ok {
+		switch text := reason["Text"].(type) {
+		case string:
+			return text
+		case map[string]interface{}:
+			if message, ok := text["#text"].(string); ok {
+				return message
+			}
+		}
+	}
+	return "SOAP故障"
+}
@@ -0,0 +1,209 @@
+package i18n
+
+// catalogs 按语言保存消息代码对应的格式字符串，新增消息时需要同时补充所有语言
+// 代码按用途分组：mcp 为 JSON-RPC 错误，http 为 SSE 端点的 HTTP 错误，validation 为参数和响应校验，
+// handler 为工具调用错误，upstream 为上游错误结果，cli 为命令行工具的输出
+var catalogs = map[string]map[string]string{
+	Chinese: {
+		"mcp.parse_error":                    "解析请求失败",
+		"mcp.read_input_failed":              "读取输入失败: %v",
+		"mcp.unsupported_jsonrpc":            "不支持的JSON-RPC版本",
+		"mcp.method_not_found":               "不支持的方法",
+		"mcp.invalid_initialize_params":      "无效的初始化参数",
+		"mcp.invalid_params":                 "无效的参数: %v",
+		"mcp.invalid_arguments":              "工具 %s 的参数不符合规范: %s (详见 error.data.violations)",
+		"mcp.example_call":                   "示例参数: %s",
+		"mcp.example_response":               "示例响应: %s",
+		"mcp.internal_error":                 "内部错误: %v",
+		"mcp.request_failed":                 "处理请求失败: %v",
+		"mcp.request_timeout":                "Request timed out",
+		"mcp.exit_disabled":                  "服务器已禁用 exit 方法",
+		"mcp.shutting_down":                  "服务器正在关闭",
+		"mcp.tool_forbidden":                 "当前客户端无权调用工具 %s",
+		"mcp.rate_limited":                   "调用过于频繁，请在 %d 秒后重试",
+		"mcp.quota_exceeded":                 "工具 %s 超过配额 %s (上限 %d 次)，%s 重置",
+		"mcp.create_response_failed":         "创建响应失败",
+		"mcp.marshal_response_failed":        "序列化响应失败",
+		"mcp.marshal_response_failed_detail": "序列化响应失败: %v",
+		"mcp.tool_error":                     "错误: %v",
+		"mcp.result_truncated":               "\n…(结果已截断，共 %d 个字符)",
+		"mcp.result_summarized":              "\n\n(以上为摘要，原始结果共 %d 个字符，可通过资源 %s 读取)",
+		"mcp.result_raw_description":         "工具 %s 的原始结果 (%d 个字符)",
+		"mcp.resource_missing_uri":           "无效的参数: 缺少 uri",
+		"mcp.resource_not_found":             "资源不存在: %s",
+		"mcp.resource_marshal_failed":        "序列化资源失败: %v",
+		"http.request_too_large":             "请求体超过大小限制 (max_request_size=%d 字节)",
+		"http.read_body_failed":              "读取请求体失败",
+		"http.request_failed":                "处理请求失败",
+		"validation.missing_path_param":      "缺少必需的路径参数: %s",
+		"validation.missing_query_param":     "缺少必需的查询参数: %s",
+		"validation.missing_body_param":      "缺少必需的请求体参数: %s",
+		"validation.missing_param":           "缺少必需参数: %s",
+		"validation.missing_argument":        "%s 缺少 %s 参数",
+		"validation.arguments_not_object":    "%s 的 arguments 必须是对象",
+		"validation.unknown_operation":       "%s 中没有操作 %s",
+		"validation.type_mismatch":           "类型不匹配: 期望 %s, 实际 %s",
+		"validation.unsafe_path_param":       "路径参数 %s 的值 %q 会改变请求路径: %s",
+		"validation.path_empty_value":        "值为空，会产生空的路径段",
+		"validation.path_dot_segment":        "包含相对路径段 (. 或 ..)，会被规范化为其他路径",
+		"validation.path_query_or_fragment":  "包含 ? 或 #，会截断路径",
+		"validation.path_empty_segment":      "包含空的路径段",
+		"validation.invalid_datetime":        "参数 %s 不是可识别的日期时间: %v (支持 RFC 3339、2006-01-02 和 Unix 时间戳)",
+		"validation.invalid_base64":          "参数 %s 不是有效的 base64 编码",
+		"validation.missing_field":           "缺少必需字段",
+		"validation.invalid_param":           "参数 %s: %s",
+		"validation.enum_mismatch":           "取值 %v 不是允许的值之一: %s",
+		"validation.out_of_range":            "取值 %v 超出允许的范围 %s",
+		"validation.length_out_of_range":     "长度 %d 超出允许的范围 %s",
+		"handler.operation_not_found":        "查找操作失败: 未找到操作ID为 %s 的操作",
+		"handler.tool_forbidden":             "当前客户端无权调用工具 %s",
+		"handler.build_request_failed":       "构建HTTP请求失败: %w",
+		"upstream.error_status":              "API返回错误状态码: %d",
+		"upstream.client_error":              "客户端错误",
+		"upstream.clock_skew":                "上游时钟与本地相差 %s，请求签名或令牌有效期校验可能因时钟偏差失败",
+		"upstream.server_error":              "服务器错误",
+		"cli.usage":                          "用法: mcp2rest [-config openapi.yaml]\n      mcp2rest <命令> [参数]\n\n命令:",
+		"cli.unknown_command":                "未知命令: %s",
+		"cli.written":                        "已写入 %s",
+		"cli.write_failed":                   "写入 %s 失败: %w",
+		"cli.read_failed":                    "读取 %s 失败: %w",
+		"cli.flag_output":                    "输出文件，默认写入标准输出",
+		"cli.command.import":                 "从 HAR 文件或 curl 命令生成 OpenAPI 端点配置",
+		"cli.command.export":                 "为 MCP 客户端生成配置片段",
+		"cli.command.presets":                "列出内置的预置 (Docker、GitHub、Jira、Slack 等)，或在本地生成预置的规范和配置",
+		"cli.command.bench":                  "对 stdio/SSE 实例压测并输出延迟分布，或运行进程内基准测试",
+		"cli.command.diff":                   "比较两个规范生成的工具列表，报告新增、删除、重命名的工具和不兼容的参数变化",
+		"cli.command.report":                 "输出每个工具对应的 HTTP 方法、路径、认证方案、参数和响应转换 (Markdown 或 JSON)",
+		"cli.command.env":                    "列出规范和配置需要的环境变量 (认证密钥、令牌)，检查是否已设置或生成 .env.example",
+		"cli.command.secrets":                "生成主密钥，加密或解密凭据文件 .env.enc",
+		"cli.import.usage":                   "用法: mcp2rest import har <file.har> | mcp2rest import curl <命令|文件|->",
+		"cli.import.flag_title":              "生成规范的标题",
+		"cli.import.flag_host":               "只导入该主机的请求 (仅 har)",
+		"cli.import.flag_prefix":             "只导入该前缀下的路径 (仅 har)",
+		"cli.import.missing_input":           "缺少输入",
+		"cli.export.flag_config":             "OpenAPI规范文件路径",
+		"cli.export.flag_server_config":      "服务器配置文件，默认 stdio 客户端使用 configs/stdio.yaml，sse 使用 configs/sse.yaml",
+		"cli.export.flag_client":             "目标客户端: claude、cursor、vscode、stdio、sse",
+		"cli.export.flag_name":               "客户端配置中的服务器名称",
+		"cli.export.flag_binary":             "mcp2rest-stdio 可执行文件路径，默认为当前程序所在目录下的 mcp2rest-stdio",
+		"cli.export.abs_path_failed":         "获取规范文件绝对路径失败: %w",
+		"cli.export.server_config_default":   "未能加载服务器配置，使用默认值: %v",
+		"cli.export.sse_env":                 "SSE 服务器需要在启动时设置环境变量: %s",
+		"cli.export.unsupported_client":      "不支持的客户端: %s (可选 claude、cursor、vscode、stdio、sse)",
+		"cli.export.binary_missing":          "警告: %s 不存在，请先运行 make build-stdio 或使用 -binary 指定路径",
+		"cli.export.generate_failed":         "生成配置失败: %w",
+		"cli.presets.hint":                   "使用 mcp2rest presets init <名称> 在本地生成规范和配置，或直接运行 mcp2rest-stdio -config preset:<名称>",
+		"cli.presets.usage":                  "用法: mcp2rest presets [list] | presets show <名称> | presets init <名称> [-dir 目录] [-force]",
+		"cli.presets.flag_dir":               "目标目录，默认为预置名称",
+		"cli.presets.flag_force":             "覆盖已存在的文件",
+		"cli.presets.exists":                 "%s 已存在，使用 -force 覆盖",
+		"cli.presets.mkdir_failed":           "创建目录 %s 失败: %w",
+		"cli.presets.next_steps":             "下一步:",
+		"cli.presets.step_env":               "把 %s 复制为 %s 并填写凭据",
+		"cli.presets.step_review":            "检查 %s 中的服务器地址和操作",
+		"cli.presets.step_run":               "运行 mcp2rest-stdio -base-dir %s -config %s",
+		"cli.presets.missing_name":           "缺少预置名称，运行 mcp2rest presets 查看可用的预置",
+		"cli.presets.unknown":                "未知的预置: %s，运行 mcp2rest presets 查看可用的预置",
+	},
+	English: {
+		"mcp.parse_error":                    "Parse error",
+		"mcp.read_input_failed":              "Failed to read input: %v",
+		"mcp.unsupported_jsonrpc":            "Unsupported JSON-RPC version",
+		"mcp.method_not_found":               "Method not found",
+		"mcp.invalid_initialize_params":      "Invalid initialize params",
+		"mcp.invalid_params":                 "Invalid params: %v",
+		"mcp.invalid_arguments":              "Invalid arguments for tool %s: %s (see error.data.violations)",
+		"mcp.example_call":                   "Example arguments: %s",
+		"mcp.example_response":               "Example response: %s",
+		"mcp.internal_error":                 "Internal error: %v",
+		"mcp.request_failed":                 "Failed to process request: %v",
+		"mcp.request_timeout":                "Request timed out",
+		"mcp.exit_disabled":                  "The exit method is disabled on this server",
+		"mcp.shutting_down":                  "Server is shutting down",
+		"mcp.tool_forbidden":                 "This client is not allowed to call tool %s",
+		"mcp.rate_limited":                   "Rate limit exceeded, retry in %d seconds",
+		"mcp.quota_exceeded":                 "Tool %s exceeded quota %s (limit %d calls), resets at %s",
+		"mcp.create_response_failed":         "Failed to create response",
+		"mcp.marshal_response_failed":        "Failed to serialize response",
+		"mcp.marshal_response_failed_detail": "Failed to serialize response: %v",
+		"mcp.tool_error":                     "Error: %v",
+		"mcp.result_truncated":               "\n…(result truncated, %d characters in total)",
+		"mcp.result_summarized":              "\n\n(summary of the result; the raw result has %d characters and can be read from resource %s)",
+		"mcp.result_raw_description":         "Raw result of tool %s (%d characters)",
+		"mcp.resource_missing_uri":           "Invalid params: missing uri",
+		"mcp.resource_not_found":             "Resource not found: %s",
+		"mcp.resource_marshal_failed":        "Failed to serialize resource: %v",
+		"http.request_too_large":             "Request body exceeds the size limit (max_request_size=%d bytes)",
+		"http.read_body_failed":              "Failed to read request body",
+		"http.request_failed":                "Failed to process request",
+		"validation.missing_path_param":      "Missing required path parameter: %s",
+		"validation.missing_query_param":     "Missing required query parameter: %s",
+		"validation.missing_body_param":      "Missing required body parameter: %s",
+		"validation.missing_param":           "Missing required parameter: %s",
+		"validation.missing_argument":        "%s: missing %s argument",
+		"validation.arguments_not_object":    "%s: arguments must be an object",
+		"validation.unknown_operation":       "%s has no operation %s",
+		"validation.type_mismatch":           "Type mismatch: expected %s, got %s",
+		"validation.unsafe_path_param":       "Path parameter %s value %q would change the request path: %s",
+		"validation.path_empty_value":        "empty value produces an empty path segment",
+		"validation.path_dot_segment":        "contains a relative segment (. or ..) that is normalized to a different path",
+		"validation.path_query_or_fragment":  "contains ? or # which truncates the path",
+		"validation.path_empty_segment":      "contains an empty path segment",
+		"validation.invalid_datetime":        "Parameter %s is not a recognized date/time: %v (accepted: RFC 3339, 2006-01-02 and Unix timestamps)",
+		"validation.invalid_base64":          "Parameter %s is not valid base64",
+		"validation.missing_field":           "Missing required field",
+		"validation.invalid_param":           "Parameter %s: %s",
+		"validation.enum_mismatch":           "Value %v is not one of the allowed values: %s",
+		"validation.out_of_range":            "Value %v is outside the allowed range %s",
+		"validation.length_out_of_range":     "Length %d is outside the allowed range %s",
+		"handler.operation_not_found":        "Unknown tool: %s",
+		"handler.tool_forbidden":             "This client is not allowed to call tool %s",
+		"handler.build_request_failed":       "Failed to build HTTP request: %w",
+		"upstream.error_status":              "API returned error status: %d",
+		"upstream.client_error":              "Client error",
+		"upstream.clock_skew":                "The upstream clock differs from the local clock by %s; signature or token expiry checks may fail because of clock skew",
+		"upstream.server_error":              "Server error",
+		"cli.usage":                          "Usage: mcp2rest [-config openapi.yaml]\n       mcp2rest <command> [arguments]\n\nCommands:",
+		"cli.unknown_command":                "Unknown command: %s",
+		"cli.written":                        "Wrote %s",
+		"cli.write_failed":                   "Failed to write %s: %w",
+		"cli.read_failed":                    "Failed to read %s: %w",
+		"cli.flag_output":                    "Output file, defaults to standard output",
+		"cli.command.import":                 "Generate OpenAPI endpoint configuration from a HAR file or curl command",
+		"cli.command.export":                 "Generate configuration snippets for MCP clients",
+		"cli.command.presets":                "List built-in presets (Docker, GitHub, Jira, Slack, ...) or write a preset's spec and config locally",
+		"cli.command.bench":                  "Load-test a stdio/SSE instance and print the latency distribution, or run in-process benchmarks",
+		"cli.command.diff":                   "Compare the tool lists generated from two specs and report added, removed and renamed tools and breaking input changes",
+		"cli.command.report":                 "Report the HTTP method, path, auth scheme, parameters and transforms behind each tool (Markdown or JSON)",
+		"cli.command.env":                    "List the environment variables (API keys, tokens) the spec and config need, check that they are set, or generate .env.example",
+		"cli.command.secrets":                "Generate a master key, encrypt or decrypt the credentials file .env.enc",
+		"cli.import.usage":                   "Usage: mcp2rest import har <file.har> | mcp2rest import curl <command|file|->",
+		"cli.import.flag_title":              "Title of the generated spec",
+		"cli.import.flag_host":               "Only import requests to this host (har only)",
+		"cli.import.flag_prefix":             "Only import paths under this prefix (har only)",
+		"cli.import.missing_input":           "Missing input",
+		"cli.export.flag_config":             "Path of the OpenAPI spec",
+		"cli.export.flag_server_config":      "Server configuration file, defaults to configs/stdio.yaml for stdio clients and configs/sse.yaml for sse",
+		"cli.export.flag_client":             "Target client: claude, cursor, vscode, stdio, sse",
+		"cli.export.flag_name":               "Server name in the client configuration",
+		"cli.export.flag_binary":             "Path of the mcp2rest-stdio executable, defaults to mcp2rest-stdio next to this program",
+		"cli.export.abs_path_failed":         "Failed to resolve the absolute path of the spec: %w",
+		"cli.export.server_config_default":   "Could not load the server configuration, using defaults: %v",
+		"cli.export.sse_env":                 "The SSE server needs these environment variables at startup: %s",
+		"cli.export.unsupported_client":      "Unsupported client: %s (choose claude, cursor, vscode, stdio, sse)",
+		"cli.export.binary_missing":          "Warning: %s does not exist, run make build-stdio first or pass -binary",
+		"cli.export.generate_failed":         "Failed to generate configuration: %w",
+		"cli.presets.hint":                   "Run mcp2rest presets init <name> to write the spec and config locally, or run mcp2rest-stdio -config preset:<name> directly",
+		"cli.presets.usage":                  "Usage: mcp2rest presets [list] | presets show <name> | presets init <name> [-dir directory] [-force]",
+		"cli.presets.flag_dir":               "Target directory, defaults to the preset name",
+		"cli.presets.flag_force":             "Overwrite existing files",
+		"cli.presets.exists":                 "%s already exists, use -force to overwrite",
+		"cli.presets.mkdir_failed":           "Failed to create directory %s: %w",
+		"cli.presets.next_steps":             "Next steps:",
+		"cli.presets.step_env":               "Copy %s to %s and fill in the credentials",
+		"cli.presets.step_review":            "Review the server URL and operations in %s",
+		"cli.presets.step_run":               "Run mcp2rest-stdio -base-dir %s -config %s",
+		"cli.presets.missing_name":           "Missing preset name, run mcp2rest presets to list the available presets",
+		"cli.presets.unknown":                "Unknown preset: %s, run mcp2rest presets to list the available presets",
+	},
+}
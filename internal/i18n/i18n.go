@@ -0,0 +1,151 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// 支持的语言
+const (
+	Chinese = "zh"
+	English = "en"
+)
+
+// LocaleEnv 选择语言的环境变量，命令行工具还会参考 LC_ALL、LC_MESSAGES 和 LANG
+const LocaleEnv = "MCP2REST_LOCALE"
+
+// defaultLocale 上下文中没有指定语言时使用的语言
+var defaultLocale atomic.Value
+
+type localeKey struct{}
+
+// SetDefault 设置默认语言，接受 zh、en 以及 zh-CN、en_US.UTF-8 等形式，空字符串表示不修改
+func SetDefault(locale string) error {
+	if locale == "" {
+		return nil
+	}
+	normalized, ok := Normalize(locale)
+	if !ok {
+		return fmt.Errorf("不支持的语言: %s (可选 %s)", locale, strings.Join(Supported(), "、"))
+	}
+	defaultLocale.Store(normalized)
+	return nil
+}
+
+// Default 返回默认语言，未设置时为中文
+func Default() string {
+	if locale, ok := defaultLocale.Load().(string); ok {
+		return locale
+	}
+	return Chinese
+}
+
+// Supported 返回支持的语言，按名称排序
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Normalize 把 zh-CN、en_US.UTF-8 等语言标签转换为支持的语言，不支持时 ok 为 false
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_.@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if _, exists := catalogs[tag]; exists {
+		return tag, true
+	}
+	return "", false
+}
+
+// ParseAcceptLanguage 按权重从 Accept-Language 中选出支持的语言，没有支持的语言时返回空字符串
+func ParseAcceptLanguage(header string) string {
+	best, bestWeight := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, weight := part, 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			tag = part[:i]
+			if q, found := strings.CutPrefix(strings.TrimSpace(part[i+1:]), "q="); found {
+				if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+					weight = parsed
+				}
+			}
+		}
+		locale, ok := Normalize(tag)
+		if ok && weight > bestWeight {
+			best, bestWeight = locale, weight
+		}
+	}
+	return best
+}
+
+// FromEnvironment 返回命令行工具使用的语言：依次检查 MCP2REST_LOCALE、LC_ALL、LC_MESSAGES 和 LANG，
+// 都没有设置或不支持时返回空字符串
+func FromEnvironment() string {
+	for _, name := range []string{LocaleEnv, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		// 按 POSIX 规则，第一个已设置的变量决定语言，C/POSIX 等不支持的值不再继续查找
+		locale, _ := Normalize(value)
+		return locale
+	}
+	return ""
+}
+
+// WithLocale 返回携带语言的上下文，locale 为空或不支持时返回原上下文
+func WithLocale(ctx context.Context, locale string) context.Context {
+	normalized, ok := Normalize(locale)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, localeKey{}, normalized)
+}
+
+// FromContext 返回上下文中的语言，没有时返回默认语言
+func FromContext(ctx context.Context) string {
+	if ctx != nil {
+		if locale, ok := ctx.Value(localeKey{}).(string); ok {
+			return locale
+		}
+	}
+	return Default()
+}
+
+// Message 返回消息代码在指定语言中的格式字符串，缺少翻译时依次使用默认语言和中文，都没有时返回代码本身
+func Message(locale, code string) string {
+	for _, candidate := range []string{locale, Default(), Chinese} {
+		if message, exists := catalogs[candidate][code]; exists {
+			return message
+		}
+	}
+	return code
+}
+
+// T 返回格式化后的消息
+func T(locale, code string, args ...interface{}) string {
+	if len(args) == 0 {
+		return Message(locale, code)
+	}
+	return fmt.Sprintf(Message(locale, code), args...)
+}
+
+// Tc 按上下文中的语言返回格式化后的消息
+func Tc(ctx context.Context, code string, args ...interface{}) string {
+	return T(FromContext(ctx), code, args...)
+}
+
+// Errorf 按上下文中的语言创建错误，消息中的 %w 与 fmt.Errorf 相同
+func Errorf(ctx context.Context, code string, args ...interface{}) error {
+	return fmt.Errorf(Message(FromContext(ctx), code), args...)
+}
@@ -0,0 +1,282 @@
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 需要参数但与导入无关的 curl 选项
+var curlValueFlags = map[string]bool{
+	"-A": true, "--user-agent": true, "-e": true, "--referer": true, "-b": true, "--cookie": true,
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"-x": true, "--proxy": true, "-w": true, "--write-out": true, "--cert": true, "--key": true,
+	"--cacert": true, "--retry": true, "-c": true, "--cookie-jar": true, "-E": true, "--resolve": true,
+}
+
+// SplitCurlCommands 将包含多条 curl 命令的文本拆分为单独的命令
+func SplitCurlCommands(text string) []string {
+	// 先合并反斜杠续行
+	text = strings.ReplaceAll(text, "\\\r\n", " ")
+	text = strings.ReplaceAll(text, "\\\n", " ")
+
+	var commands []string
+	var current strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "curl ") && current.Len() > 0 {
+			commands = append(commands, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		commands = append(commands, current.String())
+	}
+	return commands
+}
+
+// ParseCurl 解析 curl 命令（例如浏览器的 "Copy as cURL"）
+func ParseCurl(command string) (Sample, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return Sample{}, fmt.Errorf("解析curl命令失败: %w", err)
+	}
+	if len(args) > 0 && (args[0] == "curl" || strings.HasSuffix(args[0], "/curl")) {
+		args = args[1:]
+	}
+
+	sample := Sample{Header: make(http.Header)}
+	var rawURL string
+	var data []string
+	var get bool
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		flag, value, hasValue := arg, "", false
+
+		// --flag=value 和 -Xvalue 形式
+		if strings.HasPrefix(arg, "--") {
+			if eq := strings.Index(arg, "="); eq > 0 {
+				flag, value, hasValue = arg[:eq], arg[eq+1:], true
+			}
+		} else if strings.HasPrefix(arg, "-") && len(arg) > 2 && strings.ContainsAny(arg[1:2], "XHdFuAbeomx") {
+			flag, value, hasValue = arg[:2], arg[2:], true
+		}
+
+		needValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("curl选项 %s 缺少参数", flag)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch flag {
+		case "-X", "--request":
+			if sample.Method, err = needValue(); err != nil {
+				return Sample{}, err
+			}
+			sample.Method = strings.ToUpper(sample.Method)
+		case "-H", "--header":
+			header, err := needValue()
+			if err != nil {
+				return Sample{}, err
+			}
+			if colon := strings.Index(header, ":"); colon > 0 {
+				sample.Header.Add(strings.TrimSpace(header[:colon]), strings.TrimSpace(header[colon+1:]))
+			}
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw", "--data-urlencode":
+			d, err := needValue()
+			if err != nil {
+				return Sample{}, err
+			}
+			if strings.HasPrefix(d, "@") && flag != "--data-raw" {
+				content, err := ioutil.ReadFile(d[1:])
+				if err != nil {
+					return Sample{}, fmt.Errorf("读取curl数据文件失败: %w", err)
+				}
+				d = string(content)
+			}
+			if flag == "--data-urlencode" {
+				d = encodeCurlData(d)
+			}
+			data = append(data, d)
+		case "--json":
+			d, err := needValue()
+			if err != nil {
+				return Sample{}, err
+			}
+			data = append(data, d)
+			sample.Header.Set("Content-Type", "application/json")
+			sample.Header.Set("Accept", "application/json")
+		case "-F", "--form":
+			field, err := needValue()
+			if err != nil {
+				return Sample{}, err
+			}
+			data = append(data, field)
+			if sample.Header.Get("Content-Type") == "" {
+				sample.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+		case "-u", "--user":
+			credentials, err := needValue()
+			if err != nil {
+				return Sample{}, err
+			}
+			sample.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+		case "-G", "--get":
+			get = true
+		case "--url":
+			if rawURL, err = needValue(); err != nil {
+				return Sample{}, err
+			}
+		default:
+			if curlValueFlags[flag] {
+				if _, err := needValue(); err != nil {
+					return Sample{}, err
+				}
+			} else if !strings.HasPrefix(arg, "-") && rawURL == "" {
+				rawURL = arg
+			}
+		}
+	}
+
+	if rawURL == "" {
+		return Sample{}, fmt.Errorf("curl命令中没有URL")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Sample{}, fmt.Errorf("无效的URL %s: %w", rawURL, err)
+	}
+	sample.URL = u
+
+	body := strings.Join(data, "&")
+	if get {
+		if body != "" {
+			if u.RawQuery != "" {
+				u.RawQuery += "&"
+			}
+			u.RawQuery += body
+		}
+		if sample.Method == "" {
+			sample.Method = "GET"
+		}
+	} else if body != "" {
+		sample.Body = []byte(body)
+		if sample.Header.Get("Content-Type") == "" {
+			sample.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if sample.Method == "" {
+			sample.Method = "POST"
+		}
+	}
+	if sample.Method == "" {
+		sample.Method = "GET"
+	}
+
+	return sample, nil
+}
+
+// encodeCurlData 按 --data-urlencode 的规则编码 name=value
+func encodeCurlData(d string) string {
+	if eq := strings.Index(d, "="); eq >= 0 {
+		return d[:eq+1] + url.QueryEscape(d[eq+1:])
+	}
+	return url.QueryEscape(d)
+}
+
+// splitShellWords 按 POSIX shell 规则拆分参数，支持单引号、双引号和 $'...'
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			inWord = true
+			if i+1 < len(s) {
+				i++
+				if s[i] != '\n' {
+					current.WriteByte(s[i])
+				}
+			}
+		case c == '\'':
+			inWord = true
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("单引号未闭合")
+			}
+			current.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			inWord = true
+			i += 2
+			for ; i < len(s) && s[i] != '\''; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+					switch s[i] {
+					case 'n':
+						current.WriteByte('\n')
+					case 't':
+						current.WriteByte('\t')
+					case 'r':
+						current.WriteByte('\r')
+					default:
+						current.WriteByte(s[i])
+					}
+					continue
+				}
+				current.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("$'...' 引号未闭合")
+			}
+		case c == '"':
+			inWord = true
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) >= 0 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("双引号未闭合")
+			}
+		default:
+			inWord = true
+			current.WriteByte(c)
+		}
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
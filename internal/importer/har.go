@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// harFile 表示 HAR 1.2 文件中用到的部分
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method   string      `json:"method"`
+				URL      string      `json:"url"`
+				Headers  []harHeader `json:"headers"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status     int         `json:"status"`
+				StatusText string      `json:"statusText"`
+				Headers    []harHeader `json:"headers"`
+				Content    struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+type harHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARFilter 控制从 HAR 中选择哪些请求
+type HARFilter struct {
+	Host       string // 只导入该主机的请求
+	PathPrefix string // 只导入该前缀下的路径
+}
+
+// 浏览器录制中与 API 无关的资源类型
+var staticMimePrefixes = []string{"image/", "font/", "audio/", "video/", "text/css", "text/html", "application/javascript", "text/javascript", "application/x-javascript"}
+
+// ParseHAR 解析 HAR 文件，跳过静态资源和 CORS 预检请求
+func ParseHAR(data []byte, filter HARFilter) ([]Sample, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("解析HAR文件失败: %w", err)
+	}
+
+	var samples []Sample
+	for _, entry := range har.Log.Entries {
+		if strings.EqualFold(entry.Request.Method, "OPTIONS") || isStaticMime(entry.Response.Content.MimeType) {
+			continue
+		}
+
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if filter.Host != "" && !strings.EqualFold(u.Hostname(), filter.Host) {
+			continue
+		}
+		if filter.PathPrefix != "" && !strings.HasPrefix(u.Path, filter.PathPrefix) {
+			continue
+		}
+
+		sample := Sample{
+			Method:       strings.ToUpper(entry.Request.Method),
+			URL:          u,
+			Header:       harHeaders(entry.Request.Headers),
+			Status:       entry.Response.Status,
+			StatusText:   entry.Response.StatusText,
+			ResponseType: entry.Response.Content.MimeType,
+		}
+		if entry.Request.PostData != nil {
+			sample.Body = []byte(entry.Request.PostData.Text)
+			if sample.Header.Get("Content-Type") == "" && entry.Request.PostData.MimeType != "" {
+				sample.Header.Set("Content-Type", entry.Request.PostData.MimeType)
+			}
+		}
+
+		body := entry.Response.Content.Text
+		if entry.Response.Content.Encoding == "base64" {
+			if decoded, err := base64.StdEncoding.DecodeString(body); err == nil {
+				body = string(decoded)
+			}
+		}
+		sample.ResponseBody = []byte(body)
+
+		samples = append(samples, sample)
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("HAR文件中没有匹配的API请求")
+	}
+	return samples, nil
+}
+
+func harHeaders(headers []harHeader) http.Header {
+	result := make(http.Header)
+	for _, h := range headers {
+		// HTTP/2 伪头部不是真正的请求头
+		if strings.HasPrefix(h.Name, ":") {
+			continue
+		}
+		result.Add(h.Name, h.Value)
+	}
+	return result
+}
+
+func isStaticMime(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	for _, prefix := range staticMimePrefixes {
+		if strings.HasPrefix(mimeType, prefix) {
+			return true
+		}
+	}
+	return false
+}
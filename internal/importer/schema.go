@@ -0,0 +1,162 @@
+package importer
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// schema 表示从样本推断出的 JSON Schema
+type schema struct {
+	Type       string
+	Format     string
+	Properties map[string]*schema
+	seen       map[string]int // 属性在样本中出现的次数，用于推断 required
+	samples    int
+	Items      *schema
+	Example    interface{}
+}
+
+// inferSchema 根据 JSON 值推断 Schema
+func inferSchema(value interface{}) *schema {
+	s := &schema{samples: 1}
+	switch v := value.(type) {
+	case nil:
+		// null 无法推断类型
+	case bool:
+		s.Type = "boolean"
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			s.Type = "integer"
+		} else {
+			s.Type = "number"
+		}
+	case string:
+		s.Type = "string"
+		s.Format = stringFormat(v)
+	case []interface{}:
+		s.Type = "array"
+		for _, item := range v {
+			s.Items = mergeSchema(s.Items, inferSchema(item))
+		}
+	case map[string]interface{}:
+		s.Type = "object"
+		s.Properties = make(map[string]*schema, len(v))
+		s.seen = make(map[string]int, len(v))
+		for key, item := range v {
+			s.Properties[key] = inferSchema(item)
+			s.seen[key] = 1
+		}
+	}
+	return s
+}
+
+// mergeSchema 合并两个样本推断出的 Schema
+func mergeSchema(a, b *schema) *schema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	switch {
+	case a.Type == "":
+		a.Type, a.Format = b.Type, b.Format
+	case b.Type == "" || a.Type == b.Type:
+	case (a.Type == "integer" && b.Type == "number") || (a.Type == "number" && b.Type == "integer"):
+		a.Type = "number"
+	}
+	if a.Format != b.Format {
+		a.Format = ""
+	}
+
+	if a.Type == "array" {
+		a.Items = mergeSchema(a.Items, b.Items)
+	}
+	if a.Type == "object" && b.Type == "object" {
+		if a.Properties == nil {
+			a.Properties = make(map[string]*schema)
+			a.seen = make(map[string]int)
+		}
+		for key, property := range b.Properties {
+			a.Properties[key] = mergeSchema(a.Properties[key], property)
+			a.seen[key] += b.seen[key]
+		}
+	}
+	a.samples += b.samples
+	return a
+}
+
+// stringFormat 推断字符串格式
+func stringFormat(s string) string {
+	if uuidPattern.MatchString(s) {
+		return "uuid"
+	}
+	if _, err := time.Parse(time.RFC3339, s); err == nil {
+		return "date-time"
+	}
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return "date"
+	}
+	return ""
+}
+
+// node 将 Schema 转换为 YAML 节点
+func (s *schema) node() *yaml.Node {
+	n := newMapping()
+	if s.Type != "" {
+		set(n, "type", s.Type)
+	}
+	if s.Format != "" {
+		set(n, "format", s.Format)
+	}
+
+	if len(s.Properties) > 0 {
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		properties := newMapping()
+		var required []string
+		for _, name := range names {
+			set(properties, name, s.Properties[name].node())
+			if s.seen[name] == s.samples {
+				required = append(required, name)
+			}
+		}
+		set(n, "properties", properties)
+		if len(required) > 0 {
+			set(n, "required", required)
+		}
+	}
+
+	if s.Items != nil {
+		set(n, "items", s.Items.node())
+	}
+	return n
+}
+
+func newMapping() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode}
+}
+
+// set 按插入顺序向映射节点添加键值
+func set(n *yaml.Node, key string, value interface{}) {
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	valueNode, ok := value.(*yaml.Node)
+	if !ok {
+		valueNode = &yaml.Node{}
+		if err := valueNode.Encode(value); err != nil {
+			valueNode = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		}
+	}
+	n.Content = append(n.Content, keyNode, valueNode)
+}
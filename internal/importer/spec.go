@@ -0,0 +1,539 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sample 表示一次记录下来的 HTTP 调用
+type Sample struct {
+	Method       string
+	URL          *url.URL
+	Header       http.Header
+	Body         []byte
+	Status       int // 没有响应时为 0
+	StatusText   string
+	ResponseType string // 响应的 Content-Type
+	ResponseBody []byte
+}
+
+// Options 控制规范的生成
+type Options struct {
+	Title       string
+	Description string
+}
+
+var (
+	hexIDPattern   = regexp.MustCompile(`^[0-9a-fA-F]{12,}$`)
+	tokenIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{20,}$`)
+	digitPattern   = regexp.MustCompile(`[0-9]`)
+)
+
+// 不作为参数导出的请求头
+var ignoredHeaders = map[string]bool{
+	"Accept": true, "Accept-Encoding": true, "Accept-Language": true, "Cache-Control": true,
+	"Connection": true, "Content-Length": true, "Content-Type": true, "Cookie": true,
+	"Host": true, "Origin": true, "Pragma": true, "Referer": true, "User-Agent": true,
+	"X-Requested-With": true, "Authorization": true,
+}
+
+// endpoint 汇总同一方法和路径模板下的样本
+type endpoint struct {
+	method     string
+	path       string
+	pathParams []pathParam
+	query      map[string]*paramStats
+	headers    map[string]*paramStats
+	samples    int
+	bodyType   string
+	body       *schema
+	form       map[string]*paramStats
+	responses  map[int]*responseStats
+	auth       map[string]bool // 样本中出现的安全方案
+}
+
+type pathParam struct {
+	name    string
+	example string
+}
+
+type paramStats struct {
+	count   int
+	example string
+}
+
+type responseStats struct {
+	description string
+	contentType string
+	body        *schema
+}
+
+// BuildSpec 根据样本生成 OpenAPI 3 规范 (YAML)
+func BuildSpec(samples []Sample, opts Options) ([]byte, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("没有可导入的请求")
+	}
+
+	var servers []string
+	serverSeen := make(map[string]bool)
+	endpoints := make(map[string]*endpoint)
+	var order []string
+	auth := make(map[string]*yaml.Node)
+
+	for _, sample := range samples {
+		server := sample.URL.Scheme + "://" + sample.URL.Host
+		if !serverSeen[server] {
+			serverSeen[server] = true
+			servers = append(servers, server)
+		}
+
+		path, params := templatePath(sample.URL.Path)
+		method := strings.ToLower(sample.Method)
+		key := method + " " + path
+		ep, exists := endpoints[key]
+		if !exists {
+			ep = &endpoint{
+				method:     method,
+				path:       path,
+				pathParams: params,
+				query:      make(map[string]*paramStats),
+				headers:    make(map[string]*paramStats),
+				form:       make(map[string]*paramStats),
+				responses:  make(map[int]*responseStats),
+				auth:       make(map[string]bool),
+			}
+			endpoints[key] = ep
+			order = append(order, key)
+		}
+		ep.add(sample)
+		for _, name := range detectAuth(sample.Header, auth) {
+			ep.auth[name] = true
+		}
+	}
+
+	doc := newMapping()
+	set(doc, "openapi", "3.0.3")
+
+	info := newMapping()
+	title := opts.Title
+	if title == "" {
+		title = "Imported API"
+	}
+	set(info, "title", title)
+	if opts.Description != "" {
+		set(info, "description", opts.Description)
+	}
+	set(info, "version", "1.0.0")
+	set(doc, "info", info)
+
+	serverList := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, server := range servers {
+		s := newMapping()
+		set(s, "url", server)
+		serverList.Content = append(serverList.Content, s)
+	}
+	set(doc, "servers", serverList)
+
+	// 路径按字母顺序输出，同一路径下的方法保持出现顺序
+	sort.SliceStable(order, func(i, j int) bool {
+		return endpoints[order[i]].path < endpoints[order[j]].path
+	})
+	paths := newMapping()
+	pathNodes := make(map[string]*yaml.Node)
+	for _, key := range order {
+		ep := endpoints[key]
+		pathNode, exists := pathNodes[ep.path]
+		if !exists {
+			pathNode = newMapping()
+			pathNodes[ep.path] = pathNode
+			set(paths, ep.path, pathNode)
+		}
+		set(pathNode, ep.method, ep.node())
+	}
+	set(doc, "paths", paths)
+
+	if len(auth) > 0 {
+		names := make([]string, 0, len(auth))
+		for name := range auth {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		schemes := newMapping()
+		for _, name := range names {
+			set(schemes, name, auth[name])
+		}
+		components := newMapping()
+		set(components, "securitySchemes", schemes)
+		set(doc, "components", components)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("生成YAML失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// add 将样本合并到端点中
+func (ep *endpoint) add(sample Sample) {
+	ep.samples++
+
+	for name, values := range sample.URL.Query() {
+		stats := ep.query[name]
+		if stats == nil {
+			stats = &paramStats{}
+			ep.query[name] = stats
+		}
+		stats.count++
+		if stats.example == "" && len(values) > 0 {
+			stats.example = values[0]
+		}
+	}
+
+	for name, values := range sample.Header {
+		name = http.CanonicalHeaderKey(name)
+		if ignoredHeaders[name] || isAPIKeyHeader(name) || strings.HasPrefix(name, "Sec-") || strings.HasPrefix(name, ":") {
+			continue
+		}
+		stats := ep.headers[name]
+		if stats == nil {
+			stats = &paramStats{}
+			ep.headers[name] = stats
+		}
+		stats.count++
+		if stats.example == "" && len(values) > 0 {
+			stats.example = values[0]
+		}
+	}
+
+	if len(sample.Body) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(sample.Header.Get("Content-Type"))
+		var value interface{}
+		switch {
+		case mediaType == "application/x-www-form-urlencoded":
+			ep.bodyType = mediaType
+			if form, err := url.ParseQuery(string(sample.Body)); err == nil {
+				for name, values := range form {
+					stats := ep.form[name]
+					if stats == nil {
+						stats = &paramStats{}
+						ep.form[name] = stats
+					}
+					stats.count++
+					if stats.example == "" && len(values) > 0 {
+						stats.example = values[0]
+					}
+				}
+			}
+		case json.Unmarshal(sample.Body, &value) == nil:
+			ep.bodyType = "application/json"
+			ep.body = mergeSchema(ep.body, inferSchema(value))
+		default:
+			if mediaType == "" {
+				mediaType = "text/plain"
+			}
+			ep.bodyType = mediaType
+			if ep.body == nil {
+				ep.body = &schema{Type: "string", samples: 1}
+			}
+		}
+	}
+
+	if sample.Status > 0 {
+		resp := ep.responses[sample.Status]
+		if resp == nil {
+			description := sample.StatusText
+			if description == "" {
+				description = http.StatusText(sample.Status)
+			}
+			resp = &responseStats{description: description}
+			ep.responses[sample.Status] = resp
+		}
+		mediaType, _, _ := mime.ParseMediaType(sample.ResponseType)
+		var value interface{}
+		if len(sample.ResponseBody) > 0 && json.Unmarshal(sample.ResponseBody, &value) == nil {
+			resp.contentType = "application/json"
+			resp.body = mergeSchema(resp.body, inferSchema(value))
+		} else if mediaType != "" && resp.contentType == "" && len(sample.ResponseBody) > 0 {
+			resp.contentType = mediaType
+		}
+	}
+}
+
+// node 生成操作的 YAML 节点
+func (ep *endpoint) node() *yaml.Node {
+	op := newMapping()
+	set(op, "operationId", operationID(ep.method, ep.path))
+	set(op, "summary", strings.ToUpper(ep.method)+" "+ep.path)
+
+	params := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, p := range ep.pathParams {
+		params.Content = append(params.Content, parameterNode(p.name, "path", true, p.example))
+	}
+	for _, name := range sortedKeys(ep.query) {
+		stats := ep.query[name]
+		params.Content = append(params.Content, parameterNode(name, "query", stats.count == ep.samples, stats.example))
+	}
+	for _, name := range sortedKeys(ep.headers) {
+		stats := ep.headers[name]
+		params.Content = append(params.Content, parameterNode(name, "header", stats.count == ep.samples, stats.example))
+	}
+	if len(params.Content) > 0 {
+		set(op, "parameters", params)
+	}
+
+	if ep.bodyType != "" {
+		bodySchema := ep.body
+		if ep.bodyType == "application/x-www-form-urlencoded" {
+			bodySchema = &schema{Type: "object", Properties: make(map[string]*schema), seen: make(map[string]int), samples: ep.samples}
+			for name, stats := range ep.form {
+				bodySchema.Properties[name] = &schema{Type: scalarType(stats.example)}
+				bodySchema.seen[name] = stats.count
+			}
+		}
+		media := newMapping()
+		set(media, "schema", bodySchema.node())
+		content := newMapping()
+		set(content, ep.bodyType, media)
+		body := newMapping()
+		set(body, "required", true)
+		set(body, "content", content)
+		set(op, "requestBody", body)
+	}
+
+	responses := newMapping()
+	codes := make([]int, 0, len(ep.responses))
+	for code := range ep.responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		resp := ep.responses[code]
+		r := newMapping()
+		set(r, "description", resp.description)
+		if resp.contentType != "" {
+			media := newMapping()
+			if resp.body != nil {
+				set(media, "schema", resp.body.node())
+			}
+			content := newMapping()
+			set(content, resp.contentType, media)
+			set(r, "content", content)
+		}
+		set(responses, strconv.Itoa(code), r)
+	}
+	if len(codes) == 0 {
+		r := newMapping()
+		set(r, "description", "OK")
+		set(responses, "200", r)
+	}
+	set(op, "responses", responses)
+
+	// 处理器按操作上的 security 应用身份验证
+	if len(ep.auth) > 0 {
+		names := make([]string, 0, len(ep.auth))
+		for name := range ep.auth {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		security := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, name := range names {
+			requirement := newMapping()
+			set(requirement, name, []string{})
+			security.Content = append(security.Content, requirement)
+		}
+		set(op, "security", security)
+	}
+	return op
+}
+
+func parameterNode(name, in string, required bool, example string) *yaml.Node {
+	p := newMapping()
+	set(p, "name", name)
+	set(p, "in", in)
+	set(p, "required", required)
+	typ := scalarType(example)
+	s := newMapping()
+	set(s, "type", typ)
+	set(p, "schema", s)
+	if example != "" && in != "header" {
+		set(p, "example", typedExample(typ, example))
+	}
+	return p
+}
+
+// templatePath 将看起来像标识符的路径段替换为路径参数
+func templatePath(path string) (string, []pathParam) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var params []pathParam
+	used := make(map[string]int)
+
+	for i, segment := range segments {
+		if !isIdentifierSegment(segment) {
+			continue
+		}
+
+		name := "id"
+		if i > 0 && !strings.HasPrefix(segments[i-1], "{") {
+			name = lowerCamel(singular(segments[i-1])) + "Id"
+		}
+		used[name]++
+		if used[name] > 1 {
+			name += strconv.Itoa(used[name])
+		}
+
+		params = append(params, pathParam{name: name, example: segment})
+		segments[i] = "{" + name + "}"
+	}
+
+	return "/" + strings.Join(segments, "/"), params
+}
+
+func isIdentifierSegment(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if _, err := strconv.ParseInt(segment, 10, 64); err == nil {
+		return true
+	}
+	if uuidPattern.MatchString(segment) {
+		return true
+	}
+	if hexIDPattern.MatchString(segment) && digitPattern.MatchString(segment) {
+		return true
+	}
+	return tokenIDPattern.MatchString(segment) && digitPattern.MatchString(segment)
+}
+
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ses") || strings.HasSuffix(word, "xes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// lowerCamel 将 user-groups、user_groups 转换为 userGroups
+func lowerCamel(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for i := range parts {
+		if i > 0 && parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// operationID 使用与工具名称相同的规则生成操作ID
+func operationID(method, path string) string {
+	var result []string
+	for _, part := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			continue
+		}
+		if len(part) > 0 {
+			result = append(result, strings.Title(part))
+		}
+	}
+	return strings.ToLower(method) + strings.Join(result, "")
+}
+
+func scalarType(example string) string {
+	if example == "" {
+		return "string"
+	}
+	if _, err := strconv.ParseInt(example, 10, 64); err == nil {
+		return "integer"
+	}
+	if _, err := strconv.ParseFloat(example, 64); err == nil {
+		return "number"
+	}
+	if example == "true" || example == "false" {
+		return "boolean"
+	}
+	return "string"
+}
+
+// typedExample 按推断的类型输出示例值
+func typedExample(typ, example string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(example, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(example, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		return example == "true"
+	}
+	return example
+}
+
+// detectAuth 根据请求头推断安全方案，返回样本使用的方案名称
+func detectAuth(header http.Header, auth map[string]*yaml.Node) []string {
+	var names []string
+	if value := header.Get("Authorization"); value != "" {
+		scheme := newMapping()
+		switch {
+		case strings.HasPrefix(strings.ToLower(value), "bearer "):
+			set(scheme, "type", "http")
+			set(scheme, "scheme", "bearer")
+			auth["bearerAuth"] = scheme
+			names = append(names, "bearerAuth")
+		case strings.HasPrefix(strings.ToLower(value), "basic "):
+			set(scheme, "type", "http")
+			set(scheme, "scheme", "basic")
+			auth["basicAuth"] = scheme
+			names = append(names, "basicAuth")
+		}
+	}
+
+	for name := range header {
+		name = http.CanonicalHeaderKey(name)
+		if isAPIKeyHeader(name) {
+			scheme := newMapping()
+			set(scheme, "type", "apiKey")
+			set(scheme, "in", "header")
+			set(scheme, "name", name)
+			auth["apiKeyAuth"] = scheme
+			names = append(names, "apiKeyAuth")
+		}
+	}
+	return names
+}
+
+func isAPIKeyHeader(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "x-api-key" || lower == "api-key" || lower == "apikey" || lower == "x-auth-token"
+}
+
+func sortedKeys(m map[string]*paramStats) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"regexp"
+)
+
+// RequestIDHeader 传递请求关联ID的 HTTP 头，SSE 客户端可通过它指定ID，上游请求也会携带它
+const RequestIDHeader = "X-Request-Id"
+
+// validRequestID 接受客户端提供的关联ID的格式，避免日志注入
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestKey struct{}
+
+// requestScope 保存在上下文中的关联ID和带前缀的日志器
+type requestScope struct {
+	id     string
+	logger *log.Logger
+}
+
+// NewRequestID 生成新的请求关联ID
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
+
+// ValidRequestID 检查客户端提供的关联ID是否可以直接使用
+func ValidRequestID(id string) bool {
+	return validRequestID.MatchString(id)
+}
+
+// WithRequestID 返回携带关联ID的上下文，通过 FromContext 获取的日志器会在每行前加上该ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	scope := &requestScope{id: id}
+	if Logger != nil {
+		scope.logger = log.New(Logger.Writer(), "[req "+id+"] ", Logger.Flags()|log.Lmsgprefix)
+	}
+	return context.WithValue(ctx, requestKey{}, scope)
+}
+
+// RequestID 返回上下文中的关联ID，没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if scope, ok := ctx.Value(requestKey{}).(*requestScope); ok {
+		return scope.id
+	}
+	return ""
+}
+
+// FromContext 返回带有关联ID前缀的日志器，上下文中没有关联ID时返回全局日志器
+func FromContext(ctx context.Context) *log.Logger {
+	if ctx != nil {
+		if scope, ok := ctx.Value(requestKey{}).(*requestScope); ok && scope.logger != nil {
+			return scope.logger
+		}
+	}
+	return Logger
+}
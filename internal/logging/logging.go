@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+
+	"github.com/mcp2rest/internal/paths"
 )
 
 var Logger *log.Logger
@@ -16,16 +18,13 @@ func InitLogger() error {
 		return fmt.Errorf("无法获取可执行文件路径: %v", err)
 	}
 
-	// 获取可执行文件所在目录
-	exeDir := filepath.Dir(exePath)
-
-	// 如果可执行文件在 bin 目录下，使用上级目录
-	if filepath.Base(exeDir) == "bin" {
-		exeDir = filepath.Dir(exeDir)
+	// 获取日志目录 (基础目录或可执行文件目录下的 logs)
+	logDir, err := paths.LogDir()
+	if err != nil {
+		return fmt.Errorf("无法确定日志目录: %v", err)
 	}
 
 	// 创建日志目录
-	logDir := filepath.Join(exeDir, "logs")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return fmt.Errorf("无法创建日志目录: %v", err)
 	}
@@ -55,3 +54,9 @@ func InitLogger() error {
 	Logger = log.New(file, "", log.Ldate|log.Ltime|log.Lshortfile)
 	return nil
 }
+
+// InitServiceLogger 初始化面向 journald 的日志，以服务方式运行时使用
+// 日志写入标准错误，由 journald 采集并添加时间戳，因此这里不再输出日期时间
+func InitServiceLogger() {
+	Logger = log.New(os.Stderr, "", log.Lshortfile)
+}
@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// AuthConfigForScheme 根据安全方案生成认证配置，凭据从以方案名命名的环境变量读取，
+// 例如 apiKeyAuth 使用 APIKEYAUTH_API_KEY，bearerAuth 使用 BEARERAUTH_TOKEN，
+// basicAuth 使用 BASICAUTH_USERNAME 和 BASICAUTH_PASSWORD。
+// 方案设置了 Auth (来自 global.auth 或认证配置文件) 时，其中非空的字段覆盖推导的配置
+func AuthConfigForScheme(schemeName string, scheme *config.SecurityScheme) *config.AuthConfig {
+	authConfig := defaultAuthConfig(schemeName, scheme)
+	override := scheme.Auth
+	if override == nil {
+		return authConfig
+	}
+
+	// 改变认证类型时按新类型重新推导环境变量，保留 API 密钥的位置
+	if override.Type != "" && override.Type != authConfig.Type {
+		authConfig = &config.AuthConfig{Type: override.Type, HeaderName: authConfig.HeaderName, QueryParam: authConfig.QueryParam}
+		setDefaultAuthEnv(schemeName, authConfig)
+	}
+	if override.HeaderName != "" || override.QueryParam != "" {
+		authConfig.HeaderName, authConfig.QueryParam = override.HeaderName, override.QueryParam
+	}
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{
+		{&authConfig.TokenEnv, override.TokenEnv},
+		{&authConfig.KeyEnv, override.KeyEnv},
+		{&authConfig.Username, override.Username},
+		{&authConfig.Password, override.Password},
+		{&authConfig.Description, override.Description},
+		{&authConfig.Krb5Config, override.Krb5Config},
+		{&authConfig.Keytab, override.Keytab},
+		{&authConfig.SPN, override.SPN},
+	} {
+		if field.src != "" {
+			*field.dst = field.src
+		}
+	}
+	if override.Refresh != nil {
+		authConfig.Refresh = override.Refresh
+	}
+	return authConfig
+}
+
+// ApplyAuthConfigs 把按方案名称配置的认证绑定到规范中的安全方案，没有对应方案的条目被忽略
+// (同一个认证配置文件可以供多个规范使用)。名称优先精确匹配，没有精确匹配时忽略大小写匹配
+func ApplyAuthConfigs(spec *config.OpenAPISpec, configs map[string]*config.AuthConfig) {
+	if spec == nil || len(configs) == 0 {
+		return
+	}
+	for name, scheme := range spec.Components.SecuritySchemes {
+		auth, ok := configs[name]
+		if !ok {
+			for key, candidate := range configs {
+				if strings.EqualFold(key, name) {
+					auth, ok = candidate, true
+					break
+				}
+			}
+		}
+		if ok {
+			scheme.Auth = auth
+			spec.Components.SecuritySchemes[name] = scheme
+		}
+	}
+}
+
+// defaultAuthConfig 根据安全方案的类型推导认证配置
+func defaultAuthConfig(schemeName string, scheme *config.SecurityScheme) *config.AuthConfig {
+	authConfig := &config.AuthConfig{}
+	switch scheme.Type {
+	case "apiKey":
+		authConfig.Type = "api_key"
+		if scheme.In == "query" {
+			authConfig.QueryParam = scheme.Name
+		} else {
+			authConfig.HeaderName = scheme.Name
+		}
+	case "http":
+		switch strings.ToLower(scheme.Scheme) {
+		case "bearer", "basic", "ntlm", "negotiate":
+			authConfig.Type = strings.ToLower(scheme.Scheme)
+		}
+	case "oauth2":
+		authConfig.Type = "oauth2"
+	}
+	setDefaultAuthEnv(schemeName, authConfig)
+	return authConfig
+}
+
+// setDefaultAuthEnv 按认证类型设置以方案名命名的凭据环境变量
+func setDefaultAuthEnv(schemeName string, authConfig *config.AuthConfig) {
+	prefix := strings.ToUpper(schemeName)
+	switch authConfig.Type {
+	case "api_key":
+		authConfig.KeyEnv = prefix + "_API_KEY"
+	case "bearer", "oauth2":
+		authConfig.TokenEnv = prefix + "_TOKEN"
+	case "basic", "ntlm", "negotiate":
+		authConfig.TokenEnv = prefix + "_USERNAME"
+		authConfig.KeyEnv = prefix + "_PASSWORD"
+	}
+}
+
+// SchemeUsage 操作实际使用的安全方案，以及使用它的操作数量
+type SchemeUsage struct {
+	Name       string
+	Scheme     *config.SecurityScheme
+	Auth       *config.AuthConfig
+	Operations int
+}
+
+// UsedSchemes 返回规范中操作使用的安全方案，按名称排序；与处理器一致，每个操作只使用第一个安全要求
+func UsedSchemes(spec *config.OpenAPISpec) []SchemeUsage {
+	usages := make(map[string]*SchemeUsage)
+	for _, pathItem := range spec.Paths {
+		for method, operation := range pathItem {
+			if !isHTTPMethod(method) || len(operation.Security) == 0 {
+				continue
+			}
+			for schemeName := range operation.Security[0] {
+				if usage, exists := usages[schemeName]; exists {
+					usage.Operations++
+					continue
+				}
+				scheme, err := GetSecurityScheme(spec, schemeName)
+				if err != nil {
+					continue
+				}
+				usages[schemeName] = &SchemeUsage{
+					Name:       schemeName,
+					Scheme:     scheme,
+					Auth:       AuthConfigForScheme(schemeName, scheme),
+					Operations: 1,
+				}
+			}
+		}
+	}
+
+	result := make([]SchemeUsage, 0, len(usages))
+	for _, usage := range usages {
+		result = append(result, *usage)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// RequiredEnvVars 返回规范中操作使用的安全方案需要的环境变量
+func RequiredEnvVars(spec *config.OpenAPISpec) []string {
+	seen := make(map[string]bool)
+	for _, usage := range UsedSchemes(spec) {
+		for _, env := range []string{usage.Auth.TokenEnv, usage.Auth.KeyEnv} {
+			if env != "" {
+				seen[env] = true
+			}
+		}
+	}
+
+	vars := make([]string, 0, len(seen))
+	for env := range seen {
+		vars = append(vars, env)
+	}
+	sort.Strings(vars)
+	return vars
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// ApplyDescriptions 按描述覆盖文件替换工具名称、标题、描述和参数描述，键为工具名称 (operationId 或根据方法和路径生成的名称)
+// 返回应用的覆盖项数量，以及规范中不存在的工具和参数 (如已被标签筛选去掉)；重命名后与其他工具同名时返回错误
+func ApplyDescriptions(spec *config.OpenAPISpec, overrides map[string]*config.DescriptionOverride) (applied int, unknown []string, err error) {
+	names := make(map[string]bool)
+	for path, pathItem := range spec.Paths {
+		for method, operation := range pathItem {
+			if isHTTPMethod(method) {
+				names[operationName(method, path, operation)] = true
+			}
+		}
+	}
+
+	// 先检查重命名后的名称，避免只应用了一部分；两个工具互换名称是允许的
+	final := make(map[string]string, len(names))
+	for name := range names {
+		target := name
+		if override, exists := overrides[name]; exists && override.Name != "" {
+			target = override.Name
+		}
+		if other, exists := final[target]; exists {
+			first, second := other, name
+			if first > second {
+				first, second = second, first
+			}
+			return 0, nil, fmt.Errorf("描述覆盖后 %s 和 %s 的工具名称都是 %s", first, second, target)
+		}
+		final[target] = name
+	}
+
+	for path, pathItem := range spec.Paths {
+		for method, operation := range pathItem {
+			if !isHTTPMethod(method) {
+				continue
+			}
+			name := operationName(method, path, operation)
+			override, exists := overrides[name]
+			if !exists {
+				continue
+			}
+
+			if override.Name != "" {
+				operation.OperationID = override.Name
+			}
+			if override.Summary != "" {
+				operation.Summary = override.Summary
+			}
+			if override.Description != "" {
+				operation.Description = override.Description
+			}
+			// 参数切片与规范中的其他引用共享底层数组，修改前先复制
+			if len(override.Parameters) > 0 {
+				operation.Parameters = append([]config.Parameter(nil), operation.Parameters...)
+			}
+			for param, description := range override.Parameters {
+				found := false
+				for i := range operation.Parameters {
+					if operation.Parameters[i].Name == param {
+						operation.Parameters[i].Description = description
+						found = true
+					}
+				}
+				if !found {
+					unknown = append(unknown, name+"."+param)
+				}
+			}
+			pathItem[method] = operation
+			applied++
+		}
+	}
+
+	for name := range overrides {
+		if !names[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return applied, unknown, nil
+}
+
+// operationName 返回操作对应的工具名称，与处理器使用的名称一致
+func operationName(method, path string, operation config.Operation) string {
+	if operation.OperationID != "" {
+		return operation.OperationID
+	}
+	return generateOperationID(strings.ToLower(method), path)
+}
@@ -0,0 +1,91 @@
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/faker"
+)
+
+// ExampleResponse 规范中为操作声明的示例响应
+type ExampleResponse struct {
+	Status      int
+	ContentType string
+	// Body 示例值，Synthesized 为 true 表示其中有按响应模式生成的值
+	Body        interface{}
+	Synthesized bool
+}
+
+// OperationExample 返回操作成功响应的示例：选择最小的 2XX 状态码 (没有时使用 default)，
+// 优先使用媒体类型的 example、examples 中按名称排序的第一个，都没有时按响应模式生成假数据，seed 相同时生成的数据相同
+func OperationExample(spec *config.OpenAPISpec, operation *config.Operation, seed int64) ExampleResponse {
+	code, response, found := successResponse(operation.Responses)
+	if !found {
+		return ExampleResponse{Status: http.StatusOK}
+	}
+	example := ExampleResponse{Status: code}
+	if len(response.Content) == 0 {
+		return example
+	}
+
+	example.ContentType = preferredContentType(response.Content)
+	media := response.Content[example.ContentType]
+	switch {
+	case media.Example != nil:
+		example.Body = media.Example
+	case len(media.Examples) > 0:
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		example.Body = media.Examples[names[0]].Value
+	default:
+		generator := faker.New(spec, seed)
+		example.Body = generator.Value(media.Schema)
+		example.Synthesized = generator.Generated()
+	}
+	return example
+}
+
+// successResponse 返回最小的 2XX 响应，没有时返回 default 响应
+func successResponse(responses map[string]config.Response) (int, config.Response, bool) {
+	best := 0
+	var chosen config.Response
+	for code, response := range responses {
+		status := 0
+		if strings.EqualFold(code, "2XX") {
+			status = http.StatusOK
+		} else if n, err := strconv.Atoi(code); err == nil && n >= 200 && n < 300 {
+			status = n
+		}
+		if status != 0 && (best == 0 || status < best) {
+			best, chosen = status, response
+		}
+	}
+	if best != 0 {
+		return best, chosen, true
+	}
+	if response, exists := responses["default"]; exists {
+		return http.StatusOK, response, true
+	}
+	return 0, config.Response{}, false
+}
+
+// preferredContentType 优先选择 JSON 媒体类型，其次按名称排序的第一个
+func preferredContentType(content map[string]config.MediaType) string {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	for _, contentType := range types {
+		if contentType == "application/json" || strings.HasSuffix(contentType, "+json") {
+			return contentType
+		}
+	}
+	return types[0]
+}
@@ -0,0 +1,65 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// FilterByTags 按标签裁剪规范中的操作，返回保留和原有的操作数量
+// include 非空时只保留带有其中任一标签的操作，exclude 中的标签优先；标签比较不区分大小写
+// 指定了 include 但没有任何操作匹配时返回错误，通常是标签名称写错了
+func FilterByTags(spec *config.OpenAPISpec, include, exclude []string) (kept, total int, err error) {
+	available := make(map[string]bool)
+	for path, pathItem := range spec.Paths {
+		for method, operation := range pathItem {
+			if !isHTTPMethod(method) {
+				continue
+			}
+			total++
+			for _, tag := range operation.Tags {
+				available[tag] = true
+			}
+			if hasAnyTag(operation.Tags, exclude) || (len(include) > 0 && !hasAnyTag(operation.Tags, include)) {
+				delete(pathItem, method)
+				continue
+			}
+			kept++
+		}
+		if !hasOperations(pathItem) {
+			delete(spec.Paths, path)
+		}
+	}
+
+	if len(include) > 0 && kept == 0 {
+		tags := make([]string, 0, len(available))
+		for tag := range available {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		return kept, total, fmt.Errorf("没有操作带有标签 %s，规范中的标签: %s", strings.Join(include, ", "), strings.Join(tags, ", "))
+	}
+	return kept, total, nil
+}
+
+func hasAnyTag(tags, candidates []string) bool {
+	for _, tag := range tags {
+		for _, candidate := range candidates {
+			if strings.EqualFold(tag, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasOperations(pathItem config.PathItem) bool {
+	for method := range pathItem {
+		if isHTTPMethod(method) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"path/filepath"
 	"strings"
 
@@ -24,6 +25,11 @@ func (l *Loader) LoadFromOpenAPI(filePath string) (*config.OpenAPISpec, error) {
 	return ParseOpenAPISpec(filePath)
 }
 
+// LoadFromOpenAPIData 从内存中的 OpenAPI 规范加载配置
+func (l *Loader) LoadFromOpenAPIData(data []byte) (*config.OpenAPISpec, error) {
+	return ParseOpenAPISpecData(data, "")
+}
+
 // ParseOpenAPISpec 解析OpenAPI规范文件
 func ParseOpenAPISpec(filePath string) (*config.OpenAPISpec, error) {
 	data, err := ioutil.ReadFile(filePath)
@@ -31,23 +37,54 @@ func ParseOpenAPISpec(filePath string) (*config.OpenAPISpec, error) {
 		return nil, fmt.Errorf("读取OpenAPI规范文件失败: %w", err)
 	}
 
-	var spec config.OpenAPISpec
 	ext := strings.ToLower(filepath.Ext(filePath))
-	if ext == ".json" {
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("不支持的文件格式: %s", ext)
+	}
+	return ParseOpenAPISpecData(data, strings.TrimPrefix(ext, "."))
+}
+
+// ParseOpenAPISpecData 解析内存中的OpenAPI规范，format 为 "json" 或 "yaml"，为空时根据内容判断
+func ParseOpenAPISpecData(data []byte, format string) (*config.OpenAPISpec, error) {
+	if format == "" {
+		format = "yaml"
+		if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+			format = "json"
+		}
+	}
+
+	var spec config.OpenAPISpec
+	if format == "json" {
 		if err := json.Unmarshal(data, &spec); err != nil {
 			return nil, fmt.Errorf("解析JSON格式的OpenAPI规范失败: %w", err)
 		}
-	} else if ext == ".yaml" || ext == ".yml" {
+	} else {
 		if err := yaml.Unmarshal(data, &spec); err != nil {
 			return nil, fmt.Errorf("解析YAML格式的OpenAPI规范失败: %w", err)
 		}
-	} else {
-		return nil, fmt.Errorf("不支持的文件格式: %s", ext)
 	}
 
+	if err := checkOperationSizes(&spec); err != nil {
+		return nil, err
+	}
 	return &spec, nil
 }
 
+// checkOperationSizes 检查操作的 x-mcp-max-response-size，与 global.max_response_size 一样在加载时拒绝无效的大小
+func checkOperationSizes(spec *config.OpenAPISpec) error {
+	for path, pathItem := range spec.Paths {
+		for method, operation := range pathItem {
+			if !isHTTPMethod(method) || operation.MaxResponseSize == "" {
+				continue
+			}
+			if _, err := config.ParseSize(operation.MaxResponseSize); err != nil {
+				return fmt.Errorf("操作 %s %s 的 x-mcp-max-response-size 无效: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+	return nil
+}
+
 // GetOperationByID 根据操作ID获取操作
 func GetOperationByID(spec *config.OpenAPISpec, operationID string) (*config.Operation, string, string, error) {
 	for path, pathItem := range spec.Paths {
@@ -136,6 +173,21 @@ func GetBaseURL(spec *config.OpenAPISpec) string {
 	return ""
 }
 
+// OperationBaseURL 获取操作的基础URL: 操作或路径上声明的 servers 优先于规范的 servers，都使用第一个地址
+// 以 / 开头的相对地址基于规范 servers 中的协议和主机
+func OperationBaseURL(spec *config.OpenAPISpec, operation *config.Operation) string {
+	if len(operation.Servers) == 0 || operation.Servers[0].URL == "" {
+		return GetBaseURL(spec)
+	}
+	serverURL := operation.Servers[0].URL
+	if strings.HasPrefix(serverURL, "/") {
+		if base, err := url.Parse(GetBaseURL(spec)); err == nil && base.Host != "" {
+			return base.Scheme + "://" + base.Host + serverURL
+		}
+	}
+	return serverURL
+}
+
 // isHTTPMethod 检查字符串是否为HTTP方法
 func isHTTPMethod(method string) bool {
 	method = strings.ToUpper(method)
@@ -0,0 +1,49 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMaxResponseSize(t *testing.T) {
+	spec := func(size string) []byte {
+		return []byte(`
+openapi: 3.0.0
+info: {title: test, version: "1.0"}
+paths:
+  /reports:
+    get:
+      operationId: getReport
+      x-mcp-max-response-size: "` + size + `"
+      responses:
+        "200": {description: ok}
+`)
+	}
+
+	tests := []struct {
+		size    string
+		wantErr bool
+	}{
+		{"50MB", false},
+		{"512kb", false},
+		{"1048576", false},
+		{"50 MiB", false},
+		{"lots", true},
+		{"-1MB", true},
+		{"0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			_, err := ParseOpenAPISpecData(spec(tt.size), "yaml")
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("ParseOpenAPISpecData() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "GET /reports") || !strings.Contains(err.Error(), "x-mcp-max-response-size") {
+				t.Fatalf("ParseOpenAPISpecData() error = %v, want invalid x-mcp-max-response-size", err)
+			}
+		})
+	}
+}
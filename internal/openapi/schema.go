@@ -0,0 +1,61 @@
+package openapi
+
+import (
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// SchemaToJSON 把模式转换为 JSON Schema 对象，展开规范内部的 $ref
+// 引用嵌套超过 maxRefDepth 或出现循环时保留 $ref，只输出已声明的字段
+func SchemaToJSON(spec *config.OpenAPISpec, schema config.Schema) map[string]interface{} {
+	return schemaToJSON(spec, schema, make(map[string]bool), 0)
+}
+
+func schemaToJSON(spec *config.OpenAPISpec, schema config.Schema, visiting map[string]bool, depth int) map[string]interface{} {
+	if schema.Ref != "" {
+		resolved, ok := resolveRef(spec, schema.Ref)
+		if !ok || visiting[schema.Ref] || depth >= maxRefDepth {
+			return map[string]interface{}{"$ref": schema.Ref}
+		}
+		visiting[schema.Ref] = true
+		defer delete(visiting, schema.Ref)
+		result := schemaToJSON(spec, resolved, visiting, depth+1)
+		result["title"] = strings.TrimPrefix(schema.Ref, schemaRefPrefix)
+		return result
+	}
+
+	result := make(map[string]interface{})
+	if schema.Type != "" {
+		result["type"] = schema.Type
+	}
+	if schema.Format != "" {
+		result["format"] = schema.Format
+	}
+	if schema.Description != "" {
+		result["description"] = schema.Description
+	}
+	if len(schema.Enum) > 0 {
+		result["enum"] = schema.Enum
+	}
+	if schema.Nullable {
+		result["nullable"] = true
+	}
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+	if len(schema.Required) > 0 {
+		result["required"] = schema.Required
+	}
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			properties[name] = schemaToJSON(spec, property, visiting, depth)
+		}
+		result["properties"] = properties
+	}
+	if schema.Items != nil {
+		result["items"] = schemaToJSON(spec, *schema.Items, visiting, depth)
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// serverVariablePattern 服务器地址中的 {name} 变量
+var serverVariablePattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// ApplyServerVariables 替换规范级、路径级和操作级 servers 地址中的变量，取值依次使用 values (global.server_variables) 和规范中的默认值
+// 路径级和操作级地址中未声明的变量沿用规范级 servers 中的同名声明；
+// 变量声明了 enum 时取值必须是其中之一；变量既没有取值也没有默认值时返回错误
+func ApplyServerVariables(spec *config.OpenAPISpec, values map[string]string) error {
+	inherited := make(map[string]config.ServerVariable)
+	for _, server := range spec.Servers {
+		for name, variable := range server.Variables {
+			if _, exists := inherited[name]; !exists {
+				inherited[name] = variable
+			}
+		}
+	}
+
+	for i := range spec.Servers {
+		if err := resolveServer(&spec.Servers[i], values, nil); err != nil {
+			return err
+		}
+	}
+	for _, pathItem := range spec.Paths {
+		for method, operation := range pathItem {
+			if len(operation.Servers) == 0 {
+				continue
+			}
+			// 路径级 servers 在解析时由该路径的所有操作共享，修改前先复制
+			servers := append([]config.OpenAPIServer(nil), operation.Servers...)
+			for i := range servers {
+				if err := resolveServer(&servers[i], values, inherited); err != nil {
+					return err
+				}
+			}
+			operation.Servers = servers
+			pathItem[method] = operation
+		}
+	}
+	return nil
+}
+
+// resolveServer 替换一个服务器地址中的变量，inherited 为地址中未声明的变量使用的声明
+func resolveServer(server *config.OpenAPIServer, values map[string]string, inherited map[string]config.ServerVariable) error {
+	var err error
+	server.URL = serverVariablePattern.ReplaceAllStringFunc(server.URL, func(match string) string {
+		name := strings.TrimSuffix(strings.TrimPrefix(match, "{"), "}")
+		variable, declared := server.Variables[name]
+		if !declared {
+			variable, declared = inherited[name]
+		}
+		value, configured := values[name]
+		if !configured {
+			value = variable.Default
+		}
+		switch {
+		case err != nil:
+		case value == "":
+			err = fmt.Errorf("服务器地址 %s 中的变量 %s 没有默认值，需要在 global.server_variables 中设置", server.URL, name)
+		case declared && len(variable.Enum) > 0 && !containsString(variable.Enum, value):
+			err = fmt.Errorf("服务器地址 %s 中的变量 %s 的取值 %q 无效 (可选: %s)", server.URL, name, value, strings.Join(variable.Enum, ", "))
+		}
+		return value
+	})
+	return err
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinURL 把服务器地址和操作路径拼接为请求地址，两者之间只保留一个 /
+// 服务器地址中的路径前缀 (如 /api/v2) 保留在操作路径之前，操作路径末尾的 / 原样保留；
+// 服务器地址带有查询字符串 (如 ?api-version=2) 时，操作路径插入到查询字符串之前
+func JoinURL(baseURL, path string) string {
+	query := ""
+	if i := strings.Index(baseURL, "?"); i >= 0 {
+		baseURL, query = baseURL[:i], baseURL[i:]
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	if path != "" {
+		path = "/" + strings.TrimLeft(path, "/")
+	}
+	return baseURL + path + query
+}
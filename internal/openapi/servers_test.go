@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		path string
+		want string
+	}{
+		{"host without trailing slash", "https://api.example.com", "/users", "https://api.example.com/users"},
+		{"host with trailing slash", "https://api.example.com/", "/users", "https://api.example.com/users"},
+		{"base path prefix", "https://api.example.com/api/v2", "/users/{id}", "https://api.example.com/api/v2/users/{id}"},
+		{"base path prefix with trailing slash", "https://api.example.com/api/v2/", "/users", "https://api.example.com/api/v2/users"},
+		{"path without leading slash", "https://api.example.com/api/v2", "users", "https://api.example.com/api/v2/users"},
+		{"repeated slashes", "https://api.example.com/api//", "//users", "https://api.example.com/api/users"},
+		{"path trailing slash kept", "https://api.example.com/api", "/users/", "https://api.example.com/api/users/"},
+		{"empty path", "https://api.example.com/api/v2/", "", "https://api.example.com/api/v2"},
+		{"root path", "https://api.example.com/api/v2", "/", "https://api.example.com/api/v2/"},
+		{"base query string", "https://api.example.com/api?api-version=2", "/users", "https://api.example.com/api/users?api-version=2"},
+		{"base query string with trailing slash", "https://api.example.com/api/?api-version=2", "/users", "https://api.example.com/api/users?api-version=2"},
+		{"relative server url", "/v2", "/users", "/v2/users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinURL(tt.base, tt.path); got != tt.want {
+				t.Errorf("JoinURL(%q, %q) = %q, want %q", tt.base, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperationBaseURLWithPath(t *testing.T) {
+	spec := &config.OpenAPISpec{Servers: []config.OpenAPIServer{{URL: "https://api.example.com/api/v1/"}}}
+	tests := []struct {
+		name    string
+		servers []config.OpenAPIServer
+		path    string
+		want    string
+	}{
+		{"spec server", nil, "/users", "https://api.example.com/api/v1/users"},
+		{"absolute operation server", []config.OpenAPIServer{{URL: "https://files.example.com/store/"}}, "/files", "https://files.example.com/store/files"},
+		{"relative operation server", []config.OpenAPIServer{{URL: "/v2"}}, "/users", "https://api.example.com/v2/users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := &config.Operation{Servers: tt.servers}
+			if got := JoinURL(OperationBaseURL(spec, operation), tt.path); got != tt.want {
+				t.Errorf("request URL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
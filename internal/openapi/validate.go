@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/i18n"
+)
+
+// maxMismatches 单次校验最多报告的不匹配数量
+const maxMismatches = 20
+
+// schemaRefPrefix 规范内部模式引用的前缀
+const schemaRefPrefix = "#/components/schemas/"
+
+// maxRefDepth 解析 $ref 的最大嵌套深度，防止递归引用导致无限循环
+const maxRefDepth = 32
+
+// Mismatch 表示响应数据与模式不匹配的位置
+type Mismatch struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Constraint 违反的约束: type 或 required
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// ValidateValue 按模式校验 JSON 解码得到的数据，返回不匹配之处 (最多 20 条)，消息使用 locale 指定的语言
+// 只检查类型、必需字段、对象属性和数组元素，未声明的字段不视为不匹配
+func ValidateValue(spec *config.OpenAPISpec, schema config.Schema, value interface{}, locale string) []Mismatch {
+	v := &validator{spec: spec, locale: locale}
+	v.validate(schema, value, "$", 0)
+	return v.mismatches
+}
+
+type validator struct {
+	spec       *config.OpenAPISpec
+	locale     string
+	mismatches []Mismatch
+}
+
+func (v *validator) report(path, constraint, code string, args ...interface{}) {
+	if len(v.mismatches) < maxMismatches {
+		v.mismatches = append(v.mismatches, Mismatch{Path: path, Message: i18n.T(v.locale, code, args...), Constraint: constraint})
+	}
+}
+
+func (v *validator) validate(schema config.Schema, value interface{}, path string, depth int) {
+	if len(v.mismatches) >= maxMismatches {
+		return
+	}
+	if schema.Ref != "" {
+		resolved, ok := v.resolveRef(schema.Ref)
+		if !ok || depth >= maxRefDepth {
+			return
+		}
+		v.validate(resolved, value, path, depth+1)
+		return
+	}
+
+	if value == nil {
+		if schema.Type != "" && !schema.Nullable {
+			v.report(path, "type", "validation.type_mismatch", schema.Type, "null")
+		}
+		return
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		v.report(path, "type", "validation.type_mismatch", schema.Type, jsonType(value))
+		return
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, exists := typed[name]; !exists {
+				v.report(path+"."+name, "required", "validation.missing_field")
+			}
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if fieldValue, exists := typed[name]; exists {
+				v.validate(schema.Properties[name], fieldValue, path+"."+name, depth)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range typed {
+				v.validate(*schema.Items, item, fmt.Sprintf("%s[%d]", path, i), depth)
+			}
+		}
+	}
+}
+
+func (v *validator) resolveRef(ref string) (config.Schema, bool) {
+	return resolveRef(v.spec, ref)
+}
+
+// ResolveSchema 沿 $ref 找到实际的模式，无法解析的引用原样返回
+func ResolveSchema(spec *config.OpenAPISpec, schema config.Schema) config.Schema {
+	for depth := 0; schema.Ref != "" && depth < maxRefDepth; depth++ {
+		resolved, ok := resolveRef(spec, schema.Ref)
+		if !ok {
+			break
+		}
+		schema = resolved
+	}
+	return schema
+}
+
+// resolveRef 解析 #/components/schemas/<name> 形式的引用，不支持外部引用
+func resolveRef(spec *config.OpenAPISpec, ref string) (config.Schema, bool) {
+	if spec == nil || !strings.HasPrefix(ref, schemaRefPrefix) {
+		return config.Schema{}, false
+	}
+	schema, exists := spec.Components.Schemas[strings.TrimPrefix(ref, schemaRefPrefix)]
+	return schema, exists
+}
+
+// matchesType 检查值是否符合模式类型，未知类型视为匹配
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return true
+}
+
+// jsonType 返回值的 JSON 类型名称
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
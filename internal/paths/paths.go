@@ -0,0 +1,110 @@
+// Package paths 统一解析配置、规范、.env 和日志文件的路径
+//
+// 通过 --base-dir 或 MCP2REST_HOME 指定基础目录后，所有相对路径都相对于基础目录解析；
+// 未指定时才使用当前目录和可执行文件目录的启发式查找。
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// HomeEnv 指定基础目录的环境变量
+const HomeEnv = "MCP2REST_HOME"
+
+var baseDir string
+
+// SetBaseDir 设置基础目录 (--base-dir)，优先级高于 MCP2REST_HOME
+func SetBaseDir(dir string) {
+	baseDir = absolute(dir)
+}
+
+// BaseDir 返回显式配置的基础目录，未配置时返回空字符串
+func BaseDir() string {
+	if baseDir != "" {
+		return baseDir
+	}
+	return absolute(os.Getenv(HomeEnv))
+}
+
+// Resolve 解析相对路径
+// 配置了基础目录时直接相对于基础目录；否则依次尝试当前目录、可执行文件目录及其上级 (位于 bin 目录时)，
+// 都不存在时返回原始路径，由调用方报告文件不存在
+func Resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	if base := BaseDir(); base != "" {
+		return filepath.Join(base, path)
+	}
+
+	if exists(path) {
+		return path
+	}
+	for _, dir := range exeDirs() {
+		if candidate := filepath.Join(dir, path); exists(candidate) {
+			return candidate
+		}
+	}
+	return path
+}
+
+// SearchDirs 返回查找 .env 等文件的目录，配置了基础目录时只查找基础目录
+func SearchDirs() []string {
+	if base := BaseDir(); base != "" {
+		return []string{base, filepath.Join(base, "configs")}
+	}
+
+	dirs := []string{".", "configs"}
+	if exePath, err := os.Executable(); err == nil {
+		exeDir := filepath.Dir(exePath)
+		parentDir := filepath.Dir(exeDir)
+		dirs = append(dirs, exeDir, filepath.Join(exeDir, "configs"), parentDir, filepath.Join(parentDir, "configs"))
+	}
+	return dirs
+}
+
+// LogDir 返回日志目录：基础目录下的 logs，未配置时为可执行文件目录 (位于 bin 目录时为其上级) 下的 logs
+func LogDir() (string, error) {
+	if base := BaseDir(); base != "" {
+		return filepath.Join(base, "logs"), nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	exeDir := filepath.Dir(exePath)
+	if filepath.Base(exeDir) == "bin" {
+		exeDir = filepath.Dir(exeDir)
+	}
+	return filepath.Join(exeDir, "logs"), nil
+}
+
+// exeDirs 返回可执行文件目录，位于 bin 目录时同时返回其上级目录
+func exeDirs() []string {
+	exePath, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	exeDir := filepath.Dir(exePath)
+	if filepath.Base(exeDir) == "bin" {
+		return []string{exeDir, filepath.Dir(exeDir)}
+	}
+	return []string{exeDir}
+}
+
+func absolute(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,98 @@
+// Package presets 提供编译进二进制文件的预置配置，每个预置包含 OpenAPI 规范、服务器配置模板
+// 和可选的凭据模板 (env.example)
+//
+// 使用 -config preset:<名称> 直接加载预置的规范，例如 -config preset:docker；
+// 或使用 mcp2rest presets init <名称> 把预置写到本地后再修改
+package presets
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// Prefix 规范路径使用该前缀时从预置中加载
+const Prefix = "preset:"
+
+//go:embed docker github jira openweather slack
+var files embed.FS
+
+// Preset 表示一个预置配置
+type Preset struct {
+	Name        string
+	Description string
+	dir         string
+}
+
+var presets = map[string]Preset{
+	"docker": {
+		Name:        "docker",
+		Description: "Docker Engine API (通过 /var/run/docker.sock)：列出容器、查看详情和日志",
+		dir:         "docker",
+	},
+	"github": {
+		Name:        "github",
+		Description: "GitHub REST API：搜索仓库、查看和创建 Issue、列出 Pull Request",
+		dir:         "github",
+	},
+	"jira": {
+		Name:        "jira",
+		Description: "Jira Cloud REST API：按 JQL 搜索、查看 Issue、添加评论、列出项目",
+		dir:         "jira",
+	},
+	"openweather": {
+		Name:        "openweather",
+		Description: "OpenWeather API：当前天气和 5 天预报",
+		dir:         "openweather",
+	},
+	"slack": {
+		Name:        "slack",
+		Description: "Slack Web API：列出频道、读取频道消息、发送消息",
+		dir:         "slack",
+	},
+}
+
+// Get 按名称查找预置
+func Get(name string) (Preset, bool) {
+	preset, exists := presets[name]
+	return preset, exists
+}
+
+// List 按名称顺序返回所有预置
+func List() []Preset {
+	list := make([]Preset, 0, len(presets))
+	for _, preset := range presets {
+		list = append(list, preset)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Spec 返回预置的 OpenAPI 规范
+func (p Preset) Spec() ([]byte, error) {
+	return p.file("openapi.yaml")
+}
+
+// ServerConfig 返回预置的服务器配置模板 (与 configs/stdio.yaml 格式相同)
+func (p Preset) ServerConfig() ([]byte, error) {
+	return p.file("stdio.yaml")
+}
+
+// EnvExample 返回预置的凭据模板，预置不需要凭据时返回 nil
+func (p Preset) EnvExample() ([]byte, error) {
+	data, err := p.file("env.example")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (p Preset) file(name string) ([]byte, error) {
+	data, err := files.ReadFile(p.dir + "/" + name)
+	if err != nil {
+		return nil, fmt.Errorf("读取预置 %s 的 %s 失败: %w", p.Name, name, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,62 @@
+// Package process 封装与平台相关的进程管理: 关闭信号和子进程的优雅退出
+//
+// Windows 不支持向其他进程发送 SIGTERM，因此子进程的首选关闭方式是关闭其标准输入，
+// stdio 服务器在读到 EOF 后会自行退出；信号只作为 Unix 上的后备手段，最后才强制终止。
+package process
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ErrSignalUnsupported 当前平台不支持向子进程发送终止信号
+var ErrSignalUnsupported = errors.New("当前平台不支持发送终止信号")
+
+// ShutdownSignals 返回服务器需要监听的关闭信号
+// Unix 上为 SIGINT/SIGTERM；Windows 上为 Ctrl+C 以及控制台关闭、注销、关机事件 (Go 将后者映射为 SIGTERM)
+func ShutdownSignals() []os.Signal {
+	return shutdownSignals
+}
+
+// Terminate 请求进程优雅退出，Windows 上返回 ErrSignalUnsupported
+func Terminate(p *os.Process) error {
+	return terminate(p)
+}
+
+// Shutdown 依次通过关闭标准输入、发送终止信号、强制终止的方式关闭子进程
+// 每个阶段最多等待 timeout，返回 cmd.Wait 的结果
+func Shutdown(cmd *exec.Cmd, stdin io.Closer, timeout time.Duration) error {
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	if stdin != nil {
+		stdin.Close()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(timeout):
+		}
+	}
+
+	if err := Terminate(cmd.Process); err == nil {
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(timeout):
+		}
+	}
+
+	if err := cmd.Process.Kill(); err != nil {
+		return err
+	}
+	return <-done
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+func terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
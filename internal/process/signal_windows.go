@@ -0,0 +1,15 @@
+//go:build windows
+
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+// syscall.SIGTERM 在 Windows 上对应 CTRL_CLOSE/CTRL_LOGOFF/CTRL_SHUTDOWN 控制台事件
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+func terminate(p *os.Process) error {
+	return ErrSignalUnsupported
+}
@@ -0,0 +1,195 @@
+// Package quota 按会话或入站客户端统计选定工具每小时、每天的调用次数
+//
+// 适用于按调用次数计费的上游 API。计数只保存在内存中，服务器重启后清零；
+// 小时和天按服务器本地时间的整点和零点重置。
+package quota
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+const (
+	// PerSession 按会话计算配额 (默认)
+	PerSession = "session"
+	// PerClient 按入站客户端 (global.access) 计算配额，同一客户端的所有会话共享
+	PerClient = "client"
+
+	// WindowHour 每小时的配额
+	WindowHour = "hour"
+	// WindowDay 每天的配额
+	WindowDay = "day"
+)
+
+// Subject 调用方，Session 为空表示标准输入/输出，Client 为空表示未配置访问控制
+type Subject struct {
+	Session string
+	Client  string
+}
+
+// ExceededError 调用超过配额，ResetAt 之后可以再次调用
+type ExceededError struct {
+	Quota   string
+	Tool    string
+	Window  string
+	Limit   int
+	ResetAt time.Time
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("工具 %s 超过配额 %s (每%s %d 次)，%s 重置", e.Tool, e.Quota, windowName(e.Window), e.Limit, e.ResetAt.Format(time.RFC3339))
+}
+
+func windowName(window string) string {
+	if window == WindowHour {
+		return "小时"
+	}
+	return "天"
+}
+
+// Tracker 统计调用次数并检查配额
+type Tracker struct {
+	rules []*rule
+
+	mu       sync.Mutex
+	counters map[counterKey]*counter
+	// swept 上次清理过期计数的日期
+	swept time.Time
+}
+
+type rule struct {
+	name   string
+	tools  []string
+	per    string
+	hourly int
+	daily  int
+}
+
+type counterKey struct {
+	rule    int
+	subject string
+}
+
+// counter 当前小时和当天的调用次数
+type counter struct {
+	hour, day           time.Time
+	hourCount, dayCount int
+}
+
+// New 根据配置创建配额统计，未配置配额时返回 nil
+func New(cfg []config.QuotaConfig) (*Tracker, error) {
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+	tracker := &Tracker{counters: make(map[counterKey]*counter)}
+	for i, quotaCfg := range cfg {
+		name := quotaCfg.Name
+		if name == "" {
+			name = fmt.Sprintf("quotas[%d]", i)
+		}
+		if len(quotaCfg.Tools) == 0 {
+			return nil, fmt.Errorf("配额 %s 缺少 tools", name)
+		}
+		for _, pattern := range quotaCfg.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("配额 %s 的工具名称模式无效 %q: %w", name, pattern, err)
+			}
+		}
+		per := quotaCfg.Per
+		if per == "" {
+			per = PerSession
+		}
+		if per != PerSession && per != PerClient {
+			return nil, fmt.Errorf("配额 %s 的 per 无效: %s (支持: session、client)", name, per)
+		}
+		if quotaCfg.Hourly <= 0 && quotaCfg.Daily <= 0 {
+			return nil, fmt.Errorf("配额 %s 需要设置 hourly 或 daily", name)
+		}
+		tracker.rules = append(tracker.rules, &rule{
+			name:   name,
+			tools:  quotaCfg.Tools,
+			per:    per,
+			hourly: quotaCfg.Hourly,
+			daily:  quotaCfg.Daily,
+		})
+	}
+	return tracker, nil
+}
+
+// Take 记录一次工具调用，任一适用的配额已用完时返回 *ExceededError，此次调用不计数
+func (t *Tracker) Take(subject Subject, tool string, now time.Time) error {
+	year, month, date := now.Date()
+	day := time.Date(year, month, date, 0, 0, 0, 0, now.Location())
+	hour := time.Date(year, month, date, now.Hour(), 0, 0, 0, now.Location())
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sweepLocked(day)
+
+	var matched []*counter
+	for i, r := range t.rules {
+		if !matchAny(r.tools, tool) {
+			continue
+		}
+		key := counterKey{rule: i, subject: r.subjectKey(subject)}
+		c := t.counters[key]
+		if c == nil {
+			c = &counter{}
+			t.counters[key] = c
+		}
+		if !c.hour.Equal(hour) {
+			c.hour, c.hourCount = hour, 0
+		}
+		if !c.day.Equal(day) {
+			c.day, c.dayCount = day, 0
+		}
+
+		if r.hourly > 0 && c.hourCount >= r.hourly {
+			return &ExceededError{Quota: r.name, Tool: tool, Window: WindowHour, Limit: r.hourly, ResetAt: hour.Add(time.Hour)}
+		}
+		if r.daily > 0 && c.dayCount >= r.daily {
+			return &ExceededError{Quota: r.name, Tool: tool, Window: WindowDay, Limit: r.daily, ResetAt: day.AddDate(0, 0, 1)}
+		}
+		matched = append(matched, c)
+	}
+
+	for _, c := range matched {
+		c.hourCount++
+		c.dayCount++
+	}
+	return nil
+}
+
+// sweepLocked 每天第一次调用时删除前一天及更早的计数，避免已结束会话的计数一直保留
+func (t *Tracker) sweepLocked(day time.Time) {
+	if t.swept.Equal(day) {
+		return
+	}
+	t.swept = day
+	for key, c := range t.counters {
+		if c.day.Before(day) {
+			delete(t.counters, key)
+		}
+	}
+}
+
+// subjectKey 返回计数的归属，按客户端计算但未配置访问控制时退回按会话计算
+func (r *rule) subjectKey(subject Subject) string {
+	if r.per == PerClient && subject.Client != "" {
+		return "client:" + subject.Client
+	}
+	return "session:" + subject.Session
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
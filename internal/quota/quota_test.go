@@ -0,0 +1,119 @@
+package quota
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+func newTracker(t *testing.T, cfg ...config.QuotaConfig) *Tracker {
+	t.Helper()
+	tracker, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return tracker
+}
+
+func exceeded(err error) *ExceededError {
+	var e *ExceededError
+	errors.As(err, &e)
+	return e
+}
+
+func TestTakeWindowReset(t *testing.T) {
+	tracker := newTracker(t, config.QuotaConfig{Name: "geo", Tools: []string{"geocode*"}, Hourly: 2, Daily: 3})
+	subject := Subject{Session: "s1"}
+	now := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if err := tracker.Take(subject, "geocodeAddress", now); err != nil {
+			t.Fatalf("Take() #%d error = %v", i+1, err)
+		}
+	}
+	e := exceeded(tracker.Take(subject, "geocodeAddress", now.Add(30*time.Minute)))
+	if e == nil || e.Window != WindowHour || !e.ResetAt.Equal(time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("超过每小时配额 = %+v", e)
+	}
+
+	// 下一个整点重置每小时计数，但当天的计数保留
+	if err := tracker.Take(subject, "geocodeAddress", now.Add(45*time.Minute)); err != nil {
+		t.Fatalf("整点后 Take() error = %v", err)
+	}
+	e = exceeded(tracker.Take(subject, "geocodeAddress", now.Add(2*time.Hour)))
+	if e == nil || e.Window != WindowDay || !e.ResetAt.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("超过每天配额 = %+v", e)
+	}
+
+	// 零点重置两个计数
+	if err := tracker.Take(subject, "geocodeAddress", time.Date(2026, 3, 2, 0, 1, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("零点后 Take() error = %v", err)
+	}
+}
+
+func TestTakeMultiRuleRollback(t *testing.T) {
+	tracker := newTracker(t,
+		config.QuotaConfig{Name: "all", Tools: []string{"*"}, Hourly: 10},
+		config.QuotaConfig{Name: "geo", Tools: []string{"geocode"}, Hourly: 1},
+	)
+	subject := Subject{Session: "s1"}
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	if err := tracker.Take(subject, "geocode", now); err != nil {
+		t.Fatal(err)
+	}
+	// 第二条规则拒绝时，第一条规则也不计数
+	for i := 0; i < 5; i++ {
+		if e := exceeded(tracker.Take(subject, "geocode", now)); e == nil || e.Quota != "geo" {
+			t.Fatalf("Take() = %+v, want geo exceeded", e)
+		}
+	}
+	for i := 0; i < 9; i++ {
+		if err := tracker.Take(subject, "listUsers", now); err != nil {
+			t.Fatalf("Take(listUsers) #%d error = %v, 被拒绝的调用不应计入其他配额", i+1, err)
+		}
+	}
+	if e := exceeded(tracker.Take(subject, "listUsers", now)); e == nil || e.Quota != "all" {
+		t.Fatalf("Take() = %+v, want all exceeded", e)
+	}
+}
+
+func TestTakeSubjects(t *testing.T) {
+	tracker := newTracker(t,
+		config.QuotaConfig{Name: "session", Tools: []string{"a"}, Hourly: 1},
+		config.QuotaConfig{Name: "client", Tools: []string{"b"}, Per: PerClient, Hourly: 1},
+	)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	first := Subject{Session: "s1", Client: "agent"}
+	second := Subject{Session: "s2", Client: "agent"}
+
+	if tracker.Take(first, "a", now) != nil || tracker.Take(second, "a", now) != nil {
+		t.Fatal("按会话计算的配额不应在会话之间共享")
+	}
+	if tracker.Take(first, "b", now) != nil {
+		t.Fatal("Take(b) 第一次调用失败")
+	}
+	if exceeded(tracker.Take(second, "b", now)) == nil {
+		t.Fatal("按客户端计算的配额应在同一客户端的会话之间共享")
+	}
+	// 未配置访问控制时按会话计算
+	if tracker.Take(Subject{Session: "s3"}, "b", now) != nil {
+		t.Fatal("没有客户端时应按会话计算")
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := map[string]config.QuotaConfig{
+		"no tools":    {Hourly: 1},
+		"bad pattern": {Tools: []string{"["}, Hourly: 1},
+		"bad per":     {Tools: []string{"a"}, Per: "user", Hourly: 1},
+		"no limits":   {Tools: []string{"a"}},
+	}
+	for name, cfg := range tests {
+		if _, err := New([]config.QuotaConfig{cfg}); err == nil {
+			t.Errorf("%s: New() error = nil", name)
+		}
+	}
+}
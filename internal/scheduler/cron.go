@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 计算下一次执行时间
+type Schedule interface {
+	// Next 返回严格晚于 t 的下一次执行时间
+	Next(t time.Time) time.Time
+}
+
+// everySchedule 固定间隔 (@every <duration>)
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// cronSchedule 5 字段 cron 表达式: 分 时 日 月 周
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// 日和周都被限制时，满足其中之一即可 (与标准 cron 一致)
+	domRestricted, dowRestricted bool
+	location                     *time.Location
+}
+
+// descriptors 预定义的表达式
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// fieldBounds 各字段的取值范围
+var fieldBounds = []struct {
+	name     string
+	min, max int
+}{
+	{"分钟", 0, 59}, {"小时", 0, 23}, {"日", 1, 31}, {"月", 1, 12}, {"星期", 0, 7},
+}
+
+// Parse 解析 cron 表达式，支持 5 字段格式 (*、*/n、a-b、a-b/n、逗号列表)、@daily 等预定义表达式和 @every <duration>
+// location 为空时使用本地时区
+func Parse(expr string, location *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if location == nil {
+		location = time.Local
+	}
+
+	if strings.HasPrefix(expr, "@every ") {
+		interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("无效的间隔: %s", expr)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if standard, exists := descriptors[expr]; exists {
+		expr = standard
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron 表达式需要 5 个字段 (分 时 日 月 周): %s", expr)
+	}
+
+	bits := make([]uint64, 5)
+	for i, field := range fields {
+		value, err := parseField(field, fieldBounds[i].min, fieldBounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("解析%s字段失败: %w", fieldBounds[i].name, err)
+		}
+		bits[i] = value
+	}
+	// 周日可以写作 0 或 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: !strings.HasPrefix(fields[2], "*"),
+		dowRestricted: !strings.HasPrefix(fields[4], "*"),
+		location:      location,
+	}, nil
+}
+
+// parseField 把单个字段解析为位集合
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("无效的步长: %s", part)
+			}
+			step = n
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			low, err1 = strconv.Atoi(bounds[0])
+			high, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("无效的范围: %s", part)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("无效的值: %s", part)
+			}
+			low = n
+			// "5/10" 表示从 5 开始每 10 个单位一次
+			if step == 1 {
+				high = n
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("超出范围 %d-%d: %s", min, max, part)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next 逐级查找匹配的月、日、时、分，最多向后查找 5 年
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 检查日期是否满足日和星期字段
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
@@ -0,0 +1,266 @@
+// Package scheduler 按 cron 表达式定时执行工具调用，结果保存为 MCP 资源
+//
+// 每个定时任务对应资源 schedule://<name>，首次产生结果时资源列表发生变化，
+// 之后每次执行都会更新该资源，由服务器负责向客户端发送相应的通知。
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+const (
+	// URIScheme 定时任务结果资源 URI 的前缀
+	URIScheme = "schedule://"
+
+	defaultKeep = 10
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Executor 执行一次工具调用，返回工具结果
+type Executor func(ctx context.Context, tool string, params map[string]interface{}) (interface{}, error)
+
+// Notifier 任务产生新结果后调用，first 表示该任务的资源首次出现在资源列表中
+type Notifier func(uri string, first bool)
+
+// Run 一次执行的结果
+type Run struct {
+	StartedAt  time.Time   `json:"started_at"`
+	DurationMs int64       `json:"duration_ms"`
+	Success    bool        `json:"success"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// job 单个定时任务
+type job struct {
+	config   config.ScheduleConfig
+	schedule Schedule
+
+	mu      sync.Mutex
+	runs    []Run
+	nextRun time.Time
+	running bool
+}
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	jobs    map[string]*job
+	execute Executor
+	notify  Notifier
+	timeout time.Duration
+	wg      sync.WaitGroup
+}
+
+// New 根据配置创建调度器，表达式或时区无效时返回错误
+func New(schedules []config.ScheduleConfig, timeout time.Duration, execute Executor, notify Notifier) (*Scheduler, error) {
+	s := &Scheduler{
+		jobs:    make(map[string]*job),
+		execute: execute,
+		notify:  notify,
+		timeout: timeout,
+	}
+
+	for _, cfg := range schedules {
+		if !validName.MatchString(cfg.Name) {
+			return nil, fmt.Errorf("无效的定时任务名称: %q (只能包含字母、数字、- 和 _)", cfg.Name)
+		}
+		if _, exists := s.jobs[cfg.Name]; exists {
+			return nil, fmt.Errorf("定时任务名称重复: %s", cfg.Name)
+		}
+		if cfg.Tool == "" {
+			return nil, fmt.Errorf("定时任务 %s 缺少 tool", cfg.Name)
+		}
+
+		var location *time.Location
+		if cfg.Timezone != "" {
+			loc, err := time.LoadLocation(cfg.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("定时任务 %s 的时区无效: %w", cfg.Name, err)
+			}
+			location = loc
+		}
+		schedule, err := Parse(cfg.Cron, location)
+		if err != nil {
+			return nil, fmt.Errorf("定时任务 %s 的 cron 表达式无效: %w", cfg.Name, err)
+		}
+		if cfg.Keep <= 0 {
+			cfg.Keep = defaultKeep
+		}
+		s.jobs[cfg.Name] = &job{config: cfg, schedule: schedule}
+	}
+	return s, nil
+}
+
+// Empty 是否没有配置任何定时任务
+func (s *Scheduler) Empty() bool {
+	return len(s.jobs) == 0
+}
+
+// Start 为每个任务启动调度协程，ctx 取消后停止
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Wait 等待所有调度协程退出
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// loop 单个任务的调度循环，上一次执行尚未完成时跳过本次触发
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	if j.config.RunOnStart {
+		s.runJob(ctx, j)
+	}
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			logging.Logger.Printf("定时任务 %s 没有下一次执行时间，停止调度", j.config.Name)
+			return
+		}
+		j.mu.Lock()
+		j.nextRun = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		j.mu.Lock()
+		busy := j.running
+		j.mu.Unlock()
+		if busy {
+			logging.Logger.Printf("定时任务 %s 上一次执行尚未完成，跳过本次触发", j.config.Name)
+			continue
+		}
+		go s.runJob(ctx, j)
+	}
+}
+
+// runJob 执行一次任务并保存结果
+func (s *Scheduler) runJob(ctx context.Context, j *job) {
+	j.mu.Lock()
+	j.running = true
+	j.mu.Unlock()
+
+	runCtx := ctx
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	result, err := s.execute(runCtx, j.config.Tool, copyParams(j.config.Params))
+	run := Run{StartedAt: started, DurationMs: time.Since(started).Milliseconds(), Success: err == nil}
+	if err != nil {
+		run.Error = err.Error()
+		logging.Logger.Printf("定时任务 %s 执行失败: %v", j.config.Name, err)
+	} else {
+		run.Result = result
+		logging.Logger.Printf("定时任务 %s 执行完成，耗时 %dms", j.config.Name, run.DurationMs)
+	}
+
+	j.mu.Lock()
+	first := len(j.runs) == 0
+	j.runs = append(j.runs, run)
+	if len(j.runs) > j.config.Keep {
+		j.runs = j.runs[len(j.runs)-j.config.Keep:]
+	}
+	j.running = false
+	j.mu.Unlock()
+
+	if s.notify != nil && ctx.Err() == nil {
+		s.notify(URIScheme+j.config.Name, first)
+	}
+}
+
+// copyParams 每次执行使用参数的副本，避免处理过程中修改配置
+func copyParams(params map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Resources 返回已有执行结果的任务资源
+func (s *Scheduler) Resources() []map[string]interface{} {
+	names := make([]string, 0, len(s.jobs))
+	for name, j := range s.jobs {
+		j.mu.Lock()
+		hasRuns := len(j.runs) > 0
+		j.mu.Unlock()
+		if hasRuns {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	resources := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		cfg := s.jobs[name].config
+		description := cfg.Description
+		if description == "" {
+			description = fmt.Sprintf("定时任务 %s (%s) 调用工具 %s 的最近 %d 次结果", name, cfg.Cron, cfg.Tool, cfg.Keep)
+		}
+		resources = append(resources, map[string]interface{}{
+			"uri":         URIScheme + name,
+			"name":        "schedule-" + name,
+			"description": description,
+			"mimeType":    "application/json",
+		})
+	}
+	return resources
+}
+
+// Has 是否存在该 URI 对应的任务
+func (s *Scheduler) Has(uri string) bool {
+	_, exists := s.jobs[strings.TrimPrefix(uri, URIScheme)]
+	return strings.HasPrefix(uri, URIScheme) && exists
+}
+
+// Read 读取任务的执行结果，最新的结果位于 latest
+func (s *Scheduler) Read(ctx context.Context, uri string) (map[string]interface{}, error) {
+	if !s.Has(uri) {
+		return nil, fmt.Errorf("资源不存在: %s", uri)
+	}
+	j := s.jobs[strings.TrimPrefix(uri, URIScheme)]
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	runs := make([]Run, len(j.runs))
+	copy(runs, j.runs)
+
+	content := map[string]interface{}{
+		"schedule": j.config.Name,
+		"tool":     j.config.Tool,
+		"cron":     j.config.Cron,
+		"runs":     runs,
+	}
+	if len(runs) > 0 {
+		content["latest"] = runs[len(runs)-1]
+	}
+	if !j.nextRun.IsZero() {
+		content["next_run"] = j.nextRun
+	}
+	return content, nil
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mcp2rest/internal/access"
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/logging"
+)
+
+// authenticate 识别 SSE 请求的客户端，未配置访问控制时返回 nil
+// 无法识别时回复 401，ok 为 false
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (client *access.Client, ok bool) {
+	if s.access == nil {
+		return nil, true
+	}
+	client, err := s.access.Authenticate(r)
+	if err != nil {
+		logging.Logger.Printf("拒绝来自 %s 的请求: %v", r.RemoteAddr, err)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	return client, true
+}
+
+// clientName 返回客户端名称，未配置访问控制时为空
+func clientName(client *access.Client) string {
+	if client == nil {
+		return ""
+	}
+	return client.Name
+}
+
+// accessClient 返回会话的客户端，空会话ID表示标准输入/输出，未配置访问控制或不限制时返回 nil
+func (s *Server) accessClient(sessionID string) *access.Client {
+	if s.access == nil {
+		return nil
+	}
+	if sessionID == "" {
+		return s.access.StdioClient()
+	}
+	s.sessionMutex.RLock()
+	session, exists := s.sessions[sessionID]
+	name := ""
+	if exists {
+		name = session.Client
+	}
+	s.sessionMutex.RUnlock()
+	return s.access.Client(name)
+}
+
+// sessionClientMatches 检查请求的客户端是否与建立会话的客户端相同，避免凭据不同的客户端使用他人的会话
+func (s *Server) sessionClientMatches(sessionID, name string) bool {
+	if s.access == nil {
+		return true
+	}
+	s.sessionMutex.RLock()
+	defer s.sessionMutex.RUnlock()
+	session, exists := s.sessions[sessionID]
+	return exists && session.Client == name
+}
+
+// withAccess 把客户端及其工具权限附加到上下文，client 为 nil 时不限制
+func withAccess(ctx context.Context, client *access.Client) context.Context {
+	if client == nil {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, callerContextKey{}, client)
+	return handler.WithToolFilter(ctx, client.Allows)
+}
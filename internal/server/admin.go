@@ -0,0 +1,190 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/diagnostics"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/paths"
+	"github.com/mcp2rest/internal/stats"
+)
+
+// maxSummaryTools 周期性统计摘要中最多列出的工具数
+const maxSummaryTools = 20
+
+// adminHandler 返回管理接口的路由，所有端点位于 /admin/ 下
+func (s *Server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/stats", s.handleAdminStats)
+	mux.HandleFunc("/admin/selfcheck", s.handleAdminSelfCheck)
+	return s.requireAdminToken(mux)
+}
+
+// requireAdminToken 配置了令牌时校验 Authorization: Bearer <token>
+func (s *Server) requireAdminToken(next http.Handler) http.Handler {
+	token := s.config.Global.Admin.Token
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startAdminServer 配置了独立地址时在该地址上启动管理接口，stdio 模式只能使用这种方式
+func (s *Server) startAdminServer() error {
+	admin := s.config.Global.Admin
+	if !admin.Enabled {
+		return nil
+	}
+	if admin.Address == "" {
+		if s.config.Server.Mode != "sse" {
+			logging.Logger.Printf("警告: %s 模式需要配置 admin.address 才能启用管理接口", s.config.Server.Mode)
+		}
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", admin.Address)
+	if err != nil {
+		return err
+	}
+	s.adminServer = &http.Server{Handler: s.adminHandler()}
+	go func() {
+		if err := s.adminServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logging.Logger.Printf("管理接口停止: %v", err)
+		}
+	}()
+	logging.Logger.Printf("管理接口启动在 %s/admin/", admin.Address)
+	return nil
+}
+
+// handleAdminStats 返回每个工具的调用统计，以及尚未被调用过的工具
+func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tools := s.stats.Snapshot()
+	called := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		called[tool.Name] = true
+	}
+	unused := []string{}
+	for _, tool := range s.handler.GetAvailableTools() {
+		if name, _ := tool["name"].(string); name != "" && !called[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+
+	response := map[string]interface{}{
+		"since":  s.stats.Since(),
+		"tools":  tools,
+		"unused": unused,
+	}
+	if cacheStats := s.handler.CacheStats(); cacheStats != nil {
+		response["cache"] = cacheStats
+	}
+	if coalesceStats := s.handler.CoalesceStats(); coalesceStats != nil {
+		response["coalesce"] = coalesceStats
+	}
+	response["tls"] = s.handler.TLSStats()
+	if s.config.Server.Mode == "sse" {
+		response["sse"] = s.sseStats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleAdminSelfCheck 返回最近一次启动自检的报告，refresh=1 或尚未运行过时重新运行
+// 有检查失败时状态码为 503，便于作为就绪探针使用
+func (s *Server) handleAdminSelfCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report := s.selfCheck.Load()
+	if report == nil || r.URL.Query().Get("refresh") == "1" {
+		report = s.SelfCheck(r.Context())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Status == diagnostics.StatusFail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// recordToolCall 记录工具调用统计，慢调用单独记录日志
+func (s *Server) recordToolCall(ctx context.Context, tool string, duration time.Duration, failed bool) {
+	if s.stats.Record(tool, duration, failed) {
+		logging.FromContext(ctx).Printf("慢调用: 工具=%s, 耗时=%v, 阈值=%v", tool, duration, s.config.Global.Stats.SlowThreshold)
+	}
+}
+
+// startStatsReporter 按 stats.log_interval 周期性输出统计摘要
+func (s *Server) startStatsReporter() {
+	interval := s.config.Global.Stats.LogInterval
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				logStatsSummary(s.stats)
+			}
+		}
+	}()
+}
+
+// logStatsSummary 输出按调用次数排序的统计摘要
+func logStatsSummary(recorder *stats.Recorder) {
+	tools := recorder.Snapshot()
+	if len(tools) == 0 {
+		return
+	}
+	logging.Logger.Printf("工具调用统计 (自 %s 起，共 %d 个工具):", recorder.Since().Format(time.RFC3339), len(tools))
+	for i, tool := range tools {
+		if i == maxSummaryTools {
+			logging.Logger.Printf("  ... 其余 %d 个工具省略", len(tools)-maxSummaryTools)
+			break
+		}
+		logging.Logger.Printf("  %s: 调用 %d, 错误率 %.1f%%, 慢调用 %d, p50 %.1fms, p90 %.1fms, p99 %.1fms, 发送 %d 字节, 接收 %d 字节 (单次最大 %d)",
+			tool.Name, tool.Calls, tool.ErrorRate*100, tool.SlowCalls, tool.P50Ms, tool.P90Ms, tool.P99Ms,
+			tool.BytesSent, tool.BytesReceived, tool.MaxReceived)
+	}
+}
+
+// saveStats 停止时把统计写入 stats.file，只执行一次
+func (s *Server) saveStats() {
+	s.statsOnce.Do(func() {
+		file := s.config.Global.Stats.File
+		if file == "" {
+			return
+		}
+		if err := s.stats.Save(paths.Resolve(file)); err != nil {
+			logging.Logger.Printf("保存工具调用统计失败: %v", err)
+			return
+		}
+		logging.Logger.Printf("工具调用统计已保存到 %s", file)
+	})
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// setClientCapabilities 保存会话协商的协议版本和客户端在 initialize 中声明的能力，空会话ID表示标准输入/输出
+func (s *Server) setClientCapabilities(sessionID, protocolVersion string, capabilities mcp.ClientCapabilities) {
+	s.sessionMutex.Lock()
+	if sessionID == "" {
+		s.stdioProtocolVersion = protocolVersion
+		s.stdioCapabilities = capabilities
+	} else if session, exists := s.sessions[sessionID]; exists {
+		session.ProtocolVersion = protocolVersion
+		session.Capabilities = capabilities
+	}
+	s.sessionMutex.Unlock()
+	s.persistSession(sessionID)
+}
+
+// ProtocolVersion 返回会话协商的协议版本，会话不存在或尚未初始化时返回 mcp.DefaultProtocolVersion
+func (s *Server) ProtocolVersion(sessionID string) string {
+	s.sessionMutex.RLock()
+	defer s.sessionMutex.RUnlock()
+	version := s.stdioProtocolVersion
+	if sessionID != "" {
+		version = ""
+		if session, exists := s.sessions[sessionID]; exists {
+			version = session.ProtocolVersion
+		}
+	}
+	if version == "" {
+		return mcp.DefaultProtocolVersion
+	}
+	return version
+}
+
+// ClientCapabilities 返回会话的客户端在 initialize 中声明的能力，空会话ID表示标准输入/输出
+// 会话不存在或尚未初始化时 ok 为 false
+func (s *Server) ClientCapabilities(sessionID string) (capabilities mcp.ClientCapabilities, ok bool) {
+	s.sessionMutex.RLock()
+	defer s.sessionMutex.RUnlock()
+	capabilities = s.capabilitiesLocked(sessionID)
+	return capabilities, capabilities != nil
+}
+
+// ClientSupports 检查会话的客户端是否声明了能力，name 见 mcp.Capability* 常量
+func (s *Server) ClientSupports(sessionID, name string) bool {
+	s.sessionMutex.RLock()
+	defer s.sessionMutex.RUnlock()
+	return supports(s.capabilitiesLocked(sessionID), name)
+}
+
+// capabilitiesLocked 返回会话声明的能力，调用方需要持有 sessionMutex
+func (s *Server) capabilitiesLocked(sessionID string) mcp.ClientCapabilities {
+	if sessionID == "" {
+		return s.stdioCapabilities
+	}
+	if session, exists := s.sessions[sessionID]; exists {
+		return session.Capabilities
+	}
+	return nil
+}
+
+// supports 检查能力是否已声明，尚未初始化 (capabilities 为 nil) 的会话视为支持，
+// 以兼容不发送 initialize 的旧客户端和测试工具
+func supports(capabilities mcp.ClientCapabilities, name string) bool {
+	return capabilities == nil || capabilities.Has(name)
+}
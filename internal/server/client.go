@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// sessionClient 表示某个会话对应的客户端，用于服务器主动发起请求
+type sessionClient struct {
+	server    *Server
+	sessionID string // 为空表示标准输入/输出会话
+}
+
+// pendingRequest 等待客户端响应的请求，只接受发起请求的会话返回的响应
+type pendingRequest struct {
+	sessionID string
+	response  chan *mcp.MCPResponse
+}
+
+// Request 向客户端发送请求并等待响应，请求ID不可预测，其他会话无法冒充客户端响应
+func (c *sessionClient) Request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id := "mcp2rest-" + uuid.New().String()
+	request, err := mcp.NewRequest(id, method, params)
+	if err != nil {
+		return nil, fmt.Errorf("创建客户端请求失败: %w", err)
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("序列化客户端请求失败: %w", err)
+	}
+
+	// 登记等待中的请求
+	responseChan := make(chan *mcp.MCPResponse, 1)
+	c.server.pendingMutex.Lock()
+	c.server.pendingRequests[id] = &pendingRequest{sessionID: c.sessionID, response: responseChan}
+	c.server.pendingMutex.Unlock()
+
+	defer func() {
+		c.server.pendingMutex.Lock()
+		delete(c.server.pendingRequests, id)
+		c.server.pendingMutex.Unlock()
+	}()
+
+	logging.Logger.Printf("向客户端发送请求: ID=%s, Method=%s", id, method)
+	if err := c.server.sendToClient(c.sessionID, data); err != nil {
+		return nil, fmt.Errorf("发送客户端请求失败: %w", err)
+	}
+
+	select {
+	case response := <-responseChan:
+		if response.Error != nil {
+			return nil, fmt.Errorf("客户端返回错误: %d %s", response.Error.Code, response.Error.Message)
+		}
+		return response.Result, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("等待客户端响应失败: %w", ctx.Err())
+	}
+}
+
+// Supports 检查客户端是否在 initialize 中声明了能力
+func (c *sessionClient) Supports(name string) bool {
+	return c.server.ClientSupports(c.sessionID, name)
+}
+
+// Notify 向客户端发送通知
+func (c *sessionClient) Notify(method string, params interface{}) error {
+	notification, err := mcp.NewNotification(method, params)
+	if err != nil {
+		return fmt.Errorf("创建客户端通知失败: %w", err)
+	}
+
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("序列化客户端通知失败: %w", err)
+	}
+
+	return c.server.sendToClient(c.sessionID, data)
+}
+
+// sendToClient 向指定会话发送消息
+func (s *Server) sendToClient(sessionID string, message []byte) error {
+	if sessionID == "" {
+		_, err := s.stdout.Write(s.frameStdio(message))
+		return err
+	}
+
+	s.pushMessageToSession(sessionID, message)
+	return nil
+}
+
+// handleClientResponse 将客户端响应交给等待中的请求，sessionID 为发送响应的会话，
+// 与发起请求的会话不同时丢弃，避免一个会话回答另一个会话的确认或询问
+func (s *Server) handleClientResponse(sessionID string, data []byte) {
+	var response mcp.MCPResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		logging.Logger.Printf("解析客户端响应失败: %v", err)
+		return
+	}
+
+	id := response.GetIDString()
+	s.pendingMutex.Lock()
+	pending, exists := s.pendingRequests[id]
+	s.pendingMutex.Unlock()
+
+	if !exists {
+		logging.Logger.Printf("收到未知请求的客户端响应: ID=%s", id)
+		return
+	}
+	if pending.sessionID != sessionID {
+		logging.Logger.Printf("丢弃来自其他会话的客户端响应: ID=%s, 会话=%s", id, sessionID)
+		return
+	}
+
+	select {
+	case pending.response <- &response:
+	default:
+		logging.Logger.Printf("重复的客户端响应已忽略: ID=%s", id)
+	}
+}
+
+// isClientResponse 判断标准输入中的消息是否为客户端对服务器请求的响应: 带有ID但没有方法名的单个消息
+func isClientResponse(data []byte) bool {
+	var message struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return false
+	}
+	return message.Method == "" && len(message.ID) > 0 && string(message.ID) != "null"
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+func TestHandleClientResponseSession(t *testing.T) {
+	logging.Logger = log.New(io.Discard, "", 0)
+
+	tests := []struct {
+		name      string
+		owner     string
+		sender    string
+		delivered bool
+	}{
+		{"same sse session", "session-a", "session-a", true},
+		{"other sse session", "session-a", "session-b", false},
+		{"stdio", "", "", true},
+		{"sse answering stdio", "", "session-b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{pendingRequests: make(map[string]*pendingRequest)}
+			pending := &pendingRequest{sessionID: tt.owner, response: make(chan *mcp.MCPResponse, 1)}
+			s.pendingRequests["mcp2rest-1"] = pending
+
+			s.handleClientResponse(tt.sender, []byte(`{"jsonrpc":"2.0","id":"mcp2rest-1","result":{"action":"accept"}}`))
+			if got := len(pending.response) == 1; got != tt.delivered {
+				t.Errorf("delivered = %v, want %v", got, tt.delivered)
+			}
+		})
+	}
+}
+
+func TestIsClientResponse(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{`{"jsonrpc":"2.0","id":"mcp2rest-1","result":{}}`, true},
+		{`{"jsonrpc":"2.0","id":7,"error":{"code":-1,"message":"x"}}`, true},
+		{`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{}}`, false},
+		{`{"jsonrpc":"2.0","method":"notifications/initialized"}`, false},
+		{`{"jsonrpc":"2.0","id":null,"result":{}}`, false},
+		{`[{"jsonrpc":"2.0","id":1,"result":{}}]`, false},
+		{`not json`, false},
+	}
+	for _, tt := range tests {
+		if got := isClientResponse([]byte(tt.message)); got != tt.want {
+			t.Errorf("isClientResponse(%s) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/quota"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// ErrShuttingDown 已收到 exit，服务器不再接受新的工具调用
+var ErrShuttingDown = errors.New("服务器正在关闭，不再接受新的工具调用")
+
+// ToolForbiddenError 访问控制不允许调用该工具
+type ToolForbiddenError struct {
+	Tool string
+}
+
+func (e *ToolForbiddenError) Error() string {
+	return fmt.Sprintf("无权调用工具 %s", e.Tool)
+}
+
+// RateLimitedError 客户端调用过于频繁，RetryAfter 之后可以再次调用
+type RateLimitedError struct {
+	Client     string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("客户端 %s 调用过于频繁，请在 %v 后重试", e.Client, e.RetryAfter.Round(time.Second))
+}
+
+// ServeStdio 在给定的流上运行标准输入/输出服务器，直到输入结束或服务器被取消
+// 供嵌入使用，命令行程序通过 Start 使用进程的标准输入和标准输出
+func (s *Server) ServeStdio(in io.Reader, out io.Writer) error {
+	s.stdin, s.stdout = in, out
+	if err := s.startBackground(); err != nil {
+		return err
+	}
+	return s.startStdioServer()
+}
+
+// HTTPHandler 返回 SSE 模式的 HTTP 端点 (/sse、/messages/ 等)，供挂载到嵌入程序自己的 HTTP 服务器
+// prefix 为挂载的路径前缀 (如 "/mcp")，客户端收到的消息端点地址包含该前缀
+func (s *Server) HTTPHandler(prefix string) (http.Handler, error) {
+	s.pathPrefix = strings.TrimSuffix(prefix, "/")
+	if err := s.startBackground(); err != nil {
+		return nil, err
+	}
+	if err := s.subscribeForwarded(); err != nil {
+		return nil, err
+	}
+	mux := s.sseMux()
+	if s.pathPrefix == "" {
+		return mux, nil
+	}
+	return http.StripPrefix(s.pathPrefix, mux), nil
+}
+
+// CallTool 在进程内调用工具，与标准输入/输出客户端的 tools/call 一样检查访问控制 (stdio_client)、频率限制和配额，
+// 并计入工具调用统计；工具本身的错误以 Type 为 "error" 的结果返回
+func (s *Server) CallTool(ctx context.Context, name string, params map[string]interface{}) (*mcp.ToolCallResult, error) {
+	if logging.RequestID(ctx) == "" {
+		ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	}
+	s.activeCalls.Add(1)
+	defer s.activeCalls.Add(-1)
+	if s.exiting.Load() {
+		return nil, ErrShuttingDown
+	}
+
+	client := s.accessClient("")
+	ctx = withAccess(ctx, client)
+	ctx = handler.WithQuotaSubject(ctx, quota.Subject{Client: clientName(client)})
+	ctx = handler.WithCaller(ctx, handler.Caller{Client: clientName(client)})
+	return s.handler.HandleRequest(ctx, &mcp.ToolCallParams{Name: name, Parameters: params})
+}
+
+// Tools 返回 stdio_client 可以使用的工具，与 tools/list 一致
+func (s *Server) Tools(ctx context.Context) []map[string]interface{} {
+	return s.handler.AllowedTools(withAccess(ctx, s.accessClient("")))
+}
+
+// RegisterBackend 注册具名的执行后端，规范中 x-mcp-backend 为该名称的操作由它执行
+func (s *Server) RegisterBackend(name string, backend handler.Backend) {
+	s.handler.RegisterBackend(name, backend)
+}
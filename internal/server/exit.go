@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mcp2rest/internal/logging"
+)
+
+// defaultExitDrainTimeout exit 后等待正在执行的工具调用完成的默认时间
+const defaultExitDrainTimeout = 10 * time.Second
+
+type afterResponseKey struct{}
+
+// afterResponseHooks 保存响应写出后需要执行的操作
+type afterResponseHooks struct {
+	mu    sync.Mutex
+	hooks []func()
+}
+
+// withAfterResponse 返回可以登记响应后操作的上下文，写出响应后调用返回的函数执行已登记的操作
+func withAfterResponse(ctx context.Context) (context.Context, func()) {
+	hooks := &afterResponseHooks{}
+	return context.WithValue(ctx, afterResponseKey{}, hooks), func() {
+		hooks.mu.Lock()
+		pending := hooks.hooks
+		hooks.hooks = nil
+		hooks.mu.Unlock()
+		for _, hook := range pending {
+			hook()
+		}
+	}
+}
+
+// afterResponse 登记在响应写出后执行的操作，上下文不支持时立即执行
+func afterResponse(ctx context.Context, hook func()) {
+	hooks, ok := ctx.Value(afterResponseKey{}).(*afterResponseHooks)
+	if !ok {
+		hook()
+		return
+	}
+	hooks.mu.Lock()
+	hooks.hooks = append(hooks.hooks, hook)
+	hooks.mu.Unlock()
+}
+
+// exitEnabled 检查是否允许客户端通过 exit 关闭服务器，未配置时只有 stdio 模式允许
+// SSE 服务器可能同时服务多个客户端，不应由其中一个客户端关闭
+func (s *Server) exitEnabled() bool {
+	if enabled := s.config.Global.Exit.Enabled; enabled != nil {
+		return *enabled
+	}
+	return s.config.Server.Mode == "stdio"
+}
+
+// exitDrainTimeout 返回 exit 后等待工具调用完成的最长时间
+func (s *Server) exitDrainTimeout() time.Duration {
+	if timeout := s.config.Global.Exit.DrainTimeout; timeout > 0 {
+		return timeout
+	}
+	return defaultExitDrainTimeout
+}
+
+// shutdownAfterExit 在 exit 响应写出后执行：等待正在执行的工具调用完成 (有上限)，然后停止服务器并退出进程
+func (s *Server) shutdownAfterExit() {
+	timeout := s.exitDrainTimeout()
+	deadline := time.Now().Add(timeout)
+	for s.activeCalls.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if remaining := s.activeCalls.Load(); remaining > 0 {
+		logging.Logger.Printf("等待工具调用完成超时 (%v)，仍有 %d 个调用未完成", timeout, remaining)
+	}
+
+	// 停止服务器同样有时间上限，避免长连接阻塞退出
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		logging.Logger.Printf("停止服务器超时 (%v)，强制退出", timeout)
+	}
+	os.Exit(0)
+}
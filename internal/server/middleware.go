@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcp2rest/internal/access"
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// Use 在内置中间件之后追加工具调用中间件，只作用于通过访问控制和频率限制的调用
+func (s *Server) Use(middleware ...handler.Middleware) {
+	s.handler.Use(middleware...)
+}
+
+// builtinMiddleware 内置的工具调用中间件，由外到内依次为日志、访问控制、频率限制和统计
+// 被拒绝的调用不计入工具调用统计
+func (s *Server) builtinMiddleware() []handler.Middleware {
+	return []handler.Middleware{logCalls, authorizeCalls(s.handler), limitCalls, s.recordCalls}
+}
+
+// logCalls 记录工具调用的参数、耗时和结果
+func logCalls(next handler.ToolHandler) handler.ToolHandler {
+	return func(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+		logger := logging.FromContext(ctx)
+		logger.Printf("工具调用: %s, 参数: %+v", params.Name, params.Parameters)
+		started := time.Now()
+		result, err := next(ctx, params)
+		if err != nil {
+			logger.Printf("工具调用失败: %s, 耗时=%v: %v", params.Name, time.Since(started), err)
+		} else {
+			logger.Printf("工具调用完成: %s, 耗时=%v, 结果=%s", params.Name, time.Since(started), result.Type)
+		}
+		return result, err
+	}
+}
+
+// authorizeCalls 按上下文中的客户端检查工具权限，未配置访问控制时不限制
+func authorizeCalls(h *handler.RequestHandler) handler.Middleware {
+	return func(next handler.ToolHandler) handler.ToolHandler {
+		return func(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+			if !h.ToolAllowed(ctx, params.Name) {
+				logging.FromContext(ctx).Printf("客户端 %s 无权调用工具 %s", clientName(callerFromContext(ctx)), params.Name)
+				return nil, &ToolForbiddenError{Tool: params.Name}
+			}
+			return next(ctx, params)
+		}
+	}
+}
+
+// limitCalls 按上下文中客户端的 rate_limit 限制调用频率
+func limitCalls(next handler.ToolHandler) handler.ToolHandler {
+	return func(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+		if client := callerFromContext(ctx); client != nil {
+			if ok, wait := client.Reserve(); !ok {
+				logging.FromContext(ctx).Printf("客户端 %s 调用过于频繁，拒绝调用工具 %s", client.Name, params.Name)
+				return nil, &RateLimitedError{Client: client.Name, RetryAfter: wait}
+			}
+		}
+		return next(ctx, params)
+	}
+}
+
+// recordCalls 把调用和上游流量计入工具调用统计，工具返回错误结果也视为失败
+func (s *Server) recordCalls(next handler.ToolHandler) handler.ToolHandler {
+	return func(ctx context.Context, params *mcp.ToolCallParams) (*mcp.ToolCallResult, error) {
+		started := time.Now()
+		ctx, traffic := handler.WithTraffic(ctx)
+		result, err := next(ctx, params)
+		s.recordToolCall(ctx, params.Name, time.Since(started), err != nil || result.Type == "error")
+		s.stats.AddTraffic(params.Name, traffic.Sent(), traffic.Received())
+		return result, err
+	}
+}
+
+type callerContextKey struct{}
+
+// callerFromContext 返回发起调用的客户端，未配置访问控制或不限制时返回 nil
+func callerFromContext(ctx context.Context) *access.Client {
+	client, _ := ctx.Value(callerContextKey{}).(*access.Client)
+	return client
+}
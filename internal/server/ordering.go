@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 标准输入/输出的响应顺序
+const (
+	// orderingConcurrent 响应在处理完成后立即写出，可能与请求顺序不同 (默认)
+	orderingConcurrent = "concurrent"
+	// orderingOrdered 请求仍然并发处理，响应按请求的顺序写出
+	orderingOrdered = "ordered"
+)
+
+// responseSequencer 按请求的接收顺序释放响应
+// 读取协程为每条消息分配序号，处理完成后交回 (通知等没有响应的消息交回 nil)，
+// 前面的消息都已交回时才写出，后完成的响应在此等待
+type responseSequencer struct {
+	mu       sync.Mutex
+	assigned uint64
+	released uint64
+	pending  map[uint64][]byte
+	write    func([]byte) error
+}
+
+func newResponseSequencer(write func([]byte) error) *responseSequencer {
+	return &responseSequencer{pending: make(map[uint64][]byte), write: write}
+}
+
+// parseOrdering 检查 server.ordering 的取值，为空时使用 concurrent
+func parseOrdering(value string) (string, error) {
+	switch value {
+	case "", orderingConcurrent:
+		return orderingConcurrent, nil
+	case orderingOrdered:
+		return orderingOrdered, nil
+	}
+	return "", fmt.Errorf("无效的响应顺序 %q，应为 concurrent 或 ordered", value)
+}
+
+// assign 分配下一个序号，只由读取协程调用
+func (q *responseSequencer) assign() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	seq := q.assigned
+	q.assigned++
+	return seq
+}
+
+// done 交回序号对应的响应并写出已经可以释放的响应，返回其中第一个写入错误
+// 每个序号必须恰好交回一次，否则后面的响应会一直等待
+func (q *responseSequencer) done(seq uint64, data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending[seq] = data
+
+	var firstErr error
+	for {
+		data, ok := q.pending[q.released]
+		if !ok {
+			return firstErr
+		}
+		delete(q.pending, q.released)
+		q.released++
+		if data == nil {
+			continue
+		}
+		if err := q.write(data); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// toolFieldVersions 工具定义中较新协议版本才有的字段及其最低版本
+var toolFieldVersions = map[string]string{
+	"annotations":  mcp.ProtocolVersion20250326,
+	"title":        mcp.ProtocolVersion20250618,
+	"outputSchema": mcp.ProtocolVersion20250618,
+}
+
+// adaptTools 按会话协商的协议版本去掉工具定义中该版本没有的字段
+// 工具定义可能被后端缓存复用，需要修改时复制一份，不修改原定义
+func adaptTools(version string, tools []map[string]interface{}) []map[string]interface{} {
+	adapted := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		var copied map[string]interface{}
+		for field, minimum := range toolFieldVersions {
+			if _, exists := tool[field]; !exists || mcp.ProtocolAtLeast(version, minimum) {
+				continue
+			}
+			if copied == nil {
+				copied = make(map[string]interface{}, len(tool))
+				for key, value := range tool {
+					copied[key] = value
+				}
+			}
+			delete(copied, field)
+		}
+		if copied != nil {
+			tool = copied
+		}
+		adapted = append(adapted, tool)
+	}
+	return adapted
+}
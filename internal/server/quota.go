@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/quota"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// quotaExceededResponse 构建超过配额的错误响应，error.data 中给出配额、窗口和重置时间，客户端可据此安排重试
+func quotaExceededResponse(ctx context.Context, id string, exceeded *quota.ExceededError) *mcp.MCPResponse {
+	resetAt := exceeded.ResetAt.Format(time.RFC3339)
+	response := newErrorResponse(ctx, id, -32000, i18n.Tc(ctx, "mcp.quota_exceeded", exceeded.Tool, exceeded.Quota, exceeded.Limit, resetAt))
+	data, _ := response.Error.Data.(map[string]interface{})
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	data["quota"] = exceeded.Quota
+	data["tool"] = exceeded.Tool
+	data["window"] = exceeded.Window
+	data["limit"] = exceeded.Limit
+	data["resetAt"] = resetAt
+	data["retryAfter"] = int(time.Until(exceeded.ResetAt).Round(time.Second) / time.Second)
+	response.Error.Data = data
+	return response
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/webhook"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// resourceProvider 提供一组 MCP 资源 (Webhook 事件、定时任务结果、摘要前的原始结果)
+type resourceProvider interface {
+	Resources() []map[string]interface{}
+	Has(uri string) bool
+	Read(ctx context.Context, uri string) (map[string]interface{}, error)
+}
+
+// resourceProviders 返回所有资源提供者
+func (s *Server) resourceProviders() []resourceProvider {
+	return []resourceProvider{s.webhooks, s.scheduler, s.summarizer}
+}
+
+// findResource 返回提供该 URI 的资源提供者
+func (s *Server) findResource(uri string) resourceProvider {
+	for _, provider := range s.resourceProviders() {
+		if provider.Has(uri) {
+			return provider
+		}
+	}
+	return nil
+}
+
+// notifyResourceUpdated 向会话发送 notifications/resources/updated
+// mode 为 all 时通知所有会话，为 subscribers 时只通知订阅了该资源的会话
+func (s *Server) notifyResourceUpdated(uri, mode string) {
+	if mode == webhook.NotifyNone {
+		return
+	}
+
+	s.sessionMutex.RLock()
+	var targets []string
+	for id, session := range s.sessions {
+		if mode == webhook.NotifyAll || session.Subscriptions[uri] {
+			targets = append(targets, id)
+		}
+	}
+	if s.config.Server.Mode == "stdio" && (mode == webhook.NotifyAll || s.stdioSubscriptions[uri]) {
+		targets = append(targets, "")
+	}
+	s.sessionMutex.RUnlock()
+
+	s.broadcast(targets, "notifications/resources/updated", map[string]interface{}{"uri": uri})
+}
+
+// notifyResourceListChanged 向声明了 resources.listChanged 的会话发送 notifications/resources/list_changed
+func (s *Server) notifyResourceListChanged() {
+	s.sessionMutex.RLock()
+	targets := make([]string, 0, len(s.sessions)+1)
+	for id, session := range s.sessions {
+		if supports(session.Capabilities, mcp.CapabilityResourcesListChanged) {
+			targets = append(targets, id)
+		}
+	}
+	if s.config.Server.Mode == "stdio" && supports(s.stdioCapabilities, mcp.CapabilityResourcesListChanged) {
+		targets = append(targets, "")
+	}
+	s.sessionMutex.RUnlock()
+
+	s.broadcast(targets, "notifications/resources/list_changed", map[string]interface{}{})
+}
+
+// broadcast 向多个会话发送同一条通知，空会话ID表示标准输入/输出
+func (s *Server) broadcast(targets []string, method string, params interface{}) {
+	if len(targets) == 0 {
+		return
+	}
+
+	notification, err := mcp.NewNotification(method, params)
+	if err != nil {
+		logging.Logger.Printf("创建通知 %s 失败: %v", method, err)
+		return
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		logging.Logger.Printf("序列化通知 %s 失败: %v", method, err)
+		return
+	}
+	for _, id := range targets {
+		if err := s.sendToClient(id, data); err != nil {
+			logging.Logger.Printf("发送通知 %s 失败: %v", method, err)
+		}
+	}
+}
+
+// handleResourcesList 列出所有资源
+func (s *Server) handleResourcesList(ctx context.Context, request mcp.MCPRequest) ([]byte, error) {
+	resources := []map[string]interface{}{}
+	for _, provider := range s.resourceProviders() {
+		resources = append(resources, provider.Resources()...)
+	}
+	return mcp.MarshalSuccess(request.GetIDString(), map[string]interface{}{
+		"resources": resources,
+	})
+}
+
+// handleResourcesRead 读取资源，调用方随上下文传给资源提供者，只属于某个会话的资源据此检查权限
+func (s *Server) handleResourcesRead(ctx context.Context, sessionID string, request mcp.MCPRequest) ([]byte, error) {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.URI == "" {
+		return json.Marshal(newErrorResponse(ctx, request.GetIDString(), -32602, i18n.Tc(ctx, "mcp.resource_missing_uri")))
+	}
+
+	provider := s.findResource(params.URI)
+	if provider == nil {
+		return json.Marshal(newErrorResponse(ctx, request.GetIDString(), -32002, i18n.Tc(ctx, "mcp.resource_not_found", params.URI)))
+	}
+	ctx = handler.WithCaller(ctx, handler.Caller{Session: sessionID, Client: clientName(s.accessClient(sessionID))})
+	content, err := provider.Read(ctx, params.URI)
+	if err != nil {
+		return json.Marshal(newErrorResponse(ctx, request.GetIDString(), -32002, err.Error()))
+	}
+	text, err := json.Marshal(content)
+	if err != nil {
+		return json.Marshal(newErrorResponse(ctx, request.GetIDString(), -32603, i18n.Tc(ctx, "mcp.resource_marshal_failed", err)))
+	}
+
+	return mcp.MarshalSuccess(request.GetIDString(), map[string]interface{}{
+		"contents": []map[string]interface{}{{
+			"uri":      params.URI,
+			"mimeType": "application/json",
+			"text":     string(text),
+		}},
+	})
+}
+
+// handleResourcesSubscribe 处理 resources/subscribe 和 resources/unsubscribe
+func (s *Server) handleResourcesSubscribe(ctx context.Context, sessionID string, request mcp.MCPRequest) ([]byte, error) {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(request.Params, &params); err != nil || params.URI == "" {
+		return json.Marshal(newErrorResponse(ctx, request.GetIDString(), -32602, i18n.Tc(ctx, "mcp.resource_missing_uri")))
+	}
+	if s.findResource(params.URI) == nil {
+		return json.Marshal(newErrorResponse(ctx, request.GetIDString(), -32002, i18n.Tc(ctx, "mcp.resource_not_found", params.URI)))
+	}
+
+	// 通知使用不带查询参数的 URI
+	uri, _, _ := strings.Cut(params.URI, "?")
+	subscribe := request.Method == "resources/subscribe"
+
+	s.sessionMutex.Lock()
+	subscriptions := s.stdioSubscriptions
+	if sessionID != "" {
+		subscriptions = nil
+		if session, exists := s.sessions[sessionID]; exists {
+			if session.Subscriptions == nil {
+				session.Subscriptions = make(map[string]bool)
+			}
+			subscriptions = session.Subscriptions
+		}
+	}
+	if subscriptions != nil {
+		if subscribe {
+			subscriptions[uri] = true
+		} else {
+			delete(subscriptions, uri)
+		}
+	}
+	s.sessionMutex.Unlock()
+	s.persistSession(sessionID)
+
+	logging.FromContext(ctx).Printf("%s: %s", request.Method, uri)
+	return mcp.MarshalSuccess(request.GetIDString(), map[string]interface{}{})
+}
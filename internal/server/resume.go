@@ -0,0 +1,194 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mcp2rest/internal/logging"
+	sessionpkg "github.com/mcp2rest/internal/session"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// sessionStateLocked 把会话转换为可保存的状态，调用方需要持有 sessionMutex
+func sessionStateLocked(session *MCPSession) *sessionpkg.State {
+	state := &sessionpkg.State{
+		ID:              session.ID,
+		Endpoint:        session.Endpoint,
+		CreatedAt:       session.CreatedAt,
+		Client:          session.Client,
+		ProtocolVersion: session.ProtocolVersion,
+		Capabilities:    session.Capabilities,
+	}
+	for uri := range session.Subscriptions {
+		state.Subscriptions = append(state.Subscriptions, uri)
+	}
+	sort.Strings(state.Subscriptions)
+	for _, message := range session.Pending {
+		state.Pending = append(state.Pending, json.RawMessage(message))
+	}
+	return state
+}
+
+// persistSession 保存会话状态，未配置会话恢复或会话不存在时不做任何事
+func (s *Server) persistSession(sessionID string) {
+	if s.resumeStore == nil || sessionID == "" {
+		return
+	}
+	s.sessionMutex.RLock()
+	session, exists := s.sessions[sessionID]
+	var state *sessionpkg.State
+	if exists {
+		state = sessionStateLocked(session)
+	}
+	s.sessionMutex.RUnlock()
+	if state == nil {
+		return
+	}
+	if err := s.resumeStore.SaveState(s.ctx, state); err != nil {
+		logging.Logger.Printf("保存会话 %s 的状态失败: %v", sessionID, err)
+	}
+}
+
+// detachSessionLocked 在连接断开时保留会话等待恢复，调用方需要持有 sessionMutex
+// 超过有效期仍未恢复的会话从内存和存储中删除
+func (s *Server) detachSessionLocked(session *MCPSession) {
+	detachedAt := time.Now()
+	session.ClientID = ""
+	session.DetachedAt = detachedAt
+	ttl := s.resumeStore.TTL()
+	time.AfterFunc(ttl, func() { s.expireSession(session.ID, detachedAt) })
+	logging.Logger.Printf("会话 %s 的连接已断开，保留 %v 等待恢复", session.ID, ttl)
+}
+
+// expireSession 删除自 detachedAt 起一直没有恢复的会话
+func (s *Server) expireSession(sessionID string, detachedAt time.Time) {
+	s.sessionMutex.Lock()
+	session, exists := s.sessions[sessionID]
+	expired := exists && session.ClientID == "" && session.DetachedAt.Equal(detachedAt)
+	pending := 0
+	if expired {
+		pending = len(session.Pending)
+		delete(s.sessions, sessionID)
+	}
+	s.sessionMutex.Unlock()
+	if !expired {
+		return
+	}
+	s.dropMessages(sessionID, pending, "会话过期")
+	if err := s.resumeStore.DeleteState(s.ctx, sessionID); err != nil {
+		logging.Logger.Printf("删除会话 %s 的状态失败: %v", sessionID, err)
+	}
+	logging.Logger.Printf("会话 %s 超过有效期未恢复，已移除", sessionID)
+}
+
+// restoreSession 从存储中恢复会话 (例如服务器重启后)，恢复的会话没有连接，消息缓存到客户端重新连接
+// 会话已在内存中时直接返回，存储中不存在或已过期时返回 nil
+func (s *Server) restoreSession(sessionID string) *MCPSession {
+	if s.resumeStore == nil {
+		return nil
+	}
+	s.sessionMutex.RLock()
+	session, exists := s.sessions[sessionID]
+	s.sessionMutex.RUnlock()
+	if exists {
+		return session
+	}
+
+	state, err := s.resumeStore.LoadState(s.ctx, sessionID)
+	if err != nil {
+		logging.Logger.Printf("读取会话 %s 的状态失败: %v", sessionID, err)
+		return nil
+	}
+	if state == nil {
+		return nil
+	}
+
+	restored := &MCPSession{
+		ID:              state.ID,
+		Endpoint:        state.Endpoint,
+		CreatedAt:       state.CreatedAt,
+		Client:          state.Client,
+		LastActivity:    time.Now(),
+		ProtocolVersion: state.ProtocolVersion,
+	}
+	if state.Capabilities != nil {
+		restored.Capabilities = mcp.ClientCapabilities(state.Capabilities)
+	}
+	if len(state.Subscriptions) > 0 {
+		restored.Subscriptions = make(map[string]bool, len(state.Subscriptions))
+		for _, uri := range state.Subscriptions {
+			restored.Subscriptions[uri] = true
+		}
+	}
+	for _, message := range state.Pending {
+		restored.Pending = append(restored.Pending, []byte(message))
+	}
+
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	// 并发恢复同一会话时使用先完成的一份
+	if session, exists := s.sessions[sessionID]; exists {
+		return session
+	}
+	s.sessions[sessionID] = restored
+	s.detachSessionLocked(restored)
+	logging.Logger.Printf("已从存储恢复会话 %s", sessionID)
+	return restored
+}
+
+// attachSession 把新的 SSE 连接绑定到已有会话，返回会话和断开期间缓存的消息
+// 会话仍绑定着其他连接时由新连接接管，旧连接被关闭
+func (s *Server) attachSession(sessionID, clientID string) (*MCPSession, [][]byte) {
+	session := s.restoreSession(sessionID)
+	if session == nil {
+		return nil, nil
+	}
+
+	s.sessionMutex.Lock()
+	previous := session.ClientID
+	session.ClientID = clientID
+	session.DetachedAt = time.Time{}
+	session.LastActivity = time.Now()
+	pending := session.Pending
+	session.Pending = nil
+	s.sessionMutex.Unlock()
+
+	if previous != "" {
+		logging.Logger.Printf("会话 %s 由新连接接管，关闭旧连接 %s", sessionID, previous)
+		s.removeSSEConnection(previous)
+	}
+	s.persistSession(sessionID)
+	return session, pending
+}
+
+// queuePending 缓存发往没有连接的会话的消息，超过上限时丢弃最早的消息
+// 会话不存在或未配置会话恢复时消息被丢弃
+func (s *Server) queuePending(sessionID string, message []byte) {
+	if s.resumeStore == nil {
+		s.dropMessages(sessionID, 1, "连接已断开且未启用会话恢复 (global.session_resume)")
+		return
+	}
+
+	dropped := 0
+	s.sessionMutex.Lock()
+	session, exists := s.sessions[sessionID]
+	if exists {
+		session.Pending = append(session.Pending, message)
+		if limit := s.resumeStore.MaxPending(); len(session.Pending) > limit {
+			dropped = len(session.Pending) - limit
+			session.Pending = session.Pending[dropped:]
+		}
+	}
+	s.sessionMutex.Unlock()
+	if !exists {
+		s.dropMessages(sessionID, 1, "会话不存在")
+		return
+	}
+
+	s.sseMetrics.buffered.Add(1)
+	s.dropMessages(sessionID, dropped, fmt.Sprintf("缓存的消息超过上限 %d 条，丢弃最早的消息", s.resumeStore.MaxPending()))
+	s.persistSession(sessionID)
+	logging.Logger.Printf("会话 %s 没有连接，消息已缓存", sessionID)
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/webhook"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// executeScheduledTool 执行定时任务配置的工具调用，工具返回错误结果时视为执行失败
+func (s *Server) executeScheduledTool(ctx context.Context, tool string, params map[string]interface{}) (interface{}, error) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logging.FromContext(ctx).Printf("定时调用工具: %s, 参数: %+v", tool, params)
+
+	result, err := s.handler.HandleRequest(ctx, &mcp.ToolCallParams{Name: tool, Parameters: params})
+	if err != nil {
+		return nil, err
+	}
+	if result.Type == "error" {
+		return nil, fmt.Errorf("工具返回错误: %v", result.Result)
+	}
+	return result.Result, nil
+}
+
+// notifyScheduleResult 定时任务产生新结果后通知客户端，首次出现时资源列表发生变化
+func (s *Server) notifyScheduleResult(uri string, first bool) {
+	if first {
+		s.notifyResourceListChanged()
+	}
+	s.notifyResourceUpdated(uri, webhook.NotifySubscribers)
+}
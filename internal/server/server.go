@@ -5,19 +5,36 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	runtimedebug "runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mcp2rest/internal/access"
 	"github.com/mcp2rest/internal/config"
 	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/diagnostics"
+	"github.com/mcp2rest/internal/framing"
 	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/i18n"
 	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/internal/paths"
+	"github.com/mcp2rest/internal/quota"
+	"github.com/mcp2rest/internal/scheduler"
+	sessionpkg "github.com/mcp2rest/internal/session"
+	"github.com/mcp2rest/internal/stats"
+	"github.com/mcp2rest/internal/summarize"
+	"github.com/mcp2rest/internal/version"
+	"github.com/mcp2rest/internal/webhook"
 	"github.com/mcp2rest/pkg/mcp"
 )
 
@@ -30,12 +47,55 @@ type Server struct {
 	ctx         context.Context
 	cancel      context.CancelFunc
 	done        chan struct{}
+	ready       chan struct{}
 	// SSE 连接管理
 	sseConnections map[string]*SSEConnection
 	sseMutex       sync.RWMutex
 	// 会话管理
 	sessions map[string]*MCPSession
 	sessionMutex sync.RWMutex
+	// 多实例共享的会话存储，未配置时为 nil
+	sessionStore sessionpkg.Store
+	instanceID   string
+	// 会话状态存储，未配置会话恢复时为 nil
+	resumeStore sessionpkg.StateStore
+	// 入站客户端的访问控制，未配置时为 nil
+	access *access.Policy
+	// 标准输入/输出消息读取器，回复使用与请求相同的分帧方式
+	stdioReader *framing.Reader
+	// stdin、stdout 标准输入/输出模式读写的流，默认为进程的标准输入和标准输出，嵌入时可以替换
+	stdin  io.Reader
+	stdout io.Writer
+	// stdioOrder 按请求顺序写出标准输入/输出的响应，server.ordering 为 concurrent 时为 nil
+	stdioOrder *responseSequencer
+	// pathPrefix SSE 端点挂载的路径前缀，嵌入其他 HTTP 服务时用于生成消息端点地址
+	pathPrefix string
+	// startOnce 管理接口、统计和定时任务只启动一次
+	startOnce sync.Once
+	startErr  error
+
+	webhooks  *webhook.Hub
+	scheduler *scheduler.Scheduler
+	// summarizer 过长工具结果的摘要，保存的原始结果作为资源提供
+	summarizer *summarize.Summarizer
+	// stdioSubscriptions 标准输入/输出会话订阅的资源 URI，受 sessionMutex 保护
+	stdioSubscriptions map[string]bool
+	// 标准输入/输出客户端协商的协议版本和声明的能力，受 sessionMutex 保护
+	stdioProtocolVersion string
+	stdioCapabilities    mcp.ClientCapabilities
+
+	stats       *stats.Recorder
+	statsOnce   sync.Once
+	adminServer *http.Server
+	// selfCheck 最近一次启动自检的报告
+	selfCheck atomic.Pointer[diagnostics.Report]
+	// activeCalls 正在执行的工具调用数，exiting 表示已收到 exit，不再接受新的工具调用
+	activeCalls atomic.Int64
+	exiting     atomic.Bool
+	sseMetrics sseMetrics
+	// 服务器向客户端发起的请求
+	pendingRequests map[string]*pendingRequest
+	pendingMutex    sync.Mutex
 }
 
 // SSEConnection SSE连接
@@ -47,19 +107,81 @@ type SSEConnection struct {
 	Cancel     context.CancelFunc
 	RemoteAddr string
 	SessionID  string
+	// outbox 待发送的消息，由连接的处理协程按顺序写出
+	outbox       *sseOutbox
+	writeTimeout time.Duration
+}
+
+// writeEvent 写入一个 SSE 事件并刷新，配置了写入超时时为本次写入设置截止时间
+// 只能由连接的处理协程调用，其他协程通过 outbox 发送消息
+func (c *SSEConnection) writeEvent(event, data string) error {
+	rc := http.NewResponseController(c.Writer)
+	if c.writeTimeout > 0 {
+		// 不支持截止时间的 ResponseWriter 忽略该设置
+		if err := rc.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err == nil {
+			defer rc.SetWriteDeadline(time.Time{})
+		}
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return rc.Flush()
 }
 
 // MCPSession MCP会话
 type MCPSession struct {
-	ID           string
-	ClientID     string
+	ID       string
+	ClientID string
+	// Client 建立会话的入站客户端名称 (global.access)，未配置访问控制时为空
+	Client       string
 	Endpoint     string
 	CreatedAt    time.Time
 	LastActivity time.Time
+	// Subscriptions 通过 resources/subscribe 订阅的资源 URI，受 sessionMutex 保护
+	Subscriptions map[string]bool
+	// ProtocolVersion 协商的协议版本，Capabilities 客户端在 initialize 中声明的能力，
+	// 尚未初始化时分别为空和 nil，受 sessionMutex 保护
+	ProtocolVersion string
+	Capabilities    mcp.ClientCapabilities
+	// 启用会话恢复时，连接断开后 ClientID 为空，DetachedAt 为断开时间，
+	// Pending 缓存断开期间的待发送消息，受 sessionMutex 保护
+	DetachedAt time.Time
+	Pending    [][]byte
 }
 
 // NewServer 创建新的服务器实例
 func NewServer(cfg *config.Config, spec *config.OpenAPISpec) (*Server, error) {
+	// 错误消息的默认语言，SSE 请求可以通过 Accept-Language 另行指定
+	if err := i18n.SetDefault(cfg.Global.Locale); err != nil {
+		return nil, err
+	}
+
+	// 按标签裁剪规范，之后生成的工具、组合工具和定时任务都只能使用保留的操作
+	if len(cfg.Global.Tags) > 0 || len(cfg.Global.ExcludeTags) > 0 {
+		kept, total, err := openapi.FilterByTags(spec, cfg.Global.Tags, cfg.Global.ExcludeTags)
+		if err != nil {
+			return nil, fmt.Errorf("按标签筛选操作失败: %w", err)
+		}
+		logging.Logger.Printf("按标签筛选操作: 保留 %d/%d 个 (tags=%v, exclude_tags=%v)", kept, total, cfg.Global.Tags, cfg.Global.ExcludeTags)
+	}
+
+	// 描述覆盖在标签筛选之后、生成工具列表之前应用，之后的配置 (组合工具、配额等) 使用覆盖后的工具名称
+	if cfg.Global.Descriptions != "" {
+		overrides, err := config.LoadDescriptionsFile(cfg.Global.Descriptions)
+		if err != nil {
+			return nil, err
+		}
+		applied, unknown, err := openapi.ApplyDescriptions(spec, overrides)
+		if err != nil {
+			return nil, fmt.Errorf("应用描述覆盖失败: %w", err)
+		}
+		logging.Logger.Printf("已应用描述覆盖文件 %s: %d 个工具", cfg.Global.Descriptions, applied)
+		if len(unknown) > 0 {
+			logging.Logger.Printf("警告: 描述覆盖文件中的 %s 在规范中不存在", strings.Join(unknown, ", "))
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// 创建请求处理器
@@ -69,20 +191,95 @@ func NewServer(cfg *config.Config, spec *config.OpenAPISpec) (*Server, error) {
 		return nil, fmt.Errorf("创建请求处理器失败: %w", err)
 	}
 
-	return &Server{
-		config:         cfg,
-		openAPISpec:    spec,
-		handler:        reqHandler,
-		ctx:            ctx,
-		cancel:         cancel,
-		done:           make(chan struct{}),
-		sseConnections: make(map[string]*SSEConnection),
-		sessions:       make(map[string]*MCPSession),
-	}, nil
+	// 共享会话存储只用于 SSE 模式
+	var store sessionpkg.Store
+	if cfg.Server.Mode == "sse" {
+		if store, err = sessionpkg.NewStore(cfg.Global.SessionStore); err != nil {
+			cancel()
+			return nil, fmt.Errorf("创建会话存储失败: %w", err)
+		}
+	}
+	var resumeStore sessionpkg.StateStore
+	if cfg.Server.Mode == "sse" {
+		if resumeStore, err = sessionpkg.NewStateStore(cfg.Global.SessionResume, cfg.Global.SessionStore); err != nil {
+			cancel()
+			return nil, fmt.Errorf("创建会话恢复存储失败: %w", err)
+		}
+	}
+
+	policy, err := access.New(cfg.Global.Access)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("创建访问控制失败: %w", err)
+	}
+
+	hub, err := webhook.NewHub(cfg.Global.Webhooks)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("创建 Webhook 失败: %w", err)
+	}
+	if !hub.Empty() && cfg.Server.Mode != "sse" {
+		logging.Logger.Printf("警告: Webhook 只在 SSE 模式下接收事件，%s 模式下事件资源始终为空", cfg.Server.Mode)
+	}
+
+	// 摘要可以通过发起工具调用的客户端生成 (sampling)，模型名称作为提示
+	var sampler handler.ClientSampler
+	if cfg.Global.Summarize != nil && cfg.Global.Summarize.Model != "" {
+		sampler.ModelHints = []string{cfg.Global.Summarize.Model}
+	}
+	summarizer, err := summarize.New(cfg.Global.Summarize, cfg.Global.MaxResultChars, sampler)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("创建结果摘要失败: %w", err)
+	}
+
+	// 加载上次停止时保存的工具调用统计，失败时从零开始
+	recorder := stats.NewRecorder(cfg.Global.Stats.SlowThreshold)
+	if file := cfg.Global.Stats.File; file != "" {
+		if err := recorder.Load(paths.Resolve(file)); err != nil {
+			logging.Logger.Printf("加载工具调用统计失败: %v", err)
+		}
+	}
+
+	srv := &Server{
+		config:             cfg,
+		openAPISpec:        spec,
+		handler:            reqHandler,
+		ctx:                ctx,
+		cancel:             cancel,
+		done:               make(chan struct{}),
+		ready:              make(chan struct{}),
+		sseConnections:     make(map[string]*SSEConnection),
+		sessions:           make(map[string]*MCPSession),
+		pendingRequests:    make(map[string]*pendingRequest),
+		sessionStore:       store,
+		resumeStore:        resumeStore,
+		access:             policy,
+		instanceID:         uuid.New().String(),
+		stats:              recorder,
+		webhooks:           hub,
+		summarizer:         summarizer,
+		stdioSubscriptions: make(map[string]bool),
+		stdin:              os.Stdin,
+		stdout:             os.Stdout,
+	}
+
+	reqHandler.Use(srv.builtinMiddleware()...)
+
+	srv.scheduler, err = scheduler.New(cfg.Global.Schedules, cfg.Global.Timeout, srv.executeScheduledTool, srv.notifyScheduleResult)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("创建定时任务失败: %w", err)
+	}
+	return srv, nil
 }
 
 // Start 启动服务器
 func (s *Server) Start() error {
+	if err := s.startBackground(); err != nil {
+		return err
+	}
+
 	switch s.config.Server.Mode {
 	case "sse":
 		return s.startSSEServer()
@@ -93,10 +290,41 @@ func (s *Server) Start() error {
 	}
 }
 
+// startBackground 启动管理接口、统计输出、定时任务和启动自检，多次调用只启动一次
+func (s *Server) startBackground() error {
+	s.startOnce.Do(func() {
+		if err := s.startAdminServer(); err != nil {
+			s.startErr = fmt.Errorf("启动管理接口失败: %w", err)
+			return
+		}
+		s.startStatsReporter()
+		s.scheduler.Start(s.ctx)
+		if s.config.Global.SelfCheck == nil || *s.config.Global.SelfCheck {
+			go s.SelfCheck(s.ctx).Log()
+		}
+	})
+	return s.startErr
+}
+
+// SelfCheck 运行启动自检并保存报告，管理接口 /admin/selfcheck 返回最近一次的报告
+func (s *Server) SelfCheck(ctx context.Context) *diagnostics.Report {
+	report := diagnostics.Run(ctx, diagnostics.Options{
+		Spec:   s.openAPISpec,
+		Global: &s.config.Global,
+		Prober: s.handler,
+	})
+	s.selfCheck.Store(report)
+	return report
+}
+
 // Stop 停止服务器
 func (s *Server) Stop() error {
 	logging.Logger.Println("正在停止服务器...")
 	s.cancel()
+	s.saveStats()
+	if s.adminServer != nil {
+		s.adminServer.Close()
+	}
 
 	// 关闭HTTP服务器
 	if s.httpServer != nil {
@@ -118,6 +346,10 @@ func (s *Server) Stop() error {
 func (s *Server) StopWithContext(ctx context.Context) error {
 	logging.Logger.Println("正在停止服务器...")
 	s.cancel()
+	s.saveStats()
+	if s.adminServer != nil {
+		s.adminServer.Close()
+	}
 
 	// 等待 done 通道或上下文超时
 	select {
@@ -142,9 +374,15 @@ func (s *Server) Done() <-chan struct{} {
 	return s.done
 }
 
-// Cancel 取消服务器上下文
+// Ready 返回就绪通道，SSE 服务器开始监听端口后关闭
+func (s *Server) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Cancel 取消服务器上下文，并保存工具调用统计
 func (s *Server) Cancel() {
 	s.cancel()
+	s.saveStats()
 }
 
 // getServerName 根据模式获取服务器名称
@@ -159,24 +397,58 @@ func getServerName(mode string) string {
 	}
 }
 
-// startSSEServer 启动SSE服务器
-func (s *Server) startSSEServer() error {
+// sseMux 返回 SSE 模式的 HTTP 端点
+func (s *Server) sseMux() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// 按照 MCP SSE 规范设置端点
 	mux.HandleFunc("/sse", s.handleSSEConnection)           // GET: 建立 SSE 连接
 	mux.HandleFunc("/messages/", s.handleMCPMessages)       // POST: 处理 MCP 消息
+	if !s.webhooks.Empty() {
+		mux.HandleFunc("/webhooks/", s.handleWebhook) // POST: 接收上游事件
+	}
+	if admin := s.config.Global.Admin; admin.Enabled && admin.Address == "" {
+		mux.Handle("/admin/", s.adminHandler()) // 管理接口
+	}
+	return mux
+}
 
+// subscribeForwarded 订阅其他实例转发来的消息，未配置共享会话存储时不做任何事
+func (s *Server) subscribeForwarded() error {
+	if s.sessionStore == nil {
+		return nil
+	}
+	if err := s.sessionStore.Subscribe(s.ctx, s.instanceID, s.handleForwardedMessage); err != nil {
+		return fmt.Errorf("订阅会话消息失败: %w", err)
+	}
+	logging.Logger.Printf("已启用共享会话存储，实例ID: %s", s.instanceID)
+	return nil
+}
+
+// startSSEServer 启动SSE服务器
+func (s *Server) startSSEServer() error {
 	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
 	s.httpServer = &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: s.sseMux(),
 	}
 
+	// 订阅其他实例转发来的消息
+	if err := s.subscribeForwarded(); err != nil {
+		return err
+	}
+
+	// 先完成监听再通知就绪，避免服务管理器在端口可用之前认为服务已启动
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", addr, err)
+	}
+	close(s.ready)
+
 	logging.Logger.Printf("SSE服务器启动在 %s", addr)
 	logging.Logger.Printf("SSE连接端点: %s/sse", addr)
 	logging.Logger.Printf("消息处理端点: %s/messages/", addr)
-	return s.httpServer.ListenAndServe()
+	return s.httpServer.Serve(listener)
 }
 
 // handleSSEConnection 处理SSE连接建立 (GET /sse)
@@ -186,13 +458,19 @@ func (s *Server) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 配置了访问控制时识别客户端，会话之后的消息必须来自同一客户端
+	client, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
 	// 设置SSE头
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("X-Accel-Buffering", "no")
-	w.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Cache-Control")
 
 	// 创建SSE写入器
 	flusher, ok := w.(http.Flusher)
@@ -204,8 +482,18 @@ func (s *Server) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 	// 创建客户端连接标识
 	clientID := fmt.Sprintf("%s-%d", r.RemoteAddr, time.Now().UnixNano())
 	
-	// 创建会话ID
+	// 创建会话ID，客户端通过 session_id 参数请求恢复的会话仍有效时沿用原会话ID
 	sessionID := s.generateSessionID()
+	resuming := false
+	if resumeID := r.URL.Query().Get("session_id"); resumeID != "" {
+		if s.restoreSession(resumeID) == nil {
+			logging.Logger.Printf("请求恢复的会话 %s 不存在或已过期，创建新会话", resumeID)
+		} else if !s.sessionClientMatches(resumeID, clientName(client)) {
+			logging.Logger.Printf("请求恢复的会话 %s 属于其他客户端，创建新会话", resumeID)
+		} else {
+			sessionID, resuming = resumeID, true
+		}
+	}
 	
 	// 创建连接上下文
 	connCtx, connCancel := context.WithCancel(r.Context())
@@ -219,13 +507,17 @@ func (s *Server) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 		Cancel:     connCancel,
 		RemoteAddr: r.RemoteAddr,
 		SessionID:  sessionID,
+
+		outbox:       newSSEOutbox(),
+		writeTimeout: s.config.Server.SSE.WriteTimeout,
 	}
 
 	// 创建会话
 	session := &MCPSession{
 		ID:           sessionID,
 		ClientID:     clientID,
-		Endpoint:     fmt.Sprintf("/messages/?session_id=%s", sessionID),
+		Client:       clientName(client),
+		Endpoint:     fmt.Sprintf("%s/messages/?session_id=%s", s.pathPrefix, sessionID),
 		CreatedAt:    time.Now(),
 		LastActivity: time.Now(),
 	}
@@ -235,14 +527,27 @@ func (s *Server) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 	s.sseConnections[clientID] = conn
 	s.sseMutex.Unlock()
 
-	s.sessionMutex.Lock()
-	s.sessions[sessionID] = session
-	s.sessionMutex.Unlock()
+	var pending [][]byte
+	if resuming {
+		if resumed, messages := s.attachSession(sessionID, clientID); resumed != nil {
+			session, pending = resumed, messages
+			logging.Logger.Printf("会话 %s 已恢复，待推送 %d 条消息", sessionID, len(pending))
+		} else {
+			resuming = false
+		}
+	}
+	if !resuming {
+		s.sessionMutex.Lock()
+		s.sessions[sessionID] = session
+		s.sessionMutex.Unlock()
+		s.persistSession(sessionID)
+	}
+	s.saveSharedSession(session)
 
 	logging.Logger.Printf("SSE客户端连接: %s, 会话: %s", clientID, sessionID)
 
 	// 记录调试信息
-	debug.LogInfo("SSE连接建立", map[string]interface{}{
+	debug.LogInfo(r.Context(), "SSE连接建立", map[string]interface{}{
 		"remote_addr": r.RemoteAddr,
 		"method":      r.Method,
 		"url":         r.URL.String(),
@@ -252,13 +557,62 @@ func (s *Server) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 	})
 
 	// 按照 MCP 规范发送专用消息端点
-	endpointMessage := fmt.Sprintf("event: endpoint\ndata: %s\n\n", session.Endpoint)
-	fmt.Fprint(w, endpointMessage)
-	flusher.Flush()
+	// 连接关闭后，尚未写出的消息交还给会话，重新连接后重放或计为丢弃
+	defer func() {
+		for _, message := range conn.outbox.close() {
+			s.pushMessageToSession(sessionID, message)
+		}
+	}()
+
+	if err := conn.writeEvent("endpoint", session.Endpoint); err != nil {
+		s.retireConnection(conn, "消息端点", err)
+		for _, message := range pending {
+			s.queuePending(sessionID, message)
+		}
+		return
+	}
+	for i, message := range pending {
+		if err := conn.writeEvent("message", string(message)); err != nil {
+			s.retireConnection(conn, "缓存的消息", err)
+			for _, rest := range pending[i:] {
+				s.queuePending(sessionID, rest)
+			}
+			return
+		}
+		s.sseMetrics.replayed.Add(1)
+	}
 
-	// 保持连接活跃
+	sseConfig := s.config.Server.SSE
+	interval := sseConfig.HeartbeatInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	// 未配置最长存活时间时 lifetime 为 nil，永远不会触发
+	var lifetime <-chan time.Time
+	if sseConfig.MaxLifetime > 0 {
+		timer := time.NewTimer(sseConfig.MaxLifetime)
+		defer timer.Stop()
+		lifetime = timer.C
+	}
+
+	// 保持连接活跃，本协程是连接唯一的写入者，按顺序写出排队的消息和心跳
 	for {
 		select {
+		case <-conn.outbox.ready:
+			messages := conn.outbox.take()
+			for i, message := range messages {
+				if err := conn.writeEvent("message", string(message)); err != nil {
+					s.retireConnection(conn, "消息", err)
+					// 写入失败的消息和之后的消息先于队列中剩余的消息交还给会话
+					for _, rest := range append(messages[i:], conn.outbox.close()...) {
+						s.queuePending(sessionID, rest)
+					}
+					return
+				}
+			}
 		case <-s.ctx.Done():
 			logging.Logger.Printf("服务器关闭，SSE连接关闭: %s", clientID)
 			s.removeSSEConnection(clientID)
@@ -267,16 +621,18 @@ func (s *Server) handleSSEConnection(w http.ResponseWriter, r *http.Request) {
 			logging.Logger.Printf("客户端断开连接: %s", clientID)
 			s.removeSSEConnection(clientID)
 			return
-		case <-time.After(30 * time.Second):
-			// 每30秒发送一次心跳，保持连接活跃
-			s.sseMutex.RLock()
-			if currentConn, exists := s.sseConnections[clientID]; exists {
-				heartbeatMessage := fmt.Sprintf("event: heartbeat\ndata: {\"timestamp\":\"%s\",\"session_id\":\"%s\"}\n\n",
-					time.Now().Format(time.RFC3339), sessionID)
-				fmt.Fprint(currentConn.Writer, heartbeatMessage)
-				currentConn.Flusher.Flush()
+		case <-lifetime:
+			logging.Logger.Printf("SSE连接达到最长存活时间 %v，关闭连接: %s", sseConfig.MaxLifetime, clientID)
+			s.removeSSEConnection(clientID)
+			return
+		case <-heartbeat.C:
+			// 定期发送心跳保持连接活跃，写入失败说明连接已失效
+			data := fmt.Sprintf("{\"timestamp\":\"%s\",\"session_id\":\"%s\"}", time.Now().Format(time.RFC3339), sessionID)
+			if err := conn.writeEvent("heartbeat", data); err != nil {
+				s.retireConnection(conn, "心跳", err)
+				return
 			}
-			s.sseMutex.RUnlock()
+			s.saveSharedSession(session)
 		}
 	}
 }
@@ -288,6 +644,16 @@ func (s *Server) generateSessionID() string {
 
 // handleMCPMessages 处理MCP消息 (POST /messages/?session_id=xxx)
 func (s *Server) handleMCPMessages(w http.ResponseWriter, r *http.Request) {
+	// 客户端可通过 X-Request-Id 指定关联ID，否则生成新的ID，并在响应头中返回
+	requestID := r.Header.Get(logging.RequestIDHeader)
+	if !logging.ValidRequestID(requestID) {
+		requestID = logging.NewRequestID()
+	}
+	w.Header().Set(logging.RequestIDHeader, requestID)
+	ctx, runAfterResponse := withAfterResponse(i18n.WithLocale(logging.WithRequestID(s.ctx, requestID), i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))))
+	defer runAfterResponse()
+	logger := logging.FromContext(ctx)
+
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -297,7 +663,8 @@ func (s *Server) handleMCPMessages(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+logging.RequestIDHeader)
+	w.Header().Set("Access-Control-Expose-Headers", logging.RequestIDHeader)
 
 	// 处理 OPTIONS 预检请求
 	if r.Method == "OPTIONS" {
@@ -312,13 +679,59 @@ func (s *Server) handleMCPMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	client, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
 	// 验证会话
 	s.sessionMutex.RLock()
 	session, exists := s.sessions[sessionID]
 	s.sessionMutex.RUnlock()
 
+	// 会话不在本实例时查找共享存储，归属其他实例的消息转发过去处理
+	var owner string
 	if !exists {
-		http.Error(w, "Invalid session_id", http.StatusBadRequest)
+		owner = s.sharedSessionOwner(sessionID)
+		// 没有在线的归属实例时尝试从会话恢复存储中恢复，例如服务器重启后客户端继续使用原会话
+		if owner == "" {
+			if session = s.restoreSession(sessionID); session == nil {
+				http.Error(w, "Invalid session_id", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	// 归属其他实例的会话由该实例检查客户端
+	if owner == "" && !s.sessionClientMatches(sessionID, clientName(client)) {
+		logger.Printf("客户端 %s 无权使用会话 %s", clientName(client), sessionID)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	limit := s.config.Global.MaxRequestBytes()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			logger.Printf("请求体超过大小限制: %d 字节", limit)
+			http.Error(w, i18n.Tc(ctx, "http.request_too_large", limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		logger.Printf("读取请求体失败: %v", err)
+		debug.LogError(ctx, "读取MCP请求体失败", err)
+		http.Error(w, i18n.Tc(ctx, "http.read_body_failed"), http.StatusBadRequest)
+		return
+	}
+
+	if owner != "" {
+		if err := s.sessionStore.Publish(r.Context(), owner, &sessionpkg.Message{SessionID: sessionID, RequestID: requestID, Locale: i18n.FromContext(ctx), Client: clientName(client), Body: body}); err != nil {
+			logger.Printf("转发会话 %s 的消息失败: %v", sessionID, err)
+			http.Error(w, "Invalid session_id", http.StatusBadRequest)
+			return
+		}
+		logger.Printf("会话 %s 归属实例 %s，消息已转发", sessionID, owner)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"Accepted"}`))
 		return
 	}
 
@@ -327,27 +740,19 @@ func (s *Server) handleMCPMessages(w http.ResponseWriter, r *http.Request) {
 	session.LastActivity = time.Now()
 	s.sessionMutex.Unlock()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		logging.Logger.Printf("读取请求体失败: %v", err)
-		debug.LogError("读取MCP请求体失败", err)
-		http.Error(w, "读取请求体失败", http.StatusBadRequest)
-		return
-	}
-
 	// 记录请求详情
-	debug.LogRequest("POST", r.URL.Path, map[string]string{
+	debug.LogRequest(ctx, "POST", r.URL.Path, map[string]string{
 		"Content-Type": r.Header.Get("Content-Type"),
 		"User-Agent":   r.Header.Get("User-Agent"),
 		"Session-ID":   sessionID,
 	}, body)
 
 	// 处理MCP请求
-	response, err := s.handleMCPRequest(body)
+	response, err := s.handleMCPRequest(ctx, sessionID, body)
 	if err != nil {
-		logging.Logger.Printf("处理MCP请求失败: %v", err)
-		debug.LogError("处理MCP请求失败", err)
-		http.Error(w, "处理请求失败", http.StatusInternalServerError)
+		logger.Printf("处理MCP请求失败: %v", err)
+		debug.LogError(ctx, "处理MCP请求失败", err)
+		http.Error(w, i18n.Tc(ctx, "http.request_failed"), http.StatusInternalServerError)
 		return
 	}
 
@@ -356,7 +761,7 @@ func (s *Server) handleMCPMessages(w http.ResponseWriter, r *http.Request) {
 	for key, values := range w.Header() {
 		responseHeaders[key] = values[0] // 取第一个值
 	}
-	debug.LogResponse(200, responseHeaders, response)
+	debug.LogResponse(ctx, 200, responseHeaders, response)
 
 	// 按照 MCP 规范，返回 "Accepted" 状态码
 	w.WriteHeader(http.StatusAccepted)
@@ -373,23 +778,32 @@ func (s *Server) pushMessageToSession(sessionID string, message []byte) {
 	s.sessionMutex.RUnlock()
 
 	if !exists {
-		logging.Logger.Printf("会话不存在: %s", sessionID)
+		s.dropMessages(sessionID, 1, "会话不存在")
 		return
 	}
 
+	s.sessionMutex.RLock()
+	clientID := session.ClientID
+	s.sessionMutex.RUnlock()
+
 	s.sseMutex.RLock()
-	conn, exists := s.sseConnections[session.ClientID]
+	conn, exists := s.sseConnections[clientID]
 	s.sseMutex.RUnlock()
 
+	// 启用会话恢复时缓存发往已断开会话的消息，客户端恢复连接后推送
 	if !exists {
-		logging.Logger.Printf("连接不存在: %s", session.ClientID)
+		s.queuePending(sessionID, message)
 		return
 	}
 
-	// 按照 MCP 规范发送消息
-	messageEvent := fmt.Sprintf("event: message\ndata: %s\n\n", string(message))
-	fmt.Fprint(conn.Writer, messageEvent)
-	conn.Flusher.Flush()
+	// 消息交给连接的处理协程按顺序写出，连接已关闭时留待重放，客户端读取过慢时关闭连接
+	if err := conn.outbox.push(message); err != nil {
+		if err == errOutboxFull {
+			s.retireConnection(conn, "消息", err)
+		}
+		s.queuePending(sessionID, message)
+		return
+	}
 
 	logging.Logger.Printf("向会话 %s 推送消息", sessionID)
 }
@@ -403,16 +817,26 @@ func (s *Server) removeSSEConnection(clientID string) {
 		conn.Cancel()
 		delete(s.sseConnections, clientID)
 		
-		// 同时清理会话
+		// 同时清理会话，启用会话恢复时保留会话等待客户端重新连接
+		var detached string
 		s.sessionMutex.Lock()
 		for sessionID, session := range s.sessions {
 			if session.ClientID == clientID {
+				s.deleteSharedSession(sessionID)
+				if s.resumeStore != nil {
+					s.detachSessionLocked(session)
+					detached = sessionID
+					break
+				}
 				delete(s.sessions, sessionID)
 				logging.Logger.Printf("会话已移除: %s", sessionID)
 				break
 			}
 		}
 		s.sessionMutex.Unlock()
+		if detached != "" {
+			s.persistSession(detached)
+		}
 		
 		logging.Logger.Printf("SSE连接已移除: %s", clientID)
 	}
@@ -422,10 +846,46 @@ func (s *Server) removeSSEConnection(clientID string) {
 func (s *Server) startStdioServer() error {
 	logging.Logger.Println("启动标准输入/输出服务器")
 
-	// 创建带缓冲的读取器和写入器
-	reader := bufio.NewReaderSize(os.Stdin, 64*1024)   // 64KB 缓冲区
-	writer := bufio.NewWriterSize(os.Stdout, 256*1024) // 256KB 缓冲区
-	defer writer.Flush()
+	mode, err := framing.ParseMode(s.config.Server.Framing)
+	if err != nil {
+		return err
+	}
+	logging.Logger.Printf("标准输入/输出分帧方式: %s", mode)
+
+	// 所有消息经由同一个写入协程逐条写出，避免并发写入时消息交错；
+	// 宿主进程停止读取时写入不会永远阻塞，超时后关闭服务器
+	writeTimeout := s.config.Server.WriteTimeout
+	if writeTimeout == 0 {
+		writeTimeout = defaultStdoutWriteTimeout
+	}
+	out := s.stdout
+	if previous, ok := out.(*stdoutWriter); ok {
+		out = previous.w
+	}
+	stdout := newStdoutWriter(out, writeTimeout, func() {
+		logging.Logger.Printf("写入标准输出超过 %v 仍未完成，宿主进程可能已停止读取，关闭服务器", writeTimeout)
+		s.cancel()
+	})
+	defer stdout.Close()
+	s.stdout = stdout
+
+	// 按请求顺序写出响应时，后完成的响应等待前面的请求
+	ordering, err := parseOrdering(s.config.Server.Ordering)
+	if err != nil {
+		return err
+	}
+	if ordering == orderingOrdered {
+		s.stdioOrder = newResponseSequencer(func(data []byte) error {
+			_, err := s.stdout.Write(data)
+			return err
+		})
+	}
+	logging.Logger.Printf("标准输入/输出响应顺序: %s", ordering)
+
+	// 创建带缓冲的读取器
+	reader := framing.NewReader(bufio.NewReaderSize(s.stdin, 64*1024), mode) // 64KB 缓冲区
+	reader.SetMaxSize(s.config.Global.MaxRequestBytes())
+	s.stdioReader = reader
 
 	// 创建请求通道，用于并发处理
 	requestChan := make(chan *requestTask, 100) // 缓冲通道
@@ -445,10 +905,8 @@ func (s *Server) startStdioServer() error {
 		}(i)
 	}
 
-	// 启动读取协程
-	wg.Add(1)
+	// 启动读取协程，读取阻塞时不等待其退出，嵌入时取消上下文后即可返回，协程在下一次读取返回后退出
 	go func() {
-		defer wg.Done()
 		defer close(requestChan) // 确保在读取协程退出时关闭通道
 		defer func() {
 			if r := recover(); r != nil {
@@ -472,7 +930,7 @@ func (s *Server) startStdioServer() error {
 			}
 
 			// 直接读取，不使用超时，让系统自然处理 EOF
-			line, err := reader.ReadString('\n')
+			message, err := reader.ReadMessage()
 
 			if err != nil {
 				if err == io.EOF {
@@ -480,21 +938,36 @@ func (s *Server) startStdioServer() error {
 					s.cancel()
 					return
 				}
+				if tooLarge, ok := err.(*framing.TooLargeError); ok {
+					logging.Logger.Printf("丢弃超过大小限制的请求: %v", tooLarge)
+					s.sendErrorResponse("", -32600, tooLarge.Error())
+					continue
+				}
+				if err == io.ErrUnexpectedEOF {
+					logging.Logger.Println("标准输入在消息中途关闭")
+					s.cancel()
+					return
+				}
 				logging.Logger.Printf("从标准输入读取失败: %v", err)
 				// 发送错误响应
-				s.sendErrorResponse(writer, "", -32700, fmt.Sprintf("读取输入失败: %v", err))
+				s.sendErrorResponse("", -32700, i18n.Tc(s.ctx, "mcp.read_input_failed", err))
 				continue
 			}
 
-			// 去除换行符和空白字符
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue // 跳过空行
+			// 客户端对服务器请求 (如 elicitation/create) 的响应直接交给等待中的请求，不进入工作协程池；
+			// 否则所有工作协程都在等待客户端响应时，这些响应排在它们之后，调用只能等到超时
+			if isClientResponse(message) {
+				s.handleClientResponse("", message)
+				continue
 			}
 
 			// 创建请求任务
 			task := &requestTask{
-				data: []byte(line),
+				data:    message,
+				framing: reader.Detected(),
+			}
+			if s.stdioOrder != nil {
+				task.seq = s.stdioOrder.assign()
 			}
 
 			// 发送到工作协程池
@@ -520,6 +993,7 @@ func (s *Server) startStdioServer() error {
 	logging.Logger.Println("等待所有协程退出...")
 	wg.Wait()
 	logging.Logger.Println("所有协程已退出")
+	s.saveStats()
 
 	// 安全关闭 done 通道
 	select {
@@ -535,7 +1009,9 @@ func (s *Server) startStdioServer() error {
 
 // requestTask 请求任务
 type requestTask struct {
-	data []byte
+	data    []byte
+	framing framing.Mode // 响应使用与请求相同的分帧方式
+	seq     uint64       // 按请求顺序写出响应时的序号
 }
 
 // stdioWorker 标准输入/输出工作协程
@@ -555,20 +1031,30 @@ func (s *Server) stdioWorker(requestChan <-chan *requestTask) {
 
 // processRequest 处理单个请求
 func (s *Server) processRequest(task *requestTask) {
+	ctx, runAfterResponse := withAfterResponse(logging.WithRequestID(s.ctx, logging.NewRequestID()))
+	defer runAfterResponse()
+	logger := logging.FromContext(ctx)
+
 	// 记录请求详情
-	debug.LogRequest("STDIO", "stdin", map[string]string{
+	debug.LogRequest(ctx, "STDIO", "stdin", map[string]string{
 		"Content-Type": "application/json",
 	}, task.data)
 
-	// 解析MCP请求以获取详细信息
-	var mcpRequest mcp.MCPRequest
-	if err := json.Unmarshal(task.data, &mcpRequest); err == nil {
-		debug.LogMCPRequest(fmt.Sprintf("%v", mcpRequest.ID), mcpRequest.Method, mcpRequest.Params)
+	// 只解析一次，后续处理直接使用解析结果
+	request, errResp := parseMCPRequest(ctx, task.data)
+	if request == nil {
+		if err := s.writeStdioResponse(task, errResp); err != nil {
+			logger.Printf("写入 stdout 失败: %v", err)
+		}
+		return
+	}
+	if debug.IsDebugEnabled {
+		debug.LogMCPRequest(ctx, string(request.ID), request.Method, request.Params)
 	}
 
 	// 设置请求超时
-	logging.Logger.Printf("处理请求，超时配置: %v", s.config.Global.Timeout)
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.Global.Timeout)
+	logger.Printf("处理请求，超时配置: %v", s.config.Global.Timeout)
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), s.config.Global.Timeout)
 	defer cancel()
 
 	// 使用通道进行超时控制，减少协程使用
@@ -581,91 +1067,99 @@ func (s *Server) processRequest(task *requestTask) {
 
 	// 启动处理协程
 	go func() {
-		response, err := s.handleMCPRequest(task.data)
+		response, err := s.dispatchMCPRequest(ctx, "", request, task.data)
 		resultChan <- result{response: response, err: err}
 	}()
 
 	// 等待处理完成或超时
-	logging.Logger.Printf("等待请求处理完成...")
+	logger.Printf("等待请求处理完成...")
 	select {
-	case <-ctx.Done():
-		logging.Logger.Printf("请求处理超时，超时时间: %v", s.config.Global.Timeout)
-		// 直接使用 os.Stdout
-		errResp := mcp.NewErrorResponse("", -32001, "Request timed out")
-		if response, err := json.Marshal(errResp); err == nil {
-			os.Stdout.Write(response)
-			os.Stdout.Write([]byte("\n"))
-		}
+	case <-timeoutCtx.Done():
+		logger.Printf("请求处理超时，超时时间: %v", s.config.Global.Timeout)
+		// 直接写入标准输出
+		errResp := newErrorResponse(ctx, "", -32001, i18n.Tc(ctx, "mcp.request_timeout"))
+		response, _ := json.Marshal(errResp)
+		s.writeStdioResponse(task, response)
 	case res := <-resultChan:
-		logging.Logger.Printf("请求处理完成")
+		logger.Printf("请求处理完成")
 		if res.err != nil {
-			logging.Logger.Printf("处理MCP请求失败: %v", res.err)
-			debug.LogError("处理MCP请求失败", res.err)
-			// 直接使用 os.Stdout
-			errResp := mcp.NewErrorResponse("", -32603, fmt.Sprintf("处理请求失败: %v", res.err))
-			if response, err := json.Marshal(errResp); err == nil {
-				os.Stdout.Write(response)
-				os.Stdout.Write([]byte("\n"))
-			}
+			logger.Printf("处理MCP请求失败: %v", res.err)
+			debug.LogError(ctx, "处理MCP请求失败", res.err)
+			// 直接写入标准输出
+			errResp := newErrorResponse(ctx, "", -32603, i18n.Tc(ctx, "mcp.request_failed", res.err))
+			response, _ := json.Marshal(errResp)
+			s.writeStdioResponse(task, response)
 			return
 		}
 
 		// 检查响应是否为空（通知类型的请求）
 		if res.response == nil {
-			logging.Logger.Printf("通知类型请求，无需发送响应")
+			logger.Printf("通知类型请求，无需发送响应")
+			s.writeStdioResponse(task, nil)
 			return
 		}
 
 		// 记录响应详情
-		debug.LogResponse(200, map[string]string{
+		debug.LogResponse(ctx, 200, map[string]string{
 			"Content-Type": "application/json",
 		}, res.response)
 
-		// 直接使用 os.Stdout，并检查写入错误
-		logging.Logger.Printf("发送响应: %s", string(res.response))
-		if _, err := os.Stdout.Write(res.response); err != nil {
-			logging.Logger.Printf("写入 stdout 失败: %v，Client 可能已断开连接")
-			debug.LogError("写入stdout失败", err)
-			s.cancel() // 触发关闭流程
-			return
-		}
-		if _, err := os.Stdout.Write([]byte("\n")); err != nil {
-			logging.Logger.Printf("写入换行符失败: %v，Client 可能已断开连接")
-			debug.LogError("写入换行符失败", err)
+		// 直接写入标准输出，并检查写入错误
+		logger.Printf("发送响应: %s", res.response)
+		if err := s.writeStdioResponse(task, res.response); err != nil {
+			logger.Printf("写入 stdout 失败: %v，Client 可能已断开连接", err)
+			debug.LogError(ctx, "写入stdout失败", err)
 			s.cancel() // 触发关闭流程
 			return
 		}
-		logging.Logger.Printf("响应发送完成")
+		logger.Printf("响应发送完成")
 	}
 }
 
-// writeResponse 写入响应到标准输出
-func (s *Server) writeResponse(writer *bufio.Writer, response []byte) error {
-	// 写入响应数据
-	if _, err := writer.Write(response); err != nil {
-		return fmt.Errorf("写入响应数据失败: %w", err)
+// frameStdio 按当前分帧方式为标准输出消息分帧
+func (s *Server) frameStdio(message []byte) []byte {
+	mode := framing.NDJSON
+	if s.stdioReader != nil {
+		mode = s.stdioReader.Detected()
 	}
+	return framing.Encode(mode, message)
+}
 
-	// 立即刷新，确保数据被写入
-	if err := writer.Flush(); err != nil {
-		return fmt.Errorf("刷新缓冲区失败: %w", err)
+// writeStdioResponse 按请求的分帧方式写出响应，response 为 nil 表示没有响应
+// 按请求顺序写出时每个任务必须调用一次，没有响应也要调用，以释放后面的响应
+func (s *Server) writeStdioResponse(task *requestTask, response []byte) error {
+	var data []byte
+	if response != nil {
+		data = framing.Encode(task.framing, response)
 	}
-
-	// 写入换行符
-	if err := writer.WriteByte('\n'); err != nil {
-		return fmt.Errorf("写入换行符失败: %w", err)
+	if s.stdioOrder != nil {
+		return s.stdioOrder.done(task.seq, data)
 	}
-
-	// 再次刷新，确保换行符被写入
-	if err := writer.Flush(); err != nil {
-		return fmt.Errorf("刷新缓冲区失败: %w", err)
+	if data == nil {
+		return nil
 	}
+	_, err := s.stdout.Write(data)
+	return err
+}
 
+// writeResponse 写入响应到标准输出，按请求顺序写出时占用下一个序号
+func (s *Server) writeResponse(response []byte) error {
+	// 分帧后一次写入，避免消息被拆开
+	data := s.frameStdio(response)
+	var err error
+	if s.stdioOrder != nil {
+		err = s.stdioOrder.done(s.stdioOrder.assign(), data)
+	} else {
+		_, err = s.stdout.Write(data)
+	}
+	if err != nil {
+		return fmt.Errorf("写入响应数据失败: %w", err)
+	}
 	return nil
 }
 
 // sendErrorResponse 发送错误响应
-func (s *Server) sendErrorResponse(writer *bufio.Writer, id string, code int, message string) {
+func (s *Server) sendErrorResponse(id string, code int, message string) {
 	errResp := mcp.NewErrorResponse(id, code, message)
 	response, err := json.Marshal(errResp)
 	if err != nil {
@@ -673,83 +1167,140 @@ func (s *Server) sendErrorResponse(writer *bufio.Writer, id string, code int, me
 		return
 	}
 
-	if err := s.writeResponse(writer, response); err != nil {
+	if err := s.writeResponse(response); err != nil {
 		logging.Logger.Printf("发送错误响应失败: %v", err)
 	}
 }
 
-// handleMCPRequest 处理MCP请求
-func (s *Server) handleMCPRequest(data []byte) ([]byte, error) {
-	// 解析请求
+// newErrorResponse 创建错误响应，并在 error.data 中附带关联ID，便于按ID追踪失败的调用
+func newErrorResponse(ctx context.Context, id interface{}, code int, message string) *mcp.MCPResponse {
+	response := mcp.NewErrorResponse(id, code, message)
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		response.Error.Data = map[string]interface{}{"requestId": requestID}
+	}
+	return response
+}
+
+// handleMCPRequest 处理MCP请求，sessionID 为空表示标准输入/输出会话
+func (s *Server) handleMCPRequest(ctx context.Context, sessionID string, data []byte) ([]byte, error) {
+	request, errResp := parseMCPRequest(ctx, data)
+	if request == nil {
+		return errResp, nil
+	}
+	return s.dispatchMCPRequest(ctx, sessionID, request, data)
+}
+
+// parseMCPRequest 解析MCP请求，失败时返回可直接发送的错误响应
+func parseMCPRequest(ctx context.Context, data []byte) (*mcp.MCPRequest, []byte) {
+	logger := logging.FromContext(ctx)
 	var request mcp.MCPRequest
 	if err := json.Unmarshal(data, &request); err != nil {
-		logging.Logger.Printf("解析MCP请求失败: %v, 数据: %s", err, string(data))
-		errResp := mcp.NewErrorResponse("", -32700, "解析请求失败")
-		return json.Marshal(errResp)
+		logger.Printf("解析MCP请求失败: %v, 数据: %s", err, string(data))
+		errResp, _ := json.Marshal(newErrorResponse(ctx, "", -32700, i18n.Tc(ctx, "mcp.parse_error")))
+		return nil, errResp
+	}
+	return &request, nil
+}
+
+// dispatchMCPRequest 按方法分发已解析的请求，data 为原始消息，用于转交客户端响应
+func (s *Server) dispatchMCPRequest(ctx context.Context, sessionID string, request *mcp.MCPRequest, data []byte) (response []byte, err error) {
+	logger := logging.FromContext(ctx)
+	// 处理中的 panic 只让这一个请求失败，返回 -32603 错误，工作协程和其他请求不受影响
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Printf("处理MCP请求 %s 时发生panic: %v\n%s", request.Method, r, runtimedebug.Stack())
+			errResp := newErrorResponse(ctx, request.GetIDString(), -32603, i18n.Tc(ctx, "mcp.internal_error", "panic"))
+			response, err = json.Marshal(errResp)
+		}
+	}()
+	// 没有方法名但带有ID的消息是客户端对服务器请求的响应
+	if request.Method == "" && request.ID != nil {
+		s.handleClientResponse(sessionID, data)
+		return nil, nil
 	}
 
 	// 记录请求信息
-	logging.Logger.Printf("收到MCP请求: ID=%s, Method=%s", request.GetIDString(), request.Method)
+	logger.Printf("收到MCP请求: ID=%s, Method=%s", request.GetIDString(), request.Method)
 
 	// 验证请求格式
 	if request.JSONRPC != "2.0" {
-		logging.Logger.Printf("不支持的JSON-RPC版本: %s", request.JSONRPC)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32600, "不支持的JSON-RPC版本")
+		logger.Printf("不支持的JSON-RPC版本: %s", request.JSONRPC)
+		errResp := newErrorResponse(ctx, request.GetIDString(), -32600, i18n.Tc(ctx, "mcp.unsupported_jsonrpc"))
 		return json.Marshal(errResp)
 	}
 
 	// 处理不同的方法
 	switch request.Method {
 	case "initialize":
-		return s.handleInitialize(request)
+		return s.handleInitialize(ctx, sessionID, *request)
 	case "notifications/initialized":
-		return s.handleInitialized(request)
+		return s.handleInitialized(ctx, *request)
 	case "notifications/cancelled":
-		return s.handleCancelled(request)
+		return s.handleCancelled(ctx, *request)
 	case "tools/list":
-		return s.handleToolsList(request)
-	case "toolCall", "tools/call":
-		return s.handleToolCall(request)
+		return s.handleToolsList(ctx, sessionID, *request)
+	case "resources/list":
+		return s.handleResourcesList(ctx, *request)
+	case "resources/read":
+		return s.handleResourcesRead(ctx, sessionID, *request)
+	case "resources/subscribe", "resources/unsubscribe":
+		return s.handleResourcesSubscribe(ctx, sessionID, *request)
+	case "tools/call":
+		return s.handleToolCall(ctx, sessionID, *request)
+	case "toolCall":
+		// 旧的方法名只在 2024-11-05 及未初始化的会话中接受
+		if mcp.ProtocolAtLeast(s.ProtocolVersion(sessionID), mcp.ProtocolVersion20250326) {
+			logger.Printf("协议版本 %s 不支持方法 toolCall", s.ProtocolVersion(sessionID))
+			errResp := newErrorResponse(ctx, request.GetIDString(), -32601, i18n.Tc(ctx, "mcp.method_not_found"))
+			return json.Marshal(errResp)
+		}
+		return s.handleToolCall(ctx, sessionID, *request)
 	case "exit":
-		return s.handleExit(request)
+		return s.handleExit(ctx, *request)
 	default:
-		logging.Logger.Printf("不支持的方法: %s", request.Method)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32601, "不支持的方法")
+		logger.Printf("不支持的方法: %s", request.Method)
+		errResp := newErrorResponse(ctx, request.GetIDString(), -32601, i18n.Tc(ctx, "mcp.method_not_found"))
 		return json.Marshal(errResp)
 	}
 }
 
 // handleInitialize 处理初始化请求
-func (s *Server) handleInitialize(request mcp.MCPRequest) ([]byte, error) {
-	logging.Logger.Printf("处理初始化请求")
+func (s *Server) handleInitialize(ctx context.Context, sessionID string, request mcp.MCPRequest) ([]byte, error) {
+	logger := logging.FromContext(ctx)
+	logger.Printf("处理初始化请求")
 
 	// 解析初始化参数
 	var initParams struct {
 		ProtocolVersion string `json:"protocolVersion"`
-		Capabilities    struct {
-			Tools          map[string]interface{} `json:"tools"`
-			Resources      map[string]interface{} `json:"resources"`
-			Logging        map[string]interface{} `json:"logging"`
-			StreamableHttp map[string]interface{} `json:"streamableHttp"`
-		} `json:"capabilities"`
-		ClientInfo struct {
+		ClientInfo      struct {
 			Name    string `json:"name"`
 			Version string `json:"version"`
 		} `json:"clientInfo"`
 	}
 
 	if err := json.Unmarshal(request.Params, &initParams); err != nil {
-		logging.Logger.Printf("解析初始化参数失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32602, "无效的初始化参数")
+		logger.Printf("解析初始化参数失败: %v", err)
+		errResp := newErrorResponse(ctx, request.GetIDString(), -32602, i18n.Tc(ctx, "mcp.invalid_initialize_params"))
 		return json.Marshal(errResp)
 	}
 
-	logging.Logger.Printf("客户端信息: %s v%s", initParams.ClientInfo.Name, initParams.ClientInfo.Version)
-	logging.Logger.Printf("协议版本: %s", initParams.ProtocolVersion)
+	logger.Printf("客户端信息: %s v%s", initParams.ClientInfo.Name, initParams.ClientInfo.Version)
+	logger.Printf("协议版本: %s", initParams.ProtocolVersion)
+
+	// 选择双方都支持的最高协议版本，之后的消息格式按该版本调整
+	protocolVersion := mcp.NegotiateProtocolVersion(initParams.ProtocolVersion)
+	if protocolVersion != initParams.ProtocolVersion {
+		logger.Printf("不支持客户端请求的协议版本 %s，使用 %s", initParams.ProtocolVersion, protocolVersion)
+	}
+
+	// 记录客户端声明的能力，之后只向客户端发送其支持的请求和通知
+	capabilities := mcp.ParseClientCapabilities(request.Params)
+	s.setClientCapabilities(sessionID, protocolVersion, capabilities)
+	logger.Printf("客户端能力: %v", capabilities.Names())
 
 	// 构建初始化响应
 	initResult := map[string]interface{}{
-		"protocolVersion": "2024-11-05",
+		"protocolVersion": protocolVersion,
 		"capabilities": map[string]interface{}{
 			"tools": map[string]interface{}{
 				"listChanged": true,
@@ -757,6 +1308,7 @@ func (s *Server) handleInitialize(request mcp.MCPRequest) ([]byte, error) {
 			"resources": map[string]interface{}{
 				"subscribe":   true,
 				"unsubscribe": true,
+				"listChanged": true,
 			},
 			"logging": map[string]interface{}{
 				"logMessage": true,
@@ -764,117 +1316,136 @@ func (s *Server) handleInitialize(request mcp.MCPRequest) ([]byte, error) {
 			"streamableHttp": map[string]interface{}{
 				"request": true,
 			},
+			// 告知客户端单条消息的大小上限，超过时请求会被拒绝
+			"experimental": map[string]interface{}{
+				"mcp2rest": map[string]interface{}{
+					"maxRequestSize": s.config.Global.MaxRequestBytes(),
+				},
+			},
 		},
 		"serverInfo": map[string]interface{}{
 			"name":    getServerName(s.config.Server.Mode),
-			"version": "1.0.0",
+			"version": version.Version,
 		},
 	}
 
 	response, err := mcp.NewSuccessResponse(request.GetIDString(), initResult)
 	if err != nil {
-		logging.Logger.Printf("创建初始化响应失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32603, "创建响应失败")
+		logger.Printf("创建初始化响应失败: %v", err)
+		errResp := newErrorResponse(ctx, request.GetIDString(), -32603, i18n.Tc(ctx, "mcp.create_response_failed"))
 		return json.Marshal(errResp)
 	}
 
 	responseBytes, err := json.Marshal(response)
 	if err != nil {
-		logging.Logger.Printf("序列化初始化响应失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32603, "序列化响应失败")
+		logger.Printf("序列化初始化响应失败: %v", err)
+		errResp := newErrorResponse(ctx, request.GetIDString(), -32603, i18n.Tc(ctx, "mcp.marshal_response_failed"))
 		return json.Marshal(errResp)
 	}
 
-	logging.Logger.Printf("初始化响应发送成功")
+	logger.Printf("初始化响应发送成功")
 	return responseBytes, nil
 }
 
 // handleInitialized 处理初始化完成通知
-func (s *Server) handleInitialized(request mcp.MCPRequest) ([]byte, error) {
-	logging.Logger.Printf("处理初始化完成通知")
+func (s *Server) handleInitialized(ctx context.Context, request mcp.MCPRequest) ([]byte, error) {
+	logger := logging.FromContext(ctx)
+	logger.Printf("处理初始化完成通知")
 
 	// 对于通知类型的请求，不需要返回响应
 	return nil, nil
 }
 
 // handleCancelled 处理取消通知
-func (s *Server) handleCancelled(request mcp.MCPRequest) ([]byte, error) {
-	logging.Logger.Printf("处理取消通知")
+func (s *Server) handleCancelled(ctx context.Context, request mcp.MCPRequest) ([]byte, error) {
+	logger := logging.FromContext(ctx)
+	logger.Printf("处理取消通知")
 
 	// 对于通知类型的请求，不需要返回响应
 	return nil, nil
 }
 
 // handleExit 处理退出请求
-func (s *Server) handleExit(request mcp.MCPRequest) ([]byte, error) {
-	logging.Logger.Printf("收到退出请求，准备关闭服务器")
+// 响应写出后再等待正在执行的工具调用完成 (最长 global.exit.drain_timeout)，然后停止服务器并退出进程
+func (s *Server) handleExit(ctx context.Context, request mcp.MCPRequest) ([]byte, error) {
+	logger := logging.FromContext(ctx)
+	if !s.exitEnabled() {
+		logger.Printf("exit 方法已禁用，忽略退出请求")
+		errResp := newErrorResponse(ctx, request.GetIDString(), -32601, i18n.Tc(ctx, "mcp.exit_disabled"))
+		return json.Marshal(errResp)
+	}
+	logger.Printf("收到退出请求，准备关闭服务器")
 
 	// 发送退出响应
 	response, err := mcp.NewSuccessResponse(request.GetIDString(), nil)
 	if err != nil {
-		logging.Logger.Printf("创建退出响应失败: %v", err)
+		logger.Printf("创建退出响应失败: %v", err)
 		return nil, err
 	}
 
 	responseBytes, err := json.Marshal(response)
 	if err != nil {
-		logging.Logger.Printf("序列化退出响应失败: %v", err)
+		logger.Printf("序列化退出响应失败: %v", err)
 		return nil, err
 	}
 
-	// 立即关闭服务器
-	logging.Logger.Printf("执行退出操作")
-	go func() {
-		// 给响应发送一点时间
-		time.Sleep(50 * time.Millisecond)
-		s.Stop()
-		// 强制退出进程
-		os.Exit(0)
-	}()
+	if !s.exiting.CompareAndSwap(false, true) {
+		logger.Printf("服务器已在关闭中")
+		return responseBytes, nil
+	}
+	logger.Printf("执行退出操作，正在执行的工具调用: %d", s.activeCalls.Load())
+	afterResponse(ctx, func() {
+		go s.shutdownAfterExit()
+	})
 
 	return responseBytes, nil
 }
 
 // handleToolsList 处理工具列表请求
-func (s *Server) handleToolsList(request mcp.MCPRequest) ([]byte, error) {
-	logging.Logger.Printf("处理工具列表请求")
+func (s *Server) handleToolsList(ctx context.Context, sessionID string, request mcp.MCPRequest) ([]byte, error) {
+	logger := logging.FromContext(ctx)
+	logger.Printf("处理工具列表请求")
 
-	// 获取所有可用的工具名称
-	tools := s.handler.GetAvailableTools()
+	// 获取客户端可以使用的工具
+	ctx = withAccess(ctx, s.accessClient(sessionID))
+	tools := adaptTools(s.ProtocolVersion(sessionID), s.handler.AllowedTools(ctx))
 
 	// 构建工具列表响应
 	toolsListResult := map[string]interface{}{
 		"tools": tools,
 	}
 
-	response, err := mcp.NewSuccessResponse(request.GetIDString(), toolsListResult)
+	responseBytes, err := mcp.MarshalSuccess(request.GetIDString(), toolsListResult)
 	if err != nil {
-		logging.Logger.Printf("创建工具列表响应失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32603, "创建响应失败")
+		logger.Printf("序列化工具列表响应失败: %v", err)
+		errResp := newErrorResponse(ctx, request.GetIDString(), -32603, i18n.Tc(ctx, "mcp.marshal_response_failed"))
 		return json.Marshal(errResp)
 	}
 
-	responseBytes, err := json.Marshal(response)
-	if err != nil {
-		logging.Logger.Printf("序列化工具列表响应失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32603, "序列化响应失败")
-		return json.Marshal(errResp)
-	}
-
-	logging.Logger.Printf("工具列表响应发送成功，包含 %d 个工具", len(tools))
+	logger.Printf("工具列表响应发送成功，包含 %d 个工具", len(tools))
 	return responseBytes, nil
 }
 
 // handleToolCall 处理工具调用请求
-func (s *Server) handleToolCall(request mcp.MCPRequest) ([]byte, error) {
+func (s *Server) handleToolCall(ctx context.Context, sessionID string, request mcp.MCPRequest) ([]byte, error) {
+	logger := logging.FromContext(ctx)
 	// 记录请求开始时间
 	startTime := time.Now()
+	id := request.GetIDString()
+
+	// 先计数再检查，保证 exit 之后开始的调用都会被拒绝，之前开始的调用都会被等待
+	s.activeCalls.Add(1)
+	defer s.activeCalls.Add(-1)
+	if s.exiting.Load() {
+		errResp := newErrorResponse(ctx, id, -32000, i18n.Tc(ctx, "mcp.shutting_down"))
+		return json.Marshal(errResp)
+	}
 
 	// 解析工具调用参数
 	toolParams, err := mcp.ParseToolCallParams(request.Params)
 	if err != nil {
-		logging.Logger.Printf("解析工具调用参数失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32602, fmt.Sprintf("无效的参数: %v", err))
+		logger.Printf("解析工具调用参数失败: %v", err)
+		errResp := newErrorResponse(ctx, id, -32602, i18n.Tc(ctx, "mcp.invalid_params", err))
 		return json.Marshal(errResp)
 	}
 
@@ -882,23 +1453,45 @@ func (s *Server) handleToolCall(request mcp.MCPRequest) ([]byte, error) {
 	originalName := toolParams.Name
 	if strings.HasPrefix(toolParams.Name, "mcp_") {
 		toolParams.Name = strings.TrimPrefix(toolParams.Name, "mcp_")
-		logging.Logger.Printf("检测到 mcp_ 前缀，将工具名称从 %s 改为 %s", originalName, toolParams.Name)
+		logger.Printf("检测到 mcp_ 前缀，将工具名称从 %s 改为 %s", originalName, toolParams.Name)
 	}
 
-	// 记录工具调用信息
-	logging.Logger.Printf("工具调用: %s (原始名称: %s), 参数: %+v", toolParams.Name, originalName, toolParams.Parameters)
-
-	// 处理请求
-	result, err := s.handler.HandleRequest(toolParams)
+	// 处理请求，工具权限和调用频率由内置中间件按上下文中的客户端检查
+	client := s.accessClient(sessionID)
+	ctx = withAccess(ctx, client)
+	ctx = handler.WithClient(ctx, &sessionClient{server: s, sessionID: sessionID})
+	ctx = handler.WithQuotaSubject(ctx, quota.Subject{Session: sessionID, Client: clientName(client)})
+	ctx = handler.WithCaller(ctx, handler.Caller{Session: sessionID, Client: clientName(client)})
+	result, err := s.handler.HandleRequest(ctx, toolParams)
+	var exceeded *quota.ExceededError
+	var forbidden *ToolForbiddenError
+	var limited *RateLimitedError
+	var invalid *handler.ValidationError
+	switch {
+	case errors.As(err, &exceeded):
+		logger.Printf("调用超过配额: %v", exceeded)
+		return json.Marshal(quotaExceededResponse(ctx, id, exceeded))
+	case errors.As(err, &forbidden):
+		errResp := newErrorResponse(ctx, id, -32000, i18n.Tc(ctx, "mcp.tool_forbidden", forbidden.Tool))
+		return json.Marshal(errResp)
+	case errors.As(err, &limited):
+		seconds := int((limited.RetryAfter + time.Second - 1) / time.Second)
+		errResp := newErrorResponse(ctx, id, -32000, i18n.Tc(ctx, "mcp.rate_limited", seconds))
+		return json.Marshal(errResp)
+	case errors.As(err, &invalid):
+		logger.Printf("工具 %s 的参数不符合规范: %v", toolParams.Name, invalid)
+		return json.Marshal(invalidArgumentsResponse(ctx, id, toolParams.Name, invalid))
+	}
 	if err != nil {
-		logging.Logger.Printf("处理工具调用失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32603, fmt.Sprintf("内部错误: %v", err))
+		logger.Printf("处理工具调用失败: %v", err)
+		errResp := newErrorResponse(ctx, id, -32603, i18n.Tc(ctx, "mcp.internal_error", err))
 		return json.Marshal(errResp)
 	}
 
 	// 按照 MCP 规范构建工具调用响应
 	// 工具调用响应应该包含 content 数组字段
 	var toolCallResponse map[string]interface{}
+	truncated := false
 	
 	if result.Type == "error" {
 		// 错误响应
@@ -906,7 +1499,7 @@ func (s *Server) handleToolCall(request mcp.MCPRequest) ([]byte, error) {
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": fmt.Sprintf("错误: %v", result.Result),
+					"text": i18n.Tc(ctx, "mcp.tool_error", result.Result),
 				},
 			},
 			"isError": true,
@@ -915,44 +1508,56 @@ func (s *Server) handleToolCall(request mcp.MCPRequest) ([]byte, error) {
 		// 成功响应
 		// 将结果转换为文本格式
 		resultText := ""
-		if result.Result != nil {
+		if text, ok := result.Result.(string); ok {
+			// 纯文本结果直接返回，不再编码为 JSON 字符串
+			resultText = text
+		} else if result.Result != nil {
 			if resultBytes, err := json.MarshalIndent(result.Result, "", "  "); err == nil {
 				resultText = string(resultBytes)
 			} else {
 				resultText = fmt.Sprintf("%v", result.Result)
 			}
 		}
-		
-		toolCallResponse = map[string]interface{}{
-			"content": []map[string]interface{}{
+		// 过长的结果先尝试摘要，未启用或摘要失败时再截断
+		content := s.summarizeResult(ctx, sessionID, toolParams.Name, resultText, result)
+		if content != nil {
+			// 摘要后的结果与截断的结果一样不提供 structuredContent
+			truncated = true
+		} else {
+			resultText, truncated = s.truncateResult(ctx, resultText, result)
+			content = []map[string]interface{}{
 				{
 					"type": "text",
 					"text": resultText,
 				},
-			},
+			}
+		}
+		
+		toolCallResponse = map[string]interface{}{
+			"content": content,
 			"isError": false,
 		}
 	}
-
-	// 创建成功响应
-	response, err := mcp.NewSuccessResponse(request.GetIDString(), toolCallResponse)
-	if err != nil {
-		logging.Logger.Printf("创建成功响应失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32603, fmt.Sprintf("创建响应失败: %v", err))
-		return json.Marshal(errResp)
+	// 2025-06-18 起对象形式的结果同时放在 structuredContent 中
+	if structured, ok := result.Result.(map[string]interface{}); ok && result.Type != "error" && !truncated &&
+		mcp.ProtocolAtLeast(s.ProtocolVersion(sessionID), mcp.ProtocolVersion20250618) {
+		toolCallResponse["structuredContent"] = structured
+	}
+	if len(result.Meta) > 0 {
+		toolCallResponse["_meta"] = result.Meta
 	}
 
-	// 序列化响应
-	responseBytes, err := json.Marshal(response)
+	// 创建并序列化成功响应，结果只编码一次
+	responseBytes, err := mcp.MarshalSuccess(id, toolCallResponse)
 	if err != nil {
-		logging.Logger.Printf("序列化响应失败: %v", err)
-		errResp := mcp.NewErrorResponse(request.GetIDString(), -32603, fmt.Sprintf("序列化响应失败: %v", err))
+		logger.Printf("序列化响应失败: %v", err)
+		errResp := newErrorResponse(ctx, id, -32603, i18n.Tc(ctx, "mcp.marshal_response_failed_detail", err))
 		return json.Marshal(errResp)
 	}
 
 	// 记录处理时间
 	duration := time.Since(startTime)
-	logging.Logger.Printf("工具调用处理完成: ID=%s, 耗时=%v", request.GetIDString(), duration)
+	logger.Printf("工具调用处理完成: ID=%s, 耗时=%v", id, duration)
 
 	return responseBytes, nil
 }
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"time"
+
+	"github.com/mcp2rest/internal/debug"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+	sessionpkg "github.com/mcp2rest/internal/session"
+)
+
+// saveSharedSession 把本实例持有的会话写入共享存储，同时刷新过期时间
+func (s *Server) saveSharedSession(session *MCPSession) {
+	if s.sessionStore == nil {
+		return
+	}
+
+	record := &sessionpkg.Record{
+		ID:         session.ID,
+		InstanceID: s.instanceID,
+		Endpoint:   session.Endpoint,
+		CreatedAt:  session.CreatedAt,
+	}
+	if err := s.sessionStore.Save(s.ctx, record); err != nil {
+		logging.Logger.Printf("保存共享会话 %s 失败: %v", session.ID, err)
+	}
+}
+
+// deleteSharedSession 从共享存储删除会话
+func (s *Server) deleteSharedSession(sessionID string) {
+	if s.sessionStore == nil {
+		return
+	}
+	if err := s.sessionStore.Delete(s.ctx, sessionID); err != nil {
+		logging.Logger.Printf("删除共享会话 %s 失败: %v", sessionID, err)
+	}
+}
+
+// sharedSessionOwner 返回持有该会话的其他实例ID，会话不存在或归属本实例时返回空字符串
+func (s *Server) sharedSessionOwner(sessionID string) string {
+	if s.sessionStore == nil {
+		return ""
+	}
+
+	record, err := s.sessionStore.Get(s.ctx, sessionID)
+	if err != nil {
+		logging.Logger.Printf("查询共享会话 %s 失败: %v", sessionID, err)
+		return ""
+	}
+	if record == nil || record.InstanceID == s.instanceID {
+		return ""
+	}
+	return record.InstanceID
+}
+
+// handleForwardedMessage 处理其他实例转发来的客户端消息
+// 处理过程可能等待客户端的后续消息 (如 elicitation 响应)，因此不能阻塞订阅循环
+func (s *Server) handleForwardedMessage(message *sessionpkg.Message) {
+	s.sessionMutex.Lock()
+	session, exists := s.sessions[message.SessionID]
+	client := ""
+	if exists {
+		session.LastActivity = time.Now()
+		client = session.Client
+	}
+	s.sessionMutex.Unlock()
+
+	if !exists {
+		logging.Logger.Printf("转发消息的会话不在本实例: %s", message.SessionID)
+		return
+	}
+	if s.access != nil && message.Client != client {
+		logging.Logger.Printf("转发消息的客户端 %s 无权使用会话 %s", message.Client, message.SessionID)
+		return
+	}
+
+	requestID := message.RequestID
+	if !logging.ValidRequestID(requestID) {
+		requestID = logging.NewRequestID()
+	}
+	ctx, runAfterResponse := withAfterResponse(i18n.WithLocale(logging.WithRequestID(s.ctx, requestID), message.Locale))
+
+	go func() {
+		defer runAfterResponse()
+		response, err := s.handleMCPRequest(ctx, message.SessionID, message.Body)
+		if err != nil {
+			logging.FromContext(ctx).Printf("处理转发的MCP请求失败: %v", err)
+			debug.LogError(ctx, "处理转发的MCP请求失败", err)
+			return
+		}
+		if response != nil {
+			s.pushMessageToSession(message.SessionID, response)
+		}
+	}()
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"sync/atomic"
+
+	"github.com/mcp2rest/internal/logging"
+)
+
+// sseMetrics SSE 推送的计数，通过 /admin/stats 的 sse 字段查看
+type sseMetrics struct {
+	// writeFailures 写入或刷新失败的次数，包括心跳、消息端点和消息
+	writeFailures atomic.Int64
+	// pruned 因写入失败关闭的连接数
+	pruned atomic.Int64
+	// buffered 因连接断开缓存、等待客户端恢复会话后重放的消息数
+	buffered atomic.Int64
+	// replayed 恢复会话后重放的消息数
+	replayed atomic.Int64
+	// dropped 无法送达而丢弃的消息数：会话不存在、未启用会话恢复、缓存超过上限或会话过期
+	dropped atomic.Int64
+}
+
+// sseStats 返回 SSE 推送的统计信息
+func (s *Server) sseStats() map[string]interface{} {
+	s.sseMutex.RLock()
+	connections := len(s.sseConnections)
+	s.sseMutex.RUnlock()
+
+	return map[string]interface{}{
+		"connections":    connections,
+		"write_failures": s.sseMetrics.writeFailures.Load(),
+		"pruned":         s.sseMetrics.pruned.Load(),
+		"buffered":       s.sseMetrics.buffered.Load(),
+		"replayed":       s.sseMetrics.replayed.Load(),
+		"dropped":        s.sseMetrics.dropped.Load(),
+	}
+}
+
+// retireConnection 在写入失败后关闭失效的连接，启用会话恢复时会话保留等待客户端重新连接
+func (s *Server) retireConnection(conn *SSEConnection, what string, err error) {
+	s.sseMetrics.writeFailures.Add(1)
+	s.sseMetrics.pruned.Add(1)
+	logging.Logger.Printf("SSE 连接 %s 写入%s失败，关闭连接: %v", conn.ID, what, err)
+	s.removeSSEConnection(conn.ID)
+}
+
+// dropMessages 记录无法送达的消息
+func (s *Server) dropMessages(sessionID string, count int, reason string) {
+	if count <= 0 {
+		return
+	}
+	s.sseMetrics.dropped.Add(int64(count))
+	logging.Logger.Printf("丢弃发往会话 %s 的 %d 条消息: %s", sessionID, count, reason)
+}
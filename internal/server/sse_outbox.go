@@ -0,0 +1,69 @@
+package server
+
+import (
+	"errors"
+	"sync"
+)
+
+// maxOutboxMessages 每个 SSE 连接最多排队的消息数，超过说明客户端读取过慢，连接会被关闭
+const maxOutboxMessages = 1024
+
+var (
+	errOutboxClosed = errors.New("连接已关闭")
+	errOutboxFull   = errors.New("待发送消息过多，客户端读取过慢")
+)
+
+// sseOutbox SSE 连接的待发送消息队列
+// 响应和通知由各请求的协程放入队列，连接的处理协程按放入顺序逐条写出，
+// 与心跳共用同一个写入协程，事件不会交错
+type sseOutbox struct {
+	mu     sync.Mutex
+	queue  [][]byte
+	closed bool
+	// ready 有新消息时发出信号，容量为 1，多次放入只保留一个信号
+	ready chan struct{}
+}
+
+func newSSEOutbox() *sseOutbox {
+	return &sseOutbox{ready: make(chan struct{}, 1)}
+}
+
+// push 把消息放入队列，连接已关闭或队列已满时返回错误，消息不会入队
+func (o *sseOutbox) push(message []byte) error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return errOutboxClosed
+	}
+	if len(o.queue) >= maxOutboxMessages {
+		o.mu.Unlock()
+		return errOutboxFull
+	}
+	o.queue = append(o.queue, message)
+	o.mu.Unlock()
+
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// take 取出当前排队的全部消息
+func (o *sseOutbox) take() [][]byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	messages := o.queue
+	o.queue = nil
+	return messages
+}
+
+// close 关闭队列并返回尚未写出的消息，之后的 push 都会失败
+func (o *sseOutbox) close() [][]byte {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	messages := o.queue
+	o.queue = nil
+	return messages
+}
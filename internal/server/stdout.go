@@ -0,0 +1,110 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultStdoutWriteTimeout 标准输出单条消息的默认写入超时
+const defaultStdoutWriteTimeout = 30 * time.Second
+
+var (
+	// errStdoutStalled 宿主进程停止读取标准输出，写入超时
+	errStdoutStalled = errors.New("写入标准输出超时，宿主进程可能已停止读取")
+	// errStdoutClosed 标准输入/输出服务器已停止
+	errStdoutClosed = errors.New("标准输出写入器已关闭")
+)
+
+// stdoutWriter 标准输出唯一的写入者
+// 工作协程、通知和错误响应都通过 Write 把完整的消息交给同一个写入协程，消息按交付顺序逐条写出，
+// 不会在流中交错。宿主进程不再读取时管道写满，写入会永远阻塞：超过 timeout 仍未写出时返回错误并调用 onStall，
+// 之后的写入立即失败，阻塞的那次写入留在写入协程中，进程退出时随之结束
+type stdoutWriter struct {
+	w       io.Writer
+	timeout time.Duration
+	onStall func()
+
+	queue     chan *stdoutMessage
+	closed    chan struct{}
+	closeOnce sync.Once
+	stalled   atomic.Bool
+}
+
+// stdoutMessage 一条待写出的完整消息
+type stdoutMessage struct {
+	data []byte
+	done chan error
+}
+
+func newStdoutWriter(w io.Writer, timeout time.Duration, onStall func()) *stdoutWriter {
+	sw := &stdoutWriter{
+		w:       w,
+		timeout: timeout,
+		onStall: onStall,
+		queue:   make(chan *stdoutMessage),
+		closed:  make(chan struct{}),
+	}
+	go sw.run()
+	return sw
+}
+
+// run 写入协程，逐条写出消息
+func (sw *stdoutWriter) run() {
+	for {
+		select {
+		case msg := <-sw.queue:
+			_, err := sw.w.Write(msg.data)
+			msg.done <- err
+		case <-sw.closed:
+			return
+		}
+	}
+}
+
+// Write 把 p 作为一条完整的消息写出，等待写入完成；排队和写入共用 timeout
+func (sw *stdoutWriter) Write(p []byte) (int, error) {
+	if sw.stalled.Load() {
+		return 0, errStdoutStalled
+	}
+	msg := &stdoutMessage{data: append([]byte(nil), p...), done: make(chan error, 1)}
+
+	var expired <-chan time.Time
+	if sw.timeout > 0 {
+		timer := time.NewTimer(sw.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case sw.queue <- msg:
+	case <-sw.closed:
+		return 0, errStdoutClosed
+	case <-expired:
+		return 0, sw.stall()
+	}
+	select {
+	case err := <-msg.done:
+		if err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	case <-expired:
+		return 0, sw.stall()
+	}
+}
+
+// stall 标记写入已阻塞，只在第一次时调用 onStall
+func (sw *stdoutWriter) stall() error {
+	if sw.stalled.CompareAndSwap(false, true) && sw.onStall != nil {
+		sw.onStall()
+	}
+	return errStdoutStalled
+}
+
+// Close 停止写入协程，正在写入的消息不受影响
+func (sw *stdoutWriter) Close() {
+	sw.closeOnce.Do(func() { close(sw.closed) })
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// summarizeResult 对超过 global.summarize 阈值的工具结果生成摘要，返回摘要和指向原始结果的 resource_link
+// 不需要摘要或摘要失败时返回 nil，调用方按原结果继续处理 (包括截断)
+func (s *Server) summarizeResult(ctx context.Context, sessionID, tool, text string, result *mcp.ToolCallResult) []map[string]interface{} {
+	if !s.summarizer.Applies(ctx, tool, text) {
+		return nil
+	}
+
+	logger := logging.FromContext(ctx)
+	summary, err := s.summarizer.Summarize(ctx, tool, text)
+	if err != nil {
+		logger.Printf("%v，返回原始结果", err)
+		return nil
+	}
+	total := utf8.RuneCountInString(text)
+	logger.Printf("工具结果共 %d 个字符，已摘要为 %d 个字符，原始结果保存为 %s", total, utf8.RuneCountInString(summary.Text), summary.URI)
+
+	if provenance, ok := result.Meta[handler.ProvenanceMetaKey].(map[string]interface{}); ok {
+		provenance["summarized"] = true
+	}
+
+	// resource_link 自 2025-06-18 起提供，之前的协议版本在摘要文本中说明资源 URI
+	if !mcp.ProtocolAtLeast(s.ProtocolVersion(sessionID), mcp.ProtocolVersion20250618) {
+		return []map[string]interface{}{{
+			"type": "text",
+			"text": summary.Text + i18n.Tc(ctx, "mcp.result_summarized", total, summary.URI),
+		}}
+	}
+	return []map[string]interface{}{
+		{"type": "text", "text": summary.Text},
+		{
+			"type":        "resource_link",
+			"uri":         summary.URI,
+			"name":        tool + "-result",
+			"description": i18n.Tc(ctx, "mcp.result_raw_description", tool, total),
+			"mimeType":    "application/json",
+		},
+	}
+}
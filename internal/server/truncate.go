@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// truncateResult 把工具结果文本截断到 global.max_result_chars 个字符，截断时在来源信息中标记
+func (s *Server) truncateResult(ctx context.Context, text string, result *mcp.ToolCallResult) (string, bool) {
+	limit := s.config.Global.MaxResultChars
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text, false
+	}
+
+	total := utf8.RuneCountInString(text)
+	text = string([]rune(text)[:limit])
+	logging.FromContext(ctx).Printf("工具结果共 %d 个字符，超过 max_result_chars=%d，已截断", total, limit)
+
+	if provenance, ok := result.Meta[handler.ProvenanceMetaKey].(map[string]interface{}); ok {
+		provenance["truncated"] = true
+	}
+	return text + i18n.Tc(ctx, "mcp.result_truncated", total), true
+}
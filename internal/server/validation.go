@@ -0,0 +1,23 @@
+package server
+
+import (
+	"context"
+
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/i18n"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+// invalidArgumentsResponse 构建参数不符合规范的 -32602 错误响应
+// error.data.violations 逐个列出有问题的参数、违反的约束和规范中的模式摘录，模型可据此修正参数后重试
+func invalidArgumentsResponse(ctx context.Context, id, tool string, invalid *handler.ValidationError) *mcp.MCPResponse {
+	response := newErrorResponse(ctx, id, -32602, i18n.Tc(ctx, "mcp.invalid_arguments", tool, invalid.Error()))
+	data, _ := response.Error.Data.(map[string]interface{})
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	data["tool"] = tool
+	data["violations"] = invalid.Violations
+	response.Error.Data = data
+	return response
+}
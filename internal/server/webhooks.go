@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/webhook"
+)
+
+// handleWebhook 接收上游推送的事件 (POST /webhooks/<name>)
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/webhooks/")
+
+	limit := s.config.Global.MaxRequestBytes()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("请求体超过大小限制 (max_request_size=%d 字节)", limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "读取请求体失败", http.StatusBadRequest)
+		return
+	}
+
+	event, uri, notify, err := s.webhooks.Receive(name, body, r.Header)
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, webhook.ErrUnauthorized):
+		logging.Logger.Printf("Webhook %s 签名校验失败，来源: %s: %v", name, r.RemoteAddr, err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		logging.Logger.Printf("Webhook %s 处理事件失败: %v", name, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logging.Logger.Printf("Webhook %s 收到事件 #%d", name, event.Seq)
+	s.notifyResourceUpdated(uri, notify)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "accepted", "seq": event.Seq})
+}
@@ -0,0 +1,104 @@
+// Package service 提供以 systemd 服务方式运行时的集成: sd_notify 就绪通知和看门狗
+//
+// 协议实现参考 sd_notify(3)，直接向 NOTIFY_SOCKET 发送数据报，不依赖 libsystemd；
+// 未由 systemd 启动 (没有 NOTIFY_SOCKET) 时所有通知都是空操作。
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// NotifySocketEnv systemd 通知套接字环境变量
+	NotifySocketEnv = "NOTIFY_SOCKET"
+	// WatchdogUsecEnv 看门狗超时 (微秒) 环境变量
+	WatchdogUsecEnv = "WATCHDOG_USEC"
+	// WatchdogPIDEnv 看门狗目标进程环境变量
+	WatchdogPIDEnv = "WATCHDOG_PID"
+)
+
+// Notify 向 systemd 发送状态，未配置 NOTIFY_SOCKET 时返回 false
+func Notify(state string) (bool, error) {
+	socket := os.Getenv(NotifySocketEnv)
+	if socket == "" {
+		return false, nil
+	}
+	// 以 @ 开头的是 Linux 抽象命名空间套接字
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("连接 systemd 通知套接字失败: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("发送 systemd 通知失败: %w", err)
+	}
+	return true, nil
+}
+
+// Ready 通知 systemd 服务已就绪 (Type=notify)
+func Ready(status string) (bool, error) {
+	state := "READY=1\nMAINPID=" + strconv.Itoa(os.Getpid())
+	if status != "" {
+		state += "\nSTATUS=" + status
+	}
+	return Notify(state)
+}
+
+// Stopping 通知 systemd 服务正在停止
+func Stopping() (bool, error) {
+	return Notify("STOPPING=1")
+}
+
+// WatchdogInterval 返回 systemd 配置的看门狗超时，未启用或不属于当前进程时返回 0
+func WatchdogInterval() (time.Duration, error) {
+	value := os.Getenv(WatchdogUsecEnv)
+	if value == "" {
+		return 0, nil
+	}
+	usec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, fmt.Errorf("无效的 %s: %s", WatchdogUsecEnv, value)
+	}
+
+	if pid := os.Getenv(WatchdogPIDEnv); pid != "" {
+		if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+			return 0, nil
+		}
+	}
+	return time.Duration(usec) * time.Microsecond, nil
+}
+
+// StartWatchdog 按看门狗超时的一半周期发送 WATCHDOG=1，直到 ctx 取消
+// healthy 返回 false 时跳过本次心跳，让 systemd 在超时后重启服务；返回是否启用了看门狗
+func StartWatchdog(ctx context.Context, healthy func() bool) (bool, error) {
+	interval, err := WatchdogInterval()
+	if err != nil || interval == 0 {
+		return false, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if healthy == nil || healthy() {
+					Notify("WATCHDOG=1")
+				}
+			}
+		}
+	}()
+	return true, nil
+}
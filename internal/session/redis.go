@@ -0,0 +1,207 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/logging"
+)
+
+const redisTimeout = 5 * time.Second
+
+// redisStore 基于 Redis 的会话存储，会话保存为带过期时间的字符串键，消息通过 PUBLISH 转发
+type redisStore struct {
+	client *redisClient
+	prefix string
+	ttl    time.Duration
+}
+
+func newRedisStore(cfg *config.SessionStoreConfig) (*redisStore, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("Redis 会话存储缺少 address")
+	}
+
+	store := &redisStore{
+		client: &redisClient{
+			address:  cfg.Address,
+			password: cfg.Password,
+			db:       cfg.DB,
+			timeout:  redisTimeout,
+		},
+		prefix: cfg.KeyPrefix,
+		ttl:    cfg.TTL,
+	}
+	if store.prefix == "" {
+		store.prefix = defaultKeyPrefix
+	}
+	if store.ttl <= 0 {
+		store.ttl = defaultTTL
+	}
+
+	// 启动时检查连接，配置错误尽早暴露
+	if _, err := store.client.Do("PING"); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *redisStore) sessionKey(id string) string {
+	return s.prefix + ":session:" + id
+}
+
+func (s *redisStore) instanceChannel(instanceID string) string {
+	return s.prefix + ":instance:" + instanceID
+}
+
+// Save 保存会话并刷新过期时间
+func (s *redisStore) Save(ctx context.Context, record *Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化会话失败: %w", err)
+	}
+	seconds := strconv.Itoa(int((s.ttl + time.Second - 1) / time.Second))
+	if _, err := s.client.Do("SET", s.sessionKey(record.ID), string(data), "EX", seconds); err != nil {
+		return fmt.Errorf("保存会话失败: %w", err)
+	}
+	return nil
+}
+
+// Get 获取会话，不存在时返回 nil
+func (s *redisStore) Get(ctx context.Context, id string) (*Record, error) {
+	reply, err := s.client.Do("GET", s.sessionKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("读取会话失败: %w", err)
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, nil
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("解析会话失败: %w", err)
+	}
+	return &record, nil
+}
+
+// Delete 删除会话
+func (s *redisStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.client.Do("DEL", s.sessionKey(id)); err != nil {
+		return fmt.Errorf("删除会话失败: %w", err)
+	}
+	return nil
+}
+
+// Publish 把消息发送给指定实例，没有订阅者 (实例已下线) 时返回错误
+func (s *redisStore) Publish(ctx context.Context, instanceID string, message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("序列化转发消息失败: %w", err)
+	}
+	reply, err := s.client.Do("PUBLISH", s.instanceChannel(instanceID), string(data))
+	if err != nil {
+		return fmt.Errorf("转发消息失败: %w", err)
+	}
+	if receivers, _ := reply.(int64); receivers == 0 {
+		return fmt.Errorf("实例 %s 不在线", instanceID)
+	}
+	return nil
+}
+
+// Subscribe 在独立连接上订阅当前实例的频道，连接断开后自动重连，直到 ctx 取消
+func (s *redisStore) Subscribe(ctx context.Context, instanceID string, handle func(*Message)) error {
+	channel := s.instanceChannel(instanceID)
+
+	// 首次订阅同步完成，保证返回后已能接收消息
+	conn, reader, err := s.subscribe(channel)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		backoff := time.Second
+		for {
+			if conn != nil {
+				stop := make(chan struct{})
+				go func() {
+					select {
+					case <-ctx.Done():
+						conn.Close()
+					case <-stop:
+					}
+				}()
+				err := receive(reader, handle)
+				close(stop)
+				conn.Close()
+				if ctx.Err() != nil {
+					return
+				}
+				logging.Logger.Printf("Redis 订阅连接断开，准备重连: %v", err)
+				backoff = time.Second
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if conn, reader, err = s.subscribe(channel); err != nil {
+				logging.Logger.Printf("Redis 重新订阅失败: %v", err)
+				conn = nil
+				if backoff < 30*time.Second {
+					backoff *= 2
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// subscribe 建立订阅连接
+func (s *redisStore) subscribe(channel string) (net.Conn, *bufio.Reader, error) {
+	c, r, err := s.client.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := roundTrip(c, r, s.client.timeout, "SUBSCRIBE", channel); err != nil {
+		c.Close()
+		return nil, nil, fmt.Errorf("订阅频道 %s 失败: %w", channel, err)
+	}
+	return c, r, nil
+}
+
+// receive 读取推送的消息直到连接出错
+func receive(reader *bufio.Reader, handle func(*Message)) error {
+	for {
+		reply, err := readReply(reader)
+		if err != nil {
+			return err
+		}
+		items, ok := reply.([]interface{})
+		if !ok || len(items) != 3 {
+			continue
+		}
+		if kind, _ := items[0].([]byte); string(kind) != "message" {
+			continue
+		}
+		payload, _ := items[2].([]byte)
+
+		var message Message
+		if err := json.Unmarshal(payload, &message); err != nil {
+			logging.Logger.Printf("解析转发消息失败: %v", err)
+			continue
+		}
+		handle(&message)
+	}
+}
+
+// Close 关闭命令连接
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,170 @@
+package session
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// redisError Redis 返回的错误回复
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisClient 最小化的 RESP 客户端，只实现会话共享需要的命令
+type redisClient struct {
+	address  string
+	password string
+	db       int
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// dial 建立连接并完成认证和选库
+func (c *redisClient) dial() (net.Conn, *bufio.Reader, error) {
+	conn, err := net.DialTimeout("tcp", c.address, c.timeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接 Redis %s 失败: %w", c.address, err)
+	}
+	reader := bufio.NewReader(conn)
+
+	if c.password != "" {
+		if _, err := roundTrip(conn, reader, c.timeout, "AUTH", c.password); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("Redis 认证失败: %w", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := roundTrip(conn, reader, c.timeout, "SELECT", strconv.Itoa(c.db)); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("选择 Redis 数据库失败: %w", err)
+		}
+	}
+	return conn, reader, nil
+}
+
+// Do 执行命令，网络错误时丢弃连接，下次调用重新建立
+func (c *redisClient) Do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, reader, err := c.dial()
+		if err != nil {
+			return nil, err
+		}
+		c.conn, c.reader = conn, reader
+	}
+
+	reply, err := roundTrip(c.conn, c.reader, c.timeout, args...)
+	if err != nil {
+		if _, ok := err.(redisError); !ok {
+			c.conn.Close()
+			c.conn, c.reader = nil, nil
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Close 关闭连接
+func (c *redisClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.reader = nil, nil
+	return err
+}
+
+// roundTrip 写入命令并读取一个回复
+func roundTrip(conn net.Conn, reader *bufio.Reader, timeout time.Duration, args ...string) (interface{}, error) {
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+	if err := writeCommand(conn, args); err != nil {
+		return nil, err
+	}
+	return readReply(reader)
+}
+
+// writeCommand 以 RESP 数组形式编码命令
+func writeCommand(w io.Writer, args []string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// readReply 读取一个 RESP 回复
+// 简单字符串返回 string，整数返回 int64，批量字符串返回 []byte (空值为 nil)，数组返回 []interface{}
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("无效的 Redis 回复: %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 Redis 批量长度: %q", body)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 Redis 数组长度: %q", body)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("未知的 Redis 回复类型: %q", kind)
+	}
+}
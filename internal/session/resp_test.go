@@ -0,0 +1,135 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCommand(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCommand(&buf, []string{"SET", "key", "", "值"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "*4\r\n$3\r\nSET\r\n$3\r\nkey\r\n$0\r\n\r\n$3\r\n值\r\n"
+	if buf.String() != want {
+		t.Fatalf("writeCommand() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    interface{}
+		wantErr string
+	}{
+		{"simple string", "+OK\r\n", "OK", ""},
+		{"error", "-ERR unknown command\r\n", nil, "redis: ERR unknown command"},
+		{"integer", ":-42\r\n", int64(-42), ""},
+		{"bulk string", "$5\r\nhello\r\n", []byte("hello"), ""},
+		{"binary bulk string", "$4\r\na\r\nb\r\n", []byte("a\r\nb"), ""},
+		{"empty bulk string", "$0\r\n\r\n", []byte{}, ""},
+		{"null bulk string", "$-1\r\n", nil, ""},
+		{"null array", "*-1\r\n", nil, ""},
+		{"nested array", "*3\r\n$7\r\nmessage\r\n:1\r\n*1\r\n+x\r\n", []interface{}{[]byte("message"), int64(1), []interface{}{"x"}}, ""},
+		{"missing CR", "+OK\n", nil, "无效的 Redis 回复"},
+		{"unknown type", "?1\r\n", nil, "未知的 Redis 回复类型"},
+		{"bad bulk length", "$x\r\n", nil, "无效的 Redis 批量长度"},
+		{"truncated bulk string", "$5\r\nhel", nil, "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.input)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readReply() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readReply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("readReply() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRedis 在本地端口上按顺序回复固定的响应，并记录收到的命令
+func fakeRedis(t *testing.T, replies ...string) (string, <-chan []interface{}) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("无法监听本地端口: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	commands := make(chan []interface{}, len(replies))
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for _, reply := range replies {
+			command, err := readReply(reader)
+			if err != nil {
+				return
+			}
+			commands <- command.([]interface{})
+			conn.Write([]byte(reply))
+		}
+	}()
+	return listener.Addr().String(), commands
+}
+
+func TestRedisClientDo(t *testing.T) {
+	address, commands := fakeRedis(t, "+OK\r\n", "+OK\r\n", "$3\r\nbar\r\n", "-WRONGTYPE bad\r\n", ":1\r\n")
+	client := &redisClient{address: address, password: "secret", db: 2, timeout: time.Second}
+	defer client.Close()
+
+	reply, err := client.Do("GET", "foo")
+	if err != nil || !reflect.DeepEqual(reply, []byte("bar")) {
+		t.Fatalf("Do(GET) = %#v, %v", reply, err)
+	}
+	// 错误回复不丢弃连接，后续命令继续使用同一个连接
+	if _, err := client.Do("INCR", "foo"); err == nil || err.Error() != "redis: WRONGTYPE bad" {
+		t.Fatalf("Do(INCR) error = %v", err)
+	}
+	if reply, err := client.Do("DEL", "foo"); err != nil || reply != int64(1) {
+		t.Fatalf("Do(DEL) = %#v, %v", reply, err)
+	}
+
+	want := [][]string{{"AUTH", "secret"}, {"SELECT", "2"}, {"GET", "foo"}, {"INCR", "foo"}, {"DEL", "foo"}}
+	for _, args := range want {
+		command := <-commands
+		got := make([]string, len(command))
+		for i, arg := range command {
+			got[i] = string(arg.([]byte))
+		}
+		if !reflect.DeepEqual(got, args) {
+			t.Fatalf("command = %q, want %q", got, args)
+		}
+	}
+}
+
+func TestRedisClientAuthFailure(t *testing.T) {
+	address, _ := fakeRedis(t, "-WRONGPASS invalid password\r\n")
+	client := &redisClient{address: address, password: "wrong", timeout: time.Second}
+	defer client.Close()
+
+	_, err := client.Do("PING")
+	if err == nil || !strings.Contains(err.Error(), "Redis 认证失败") {
+		t.Fatalf("Do() error = %v, want authentication failure", err)
+	}
+	if client.conn != nil {
+		t.Fatal("认证失败后不应保留连接")
+	}
+}
@@ -0,0 +1,242 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/paths"
+)
+
+const (
+	defaultResumeTTL        = 5 * time.Minute
+	defaultResumeMaxPending = 100
+	defaultResumeDir        = "sessions"
+)
+
+// State 可恢复的会话状态，连接断开或服务器重启后客户端可以在有效期内用原会话ID恢复
+type State struct {
+	ID              string                 `json:"id"`
+	Endpoint        string                 `json:"endpoint"`
+	CreatedAt       time.Time              `json:"created_at"`
+	Client          string                 `json:"client,omitempty"`
+	ProtocolVersion string                 `json:"protocol_version,omitempty"`
+	Capabilities    map[string]interface{} `json:"capabilities,omitempty"`
+	Subscriptions   []string               `json:"subscriptions,omitempty"`
+	// Pending 连接断开期间产生的待发送消息，恢复连接后按顺序推送
+	Pending []json.RawMessage `json:"pending,omitempty"`
+}
+
+// StateStore 会话状态存储，记录在最后一次保存后 TTL 到期
+type StateStore interface {
+	// SaveState 保存会话状态并刷新过期时间
+	SaveState(ctx context.Context, state *State) error
+	// LoadState 读取会话状态，不存在或已过期时返回 nil
+	LoadState(ctx context.Context, id string) (*State, error)
+	// DeleteState 删除会话状态
+	DeleteState(ctx context.Context, id string) error
+	// TTL 返回会话状态的有效期
+	TTL() time.Duration
+	// MaxPending 返回最多缓存的待发送消息数
+	MaxPending() int
+	Close() error
+}
+
+// NewStateStore 根据配置创建会话状态存储，未配置时返回 nil
+// redis 类型使用 storeCfg (global.session_store) 的连接设置
+func NewStateStore(cfg *config.SessionResumeConfig, storeCfg *config.SessionStoreConfig) (StateStore, error) {
+	if cfg == nil || cfg.Type == "" {
+		return nil, nil
+	}
+
+	limits := resumeLimits{ttl: cfg.TTL, maxPending: cfg.MaxPending}
+	if limits.ttl <= 0 {
+		limits.ttl = defaultResumeTTL
+	}
+	if limits.maxPending <= 0 {
+		limits.maxPending = defaultResumeMaxPending
+	}
+
+	switch cfg.Type {
+	case "file":
+		dir := cfg.Dir
+		if dir == "" {
+			dir = defaultResumeDir
+		}
+		dir = paths.Resolve(dir)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("创建会话目录 %s 失败: %w", dir, err)
+		}
+		return &fileStateStore{resumeLimits: limits, dir: dir}, nil
+	case "redis":
+		if storeCfg == nil || storeCfg.Type != "redis" {
+			return nil, fmt.Errorf("redis 类型的会话恢复需要配置 session_store (type: redis)")
+		}
+		store, err := newRedisStore(storeCfg)
+		if err != nil {
+			return nil, err
+		}
+		return &redisStateStore{resumeLimits: limits, store: store}, nil
+	default:
+		return nil, fmt.Errorf("不支持的会话恢复存储类型: %s (支持: file、redis)", cfg.Type)
+	}
+}
+
+// validStateID 检查会话ID是否只包含十六进制字符，避免拼接到文件路径和键名中
+func validStateID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+type resumeLimits struct {
+	ttl        time.Duration
+	maxPending int
+}
+
+func (l resumeLimits) TTL() time.Duration { return l.ttl }
+func (l resumeLimits) MaxPending() int    { return l.maxPending }
+
+// fileStateStore 每个会话保存为目录下的一个 JSON 文件，按修改时间判断是否过期
+type fileStateStore struct {
+	resumeLimits
+	dir string
+}
+
+func (s *fileStateStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// SaveState 先写入临时文件再重命名，避免重启时读到写了一半的文件
+func (s *fileStateStore) SaveState(ctx context.Context, state *State) error {
+	if !validStateID(state.ID) {
+		return fmt.Errorf("无效的会话ID: %s", state.ID)
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("序列化会话状态失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return fmt.Errorf("保存会话状态失败: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("保存会话状态失败: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), s.path(state.ID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("保存会话状态失败: %w", err)
+	}
+	return nil
+}
+
+// LoadState 读取会话状态，过期的文件会被删除
+func (s *fileStateStore) LoadState(ctx context.Context, id string) (*State, error) {
+	if !validStateID(id) {
+		return nil, nil
+	}
+	path := s.path(id)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取会话状态失败: %w", err)
+	}
+	if time.Since(info.ModTime()) > s.ttl {
+		os.Remove(path)
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取会话状态失败: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析会话状态失败: %w", err)
+	}
+	return &state, nil
+}
+
+// DeleteState 删除会话状态
+func (s *fileStateStore) DeleteState(ctx context.Context, id string) error {
+	if !validStateID(id) {
+		return nil
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除会话状态失败: %w", err)
+	}
+	return nil
+}
+
+func (s *fileStateStore) Close() error { return nil }
+
+// redisStateStore 会话状态保存为带过期时间的字符串键
+type redisStateStore struct {
+	resumeLimits
+	store *redisStore
+}
+
+func (s *redisStateStore) key(id string) string {
+	return s.store.prefix + ":state:" + id
+}
+
+// SaveState 保存会话状态并刷新过期时间
+func (s *redisStateStore) SaveState(ctx context.Context, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("序列化会话状态失败: %w", err)
+	}
+	seconds := strconv.Itoa(int((s.ttl + time.Second - 1) / time.Second))
+	if _, err := s.store.client.Do("SET", s.key(state.ID), string(data), "EX", seconds); err != nil {
+		return fmt.Errorf("保存会话状态失败: %w", err)
+	}
+	return nil
+}
+
+// LoadState 读取会话状态，不存在或已过期时返回 nil
+func (s *redisStateStore) LoadState(ctx context.Context, id string) (*State, error) {
+	if !validStateID(id) {
+		return nil, nil
+	}
+	reply, err := s.store.client.Do("GET", s.key(id))
+	if err != nil {
+		return nil, fmt.Errorf("读取会话状态失败: %w", err)
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, nil
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析会话状态失败: %w", err)
+	}
+	return &state, nil
+}
+
+// DeleteState 删除会话状态
+func (s *redisStateStore) DeleteState(ctx context.Context, id string) error {
+	if _, err := s.store.client.Do("DEL", s.key(id)); err != nil {
+		return fmt.Errorf("删除会话状态失败: %w", err)
+	}
+	return nil
+}
+
+func (s *redisStateStore) Close() error {
+	return s.store.Close()
+}
@@ -0,0 +1,65 @@
+// Package session 提供多实例部署时共享的 SSE 会话存储
+//
+// SSE 连接只能由建立它的实例写入，因此共享存储只记录会话归属的实例；
+// 其他实例收到该会话的 POST /messages/ 时，通过发布/订阅把原始消息转发给归属实例处理。
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+const (
+	defaultKeyPrefix = "mcp2rest"
+	defaultTTL       = 10 * time.Minute
+)
+
+// Record 共享存储中的会话记录
+type Record struct {
+	ID         string    `json:"id"`
+	InstanceID string    `json:"instance_id"` // 持有 SSE 连接的实例
+	Endpoint   string    `json:"endpoint"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Message 转发给归属实例的客户端消息
+type Message struct {
+	SessionID string          `json:"session_id"`
+	RequestID string          `json:"request_id,omitempty"` // 关联ID，转发后继续沿用
+	Locale    string          `json:"locale,omitempty"`     // 错误消息的语言，转发后继续沿用
+	Client    string          `json:"client,omitempty"`     // 接收消息的实例识别出的入站客户端，归属实例据此检查会话
+	Body      json.RawMessage `json:"body"`
+}
+
+// Store 共享会话存储
+type Store interface {
+	// Save 保存会话并刷新过期时间
+	Save(ctx context.Context, record *Record) error
+	// Get 获取会话，不存在时返回 nil
+	Get(ctx context.Context, id string) (*Record, error)
+	// Delete 删除会话
+	Delete(ctx context.Context, id string) error
+	// Publish 把消息发送给指定实例
+	Publish(ctx context.Context, instanceID string, message *Message) error
+	// Subscribe 接收发给当前实例的消息，直到 ctx 取消
+	Subscribe(ctx context.Context, instanceID string, handle func(*Message)) error
+	Close() error
+}
+
+// NewStore 根据配置创建共享会话存储，未配置时返回 nil
+func NewStore(cfg *config.SessionStoreConfig) (Store, error) {
+	if cfg == nil || cfg.Type == "" {
+		return nil, nil
+	}
+
+	switch cfg.Type {
+	case "redis":
+		return newRedisStore(cfg)
+	default:
+		return nil, fmt.Errorf("不支持的会话存储类型: %s (支持: redis)", cfg.Type)
+	}
+}
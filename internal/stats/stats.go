@@ -0,0 +1,255 @@
+// Package stats 记录每个工具的调用次数、错误率、延迟分布和上游流量
+//
+// 统计保存在内存中，可在停止时写入 JSON 文件并在下次启动时加载，
+// 用于帮助规范维护者了解智能体实际使用了哪些工具。
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxSamples 每个工具保留的最近延迟样本数，分位数基于这些样本计算
+const maxSamples = 1024
+
+// toolRecord 单个工具的累计统计
+type toolRecord struct {
+	Calls      int64     `json:"calls"`
+	Errors     int64     `json:"errors"`
+	SlowCalls  int64     `json:"slow_calls"`
+	TotalNanos int64     `json:"total_nanos"`
+	MaxNanos   int64     `json:"max_nanos"`
+	LastCall   time.Time `json:"last_call"`
+	// BytesSent、BytesReceived 发送给上游的请求体和从上游接收的响应体字节数，MaxReceived 为单次调用接收的最大值
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+	MaxReceived   int64 `json:"max_received"`
+	// Samples 最近的延迟样本 (纳秒)，写满后按环形缓冲区覆盖
+	Samples []int64 `json:"samples"`
+	next    int
+}
+
+// ToolStats 单个工具的统计快照
+type ToolStats struct {
+	Name      string    `json:"name"`
+	Calls     int64     `json:"calls"`
+	Errors    int64     `json:"errors"`
+	ErrorRate float64   `json:"error_rate"`
+	SlowCalls int64     `json:"slow_calls"`
+	AvgMs     float64   `json:"avg_ms"`
+	P50Ms     float64   `json:"p50_ms"`
+	P90Ms     float64   `json:"p90_ms"`
+	P99Ms     float64   `json:"p99_ms"`
+	MaxMs     float64   `json:"max_ms"`
+	LastCall  time.Time `json:"last_call"`
+	// BytesSent、BytesReceived 累计的上游流量，AvgReceived 和 MaxReceived 为单次调用接收的平均值和最大值
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+	AvgReceived   int64 `json:"avg_received"`
+	MaxReceived   int64 `json:"max_received"`
+}
+
+// Recorder 工具调用统计记录器，可并发使用
+type Recorder struct {
+	mu            sync.Mutex
+	tools         map[string]*toolRecord
+	since         time.Time
+	slowThreshold time.Duration
+}
+
+// persisted 持久化文件格式
+type persisted struct {
+	Since time.Time              `json:"since"`
+	Tools map[string]*toolRecord `json:"tools"`
+}
+
+// NewRecorder 创建记录器，slowThreshold 大于 0 时超过该耗时的调用计为慢调用
+func NewRecorder(slowThreshold time.Duration) *Recorder {
+	return &Recorder{
+		tools:         make(map[string]*toolRecord),
+		since:         time.Now(),
+		slowThreshold: slowThreshold,
+	}
+}
+
+// Record 记录一次工具调用，返回该调用是否为慢调用
+func (r *Recorder) Record(tool string, duration time.Duration, failed bool) bool {
+	slow := r.slowThreshold > 0 && duration >= r.slowThreshold
+	nanos := duration.Nanoseconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, exists := r.tools[tool]
+	if !exists {
+		record = &toolRecord{}
+		r.tools[tool] = record
+	}
+	record.Calls++
+	if failed {
+		record.Errors++
+	}
+	if slow {
+		record.SlowCalls++
+	}
+	record.TotalNanos += nanos
+	if nanos > record.MaxNanos {
+		record.MaxNanos = nanos
+	}
+	record.LastCall = time.Now()
+
+	if len(record.Samples) < maxSamples {
+		record.Samples = append(record.Samples, nanos)
+	} else {
+		record.Samples[record.next] = nanos
+		record.next = (record.next + 1) % maxSamples
+	}
+	return slow
+}
+
+// AddTraffic 累加一次工具调用的上游流量，应在 Record 之后调用
+func (r *Recorder) AddTraffic(tool string, sent, received int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, exists := r.tools[tool]
+	if !exists {
+		record = &toolRecord{}
+		r.tools[tool] = record
+	}
+	record.BytesSent += sent
+	record.BytesReceived += received
+	if received > record.MaxReceived {
+		record.MaxReceived = received
+	}
+}
+
+// Since 返回统计开始的时间 (加载持久化文件时为文件中记录的时间)
+func (r *Recorder) Since() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.since
+}
+
+// Snapshot 返回所有工具的统计快照，按调用次数降序排列
+func (r *Recorder) Snapshot() []ToolStats {
+	r.mu.Lock()
+	result := make([]ToolStats, 0, len(r.tools))
+	for name, record := range r.tools {
+		result = append(result, record.snapshot(name))
+	}
+	r.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Calls != result[j].Calls {
+			return result[i].Calls > result[j].Calls
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// snapshot 计算单个工具的快照，调用方需持有锁
+func (t *toolRecord) snapshot(name string) ToolStats {
+	stats := ToolStats{
+		Name:      name,
+		Calls:     t.Calls,
+		Errors:    t.Errors,
+		SlowCalls: t.SlowCalls,
+		MaxMs:     millis(t.MaxNanos),
+		LastCall:  t.LastCall,
+
+		BytesSent:     t.BytesSent,
+		BytesReceived: t.BytesReceived,
+		MaxReceived:   t.MaxReceived,
+	}
+	if t.Calls > 0 {
+		stats.ErrorRate = float64(t.Errors) / float64(t.Calls)
+		stats.AvgMs = millis(t.TotalNanos / t.Calls)
+		stats.AvgReceived = t.BytesReceived / t.Calls
+	}
+
+	samples := make([]int64, len(t.Samples))
+	copy(samples, t.Samples)
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	stats.P50Ms = millis(percentile(samples, 50))
+	stats.P90Ms = millis(percentile(samples, 90))
+	stats.P99Ms = millis(percentile(samples, 99))
+	return stats
+}
+
+// percentile 返回已排序样本的分位数
+func percentile(sorted []int64, p int) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := (len(sorted)*p+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+func millis(nanos int64) float64 {
+	return float64(nanos) / float64(time.Millisecond)
+}
+
+// Load 从持久化文件加载统计，文件不存在时不做任何处理
+func (r *Recorder) Load(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取统计文件失败: %w", err)
+	}
+
+	var saved persisted
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("解析统计文件失败: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, record := range saved.Tools {
+		if record == nil {
+			continue
+		}
+		if len(record.Samples) > maxSamples {
+			record.Samples = record.Samples[len(record.Samples)-maxSamples:]
+		}
+		r.tools[name] = record
+	}
+	if !saved.Since.IsZero() {
+		r.since = saved.Since
+	}
+	return nil
+}
+
+// Save 把统计写入持久化文件，先写临时文件再重命名，避免中断时留下不完整的文件
+func (r *Recorder) Save(path string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(persisted{Since: r.since, Tools: r.tools}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("序列化统计失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建统计文件目录失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入统计文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入统计文件失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/version"
+)
+
+// maxChatResponseSize 对话接口响应体的大小上限
+const maxChatResponseSize = 4 << 20
+
+// chatCompleter 通过 OpenAI 兼容的 /chat/completions 接口生成回复
+type chatCompleter struct {
+	url       string
+	model     string
+	apiKey    string
+	apiKeyEnv string
+	client    *http.Client
+}
+
+func newChatCompleter(cfg config.SummarizeConfig) *chatCompleter {
+	return &chatCompleter{
+		url:       strings.TrimSuffix(cfg.Endpoint, "/") + "/chat/completions",
+		model:     cfg.Model,
+		apiKey:    cfg.APIKey,
+		apiKeyEnv: cfg.APIKeyEnv,
+		client:    &http.Client{},
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete 发送一轮对话并返回第一个候选回复
+func (c *chatCompleter) Complete(ctx context.Context, system, input string, maxTokens int) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: input},
+		},
+		MaxTokens: maxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化对话请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("创建对话请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mcp2rest/"+version.Version)
+	if key := c.key(); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("发送对话请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxChatResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("读取对话响应失败: %w", err)
+	}
+	var result chatResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("解析对话响应失败 (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("对话接口返回错误 (HTTP %d): %s", resp.StatusCode, result.Error.Message)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("对话接口返回 HTTP %d", resp.StatusCode)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("对话响应中没有候选回复")
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// key 返回 API 密钥，api_key_env 指定的环境变量优先
+func (c *chatCompleter) key() string {
+	if c.apiKeyEnv != "" {
+		if key := os.Getenv(c.apiKeyEnv); key != "" {
+			return key
+		}
+	}
+	return c.apiKey
+}
@@ -0,0 +1,254 @@
+// Package summarize 把过长的工具结果交给外部模型生成摘要，原始结果保存为 MCP 资源
+//
+// 摘要由 OpenAI 兼容的对话接口生成，也可以通过 sampling/createMessage 请求已连接的客户端生成。
+// 每个被摘要的结果对应资源 results://<id>，工具结果中附带指向它的链接，客户端需要完整数据时再读取。
+// 原始结果只保存在进程内，超过 keep 个时丢弃最早的；这些资源不出现在 resources/list 中，
+// 资源 ID 是随机的，并且只有生成结果的同一会话和客户端可以读取。
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/handler"
+)
+
+const (
+	// URIScheme 原始结果资源 URI 的前缀
+	URIScheme = "results://"
+
+	defaultThreshold     = 20000
+	defaultMaxInputChars = 100000
+	defaultMaxTokens     = 1024
+	defaultTimeout       = 60 * time.Second
+	defaultKeep          = 50
+
+	defaultPrompt = "You summarize the output of an API call for an AI assistant. " +
+		"Keep identifiers, names, counts, totals, dates and error messages exactly as they appear. " +
+		"Describe the structure of lists and mention how many items they contain. " +
+		"Answer in the language of the data, without any preamble."
+)
+
+// sampling 的使用方式
+const (
+	SamplingOff    = "off"
+	SamplingPrefer = "prefer"
+	SamplingOnly   = "only"
+)
+
+// Completer 按系统提示词和输入生成回复
+type Completer interface {
+	Complete(ctx context.Context, system, input string, maxTokens int) (string, error)
+}
+
+// Sampler 通过发起工具调用的客户端生成回复，Available 检查上下文中的客户端是否支持
+type Sampler interface {
+	Completer
+	Available(ctx context.Context) bool
+}
+
+// Summary 一次摘要的结果
+type Summary struct {
+	Text string // 模型生成的摘要
+	URI  string // 原始结果的资源 URI
+}
+
+// stored 保存的原始结果
+type stored struct {
+	id        string
+	tool      string
+	text      string
+	createdAt time.Time
+	// owner 发起工具调用的调用方，其他会话或客户端读取时按资源不存在处理
+	owner handler.Caller
+}
+
+// Summarizer 对超过阈值的工具结果生成摘要，并保存原始结果
+type Summarizer struct {
+	config    config.SummarizeConfig
+	completer Completer // 对话接口，sampling 为 only 时为 nil
+	sampler   Sampler   // sampling 为 off 时为 nil
+	tools     map[string]bool
+
+	mu      sync.Mutex
+	results []*stored // 按保存时间排列，最早的在前
+}
+
+// New 根据配置创建摘要器，cfg 为 nil 时返回的摘要器不处理任何结果
+// maxResultChars 为 global.max_result_chars，未设置 threshold 时作为阈值；sampler 在配置了 sampling 时使用
+func New(cfg *config.SummarizeConfig, maxResultChars int, sampler Sampler) (*Summarizer, error) {
+	if cfg == nil {
+		return &Summarizer{}, nil
+	}
+
+	c := *cfg
+	switch c.Sampling {
+	case "", SamplingOff:
+		c.Sampling = SamplingOff
+	case SamplingPrefer, SamplingOnly:
+		if sampler == nil {
+			return nil, fmt.Errorf("summarize.sampling 为 %s，但没有可用的 sampling 实现", c.Sampling)
+		}
+	default:
+		return nil, fmt.Errorf("无效的 summarize.sampling: %q (支持: off, prefer, only)", c.Sampling)
+	}
+	if c.Sampling != SamplingOnly {
+		if c.Endpoint == "" {
+			return nil, fmt.Errorf("summarize.endpoint 不能为空")
+		}
+		if c.Model == "" {
+			return nil, fmt.Errorf("summarize.model 不能为空")
+		}
+	}
+	if c.Threshold <= 0 {
+		c.Threshold = maxResultChars
+		if c.Threshold <= 0 {
+			c.Threshold = defaultThreshold
+		}
+	}
+	if c.MaxInputChars <= 0 {
+		c.MaxInputChars = defaultMaxInputChars
+	}
+	if c.MaxTokens <= 0 {
+		c.MaxTokens = defaultMaxTokens
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+	if c.Keep <= 0 {
+		c.Keep = defaultKeep
+	}
+	if c.Prompt == "" {
+		c.Prompt = defaultPrompt
+	}
+
+	s := &Summarizer{config: c}
+	if c.Sampling != SamplingOnly {
+		s.completer = newChatCompleter(c)
+	}
+	if c.Sampling != SamplingOff {
+		s.sampler = sampler
+	}
+	if len(c.Tools) > 0 {
+		s.tools = make(map[string]bool, len(c.Tools))
+		for _, tool := range c.Tools {
+			s.tools[tool] = true
+		}
+	}
+	return s, nil
+}
+
+// Enabled 检查是否配置了摘要
+func (s *Summarizer) Enabled() bool {
+	return s != nil && (s.completer != nil || s.sampler != nil)
+}
+
+// Applies 检查工具结果是否需要摘要，只使用 sampling 时客户端还必须支持 sampling
+func (s *Summarizer) Applies(ctx context.Context, tool, text string) bool {
+	if !s.Enabled() || (s.tools != nil && !s.tools[tool]) {
+		return false
+	}
+	if utf8.RuneCountInString(text) <= s.config.Threshold {
+		return false
+	}
+	return s.completerFor(ctx) != nil
+}
+
+// completerFor 选择生成摘要的方式: 客户端支持时按配置使用 sampling，否则使用对话接口
+func (s *Summarizer) completerFor(ctx context.Context) Completer {
+	if s.sampler != nil && s.sampler.Available(ctx) {
+		return s.sampler
+	}
+	if s.completer != nil {
+		return s.completer
+	}
+	return nil
+}
+
+// Summarize 生成结果的摘要，成功后保存原始结果并返回其资源 URI，资源绑定到上下文中的调用方
+// 超过 max_input_chars 的部分不发送给模型，但原始结果完整保存
+func (s *Summarizer) Summarize(ctx context.Context, tool, text string) (*Summary, error) {
+	input := text
+	if utf8.RuneCountInString(input) > s.config.MaxInputChars {
+		input = string([]rune(input)[:s.config.MaxInputChars])
+	}
+
+	completer := s.completerFor(ctx)
+	if completer == nil {
+		return nil, fmt.Errorf("生成工具 %s 结果的摘要失败: 客户端不支持 sampling", tool)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	summary, err := completer.Complete(ctx, s.config.Prompt, fmt.Sprintf("Tool: %s\n\n%s", tool, input), s.config.MaxTokens)
+	if err != nil {
+		return nil, fmt.Errorf("生成工具 %s 结果的摘要失败: %w", tool, err)
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return nil, fmt.Errorf("生成工具 %s 结果的摘要失败: 模型返回了空的摘要", tool)
+	}
+
+	return &Summary{Text: summary, URI: s.store(tool, text, handler.CallerFromContext(ctx))}, nil
+}
+
+// store 保存原始结果，返回资源 URI
+func (s *Summarizer) store(tool, text string, owner handler.Caller) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &stored{id: uuid.New().String(), tool: tool, text: text, createdAt: time.Now(), owner: owner}
+	s.results = append(s.results, r)
+	if len(s.results) > s.config.Keep {
+		s.results = s.results[len(s.results)-s.config.Keep:]
+	}
+	return URIScheme + r.id
+}
+
+// find 按资源 URI 查找保存的原始结果
+func (s *Summarizer) find(uri string) *stored {
+	if s == nil || !strings.HasPrefix(uri, URIScheme) {
+		return nil
+	}
+	id := strings.TrimPrefix(uri, URIScheme)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.results {
+		if r.id == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// Resources 原始结果只通过工具结果中的链接访问，不出现在资源列表中
+func (s *Summarizer) Resources() []map[string]interface{} {
+	return nil
+}
+
+// Has 检查原始结果是否仍然保存
+func (s *Summarizer) Has(uri string) bool {
+	return s.find(uri) != nil
+}
+
+// Read 读取保存的原始结果，上下文中的调用方必须与保存结果的调用方相同
+// 属于其他调用方的结果与不存在的结果返回相同的错误
+func (s *Summarizer) Read(ctx context.Context, uri string) (map[string]interface{}, error) {
+	r := s.find(uri)
+	if r == nil || r.owner != handler.CallerFromContext(ctx) {
+		return nil, fmt.Errorf("资源不存在: %s", uri)
+	}
+	return map[string]interface{}{
+		"tool":       r.tool,
+		"created_at": r.createdAt,
+		"result":     r.text,
+	}, nil
+}
@@ -0,0 +1,79 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/handler"
+)
+
+// fakeSampler 总是可用，返回固定的摘要
+type fakeSampler struct{}
+
+func (fakeSampler) Complete(ctx context.Context, system, input string, maxTokens int) (string, error) {
+	return "summary", nil
+}
+
+func (fakeSampler) Available(ctx context.Context) bool { return true }
+
+func newTestSummarizer(t *testing.T) *Summarizer {
+	t.Helper()
+	s, err := New(&config.SummarizeConfig{Sampling: SamplingOnly, Threshold: 1}, 0, fakeSampler{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return s
+}
+
+func TestReadRequiresOwner(t *testing.T) {
+	s := newTestSummarizer(t)
+	owner := handler.Caller{Session: "session-a", Client: "reader"}
+	summary, err := s.Summarize(handler.WithCaller(context.Background(), owner), "listUsers", `{"users":[]}`)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		caller  handler.Caller
+		allowed bool
+	}{
+		{"owner", owner, true},
+		{"other session", handler.Caller{Session: "session-b", Client: "reader"}, false},
+		{"other client", handler.Caller{Session: "session-a", Client: "admin"}, false},
+		{"anonymous", handler.Caller{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := s.Read(handler.WithCaller(context.Background(), tt.caller), summary.URI)
+			if !tt.allowed {
+				if err == nil {
+					t.Fatalf("Read() by %+v = %v, want error", tt.caller, content)
+				}
+				return
+			}
+			if err != nil || content["result"] != `{"users":[]}` {
+				t.Fatalf("Read() = %v, %v", content, err)
+			}
+		})
+	}
+}
+
+func TestStoredURIsAreRandom(t *testing.T) {
+	s := newTestSummarizer(t)
+	ctx := handler.WithCaller(context.Background(), handler.Caller{Session: "s"})
+	seen := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		summary, err := s.Summarize(ctx, "tool", "result")
+		if err != nil {
+			t.Fatalf("Summarize() error = %v", err)
+		}
+		id := strings.TrimPrefix(summary.URI, URIScheme)
+		if len(id) < 32 || seen[id] {
+			t.Fatalf("资源 ID %q 不是随机的或重复", id)
+		}
+		seen[id] = true
+	}
+}
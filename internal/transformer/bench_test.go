@@ -0,0 +1,53 @@
+package transformer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// benchPayload 转换器基准测试使用的上游响应
+var benchPayload = func() interface{} {
+	items := make([]interface{}, 50)
+	for i := range items {
+		items[i] = map[string]interface{}{
+			"id":    fmt.Sprintf("item-%d", i),
+			"name":  fmt.Sprintf("名称 %d", i),
+			"score": float64(i) * 1.5,
+			"tags":  []interface{}{"a", "b", "c"},
+			"owner": map[string]interface{}{"id": float64(i), "email": "user@example.com"},
+		}
+	}
+	return map[string]interface{}{"total": float64(len(items)), "items": items}
+}()
+
+func benchmarkTransform(b *testing.B, pipeline config.TransformPipeline) {
+	t, err := NewResponseTransformer()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := t.TransformValue(benchPayload, pipeline, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTransformJQ(b *testing.B) {
+	benchmarkTransform(b, config.TransformPipeline{{Type: "jq", Expression: "[.items[] | {id, name}]"}})
+}
+
+func BenchmarkTransformJSONPath(b *testing.B) {
+	benchmarkTransform(b, config.TransformPipeline{{Type: "jsonpath", Expression: "$.items[*].owner.email"}})
+}
+
+func BenchmarkTransformFields(b *testing.B) {
+	benchmarkTransform(b, config.TransformPipeline{{Type: "fields", Fields: []string{"total", "items.id", "items.owner.id"}}})
+}
+
+func BenchmarkTransformTemplate(b *testing.B) {
+	benchmarkTransform(b, config.TransformPipeline{{Type: "template", Template: `{"count": {{len .items}}}`}})
+}
@@ -0,0 +1,176 @@
+package transformer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mcp2rest/internal/config"
+)
+
+// jsonNumber 匹配 JSON 数字
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// IsCSVContentType 检查 Content-Type 是否为 CSV 或 TSV
+func IsCSVContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/csv" || mediaType == "application/csv" || mediaType == "text/tab-separated-values"
+}
+
+// IsNDJSONContentType 检查 Content-Type 是否为每行一个 JSON 值的格式
+func IsNDJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines", "application/jsonlines":
+		return true
+	}
+	return false
+}
+
+// CSVToJSON 将 CSV 文档转换为对象数组，第一行为列名
+//
+// 缺少的字段不出现在对象中，多出的字段和空列名使用 "column_<序号>" 作为键。
+// TSV (text/tab-separated-values) 使用制表符分隔。值默认为字符串，cfg 可声明列类型或开启类型推断，
+// 声明了类型的列中的空值转换为 null，无法转换的值保留为字符串。
+func CSVToJSON(data []byte, contentType string, cfg *config.CSVConfig) ([]interface{}, error) {
+	if cfg == nil {
+		cfg = &config.CSVConfig{}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "text/tab-separated-values" {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+	if cfg.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(cfg.Delimiter)
+		if size != len(cfg.Delimiter) {
+			return nil, fmt.Errorf("CSV分隔符必须是单个字符: %q", cfg.Delimiter)
+		}
+		reader.Comma = delimiter
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析CSV列名失败: %w", err)
+	}
+	for name, columnType := range cfg.Columns {
+		switch columnType {
+		case "string", "integer", "number", "boolean":
+		default:
+			return nil, fmt.Errorf("CSV列 %s 的类型无效: %s (支持: string, integer, number, boolean)", name, columnType)
+		}
+	}
+
+	rows := []interface{}{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析CSV失败: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(record))
+		for i, value := range record {
+			name := columnName(header, i)
+			row[name] = typedValue(value, cfg.Columns[name], cfg.Infer)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// columnName 返回第 i 列的列名
+func columnName(header []string, i int) string {
+	if i < len(header) && header[i] != "" {
+		return header[i]
+	}
+	return fmt.Sprintf("column_%d", i+1)
+}
+
+// typedValue 按声明的类型转换值，未声明类型时根据 infer 推断
+func typedValue(value, columnType string, infer bool) interface{} {
+	switch columnType {
+	case "string":
+		return value
+	case "integer", "number", "boolean":
+		if value == "" {
+			return nil
+		}
+	default:
+		if !infer || value == "" {
+			return value
+		}
+	}
+
+	switch columnType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	default:
+		// 推断时只接受 JSON 数字的写法，避免把 "007" 之类的编号转换为数字
+		if value == "true" || value == "false" {
+			return value == "true"
+		}
+		if jsonNumber.MatchString(value) {
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return n
+			}
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return value
+}
+
+// NDJSONToJSON 将每行一个 JSON 值的文档转换为数组，忽略空行
+func NDJSONToJSON(data []byte) ([]interface{}, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+
+	values := []interface{}{}
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(text, &value); err != nil {
+			return nil, fmt.Errorf("解析第 %d 行失败: %w", line, err)
+		}
+		values = append(values, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取NDJSON失败: %w", err)
+	}
+	return values, nil
+}
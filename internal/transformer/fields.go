@@ -0,0 +1,60 @@
+package transformer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fieldTree 表示字段投影的路径树，叶子节点表示保留完整的值
+type fieldTree map[string]fieldTree
+
+// transformWithFields 仅保留白名单中的字段，路径使用 "." 分隔，遇到数组时作用于每个元素
+func (t *ResponseTransformer) transformWithFields(input interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("字段列表不能为空")
+	}
+
+	tree := make(fieldTree)
+	for _, field := range fields {
+		node := tree
+		for _, part := range strings.Split(strings.TrimSpace(field), ".") {
+			if part == "" {
+				return nil, fmt.Errorf("无效的字段路径: %s", field)
+			}
+			child, exists := node[part]
+			if !exists {
+				child = make(fieldTree)
+				node[part] = child
+			}
+			node = child
+		}
+	}
+
+	return projectFields(input, tree), nil
+}
+
+// projectFields 按路径树投影数据
+func projectFields(value interface{}, tree fieldTree) interface{} {
+	if len(tree) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(tree))
+		for key, subtree := range tree {
+			if child, exists := v[key]; exists {
+				result[key] = projectFields(child, subtree)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = projectFields(item, tree)
+		}
+		return result
+	default:
+		return value
+	}
+}
@@ -0,0 +1,261 @@
+package transformer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ResponseContext 表示转换时可用的响应上下文
+type ResponseContext struct {
+	StatusCode int
+	Headers    http.Header
+	Params     map[string]interface{}
+	// Sample 通过发起工具调用的 MCP 客户端 (sampling/createMessage) 生成回复，客户端不支持时为 nil
+	Sample func(prompt, input string) (string, error)
+}
+
+// templateFuncs 返回模板可用的辅助函数，参数顺序与 sprig 保持一致以便管道调用
+func templateFuncs(respCtx *ResponseContext) template.FuncMap {
+	if respCtx == nil {
+		respCtx = &ResponseContext{}
+	}
+
+	return template.FuncMap{
+		// 响应上下文
+		"status":  func() int { return respCtx.StatusCode },
+		"header":  func(name string) string { return respCtx.Headers.Get(name) },
+		"headers": func() http.Header { return respCtx.Headers },
+		"params":  func() map[string]interface{} { return respCtx.Params },
+		"param":   func(name string) interface{} { return respCtx.Params[name] },
+		"sample":  func(prompt string, input interface{}) (string, error) { return sample(respCtx, prompt, input) },
+
+		// 默认值
+		"default":  defaultValue,
+		"empty":    isEmpty,
+		"coalesce": coalesce,
+
+		// 字符串
+		"join":      join,
+		"split":     func(sep, s string) []string { return strings.Split(s, sep) },
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"xml":       xmlEscape,
+		"trim":      strings.TrimSpace,
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"truncate":  truncate,
+
+		// 日期
+		"now":  time.Now,
+		"date": formatDate,
+
+		// JSON
+		"toJSON":       toJSON,
+		"toPrettyJSON": toPrettyJSON,
+
+		// 数值
+		"add": func(a, b interface{}) float64 { return toFloat(a) + toFloat(b) },
+		"sub": func(a, b interface{}) float64 { return toFloat(a) - toFloat(b) },
+		"mul": func(a, b interface{}) float64 { return toFloat(a) * toFloat(b) },
+		"div": func(a, b interface{}) float64 {
+			if toFloat(b) == 0 {
+				return 0
+			}
+			return toFloat(a) / toFloat(b)
+		},
+
+		// 集合
+		"first": first,
+		"last":  last,
+		"keys":  keys,
+		"pluck": pluck,
+	}
+}
+
+// defaultValue 值为空时返回默认值
+func defaultValue(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return def
+	}
+	return value[0]
+}
+
+// isEmpty 检查值是否为空
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// coalesce 返回第一个非空值
+func coalesce(values ...interface{}) interface{} {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+// join 使用分隔符连接列表
+func join(sep string, list interface{}) string {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprintf("%v", list)
+	}
+	parts := make([]string, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		parts[i] = fmt.Sprintf("%v", v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// truncate 将字符串截断到指定长度（按字符计算）
+func truncate(length int, s string) string {
+	runes := []rune(s)
+	if length < 0 || len(runes) <= length {
+		return s
+	}
+	return string(runes[:length]) + "..."
+}
+
+// formatDate 格式化时间，支持 time.Time、RFC3339 字符串和 Unix 时间戳（秒）
+func formatDate(layout string, value interface{}) string {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case string:
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return v
+		}
+		t = parsed
+	default:
+		t = time.Unix(int64(toFloat(v)), 0)
+	}
+	return t.Format(layout)
+}
+
+// toJSON 将值序列化为紧凑JSON
+func toJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// toPrettyJSON 将值序列化为格式化JSON
+func toPrettyJSON(value interface{}) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// toFloat 将值转换为浮点数，无法转换时返回 0
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+// first 返回列表的第一个元素
+func first(list interface{}) interface{} {
+	if items, ok := list.([]interface{}); ok && len(items) > 0 {
+		return items[0]
+	}
+	return nil
+}
+
+// last 返回列表的最后一个元素
+func last(list interface{}) interface{} {
+	if items, ok := list.([]interface{}); ok && len(items) > 0 {
+		return items[len(items)-1]
+	}
+	return nil
+}
+
+// keys 返回对象按字母排序的键
+func keys(object interface{}) []string {
+	m, ok := object.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// pluck 从对象列表中提取指定字段
+func pluck(key string, list interface{}) []interface{} {
+	items, ok := list.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			if value, exists := m[key]; exists {
+				result = append(result, value)
+			}
+		}
+	}
+	return result
+}
+
+// sample 请求客户端按提示词处理输入，非字符串的输入先编码为 JSON
+func sample(respCtx *ResponseContext, prompt string, input interface{}) (string, error) {
+	if respCtx.Sample == nil {
+		return "", fmt.Errorf("sample: 客户端不支持 sampling")
+	}
+	text, ok := input.(string)
+	if !ok {
+		text = toJSON(input)
+	}
+	return respCtx.Sample(prompt, text)
+}
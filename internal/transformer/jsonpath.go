@@ -0,0 +1,231 @@
+package transformer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPathSegment 表示 JSONPath 中的一段选择器
+type jsonPathSegment struct {
+	key       string // 成员名称
+	index     *int   // 数组下标，支持负数
+	slice     *[2]*int
+	wildcard  bool
+	recursive bool // 递归下降 (..)
+}
+
+// transformWithJSONPath 使用 JSONPath 选择响应中的数据
+// 支持 $、.name、['name']、[n]、[start:end]、[*]、.* 和 ..name，不支持过滤表达式
+func (t *ResponseTransformer) transformWithJSONPath(input interface{}, expression string) (interface{}, error) {
+	if expression == "" {
+		return nil, fmt.Errorf("JSONPath表达式不能为空")
+	}
+
+	segments, err := parseJSONPath(expression)
+	if err != nil {
+		return nil, fmt.Errorf("解析JSONPath表达式失败: %w", err)
+	}
+
+	results := []interface{}{input}
+	definite := true
+	for _, segment := range segments {
+		if segment.wildcard || segment.recursive || segment.slice != nil {
+			definite = false
+		}
+		var next []interface{}
+		for _, value := range results {
+			next = append(next, segment.apply(value)...)
+		}
+		results = next
+	}
+
+	// 确定路径返回单个值，否则返回数组
+	if definite {
+		if len(results) == 0 {
+			return nil, nil
+		}
+		return results[0], nil
+	}
+	if results == nil {
+		results = []interface{}{}
+	}
+	return results, nil
+}
+
+// parseJSONPath 将表达式解析为选择器序列
+func parseJSONPath(expression string) ([]jsonPathSegment, error) {
+	path := strings.TrimSpace(expression)
+	path = strings.TrimPrefix(path, "$")
+
+	var segments []jsonPathSegment
+	for len(path) > 0 {
+		switch {
+		case strings.HasPrefix(path, ".."):
+			path = path[2:]
+			name, rest := readJSONPathName(path)
+			if name == "" {
+				return nil, fmt.Errorf("递归下降后缺少成员名称")
+			}
+			segments = append(segments, jsonPathSegment{key: name, recursive: true, wildcard: name == "*"})
+			path = rest
+		case path[0] == '.':
+			path = path[1:]
+			name, rest := readJSONPathName(path)
+			if name == "" {
+				return nil, fmt.Errorf("'.' 后缺少成员名称")
+			}
+			if name == "*" {
+				segments = append(segments, jsonPathSegment{wildcard: true})
+			} else {
+				segments = append(segments, jsonPathSegment{key: name})
+			}
+			path = rest
+		case path[0] == '[':
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("缺少 ']'")
+			}
+			segment, err := parseJSONPathBracket(strings.TrimSpace(path[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment)
+			path = path[end+1:]
+		default:
+			// 允许省略开头的 $ 和 .
+			name, rest := readJSONPathName(path)
+			if name == "" {
+				return nil, fmt.Errorf("无法识别的字符: %q", path[0])
+			}
+			segments = append(segments, jsonPathSegment{key: name})
+			path = rest
+		}
+	}
+	return segments, nil
+}
+
+// readJSONPathName 读取成员名称，直到遇到 '.' 或 '['
+func readJSONPathName(path string) (string, string) {
+	end := strings.IndexAny(path, ".[")
+	if end < 0 {
+		return path, ""
+	}
+	return path[:end], path[end:]
+}
+
+// parseJSONPathBracket 解析方括号中的选择器
+func parseJSONPathBracket(content string) (jsonPathSegment, error) {
+	if content == "*" {
+		return jsonPathSegment{wildcard: true}, nil
+	}
+
+	if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+		return jsonPathSegment{key: content[1 : len(content)-1]}, nil
+	}
+
+	if strings.Contains(content, ":") {
+		parts := strings.SplitN(content, ":", 2)
+		var bounds [2]*int
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return jsonPathSegment{}, fmt.Errorf("无效的切片: [%s]", content)
+			}
+			bounds[i] = &n
+		}
+		return jsonPathSegment{slice: &bounds}, nil
+	}
+
+	n, err := strconv.Atoi(content)
+	if err != nil {
+		return jsonPathSegment{}, fmt.Errorf("不支持的选择器: [%s]", content)
+	}
+	return jsonPathSegment{index: &n}, nil
+}
+
+// apply 对单个值执行选择器，返回所有匹配结果
+func (s jsonPathSegment) apply(value interface{}) []interface{} {
+	if s.recursive {
+		var results []interface{}
+		collectRecursive(value, s.key, s.wildcard, &results)
+		return results
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			results := make([]interface{}, 0, len(v))
+			for _, child := range v {
+				results = append(results, child)
+			}
+			return results
+		}
+		if child, exists := v[s.key]; exists && s.index == nil && s.slice == nil {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		switch {
+		case s.wildcard:
+			return v
+		case s.index != nil:
+			i := *s.index
+			if i < 0 {
+				i += len(v)
+			}
+			if i >= 0 && i < len(v) {
+				return []interface{}{v[i]}
+			}
+		case s.slice != nil:
+			start, end := 0, len(v)
+			if s.slice[0] != nil {
+				start = clampIndex(*s.slice[0], len(v))
+			}
+			if s.slice[1] != nil {
+				end = clampIndex(*s.slice[1], len(v))
+			}
+			if start < end {
+				return v[start:end]
+			}
+		}
+	}
+	return nil
+}
+
+// collectRecursive 递归收集所有名称匹配的成员
+func collectRecursive(value interface{}, key string, wildcard bool, results *[]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			if wildcard || name == key {
+				*results = append(*results, child)
+			}
+			collectRecursive(child, key, wildcard, results)
+		}
+	case []interface{}:
+		for _, child := range v {
+			if wildcard {
+				*results = append(*results, child)
+			}
+			collectRecursive(child, key, wildcard, results)
+		}
+	}
+}
+
+// clampIndex 将切片下标限制在有效范围内，负数表示从末尾计算
+func clampIndex(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+	return i
+}
@@ -0,0 +1,100 @@
+package transformer
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/mcp2rest/internal/paths"
+)
+
+// customPrefix 自定义转换类型的前缀，规范中写作 "custom:<name>"
+const customPrefix = "custom:"
+
+// TransformFunc 自定义转换函数，args 来自转换步骤的 args 字段
+type TransformFunc func(input interface{}, args map[string]interface{}) (interface{}, error)
+
+var (
+	customTransforms = make(map[string]TransformFunc)
+	customMutex      sync.RWMutex
+
+	// loadedPlugins 已加载的插件路径，同一进程中多次创建处理器 (diff 命令、嵌入的多个网关) 时不再重复注册
+	loadedPlugins = make(map[string]bool)
+	pluginMutex   sync.Mutex
+)
+
+// RegisterTransform 注册自定义转换函数，供嵌入方在创建服务器前调用
+func RegisterTransform(name string, fn TransformFunc) error {
+	if name == "" {
+		return fmt.Errorf("自定义转换名称不能为空")
+	}
+	if fn == nil {
+		return fmt.Errorf("自定义转换 %s 的函数不能为空", name)
+	}
+
+	customMutex.Lock()
+	defer customMutex.Unlock()
+
+	if _, exists := customTransforms[name]; exists {
+		return fmt.Errorf("自定义转换 %s 已注册", name)
+	}
+	customTransforms[name] = fn
+	return nil
+}
+
+// getCustomTransform 获取已注册的自定义转换函数
+func getCustomTransform(name string) (TransformFunc, bool) {
+	customMutex.RLock()
+	defer customMutex.RUnlock()
+
+	fn, exists := customTransforms[name]
+	return fn, exists
+}
+
+// LoadPlugin 从 Go 插件加载自定义转换函数，同一插件只加载一次，再次调用时直接返回
+// 插件需要导出变量 Transforms，类型为 map[string]func(interface{}, map[string]interface{}) (interface{}, error)
+func LoadPlugin(path string) error {
+	resolved := paths.Resolve(path)
+	if abs, err := filepath.Abs(resolved); err == nil {
+		resolved = abs
+	}
+
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+	if loadedPlugins[resolved] {
+		return nil
+	}
+
+	p, err := plugin.Open(resolved)
+	if err != nil {
+		return fmt.Errorf("打开转换插件 %s 失败: %w", path, err)
+	}
+
+	symbol, err := p.Lookup("Transforms")
+	if err != nil {
+		return fmt.Errorf("转换插件 %s 未导出 Transforms: %w", path, err)
+	}
+
+	transforms, ok := symbol.(*map[string]func(interface{}, map[string]interface{}) (interface{}, error))
+	if !ok {
+		return fmt.Errorf("转换插件 %s 的 Transforms 类型不正确: %T", path, symbol)
+	}
+
+	for name, fn := range *transforms {
+		if err := RegisterTransform(name, fn); err != nil {
+			return fmt.Errorf("注册转换插件 %s 失败: %w", path, err)
+		}
+	}
+	loadedPlugins[resolved] = true
+	return nil
+}
+
+// isCustomTransform 检查转换类型是否为自定义转换
+func isCustomTransform(transformType string) (string, bool) {
+	if !strings.HasPrefix(transformType, customPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(transformType, customPrefix), true
+}
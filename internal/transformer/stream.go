@@ -0,0 +1,49 @@
+package transformer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"mime"
+)
+
+// Docker Engine API 日志和 attach 接口的流格式 (API 1.42 起在 Content-Type 中声明)
+const (
+	dockerMultiplexedStream = "application/vnd.docker.multiplexed-stream"
+	dockerRawStream         = "application/vnd.docker.raw-stream"
+)
+
+// dockerFrameHeader 多路复用流中每帧的头部长度: 1 字节流类型、3 字节填充、4 字节大端长度
+const dockerFrameHeader = 8
+
+// IsDockerStreamContentType 检查 Content-Type 是否为 Docker 的日志流
+func IsDockerStreamContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == dockerMultiplexedStream || mediaType == dockerRawStream
+}
+
+// DockerStreamToText 把 Docker 日志流转换为文本
+// 未分配 TTY 的容器使用多路复用流，按帧顺序拼接标准输出和标准错误；分配了 TTY 的容器使用原始流，原样返回
+func DockerStreamToText(data []byte, contentType string) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != dockerMultiplexedStream {
+		return string(data), nil
+	}
+
+	var text []byte
+	for len(data) > 0 {
+		if len(data) < dockerFrameHeader {
+			return "", fmt.Errorf("多路复用流的帧头不完整: 剩余 %d 字节", len(data))
+		}
+		size := binary.BigEndian.Uint32(data[4:dockerFrameHeader])
+		data = data[dockerFrameHeader:]
+		if uint64(size) > uint64(len(data)) {
+			return "", fmt.Errorf("多路复用流的帧不完整: 声明 %d 字节，剩余 %d 字节", size, len(data))
+		}
+		text = append(text, data[:size]...)
+		data = data[size:]
+	}
+	return string(text), nil
+}
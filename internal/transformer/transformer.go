@@ -18,18 +18,72 @@ func NewResponseTransformer() (*ResponseTransformer, error) {
 	return &ResponseTransformer{}, nil
 }
 
-// TransformResponse 转换API响应，基于OpenAPI响应定义
-func (t *ResponseTransformer) TransformResponse(data []byte, responses map[string]config.Response) (interface{}, error) {
-	// 直接返回JSON解析后的响应
+// TransformResponse 转换API响应，依次执行操作上配置的转换步骤
+func (t *ResponseTransformer) TransformResponse(data []byte, pipeline config.TransformPipeline, respCtx *ResponseContext) (interface{}, error) {
+	// 空响应体 (如 204 No Content) 作为 null 处理
 	var result interface{}
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("解析JSON响应失败: %w", err)
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("解析JSON响应失败: %w", err)
+		}
+	}
+
+	return t.TransformValue(result, pipeline, respCtx)
+}
+
+// TransformValue 对已解析的数据依次执行转换步骤，未配置转换时原样返回
+func (t *ResponseTransformer) TransformValue(result interface{}, pipeline config.TransformPipeline, respCtx *ResponseContext) (interface{}, error) {
+	for i, step := range pipeline {
+		transformed, err := t.applyTransform(result, step, respCtx)
+		if err != nil {
+			return nil, fmt.Errorf("执行第 %d 个转换步骤 (%s) 失败: %w", i+1, step.Type, err)
+		}
+		result = transformed
 	}
+
 	return result, nil
 }
 
+// RenderMessage 使用模板渲染消息，"." 为响应数据，可使用与模板转换相同的辅助函数
+func (t *ResponseTransformer) RenderMessage(messageTemplate string, data interface{}, respCtx *ResponseContext) (string, error) {
+	tmpl, err := template.New("message").Funcs(templateFuncs(respCtx)).Parse(messageTemplate)
+	if err != nil {
+		return "", fmt.Errorf("解析消息模板失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("执行消息模板失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// applyTransform 执行单个转换步骤
+func (t *ResponseTransformer) applyTransform(input interface{}, step config.TransformConfig, respCtx *ResponseContext) (interface{}, error) {
+	switch step.Type {
+	case "jq":
+		return t.transformWithJQ(input, step.Expression)
+	case "template":
+		return t.transformWithTemplate(input, step.Template, respCtx)
+	case "jsonpath":
+		return t.transformWithJSONPath(input, step.Expression)
+	case "fields":
+		return t.transformWithFields(input, step.Fields)
+	}
+
+	if name, ok := isCustomTransform(step.Type); ok {
+		fn, exists := getCustomTransform(name)
+		if !exists {
+			return nil, fmt.Errorf("未注册的自定义转换: %s", name)
+		}
+		return fn(input, step.Args)
+	}
+
+	return nil, fmt.Errorf("不支持的转换类型: %s", step.Type)
+}
+
 // transformWithJQ 使用JQ表达式转换响应
-func (t *ResponseTransformer) transformWithJQ(data []byte, expression string) (interface{}, error) {
+func (t *ResponseTransformer) transformWithJQ(input interface{}, expression string) (interface{}, error) {
 	if expression == "" {
 		return nil, fmt.Errorf("JQ表达式不能为空")
 	}
@@ -40,15 +94,9 @@ func (t *ResponseTransformer) transformWithJQ(data []byte, expression string) (i
 		return nil, fmt.Errorf("解析JQ表达式失败: %w", err)
 	}
 
-	// 解析JSON数据
-	var input interface{}
-	if err := json.Unmarshal(data, &input); err != nil {
-		return nil, fmt.Errorf("解析JSON数据失败: %w", err)
-	}
-
-	// 执行JQ查询
+	// 执行JQ查询，多个输出时返回数组
 	iter := query.Run(input)
-	var result interface{}
+	var results []interface{}
 	for {
 		v, ok := iter.Next()
 		if !ok {
@@ -57,33 +105,35 @@ func (t *ResponseTransformer) transformWithJQ(data []byte, expression string) (i
 		if err, ok := v.(error); ok {
 			return nil, fmt.Errorf("执行JQ表达式失败: %w", err)
 		}
-		result = v
+		results = append(results, v)
 	}
 
-	return result, nil
+	switch len(results) {
+	case 0:
+		return nil, nil
+	case 1:
+		return results[0], nil
+	default:
+		return results, nil
+	}
 }
 
-// transformWithTemplate 使用模板转换响应
-func (t *ResponseTransformer) transformWithTemplate(data []byte, templateStr string) (interface{}, error) {
+// transformWithTemplate 使用模板转换响应，模板中 "." 为响应数据，
+// 通过 status、header、params 等函数访问响应状态码、响应头和请求参数
+func (t *ResponseTransformer) transformWithTemplate(input interface{}, templateStr string, respCtx *ResponseContext) (interface{}, error) {
 	if templateStr == "" {
 		return nil, fmt.Errorf("模板字符串不能为空")
 	}
 
-	// 解析JSON数据
-	var jsonData interface{}
-	if err := json.Unmarshal(data, &jsonData); err != nil {
-		return nil, fmt.Errorf("解析JSON数据失败: %w", err)
-	}
-
 	// 解析模板
-	tmpl, err := template.New("response").Parse(templateStr)
+	tmpl, err := template.New("response").Funcs(templateFuncs(respCtx)).Parse(templateStr)
 	if err != nil {
 		return nil, fmt.Errorf("解析模板失败: %w", err)
 	}
 
 	// 执行模板
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, jsonData); err != nil {
+	if err := tmpl.Execute(&buf, input); err != nil {
 		return nil, fmt.Errorf("执行模板失败: %w", err)
 	}
 
@@ -95,4 +145,4 @@ func (t *ResponseTransformer) transformWithTemplate(data []byte, templateStr str
 	}
 
 	return result, nil
-}
\ No newline at end of file
+}
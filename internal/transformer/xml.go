@@ -0,0 +1,119 @@
+package transformer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// IsXMLContentType 检查 Content-Type 是否为 XML (包括 SOAP 和 +xml 类型)
+func IsXMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// xmlNode 表示解析过程中的 XML 元素
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     strings.Builder
+}
+
+// XMLToJSON 将 XML 文档转换为可供转换器处理的 JSON 值
+//
+// 元素转换为对象，属性使用 "@" 前缀，元素同时包含文本和子元素时文本放在 "#text" 中，
+// 只有文本的元素直接转换为字符串，同名的兄弟元素合并为数组。命名空间前缀会被忽略。
+func XMLToJSON(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析XML失败: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("XML文档没有根元素")
+	}
+	return map[string]interface{}{root.name: root.value()}, nil
+}
+
+// value 将元素转换为 JSON 值
+func (n *xmlNode) value() interface{} {
+	text := strings.TrimSpace(n.text.String())
+
+	var attrs []xml.Attr
+	for _, attr := range n.attrs {
+		// 命名空间声明不属于数据
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+
+	if len(attrs) == 0 && len(n.children) == 0 {
+		return text
+	}
+
+	object := make(map[string]interface{})
+	for _, attr := range attrs {
+		object["@"+attr.Name.Local] = attr.Value
+	}
+	for _, child := range n.children {
+		value := child.value()
+		if existing, exists := object[child.name]; exists {
+			if list, ok := existing.([]interface{}); ok {
+				object[child.name] = append(list, value)
+			} else {
+				object[child.name] = []interface{}{existing, value}
+			}
+		} else {
+			object[child.name] = value
+		}
+	}
+	if text != "" {
+		object["#text"] = text
+	}
+	return object
+}
+
+// xmlEscape 转义 XML 文本和属性值
+func xmlEscape(value interface{}) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(fmt.Sprint(value)))
+	return buf.String()
+}
@@ -0,0 +1,6 @@
+// Package version 记录 mcp2rest 的版本，发布构建时通过
+// -ldflags "-X github.com/mcp2rest/internal/version.Version=1.2.3" 设置
+package version
+
+// Version mcp2rest 的版本
+var Version = "1.0.0"
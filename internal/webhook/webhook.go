@@ -0,0 +1,282 @@
+// Package webhook 接收上游系统通过 POST /webhooks/<name> 推送的事件
+//
+// 每个 Webhook 对应一个 MCP 资源，收到的事件缓存在该资源中供智能体轮询读取，
+// 同时由服务器向订阅了该资源的会话发送 notifications/resources/updated。
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/transformer"
+)
+
+const (
+	// URIScheme 默认事件资源 URI 的前缀，完整形式为 events://webhooks/<name>
+	URIScheme = "events://webhooks/"
+
+	defaultBuffer          = 100
+	defaultSignatureHeader = "X-Hub-Signature-256"
+	defaultMaxSkew         = 5 * time.Minute
+
+	// NotifySubscribers 只通知订阅了资源的会话 (默认)
+	NotifySubscribers = "subscribers"
+	// NotifyAll 通知所有会话，适用于不支持 resources/subscribe 的客户端
+	NotifyAll = "all"
+	// NotifyNone 只缓存事件，不发送通知
+	NotifyNone = "none"
+)
+
+var (
+	// ErrNotFound 未配置该名称的 Webhook
+	ErrNotFound = errors.New("未找到 Webhook")
+	// ErrUnauthorized 签名缺失或不匹配
+	ErrUnauthorized = errors.New("Webhook 签名校验失败")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Event 收到的一条事件
+type Event struct {
+	Seq        int64       `json:"seq"`
+	ReceivedAt time.Time   `json:"received_at"`
+	Payload    interface{} `json:"payload"`
+}
+
+// endpoint 单个 Webhook 的配置和事件缓存
+type endpoint struct {
+	config config.WebhookConfig
+	uri    string
+
+	mu     sync.Mutex
+	events []Event
+	seq    int64
+}
+
+// Hub 管理所有配置的 Webhook
+type Hub struct {
+	transformer *transformer.ResponseTransformer
+	byName      map[string]*endpoint
+	byURI       map[string]*endpoint
+}
+
+// NewHub 根据配置创建 Hub，名称重复或通知方式无效时返回错误
+func NewHub(webhooks []config.WebhookConfig) (*Hub, error) {
+	t, err := transformer.NewResponseTransformer()
+	if err != nil {
+		return nil, err
+	}
+	hub := &Hub{
+		transformer: t,
+		byName:      make(map[string]*endpoint),
+		byURI:       make(map[string]*endpoint),
+	}
+
+	for _, cfg := range webhooks {
+		if !validName.MatchString(cfg.Name) {
+			return nil, fmt.Errorf("无效的 Webhook 名称: %q (只能包含字母、数字、- 和 _)", cfg.Name)
+		}
+		if _, exists := hub.byName[cfg.Name]; exists {
+			return nil, fmt.Errorf("Webhook 名称重复: %s", cfg.Name)
+		}
+		switch cfg.Notify {
+		case "":
+			cfg.Notify = NotifySubscribers
+		case NotifySubscribers, NotifyAll, NotifyNone:
+		default:
+			return nil, fmt.Errorf("Webhook %s 的通知方式无效: %s (支持: subscribers, all, none)", cfg.Name, cfg.Notify)
+		}
+		if cfg.Buffer <= 0 {
+			cfg.Buffer = defaultBuffer
+		}
+		if cfg.SignatureHeader == "" {
+			cfg.SignatureHeader = defaultSignatureHeader
+		}
+		if cfg.MaxSkew <= 0 {
+			cfg.MaxSkew = defaultMaxSkew
+		}
+
+		ep := &endpoint{config: cfg, uri: cfg.URI}
+		if ep.uri == "" {
+			ep.uri = URIScheme + cfg.Name
+		}
+		if _, exists := hub.byURI[ep.uri]; exists {
+			return nil, fmt.Errorf("Webhook 资源 URI 重复: %s", ep.uri)
+		}
+		hub.byName[cfg.Name] = ep
+		hub.byURI[ep.uri] = ep
+	}
+	return hub, nil
+}
+
+// Empty 是否没有配置任何 Webhook
+func (h *Hub) Empty() bool {
+	return len(h.byName) == 0
+}
+
+// Receive 校验并缓存一条事件，返回事件、对应的资源 URI 和通知方式
+func (h *Hub) Receive(name string, body []byte, header http.Header) (*Event, string, string, error) {
+	ep, exists := h.byName[name]
+	if !exists {
+		return nil, "", "", ErrNotFound
+	}
+
+	if ep.config.Secret != "" {
+		if err := ep.verify(body, header); err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	// JSON 事件按原样保存，其他内容保存为字符串
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		payload = string(body)
+	}
+	if len(ep.config.Transform) > 0 {
+		transformed, err := h.transformer.TransformValue(payload, ep.config.Transform, nil)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("转换 Webhook 事件失败: %w", err)
+		}
+		payload = transformed
+	}
+
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	ep.seq++
+	event := Event{Seq: ep.seq, ReceivedAt: time.Now(), Payload: payload}
+	ep.events = append(ep.events, event)
+	if len(ep.events) > ep.config.Buffer {
+		ep.events = ep.events[len(ep.events)-ep.config.Buffer:]
+	}
+	return &event, ep.uri, ep.config.Notify, nil
+}
+
+// verify 校验签名；配置了时间戳头时签名内容包括时间戳，并检查时间戳与本地时钟的偏差
+func (ep *endpoint) verify(body []byte, header http.Header) error {
+	signed := body
+	if ep.config.TimestampHeader != "" {
+		value := strings.TrimSpace(header.Get(ep.config.TimestampHeader))
+		timestamp, err := parseTimestamp(value)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrUnauthorized, ep.config.TimestampHeader, err)
+		}
+		// 偏差较大通常是发送方或本机的时钟不准，错误消息中给出偏差便于排查
+		if skew := time.Since(timestamp); skew > ep.config.MaxSkew || skew < -ep.config.MaxSkew {
+			return fmt.Errorf("%w: 时间戳与本地时钟相差 %s，超过允许的 %s，请检查双方的时钟", ErrUnauthorized, skew.Round(time.Second), ep.config.MaxSkew)
+		}
+		signed = append([]byte(value+"."), body...)
+	}
+	if !validSignature(ep.config.Secret, signed, header.Get(ep.config.SignatureHeader)) {
+		return ErrUnauthorized
+	}
+	return nil
+}
+
+// parseTimestamp 解析 Unix 秒或 RFC 3339 格式的时间戳
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("缺少时间戳")
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的时间戳: %s", value)
+	}
+	return t, nil
+}
+
+// validSignature 校验 HMAC-SHA256 签名，签名可带 "sha256=" 前缀
+func validSignature(secret string, body []byte, signature string) bool {
+	signature = strings.TrimPrefix(strings.TrimSpace(signature), "sha256=")
+	provided, err := hex.DecodeString(signature)
+	if err != nil || len(provided) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(provided, mac.Sum(nil))
+}
+
+// Resources 返回 resources/list 中列出的事件资源
+func (h *Hub) Resources() []map[string]interface{} {
+	names := make([]string, 0, len(h.byName))
+	for name := range h.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resources := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		ep := h.byName[name]
+		description := ep.config.Description
+		if description == "" {
+			description = fmt.Sprintf("Webhook %s 收到的最近 %d 条事件，读取时可通过 ?since=<seq> 只获取新事件", name, ep.config.Buffer)
+		}
+		resources = append(resources, map[string]interface{}{
+			"uri":         ep.uri,
+			"name":        "webhook-" + name,
+			"description": description,
+			"mimeType":    "application/json",
+		})
+	}
+	return resources
+}
+
+// Has 是否存在该 URI 对应的事件资源 (忽略查询参数)
+func (h *Hub) Has(uri string) bool {
+	base, _, _ := strings.Cut(uri, "?")
+	_, exists := h.byURI[base]
+	return exists
+}
+
+// Read 读取事件资源，URI 可带 ?since=<seq> 只返回序号更大的事件
+func (h *Hub) Read(ctx context.Context, uri string) (map[string]interface{}, error) {
+	base, query, _ := strings.Cut(uri, "?")
+	ep, exists := h.byURI[base]
+	if !exists {
+		return nil, fmt.Errorf("资源不存在: %s", uri)
+	}
+
+	var since int64
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("无效的资源查询参数: %w", err)
+		}
+		if value := values.Get("since"); value != "" {
+			if since, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return nil, fmt.Errorf("无效的 since 参数: %s", value)
+			}
+		}
+	}
+
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	events := []Event{}
+	for _, event := range ep.events {
+		if event.Seq > since {
+			events = append(events, event)
+		}
+	}
+	return map[string]interface{}{
+		"webhook":  ep.config.Name,
+		"last_seq": ep.seq,
+		"events":   events,
+	}, nil
+}
@@ -0,0 +1,240 @@
+// Package gateway 把 mcp2rest 嵌入其他 Go 程序，无需启动 mcp2rest-stdio / mcp2rest-sse 子进程
+//
+// 典型用法:
+//
+//	spec, _ := gateway.LoadSpec("configs/api.yaml")
+//	cfg, _ := gateway.LoadConfig("configs/stdio.yaml")
+//	gw, _ := gateway.NewGateway(cfg, spec)
+//	defer gw.Close()
+//	result, err := gw.CallTool(ctx, "getUser", map[string]interface{}{"id": "1"})
+//
+// 配置与命令行程序相同，Config 和 Spec 的字段见服务器配置文件和 OpenAPI 扩展的说明。
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/mcp2rest/internal/config"
+	"github.com/mcp2rest/internal/handler"
+	"github.com/mcp2rest/internal/logging"
+	"github.com/mcp2rest/internal/openapi"
+	"github.com/mcp2rest/internal/quota"
+	"github.com/mcp2rest/internal/server"
+	"github.com/mcp2rest/pkg/mcp"
+)
+
+type (
+	// Config 服务器配置，对应服务器配置文件 (stdio.yaml / sse.yaml) 的 server 和 global 部分
+	Config = config.Config
+	// Spec 解析后的 OpenAPI 规范
+	Spec = config.OpenAPISpec
+	// ToolResult 工具调用的结果，Type 为 "error" 表示工具返回了错误
+	ToolResult = mcp.ToolCallResult
+	// ToolCallParams 工具调用的名称和参数
+	ToolCallParams = mcp.ToolCallParams
+	// ToolHandler 处理一次工具调用
+	ToolHandler = handler.ToolHandler
+	// Middleware 包装工具调用的中间件，见 Gateway.Use
+	Middleware = handler.Middleware
+	// Backend 执行操作的后端，见 Gateway.RegisterBackend
+	Backend = handler.Backend
+	// BackendFunc 把函数适配为 Backend
+	BackendFunc = handler.BackendFunc
+	// BackendCall 交给执行后端的请求，包含工具名称、参数和已构建的上游请求
+	BackendCall = handler.BackendCall
+
+	// ToolForbiddenError 访问控制 (global.access.stdio_client) 不允许调用该工具
+	ToolForbiddenError = server.ToolForbiddenError
+	// RateLimitedError 超过客户端的调用频率限制
+	RateLimitedError = server.RateLimitedError
+	// QuotaExceededError 超过 global.quotas 中的调用配额
+	QuotaExceededError = quota.ExceededError
+	// ValidationError 工具参数不符合规范，Violations 逐个列出有问题的参数
+	ValidationError = handler.ValidationError
+	// ParamViolation 一个不符合规范的参数，包括违反的约束和规范中的模式摘录
+	ParamViolation = handler.ParamViolation
+	// PanicError 工具调用处理 (包括中间件和执行后端) 中发生了 panic，调用栈已记录到日志
+	PanicError = handler.PanicError
+	// SamplingRequest 通过 MCP 客户端生成回复的请求，见 Sample
+	SamplingRequest = handler.SamplingRequest
+)
+
+// ErrShuttingDown 客户端发送了 exit，网关不再接受新的工具调用
+var ErrShuttingDown = server.ErrShuttingDown
+
+// ErrSamplingUnsupported 发起工具调用的客户端不支持 sampling，直接通过 CallTool 发起的调用没有客户端
+var ErrSamplingUnsupported = handler.ErrSamplingUnsupported
+
+// Sample 在中间件或执行后端中通过 sampling/createMessage 请求发起工具调用的 MCP 客户端生成回复，ctx 为工具调用的上下文
+func Sample(ctx context.Context, request SamplingRequest) (string, error) {
+	return handler.Sample(ctx, request)
+}
+
+// SamplingAvailable 检查发起工具调用的 MCP 客户端是否支持 sampling
+func SamplingAvailable(ctx context.Context) bool {
+	return handler.SamplingAvailable(ctx)
+}
+
+// Gateway 嵌入的 mcp2rest 实例
+type Gateway struct {
+	server *server.Server
+	config *Config
+}
+
+// HandlerOptions HTTP (SSE) 端点的选项
+type HandlerOptions struct {
+	// Addr ServeHTTP 监听的地址，默认使用配置中的 server.host 和 server.port
+	Addr string
+	// Prefix 端点挂载的路径前缀 (如 "/mcp")，端点为 <Prefix>/sse 和 <Prefix>/messages/
+	Prefix string
+}
+
+// SetLogger 设置网关的日志输出，未设置时 NewGateway 把日志写入标准错误
+func SetLogger(logger *log.Logger) {
+	logging.Logger = logger
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	server, global := config.GetDefaultServerConfig()
+	return &Config{Server: *server, Global: *global}
+}
+
+// LoadConfig 从服务器配置文件加载配置，MCP2REST_* 环境变量同样生效
+func LoadConfig(path string) (*Config, error) {
+	server, global, err := config.LoadServerConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{Server: *server, Global: *global}, nil
+}
+
+// LoadSpec 从文件加载 OpenAPI 规范，支持 YAML 和 JSON
+func LoadSpec(path string) (*Spec, error) {
+	return openapi.ParseOpenAPISpec(path)
+}
+
+// ParseSpec 解析 OpenAPI 规范，format 为 "yaml" 或 "json"
+func ParseSpec(data []byte, format string) (*Spec, error) {
+	return openapi.ParseOpenAPISpecData(data, format)
+}
+
+// NewGateway 根据配置和规范创建网关，创建后即可通过 CallTool 调用工具，
+// 需要对外提供 MCP 服务时再调用 ServeStdio 或 ServeHTTP
+func NewGateway(cfg *Config, spec *Spec) (*Gateway, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if spec == nil {
+		return nil, errors.New("缺少 OpenAPI 规范")
+	}
+	if logging.Logger == nil {
+		logging.InitServiceLogger()
+	}
+	srv, err := server.NewServer(cfg, spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Gateway{server: srv, config: cfg}, nil
+}
+
+// CallTool 在进程内调用工具，访问控制、频率限制和配额按标准输入/输出客户端检查
+func (g *Gateway) CallTool(ctx context.Context, name string, params map[string]interface{}) (*ToolResult, error) {
+	return g.server.CallTool(ctx, name, params)
+}
+
+// Use 注册工具调用中间件，用于注入自定义的策略 (审计、参数改写、额外的鉴权等)，应在开始提供服务之前调用
+// 中间件在内置的日志、访问控制、频率限制和统计之后执行，先注册的在外层；
+// 通过 CallTool、MCP 客户端的 tools/call 和定时任务发起的调用都会经过中间件
+func (g *Gateway) Use(middleware ...Middleware) {
+	g.server.Use(middleware...)
+}
+
+// RegisterBackend 注册具名的执行后端，规范中 x-mcp-backend 为该名称的操作不再请求上游，而是由 backend 执行，
+// 返回的 HTTP 响应与上游响应一样经过错误映射和转换；与 global.backends 中的后端同名时替换之，应在开始提供服务之前调用
+func (g *Gateway) RegisterBackend(name string, backend Backend) {
+	g.server.RegisterBackend(name, backend)
+}
+
+// Tools 返回可以调用的工具，与 tools/list 的结果一致
+func (g *Gateway) Tools(ctx context.Context) []map[string]interface{} {
+	return g.server.Tools(ctx)
+}
+
+// ServeStdio 在进程的标准输入和标准输出上提供 MCP 服务，直到输入结束或 ctx 被取消
+func (g *Gateway) ServeStdio(ctx context.Context) error {
+	return g.ServeStream(ctx, os.Stdin, os.Stdout)
+}
+
+// ServeStream 在给定的流上提供标准输入/输出模式的 MCP 服务，直到输入结束或 ctx 被取消
+// ctx 被取消时立即返回，阻塞在 in 上的读取在下一次读取返回后结束
+func (g *Gateway) ServeStream(ctx context.Context, in io.Reader, out io.Writer) error {
+	stop := g.cancelOnDone(ctx)
+	defer stop()
+	return g.server.ServeStdio(in, out)
+}
+
+// Handler 返回 SSE 模式的 HTTP 端点，供挂载到已有的 HTTP 服务器，opts.Addr 不使用
+func (g *Gateway) Handler(opts HandlerOptions) (http.Handler, error) {
+	return g.server.HTTPHandler(opts.Prefix)
+}
+
+// ServeHTTP 监听 opts.Addr 并提供 SSE 模式的 MCP 服务，直到 ctx 被取消
+func (g *Gateway) ServeHTTP(ctx context.Context, opts HandlerOptions) error {
+	handler, err := g.Handler(opts)
+	if err != nil {
+		return err
+	}
+	mux := http.Handler(handler)
+	if opts.Prefix != "" {
+		serveMux := http.NewServeMux()
+		serveMux.Handle(opts.Prefix+"/", handler)
+		mux = serveMux
+	}
+
+	addr := opts.Addr
+	if addr == "" {
+		addr = fmt.Sprintf("%s:%d", g.config.Server.Host, g.config.Server.Port)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", addr, err)
+	}
+	httpServer := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		g.server.Cancel()
+		httpServer.Close()
+	}()
+	if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Close 停止网关，取消正在执行的调用并保存工具调用统计
+func (g *Gateway) Close() error {
+	g.server.Cancel()
+	return nil
+}
+
+// cancelOnDone ctx 被取消时停止服务器，返回的函数用于在服务结束后停止监视
+func (g *Gateway) cancelOnDone(ctx context.Context) func() {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			g.server.Cancel()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// 客户端能力名称，嵌套的能力用点号分隔
+const (
+	CapabilityRoots                = "roots"
+	CapabilityRootsListChanged     = "roots.listChanged"
+	CapabilitySampling             = "sampling"
+	CapabilityElicitation          = "elicitation"
+	CapabilityToolsListChanged     = "tools.listChanged"
+	CapabilityResourcesListChanged = "resources.listChanged"
+)
+
+// ClientCapabilities 表示客户端在 initialize 中声明的能力 (params.capabilities)
+// 保留客户端发送的原始内容，未识别的能力 (包括 experimental) 也可以通过 Has 查询
+type ClientCapabilities map[string]interface{}
+
+// ParseClientCapabilities 解析 initialize 参数中的 capabilities，缺少或格式错误时返回空的能力集合
+func ParseClientCapabilities(params json.RawMessage) ClientCapabilities {
+	var initParams struct {
+		Capabilities ClientCapabilities `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &initParams); err != nil || initParams.Capabilities == nil {
+		return ClientCapabilities{}
+	}
+	return initParams.Capabilities
+}
+
+// Has 检查客户端是否声明了能力，name 为能力路径，如 "elicitation"、"roots.listChanged"
+// 路径上的值存在且不为 false 或 null 时视为已声明
+func (c ClientCapabilities) Has(name string) bool {
+	var value interface{} = map[string]interface{}(c)
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = object[key]; !ok {
+			return false
+		}
+	}
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	}
+	return true
+}
+
+// Names 返回已声明的顶层能力名称，按名称排序
+func (c ClientCapabilities) Names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		if c.Has(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
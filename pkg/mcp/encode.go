@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// bufferPool 复用响应序列化缓冲区，减少高频工具调用下的内存分配
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// maxPooledBuffer 超过该大小的缓冲区不放回池中，避免个别超大响应长期占用内存
+const maxPooledBuffer = 1 << 20
+
+// MarshalSuccess 直接序列化成功响应，等价于 json.Marshal(NewSuccessResponse(id, result))
+// 但结果只编码一次，不经过中间的 json.RawMessage
+func MarshalSuccess(id interface{}, result interface{}) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			bufferPool.Put(buf)
+		}
+	}()
+
+	buf.WriteString(`{"jsonrpc":"2.0","id":`)
+	encoder := json.NewEncoder(buf)
+	if err := encoder.Encode(id); err != nil {
+		return nil, fmt.Errorf("序列化ID失败: %w", err)
+	}
+	// Encode 会追加换行符
+	buf.Truncate(buf.Len() - 1)
+
+	buf.WriteString(`,"result":`)
+	if err := encoder.Encode(result); err != nil {
+		return nil, fmt.Errorf("序列化结果失败: %w", err)
+	}
+	buf.Truncate(buf.Len() - 1)
+	buf.WriteByte('}')
+
+	// 缓冲区会被复用，返回副本
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+	return data, nil
+}
@@ -21,10 +21,18 @@ type MCPResponse struct {
 	Error   *MCPError       `json:"error,omitempty"`
 }
 
+// MCPNotification 表示MCP通知，通知没有ID
+type MCPNotification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
 // MCPError 表示MCP错误
 type MCPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
 }
 
 // ToolCallParams 表示工具调用参数
@@ -32,6 +40,15 @@ type ToolCallParams struct {
 	Name       string                 `json:"name"`
 	Parameters map[string]interface{} `json:"parameters"`
 	Arguments  map[string]interface{} `json:"arguments"`
+	Meta       map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// ProgressToken 返回客户端请求进度通知时提供的令牌，未提供时返回 nil
+func (p *ToolCallParams) ProgressToken() interface{} {
+	if p.Meta == nil {
+		return nil
+	}
+	return p.Meta["progressToken"]
 }
 
 // ToolCallResult 表示工具调用结果
@@ -39,6 +56,8 @@ type ToolCallResult struct {
 	Type   string      `json:"type"`
 	Status string      `json:"status"`
 	Result interface{} `json:"result"`
+	// Meta 附加在工具调用响应 _meta 中的元数据，如响应模式校验结果
+	Meta map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // GetIDString 获取ID的字符串表示
@@ -146,4 +165,37 @@ func ParseToolCallParams(params json.RawMessage) (*ToolCallParams, error) {
 	}
 	
 	return &toolParams, nil
-}
\ No newline at end of file
+}
+// NewRequest 创建请求，用于服务器主动向客户端发起调用
+func NewRequest(id interface{}, method string, params interface{}) (*MCPRequest, error) {
+	idBytes, err := json.Marshal(id)
+	if err != nil {
+		return nil, fmt.Errorf("序列化ID失败: %w", err)
+	}
+
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("序列化参数失败: %w", err)
+	}
+
+	return &MCPRequest{
+		JSONRPC: "2.0",
+		ID:      idBytes,
+		Method:  method,
+		Params:  paramsBytes,
+	}, nil
+}
+
+// NewNotification 创建通知
+func NewNotification(method string, params interface{}) (*MCPNotification, error) {
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("序列化参数失败: %w", err)
+	}
+
+	return &MCPNotification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  paramsBytes,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package mcp
+
+// 支持的 MCP 协议版本，版本号为发布日期，可以按字符串比较先后
+const (
+	ProtocolVersion20241105 = "2024-11-05"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20250618 = "2025-06-18"
+)
+
+// SupportedProtocolVersions 支持的协议版本，从新到旧排列
+var SupportedProtocolVersions = []string{
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// LatestProtocolVersion 支持的最新协议版本
+const LatestProtocolVersion = ProtocolVersion20250618
+
+// DefaultProtocolVersion 客户端没有发送 initialize 时使用的协议版本
+const DefaultProtocolVersion = ProtocolVersion20241105
+
+// NegotiateProtocolVersion 根据客户端请求的版本选择双方都支持的最高版本：
+// 支持请求的版本时直接使用，请求的版本较新时使用不高于它的最新版本，
+// 请求的版本比所有支持的版本都旧或为空时使用最新版本，由客户端决定是否断开
+func NegotiateProtocolVersion(requested string) string {
+	if requested == "" {
+		return LatestProtocolVersion
+	}
+	for _, version := range SupportedProtocolVersions {
+		if version <= requested {
+			return version
+		}
+	}
+	return LatestProtocolVersion
+}
+
+// ProtocolAtLeast 检查协议版本是否不低于 minimum
+func ProtocolAtLeast(version, minimum string) bool {
+	return version >= minimum
+}
@@ -43,7 +43,7 @@ check_dependencies() {
     
     if [ ! -f "$SERVER_PATH" ]; then
         log_error "服务器可执行文件不存在: $SERVER_PATH"
-        log_info "请先编译项目: go build -o bin/mcp2rest cmd/mcp2rest/main.go"
+        log_info "请先编译项目: go build -o bin/mcp2rest ./cmd/mcp2rest"
         exit 1
     fi
     